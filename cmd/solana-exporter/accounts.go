@@ -0,0 +1,68 @@
+package main
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+type (
+	// AccountWriteTracker infers when tracked accounts were last written to, from changes in their state (balance,
+	// owner or data) between polls. Writes are only detected at poll granularity, and an account not seen changing
+	// since the exporter started is considered last written when it was first polled.
+	AccountWriteTracker struct {
+		writes map[string]accountWrite
+		mu     sync.Mutex
+	}
+
+	accountWrite struct {
+		fingerprint uint64
+		// slot and time are the context slot and time of the poll which first observed the current state
+		slot int64
+		time time.Time
+	}
+)
+
+func NewAccountWriteTracker() *AccountWriteTracker {
+	return &AccountWriteTracker{writes: make(map[string]accountWrite)}
+}
+
+// Observe records the state of an account as read at the provided slot, and returns the slot and time at which its
+// current state was first observed.
+func (t *AccountWriteTracker) Observe(address string, info *rpc.AccountInfo, slot int64, now time.Time) (int64, time.Time) {
+	return t.ObserveFingerprint(address, GetAccountFingerprint(info), slot, now)
+}
+
+// ObserveFingerprint is Observe for a fingerprint of only part of the account's state (e.g., the blockhash of a nonce
+// account), such that only changes to that part are tracked.
+func (t *AccountWriteTracker) ObserveFingerprint(
+	address string, fingerprint uint64, slot int64, now time.Time,
+) (int64, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	write, ok := t.writes[address]
+	if !ok || write.fingerprint != fingerprint {
+		write = accountWrite{fingerprint: fingerprint, slot: slot, time: now}
+		t.writes[address] = write
+	}
+	return write.slot, write.time
+}
+
+// GetAccountFingerprint returns a hash of the mutable state of an account.
+func GetAccountFingerprint(info *rpc.AccountInfo) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(strconv.FormatInt(info.Lamports, 10)))
+	_, _ = hash.Write([]byte(info.Owner))
+	_, _ = hash.Write(info.Data)
+	return hash.Sum64()
+}
+
+// GetStringFingerprint returns a hash of a string.
+func GetStringFingerprint(value string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(value))
+	return hash.Sum64()
+}
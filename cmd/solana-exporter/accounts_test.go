@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountWriteTracker_Observe(t *testing.T) {
+	tracker := NewAccountWriteTracker()
+	start := time.Unix(1_700_000_000, 0)
+	info := rpc.AccountInfo{Lamports: 10, Owner: VoteProgram, Data: json.RawMessage(`{"credits":1}`)}
+
+	slot, writeTime := tracker.Observe("AAA", &info, 100, start)
+	assert.Equal(t, int64(100), slot)
+	assert.Equal(t, start, writeTime)
+
+	// an unchanged account keeps its last write:
+	slot, writeTime = tracker.Observe("AAA", &info, 110, start.Add(time.Minute))
+	assert.Equal(t, int64(100), slot)
+	assert.Equal(t, start, writeTime)
+
+	// whereas data (or balance) changes are writes:
+	info.Data = json.RawMessage(`{"credits":2}`)
+	slot, writeTime = tracker.Observe("AAA", &info, 120, start.Add(2*time.Minute))
+	assert.Equal(t, int64(120), slot)
+	assert.Equal(t, start.Add(2*time.Minute), writeTime)
+
+	info.Lamports = 5
+	slot, _ = tracker.Observe("AAA", &info, 130, start.Add(3*time.Minute))
+	assert.Equal(t, int64(130), slot)
+}
+
+func TestAccountWriteTracker_ObserveFingerprint(t *testing.T) {
+	tracker := NewAccountWriteTracker()
+	start := time.Unix(1_700_000_000, 0)
+
+	// a nonce account is only advanced when its blockhash changes, regardless of its balance:
+	slot, _ := tracker.ObserveFingerprint("NNN", GetStringFingerprint("hash1"), 100, start)
+	assert.Equal(t, int64(100), slot)
+	slot, writeTime := tracker.ObserveFingerprint("NNN", GetStringFingerprint("hash1"), 110, start.Add(time.Minute))
+	assert.Equal(t, int64(100), slot)
+	assert.Equal(t, start, writeTime)
+
+	slot, writeTime = tracker.ObserveFingerprint("NNN", GetStringFingerprint("hash2"), 120, start.Add(2*time.Minute))
+	assert.Equal(t, int64(120), slot)
+	assert.Equal(t, start.Add(2*time.Minute), writeTime)
+}
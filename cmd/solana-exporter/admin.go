@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+type (
+	// AdminServer serves the control and debug endpoints (/healthz, /-/reload, /debug/pprof/, /debug/schema-drift
+	// and /api/status), which are kept off the metrics listener such that metrics can be exposed broadly while these stay private.
+	AdminServer struct {
+		config      *ExporterConfig
+		slotWatcher *SlotWatcher
+		// schemaDrift is nil unless RPC schema drift detection is enabled
+		schemaDrift *rpc.SchemaDrift
+		startTime   time.Time
+		logger      *zap.SugaredLogger
+	}
+
+	// ExporterStatus is the response of the JSON status API.
+	ExporterStatus struct {
+		Version           string   `json:"version"`
+		StartTime         int64    `json:"start_time"`
+		UptimeSeconds     float64  `json:"uptime_seconds"`
+		ListenAddress     string   `json:"listen_address"`
+		NodeKeys          []string `json:"nodekeys"`
+		VoteKeys          []string `json:"votekeys"`
+		ValidatorIdentity string   `json:"validator_identity,omitempty"`
+		VoteAccountPubkey string   `json:"vote_account_pubkey,omitempty"`
+		LightMode         bool     `json:"light_mode"`
+		RpcNodeMode       bool     `json:"rpc_node_mode"`
+		SlotHeight        float64  `json:"slot_height"`
+		EpochNumber       float64  `json:"epoch_number"`
+	}
+)
+
+func NewAdminServer(config *ExporterConfig, slotWatcher *SlotWatcher, schemaDrift *rpc.SchemaDrift) *AdminServer {
+	return &AdminServer{
+		config: config, slotWatcher: slotWatcher, schemaDrift: schemaDrift, startTime: time.Now(), logger: slog.Get(),
+	}
+}
+
+// Handler returns the admin endpoint mux. The pprof handlers are registered on it explicitly, as it is the only mux
+// served which they may be exposed on.
+func (s *AdminServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/-/reload", s.handleReload)
+	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/schema-drift", s.handleSchemaDrift)
+	return mux
+}
+
+func (s *AdminServer) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// handleReload re-reads all secrets, such that rotations can be applied without waiting for the reload interval.
+func (s *AdminServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := ReloadSecrets(s.config.Secrets()...); err != nil {
+		s.logger.Errorf("Failed to reload: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("Reloaded through admin endpoint.")
+	_, _ = w.Write([]byte("reloaded\n"))
+}
+
+func (s *AdminServer) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	finalized := string(rpc.CommitmentFinalized)
+	status := ExporterStatus{
+		Version:           BuildVersion,
+		StartTime:         s.startTime.Unix(),
+		UptimeSeconds:     time.Since(s.startTime).Seconds(),
+		ListenAddress:     s.config.ListenAddress,
+		NodeKeys:          s.config.NodeKeys,
+		VoteKeys:          s.config.VoteKeys,
+		ValidatorIdentity: s.config.ValidatorIdentity,
+		VoteAccountPubkey: s.config.VoteAccountPubkey,
+		LightMode:         s.config.LightMode,
+		RpcNodeMode:       s.config.RpcNodeMode,
+		SlotHeight:        gaugeValue(s.slotWatcher.SlotHeightMetric.WithLabelValues(finalized)),
+		EpochNumber:       gaugeValue(s.slotWatcher.EpochNumberMetric.WithLabelValues(finalized)),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.Errorf("Failed to write status: %v", err)
+	}
+}
+
+// handleSchemaDrift serves the RPC schema drift recorded so far, by method.
+func (s *AdminServer) handleSchemaDrift(w http.ResponseWriter, _ *http.Request) {
+	if s.schemaDrift == nil {
+		http.Error(w, "schema drift detection is disabled, see -rpc-schema-drift", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.schemaDrift.Report()); err != nil {
+		s.logger.Errorf("Failed to write schema drift: %v", err)
+	}
+}
+
+// gaugeValue reads the current value of a gauge.
+func gaugeValue(gauge prometheus.Gauge) float64 {
+	var metric dto.Metric
+	if err := gauge.Write(&metric); err != nil {
+		return 0
+	}
+	return metric.GetGauge().GetValue()
+}
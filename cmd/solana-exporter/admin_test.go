@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminServer(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+	watcher.SlotHeightMetric.WithLabelValues(string(rpc.CommitmentFinalized)).Set(35)
+	handler := NewAdminServer(config, watcher, nil).Handler()
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/healthz").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/-/reload").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/-/reload").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/debug/pprof/").Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/debug/schema-drift").Code)
+
+	response := serve(http.MethodGet, "/api/status")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var status ExporterStatus
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&status))
+	assert.Equal(t, float64(35), status.SlotHeight)
+	assert.Equal(t, simulator.Nodekeys, status.NodeKeys)
+}
+
+func TestAdminServer_SchemaDrift(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	client.SchemaDrift = rpc.NewSchemaDrift()
+	_, err := client.GetEpochInfo(context.Background(), rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	handler := NewAdminServer(config, NewSlotWatcher(client, config, prometheus.NewRegistry()), client.SchemaDrift)
+
+	recorder := httptest.NewRecorder()
+	handler.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/schema-drift", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var report map[string]rpc.SchemaDriftReport
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+	assert.Contains(t, report, "getEpochInfo")
+}
+
+func TestAdminServer_Reload(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+	token, err := NewSecret(SecretFilePrefix + path)
+	assert.NoError(t, err)
+	config.GeyserToken = token
+	handler := NewAdminServer(config, NewSlotWatcher(client, config, prometheus.NewRegistry()), nil).Handler()
+
+	// every secret of the config is reloaded, not just the Grafana ones:
+	assert.NoError(t, os.WriteFile(path, []byte("new"), 0o600))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/-/reload", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "new", config.GeyserToken.Value())
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+const (
+	EventEpochRollover     = "epoch_rollover"
+	EventDelinquencyStart  = "delinquency_start"
+	EventDelinquencyStop   = "delinquency_stop"
+	EventVersionChange     = "version_change"
+	EventIdentitySwap      = "identity_swap"
+	annotationsTag         = "solana-exporter"
+	annotationsApiEndpoint = "/api/annotations"
+)
+
+type (
+	// GrafanaAnnotator forwards detected events (epoch rollovers, delinquency changes, version changes and
+	// identity swaps) to the Grafana annotations API, such that dashboards display event markers out of the box.
+	// A nil *GrafanaAnnotator is valid and discards all events.
+	GrafanaAnnotator struct {
+		url        *Secret
+		token      *Secret
+		httpClient http.Client
+		logger     *zap.SugaredLogger
+
+		// last observed value per tracked key, for change detection:
+		observed map[string]string
+		mu       sync.Mutex
+	}
+
+	// Annotation is the request body of the Grafana annotations API.
+	// See API docs: https://grafana.com/docs/grafana/latest/developers/http_api/annotations/
+	Annotation struct {
+		Time int64    `json:"time"`
+		Tags []string `json:"tags"`
+		Text string   `json:"text"`
+	}
+)
+
+// NewGrafanaAnnotator creates a GrafanaAnnotator from the config, returning nil if no Grafana URL is configured.
+func NewGrafanaAnnotator(config *ExporterConfig) *GrafanaAnnotator {
+	if config.GrafanaUrl.Value() == "" {
+		return nil
+	}
+	return &GrafanaAnnotator{
+		url:        config.GrafanaUrl,
+		token:      config.GrafanaApiToken,
+		httpClient: http.Client{Timeout: config.HttpTimeout},
+		logger:     slog.Get(),
+		observed:   make(map[string]string),
+	}
+}
+
+// ObserveChange records the latest value of a tracked key, returning the previously observed value and whether it
+// changed. The first observation of a key only records a baseline, and is never considered a change.
+func (a *GrafanaAnnotator) ObserveChange(key, value string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous, ok := a.observed[key]
+	a.observed[key] = value
+	return previous, ok && previous != value
+}
+
+// Annotate asynchronously posts an annotation for the event, such that slow Grafana responses never hold up a scrape.
+func (a *GrafanaAnnotator) Annotate(event, text string) {
+	if a == nil {
+		return
+	}
+	annotation := Annotation{Time: time.Now().UnixMilli(), Tags: []string{annotationsTag, event}, Text: text}
+	go func() {
+		if err := a.post(context.Background(), annotation); err != nil {
+			a.logger.Errorf("Failed to post %s annotation to grafana: %v", event, err)
+			return
+		}
+		a.logger.Infof("Posted %s annotation to grafana: %s", event, text)
+	}()
+}
+
+func (a *GrafanaAnnotator) post(ctx context.Context, annotation Annotation) error {
+	body, err := json.Marshal(annotation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal annotation: %w", err)
+	}
+	url := strings.TrimSuffix(a.url.Value(), "/") + annotationsApiEndpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("content-type", "application/json")
+	if token := a.token.Value(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrafanaAnnotator_ObserveChange(t *testing.T) {
+	annotator := NewGrafanaAnnotator(&ExporterConfig{GrafanaUrl: NewStaticSecret("http://localhost:3000")})
+
+	// the first observation is only a baseline:
+	_, changed := annotator.ObserveChange(VersionLabel, "v1.18.23")
+	assert.False(t, changed)
+	_, changed = annotator.ObserveChange(VersionLabel, "v1.18.23")
+	assert.False(t, changed)
+	previous, changed := annotator.ObserveChange(VersionLabel, "v2.0.1")
+	assert.True(t, changed)
+	assert.Equal(t, "v1.18.23", previous)
+
+	// a nil annotator discards everything:
+	var disabled *GrafanaAnnotator
+	_, changed = disabled.ObserveChange(VersionLabel, "v2.0.1")
+	assert.False(t, changed)
+	disabled.Annotate(EventVersionChange, "ignored")
+	assert.Nil(t, NewGrafanaAnnotator(&ExporterConfig{}))
+}
+
+func TestGrafanaAnnotator_post(t *testing.T) {
+	var (
+		received      Annotation
+		authorization string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, annotationsApiEndpoint, r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	annotator := NewGrafanaAnnotator(&ExporterConfig{
+		GrafanaUrl:      NewStaticSecret(server.URL),
+		GrafanaApiToken: NewStaticSecret("token"),
+		HttpTimeout:     time.Second,
+	})
+	annotation := Annotation{Time: 1, Tags: []string{annotationsTag, EventEpochRollover}, Text: "Epoch 2 started"}
+	assert.NoError(t, annotator.post(context.Background(), annotation))
+	assert.Equal(t, annotation, received)
+	assert.Equal(t, "Bearer token", authorization)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"github.com/seedfourtytwo/solana-exporter/pkg/solana"
+	_ "modernc.org/sqlite"
+)
+
+// BackfillCommand is the subcommand which replays historical epochs from an archive RPC into long-term storage.
+const BackfillCommand = "backfill"
+
+// epochsTableSchema creates the table of the SQL epoch store, in SQL which both SQLite and Postgres understand. Each
+// validator has a row per epoch, keyed by its votekey. The leader slots are a JSON array, or NULL if unknown.
+const epochsTableSchema = `CREATE TABLE IF NOT EXISTS solana_epochs (
+	epoch             BIGINT NOT NULL,
+	first_slot        BIGINT NOT NULL,
+	last_slot         BIGINT NOT NULL,
+	nodekey           TEXT NOT NULL,
+	votekey           TEXT NOT NULL,
+	leader_slots      TEXT,
+	leader_slot_count BIGINT NOT NULL,
+	blocks_produced   BIGINT NOT NULL,
+	inflation_reward  DOUBLE PRECISION NOT NULL,
+	credits           BIGINT NOT NULL,
+	PRIMARY KEY (epoch, votekey)
+)`
+
+// epochsTableUpsert writes a row of the SQL epoch store, replacing the row of a previous backfill of the same epoch.
+const epochsTableUpsert = `INSERT INTO solana_epochs (
+	epoch, first_slot, last_slot, nodekey, votekey, leader_slots, leader_slot_count, blocks_produced,
+	inflation_reward, credits
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (epoch, votekey) DO UPDATE SET
+	first_slot = excluded.first_slot, last_slot = excluded.last_slot, nodekey = excluded.nodekey,
+	leader_slots = excluded.leader_slots, leader_slot_count = excluded.leader_slot_count,
+	blocks_produced = excluded.blocks_produced, inflation_reward = excluded.inflation_reward,
+	credits = excluded.credits`
+
+type (
+	// EpochRecord is the per-validator summary of a (historical) epoch.
+	EpochRecord struct {
+		Epoch     int64  `json:"epoch"`
+		FirstSlot int64  `json:"first_slot"`
+		LastSlot  int64  `json:"last_slot"`
+		Nodekey   string `json:"nodekey"`
+		Votekey   string `json:"votekey"`
+		// LeaderSlots are the absolute leader slots assigned to the validator, if the RPC still serves the epoch's
+		// leader schedule (which nodes only keep for recent epochs)
+		LeaderSlots []int64 `json:"leader_slots"`
+		// LeaderSlotCount is the number of leader slots as per the block production, which is always known
+		LeaderSlotCount int64 `json:"leader_slot_count"`
+		BlocksProduced  int64 `json:"blocks_produced"`
+		// InflationReward is the reward (in SOL) of the votekey for the epoch
+		InflationReward float64 `json:"inflation_reward"`
+		// Credits are the vote credits earned in the epoch, unknown (-1) once it has dropped out of the vote
+		// account's epoch credits history
+		Credits int64 `json:"credits"`
+	}
+
+	// EpochStore persists epoch records. The backfill writes through this interface, such that records can be
+	// loaded into whichever long-term store is used for retroactive analysis.
+	EpochStore interface {
+		WriteEpoch(record EpochRecord) error
+		Close() error
+	}
+
+	// JSONLinesEpochStore writes each epoch record as a single JSON line.
+	JSONLinesEpochStore struct {
+		writer  io.WriteCloser
+		encoder *json.Encoder
+	}
+
+	// SQLEpochStore writes each epoch record as a row of the solana_epochs table of an SQLite or Postgres database,
+	// such that backfills can be resumed or repeated without duplicating rows.
+	SQLEpochStore struct {
+		db *sql.DB
+	}
+
+	// Backfiller fetches historical epochs through the same RPC methods (and helpers) as live collection.
+	Backfiller struct {
+		client   *rpc.Client
+		nodekeys []string
+		votekeys []string
+	}
+)
+
+func NewJSONLinesEpochStore(writer io.WriteCloser) *JSONLinesEpochStore {
+	return &JSONLinesEpochStore{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+func (s *JSONLinesEpochStore) WriteEpoch(record EpochRecord) error {
+	return s.encoder.Encode(record)
+}
+
+func (s *JSONLinesEpochStore) Close() error {
+	return s.writer.Close()
+}
+
+// OpenSQLEpochStore connects to the database of the DSN, which is either "sqlite://<path>" or a Postgres URL
+// ("postgres://..."), creating the solana_epochs table if it does not exist yet.
+func OpenSQLEpochStore(ctx context.Context, dsn string) (*SQLEpochStore, error) {
+	var driver, source string
+	switch {
+	case strings.HasPrefix(dsn, "sqlite://"):
+		driver, source = "sqlite", strings.TrimPrefix(dsn, "sqlite://")
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		driver, source = "postgres", dsn
+	default:
+		return nil, fmt.Errorf("unsupported epoch store %q, must be 'sqlite://' or 'postgres://'", rpc.RedactUrl(dsn))
+	}
+	db, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open epoch store: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, epochsTableSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create epochs table: %w", err)
+	}
+	return &SQLEpochStore{db: db}, nil
+}
+
+func (s *SQLEpochStore) WriteEpoch(record EpochRecord) error {
+	var leaderSlots sql.NullString
+	if record.LeaderSlots != nil {
+		encoded, err := json.Marshal(record.LeaderSlots)
+		if err != nil {
+			return fmt.Errorf("failed to encode leader slots: %w", err)
+		}
+		leaderSlots = sql.NullString{String: string(encoded), Valid: true}
+	}
+	_, err := s.db.Exec(
+		epochsTableUpsert, record.Epoch, record.FirstSlot, record.LastSlot, record.Nodekey, record.Votekey,
+		leaderSlots, record.LeaderSlotCount, record.BlocksProduced, record.InflationReward, record.Credits,
+	)
+	return err
+}
+
+func (s *SQLEpochStore) Close() error {
+	return s.db.Close()
+}
+
+func NewBackfiller(client *rpc.Client, nodekeys, votekeys []string) *Backfiller {
+	return &Backfiller{client: client, nodekeys: nodekeys, votekeys: votekeys}
+}
+
+// Backfill writes a record per validator for every completed epoch in [fromEpoch, toEpoch].
+func (b *Backfiller) Backfill(ctx context.Context, store EpochStore, fromEpoch, toEpoch int64) error {
+	logger := slog.Get()
+	current, err := b.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	if toEpoch >= current.Epoch {
+		return fmt.Errorf("epoch %d has not completed yet, the current epoch is %d", toEpoch, current.Epoch)
+	}
+	voteAccounts, err := b.client.GetVoteAccounts(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("failed to get vote accounts: %w", err)
+	}
+	// past epochs need not be as long as the current one (e.g., warmup epochs), so they are laid out by the schedule:
+	epochSchedule, err := b.client.GetEpochSchedule(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get epoch schedule: %w", err)
+	}
+	schedule := solana.NewEpochScheduleFromRpc(epochSchedule)
+
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		logger.Infof("Backfilling epoch %d ...", epoch)
+		records, err := b.fetchEpoch(ctx, schedule, voteAccounts, epoch)
+		if err != nil {
+			return fmt.Errorf("failed to backfill epoch %d: %w", epoch, err)
+		}
+		for _, record := range records {
+			if err := store.WriteEpoch(record); err != nil {
+				return fmt.Errorf("failed to store epoch %d: %w", epoch, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Backfiller) fetchEpoch(
+	ctx context.Context, schedule solana.EpochSchedule, voteAccounts *rpc.VoteAccounts, epoch int64,
+) ([]EpochRecord, error) {
+	firstSlot, lastSlot := schedule.EpochBounds(epoch)
+	leaderSchedule, err := GetTrimmedLeaderSchedule(ctx, b.client, b.nodekeys, firstSlot, firstSlot)
+	if err != nil {
+		return nil, err
+	}
+	blockProduction, err := b.client.GetBlockProduction(ctx, rpc.CommitmentFinalized, firstSlot, lastSlot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block production: %w", err)
+	}
+	rewards, err := b.client.GetInflationReward(ctx, rpc.CommitmentFinalized, b.votekeys, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inflation rewards: %w", err)
+	}
+
+	records := make([]EpochRecord, len(b.nodekeys))
+	for i, nodekey := range b.nodekeys {
+		production := blockProduction.ByIdentity[nodekey]
+		if production.LeaderSlots > 0 && len(leaderSchedule[nodekey]) == 0 {
+			slog.Get().Warnf(
+				"Leader schedule of epoch %d is not available, only recording the leader slot count of %s",
+				epoch, nodekey,
+			)
+		}
+		records[i] = EpochRecord{
+			Epoch:           epoch,
+			FirstSlot:       firstSlot,
+			LastSlot:        lastSlot,
+			Nodekey:         nodekey,
+			Votekey:         b.votekeys[i],
+			LeaderSlots:     leaderSchedule[nodekey],
+			LeaderSlotCount: production.LeaderSlots,
+			BlocksProduced:  production.BlocksProduced,
+			Credits:         getEpochCredits(voteAccounts, b.votekeys[i], epoch),
+		}
+		if i < len(rewards) {
+			records[i].InflationReward = float64(rewards[i].Amount) / rpc.LamportsInSol
+		}
+	}
+	return records, nil
+}
+
+// getEpochCredits returns the credits the votekey earned in the epoch, or -1 if they are not in its history.
+func getEpochCredits(voteAccounts *rpc.VoteAccounts, votekey string, epoch int64) int64 {
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if account.VotePubkey != votekey {
+			continue
+		}
+		for _, entry := range account.EpochCredits {
+			if len(entry) == 3 && entry[0] == epoch {
+				return entry[1] - entry[2]
+			}
+		}
+	}
+	return -1
+}
+
+// RunBackfill parses the backfill subcommand args and backfills the requested epochs.
+func RunBackfill(ctx context.Context, args []string) error {
+	var (
+		rpcUrl     string
+		nodekeys   arrayFlags
+		fromEpoch  int64
+		toEpoch    int64
+		outputPath string
+		storeDsn   string
+		timeout    int
+	)
+	flags := flag.NewFlagSet(BackfillCommand, flag.ExitOnError)
+	flags.StringVar(&rpcUrl, "rpc-url", "", "Archive Solana RPC URL (including protocol and path).")
+	flags.Var(&nodekeys, "nodekey", "Solana nodekey (identity account) of a validator to backfill - can set multiple.")
+	flags.Int64Var(&fromEpoch, "from-epoch", 0, "First epoch [inclusive] to backfill.")
+	flags.Int64Var(&toEpoch, "to-epoch", 0, "Last epoch [inclusive] to backfill.")
+	flags.StringVar(&outputPath, "output", "", "File to append the epoch records to, defaults to stdout.")
+	flags.StringVar(
+		&storeDsn,
+		"store",
+		"",
+		"Database to write the epoch records to instead, either 'sqlite://<path>' or a 'postgres://' URL. Can be "+
+			"read from a file or env var with 'file:' or 'env:'.",
+	)
+	flags.IntVar(&timeout, "http-timeout", 60, "HTTP timeout to use, in seconds.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if rpcUrl == "" || len(nodekeys) == 0 {
+		return fmt.Errorf("-rpc-url and at least one -nodekey are required")
+	}
+	if outputPath != "" && storeDsn != "" {
+		return fmt.Errorf("only one of -output and -store can be set")
+	}
+	if fromEpoch > toEpoch {
+		return fmt.Errorf("-from-epoch %d is after -to-epoch %d", fromEpoch, toEpoch)
+	}
+	rpcUrl, err := ResolveSecret(rpcUrl)
+	if err != nil {
+		return fmt.Errorf("failed to resolve rpc url: %w", err)
+	}
+
+	client := rpc.NewRPCClient(rpcUrl, time.Duration(timeout)*time.Second, nil)
+	client.Retry = rpc.DefaultRetryPolicy
+	votekeys, err := GetAssociatedVoteAccounts(ctx, client, rpc.CommitmentFinalized, nodekeys)
+	if err != nil {
+		return fmt.Errorf("failed to get associated vote accounts for %v: %w", nodekeys, err)
+	}
+
+	store, err := openEpochStore(ctx, outputPath, storeDsn)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer store.Close()
+	return NewBackfiller(client, nodekeys, votekeys).Backfill(ctx, store, fromEpoch, toEpoch)
+}
+
+// openEpochStore opens the SQL store of the DSN (if set), or else appends JSON lines to the output file (or stdout).
+func openEpochStore(ctx context.Context, outputPath, storeDsn string) (EpochStore, error) {
+	if storeDsn != "" {
+		storeDsn, err := ResolveSecret(storeDsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve store: %w", err)
+		}
+		return OpenSQLEpochStore(ctx, storeDsn)
+	}
+	writer := io.WriteCloser(nopWriteCloser{os.Stdout})
+	if outputPath != "" {
+		file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", outputPath, err)
+		}
+		writer = file
+	}
+	return NewJSONLinesEpochStore(writer), nil
+}
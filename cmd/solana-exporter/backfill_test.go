@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryEpochStore struct {
+	records []EpochRecord
+}
+
+func (s *memoryEpochStore) WriteEpoch(record EpochRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryEpochStore) Close() error { return nil }
+
+func TestBackfiller_Backfill(t *testing.T) {
+	simulator, client := NewSimulator(t, 60)
+	store := &memoryEpochStore{}
+	backfiller := NewBackfiller(client, simulator.Nodekeys, simulator.Votekeys)
+	assert.NoError(t, backfiller.Backfill(context.Background(), store, 0, 1))
+
+	assert.Len(t, store.records, 2*len(simulator.Nodekeys))
+	record := store.records[len(simulator.Nodekeys)]
+	assert.Equal(t, int64(1), record.Epoch)
+	assert.Equal(t, int64(24), record.FirstSlot)
+	assert.Equal(t, int64(47), record.LastSlot)
+	assert.Equal(t, "aaa", record.Nodekey)
+	assert.Equal(t, "AAA", record.Votekey)
+	assert.Equal(t, []int64{24, 25, 26, 27, 36, 37, 38, 39}, record.LeaderSlots)
+	assert.Equal(t, int64(8), record.LeaderSlotCount)
+	// every 4th slot is skipped:
+	assert.Equal(t, int64(6), record.BlocksProduced)
+	assert.Equal(t, float64(simulator.InflationRewardLamports)/rpc.LamportsInSol, record.InflationReward)
+
+	// the current epoch cannot be backfilled:
+	assert.Error(t, backfiller.Backfill(context.Background(), store, 1, 2))
+}
+
+func TestBackfiller_Backfill_noLeaderSchedule(t *testing.T) {
+	simulator, client := NewSimulator(t, 60)
+	// the leader schedules of old epochs are not served:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getLeaderSchedule", nil)
+	store := &memoryEpochStore{}
+	backfiller := NewBackfiller(client, simulator.Nodekeys, simulator.Votekeys)
+	assert.NoError(t, backfiller.Backfill(context.Background(), store, 1, 1))
+
+	record := store.records[0]
+	assert.Nil(t, record.LeaderSlots)
+	// the leader slots are still counted from the block production:
+	assert.Equal(t, int64(8), record.LeaderSlotCount)
+	assert.Equal(t, int64(6), record.BlocksProduced)
+}
+
+func TestJSONLinesEpochStore(t *testing.T) {
+	var buffer bytes.Buffer
+	store := NewJSONLinesEpochStore(nopWriteCloser{&buffer})
+	assert.NoError(t, store.WriteEpoch(EpochRecord{Epoch: 5, Nodekey: "aaa", Credits: -1}))
+	assert.NoError(t, store.Close())
+
+	var record EpochRecord
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, EpochRecord{Epoch: 5, Nodekey: "aaa", Credits: -1}, record)
+}
+
+func TestSQLEpochStore(t *testing.T) {
+	ctx := context.Background()
+	_, err := OpenSQLEpochStore(ctx, "mysql://localhost/epochs")
+	assert.Error(t, err)
+
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "epochs.db")
+	store, err := OpenSQLEpochStore(ctx, dsn)
+	assert.NoError(t, err)
+	record := EpochRecord{Epoch: 5, Nodekey: "aaa", Votekey: "AAA", LeaderSlotCount: 8, BlocksProduced: 6, Credits: -1}
+	assert.NoError(t, store.WriteEpoch(record))
+	assert.NoError(t, store.WriteEpoch(EpochRecord{Epoch: 5, Nodekey: "bbb", Votekey: "BBB", Credits: -1}))
+	// backfilling an epoch again replaces its rows:
+	record.LeaderSlots, record.InflationReward = []int64{24, 25}, 1.5
+	assert.NoError(t, store.WriteEpoch(record))
+	assert.NoError(t, store.Close())
+
+	// the table is reused once created:
+	store, err = OpenSQLEpochStore(ctx, dsn)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	var count int
+	assert.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM solana_epochs").Scan(&count))
+	assert.Equal(t, 2, count)
+
+	var (
+		leaderSlots     string
+		leaderSlotCount int64
+		reward          float64
+	)
+	row := store.db.QueryRow(
+		"SELECT leader_slots, leader_slot_count, inflation_reward FROM solana_epochs WHERE epoch = 5 AND votekey = 'AAA'",
+	)
+	assert.NoError(t, row.Scan(&leaderSlots, &leaderSlotCount, &reward))
+	assert.Equal(t, "[24,25]", leaderSlots)
+	assert.Equal(t, int64(8), leaderSlotCount)
+	assert.Equal(t, 1.5, reward)
+	// unknown leader slots are stored as NULL:
+	var unknown *string
+	assert.NoError(t, store.db.QueryRow("SELECT leader_slots FROM solana_epochs WHERE votekey = 'BBB'").Scan(&unknown))
+	assert.Nil(t, unknown)
+}
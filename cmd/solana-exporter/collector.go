@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/seedfourtytwo/solana-exporter/pkg/api"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"github.com/seedfourtytwo/solana-exporter/pkg/solana"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"slices"
@@ -22,19 +26,67 @@ const (
 	AddressLabel         = "address"
 	EpochLabel           = "epoch"
 	TransactionTypeLabel = "transaction_type"
+	CommitmentLabel      = "commitment"
+	PositionLabel        = "position"
+	TenantLabel          = "tenant"
+	AuthorizedVoterLabel = "authorized_voter"
+	CollectorLabel       = "collector"
+	QuantileLabel        = "quantile"
+	MethodLabel          = "method"
+	LeaderLabel          = "leader"
+	ResultLabel          = "result"
+	MetricLabel          = "metric"
+	AuthorityLabel       = "authority"
+	ServiceLabel         = "service"
+	OwnerLabel           = "owner"
+	MintLabel            = "mint"
+	SignatureLabel       = "signature"
+	TopPercentLabel      = "top_percent"
+	DirectionLabel       = "direction"
+	ClientLabel          = "client"
+	ClusterLabel         = "cluster"
+	NameLabel            = "name"
+	PurposeLabel         = "purpose"
 
 	StatusSkipped = "skipped"
 	StatusValid   = "valid"
 
 	StateCurrent    = "current"
 	StateDelinquent = "delinquent"
+	StateHealthy    = "healthy"
+	StateUnhealthy  = "unhealthy"
+
+	BlockResultFetched      = "fetched"
+	BlockResultSkipped      = "skipped"
+	BlockResultNotAvailable = "not_available"
+	BlockResultPruned       = "pruned"
+	BlockResultFailed       = "failed"
+
+	DirectionAbove    = "above"
+	DirectionBelow    = "below"
+	DirectionIncrease = "increase"
+	DirectionDecrease = "decrease"
+
+	ClientAgave      = "agave"
+	ClientFiredancer = "firedancer"
+
+	PurposeIdentity = "identity"
+	PurposeVote     = "vote"
 
 	TransactionTypeVote    = "vote"
 	TransactionTypeNonVote = "non_vote"
+
+	// latestBlockhashKey is the key the node's latest blockhash is tracked under
+	latestBlockhashKey = "latest_blockhash"
+	// performanceSamplesLimit is the number of (minutely) performance samples slot durations and transaction rates
+	// are averaged over
+	performanceSamplesLimit = 10
 )
 
 type SolanaCollector struct {
 	rpcClient *rpc.Client
+	// clusterClient serves the cluster-wide calls, which is the reference RPC if configured
+	clusterClient *rpc.Client
 	logger    *zap.SugaredLogger
 
 	config *ExporterConfig
@@ -42,6 +94,15 @@ type SolanaCollector struct {
 	/// descriptors:
 	ValidatorActiveStake    *GaugeDesc
 	ClusterActiveStake      *GaugeDesc
+	ClusterTotalSupply       *GaugeDesc
+	ClusterCirculatingSupply *GaugeDesc
+	ClusterInflationInitial        *GaugeDesc
+	ClusterInflationTerminal       *GaugeDesc
+	ClusterInflationTaper          *GaugeDesc
+	ClusterInflationFoundation     *GaugeDesc
+	ClusterInflationFoundationTerm *GaugeDesc
+	ClusterSlotsPerEpoch            *GaugeDesc
+	ClusterLeaderScheduleSlotOffset *GaugeDesc
 	ValidatorLastVote       *GaugeDesc
 	ClusterLastVote         *GaugeDesc
 	ValidatorRootSlot       *GaugeDesc
@@ -49,29 +110,151 @@ type SolanaCollector struct {
 	ValidatorDelinquent     *GaugeDesc
 	ClusterValidatorCount   *GaugeDesc
 	AccountBalances         *GaugeDesc
+	AccountBalanceBelowThreshold *GaugeDesc
 	NodeVersion             *GaugeDesc
+	NodeMinRequiredVersion  *GaugeDesc
+	NodeVersionOutdated     *GaugeDesc
 	NodeIsHealthy           *GaugeDesc
 	NodeNumSlotsBehind      *GaugeDesc
 	NodeMinimumLedgerSlot   *GaugeDesc
 	NodeFirstAvailableBlock *GaugeDesc
+	// NodeLedgerRetainedSlots and NodeLedgerRetentionHours are how much history the node's ledger retains
+	NodeLedgerRetainedSlots  *GaugeDesc
+	NodeLedgerRetentionHours *GaugeDesc
+	// NodeMaxRetransmitSlot and NodeMaxShredInsertSlot are the node's turbine and blockstore cursors
+	NodeMaxRetransmitSlot   *GaugeDesc
+	NodeMaxShredInsertSlot  *GaugeDesc
 	NodeIdentity            *GaugeDesc
 	NodeIsActive            *GaugeDesc
 	ValidatorCurrentEpochCredits *GaugeDesc
 	ValidatorTotalCredits *GaugeDesc
 	ValidatorCommission *GaugeDesc
+	ValidatorCommissionLastChangeEpoch *GaugeDesc
+	ValidatorCommissionLastChangeSlot  *GaugeDesc
+	ValidatorCommissionPercentile *GaugeDesc
+	ValidatorStakeRank            *GaugeDesc
+	ValidatorStakeGap             *GaugeDesc
+	ValidatorLastVoteAge          *GaugeDesc
+	ClusterMeanCommission *GaugeDesc
+	ClusterMedianCommission *GaugeDesc
 	ValidatorVoteDistance *GaugeDesc
 	ValidatorRootDistance *GaugeDesc
+	ValidatorVoteLagAlert *GaugeDesc
+	ValidatorIdentityMismatch *GaugeDesc
+	NodeClockDrift *GaugeDesc
+	// NodeBlockTime and NodeBlockTimeLag are of the node's latest finalized block
+	NodeBlockTime    *GaugeDesc
+	NodeBlockTimeLag *GaugeDesc
+	// NodeBlockhashLastValidBlockHeight and NodeBlockhashUnchangedSeconds are of the node's latest blockhash
+	NodeBlockhashLastValidBlockHeight *GaugeDesc
+	NodeBlockhashUnchangedSeconds     *GaugeDesc
+	AccountRentExempt *GaugeDesc
+	AccountRentExemptMargin *GaugeDesc
+	AccountLastWriteSlot *GaugeDesc
+	AccountUnchangedSeconds *GaugeDesc
+	NonceAccountBalance     *GaugeDesc
+	TokenAccountBalance     *GaugeDesc
+	AccountRecentTransactions *GaugeDesc
+	AccountLastActivitySlot   *GaugeDesc
+	TransactionConfirmed      *GaugeDesc
+	TransactionSlot           *GaugeDesc
+	TransactionFailed         *GaugeDesc
+	NonceAccountAuthority   *GaugeDesc
+	NonceLastAdvanceSlot    *GaugeDesc
+	NonceUnadvancedSeconds  *GaugeDesc
+	CollectorRpcCalls *GaugeDesc
+	CollectorRpcResponseBytes *GaugeDesc
+	FastMetricAge             *GaugeDesc
+	CollectorStaleness        *GaugeDesc
+	ClusterSlotTimestampDrift *GaugeDesc
+	NodeEpochProgress         *GaugeDesc
+	NodeEpochSlotsRemaining   *GaugeDesc
+	NodeEpochEndTimestamp     *GaugeDesc
+	NodeAverageSlotDuration   *GaugeDesc
+	ClusterTransactionRate    *GaugeDesc
+	ValidatorAuthorizedVoter *GaugeDesc
+	ValidatorVoterRotationPending *GaugeDesc
+	ValidatorVoterRotationApplied *GaugeDesc
+	NodeGossipPeers *GaugeDesc
+	NodeGossipVisibleStake *GaugeDesc
+	NodeGossipPeersByVersion *GaugeDesc
+	NodeGossipIdentityPresent *GaugeDesc
+	NodeGossipAdvertisedPort *GaugeDesc
+	ClusterCurrentLeader   *GaugeDesc
+	ClusterNextLeader      *GaugeDesc
+	ValidatorIsLeader      *GaugeDesc
+	NodeRpcMethodLatency *GaugeDesc
+	NodeSlotsBehindReference *GaugeDesc
+	NodeBlockHeightBehindReference *GaugeDesc
+	NodeAccountIndexHealthy *GaugeDesc
+	NodeHealthLastTransition *GaugeDesc
+	ValidatorDelinquencyLastTransition *GaugeDesc
+
+	// time spent in each state, observed on transitions out of it:
+	NodeHealthStateDuration           *prometheus.HistogramVec
+	ValidatorDelinquencyStateDuration *prometheus.HistogramVec
+
+	// changes observed between collections:
+	ValidatorCommissionChanges *prometheus.CounterVec
+	NodeIdentityChanges        prometheus.Counter
+	NodeIdentityLastChange     *GaugeDesc
+
+	annotator *GrafanaAnnotator
+
+	// the latest authorized voter rotation scheduled per votekey, to check whether it took effect once its epoch starts:
+	scheduledVoters   map[string]rpc.AuthorizedVoter
+	scheduledVotersMu sync.Mutex
+
+	// epochSchedule is fetched once, as it is fixed at genesis
+	epochSchedule   *rpc.EpochSchedule
+	epochScheduleMu sync.Mutex
+
+	// apiClient fetches the minimum required version of the cluster (if -min-required-version is set)
+	apiClient *api.Client
+	// cluster is detected once (from the genesis hash), as it never changes
+	cluster   string
+	clusterMu sync.Mutex
+
+	accountWrites *AccountWriteTracker
+	// blockhashChanges tracks the latest blockhash of the node (under latestBlockhashKey)
+	blockhashChanges *AccountWriteTracker
+	// nonceAdvances tracks the blockhash of the nonce accounts, which changes whenever they are advanced
+	nonceAdvances *AccountWriteTracker
+	// signatures tracks the confirmation of the watched signatures
+	signatures *SignatureTracker
+	// geyserBalances are the balances received through Yellowstone gRPC, which need not be polled (if -geyser-url is set)
+	geyserBalances *GeyserBalances
+	// validatorNames are the names validator metrics are labelled with (if -validator-names or
+	// -validator-info-interval is set)
+	validatorNames *ValidatorNames
+	// errorTolerance serves the last-known-good metrics of collectors failing within their tolerance
+	errorTolerance *ErrorTolerance
+
+	// the health, delinquency and identity states, keyed by "health", "delinquent/<nodekey>" and "identity":
+	transitions *StateTracker
+	// commissions are the commissions of the tracked validators, for their changes
+	commissions *CommissionTracker
 	
-	// Channel for fast metrics collection
-	fastMetricsCh chan prometheus.Metric
+	lagAlert *LagAlert
+
+	// keyedDescs are the descriptors keyed by a nodekey, votekey, identity or address, which are labelled with the
+	// tenant and validator name of the key (if configured)
+	keyedDescs []*GaugeDesc
+
+	// the latest samples of the fast metrics collection
+	fastMetrics        *FastMetricsCache
 	stopFastCollection chan struct{}
 }
 
-func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCollector {
+// NewSolanaCollector creates the collector of the node and validator metrics. The collector itself is left to the
+// caller to register, the registerer is only used for the metrics of the RPC clients it creates.
+func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig, registerer prometheus.Registerer) *SolanaCollector {
 	collector := &SolanaCollector{
-		rpcClient: rpcClient,
-		logger:    slog.Get(),
-		config:    config,
+		rpcClient:     rpcClient,
+		clusterClient: NewClusterClient(rpcClient, config, registerer),
+		logger:        slog.Get(),
+		config:        config,
+		annotator:     NewGrafanaAnnotator(config),
 		ValidatorActiveStake: NewGaugeDesc(
 			"solana_validator_active_stake",
 			fmt.Sprintf("Active stake (in SOL) per validator (represented by %s and %s)", VotekeyLabel, NodekeyLabel),
@@ -81,6 +264,42 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			"solana_cluster_active_stake",
 			"Total active stake (in SOL) of the cluster",
 		),
+		ClusterTotalSupply: NewGaugeDesc(
+			"solana_cluster_total_supply_sol",
+			"Total supply (in SOL) of the cluster",
+		),
+		ClusterCirculatingSupply: NewGaugeDesc(
+			"solana_cluster_circulating_supply_sol",
+			"Circulating supply (in SOL) of the cluster",
+		),
+		ClusterInflationInitial: NewGaugeDesc(
+			"solana_cluster_inflation_initial",
+			"Initial inflation rate (as a fraction per year) of the cluster's inflation governor",
+		),
+		ClusterInflationTerminal: NewGaugeDesc(
+			"solana_cluster_inflation_terminal",
+			"Terminal inflation rate (as a fraction per year) of the cluster's inflation governor",
+		),
+		ClusterInflationTaper: NewGaugeDesc(
+			"solana_cluster_inflation_taper",
+			"Rate per year at which the inflation rate is lowered towards the terminal rate",
+		),
+		ClusterInflationFoundation: NewGaugeDesc(
+			"solana_cluster_inflation_foundation",
+			"Share of the total inflation allocated to the foundation",
+		),
+		ClusterInflationFoundationTerm: NewGaugeDesc(
+			"solana_cluster_inflation_foundation_term_years",
+			"Duration (in years) of the foundation pool inflation",
+		),
+		ClusterSlotsPerEpoch: NewGaugeDesc(
+			"solana_cluster_slots_per_epoch",
+			"Number of slots per epoch of the cluster, after the warmup epochs (if any)",
+		),
+		ClusterLeaderScheduleSlotOffset: NewGaugeDesc(
+			"solana_cluster_leader_schedule_slot_offset",
+			"Number of slots before an epoch that its leader schedule is computed",
+		),
 		ValidatorLastVote: NewGaugeDesc(
 			"solana_validator_last_vote",
 			fmt.Sprintf("Last voted-on slot per validator (represented by %s and %s)", VotekeyLabel, NodekeyLabel),
@@ -117,10 +336,30 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			fmt.Sprintf("Solana account balances, grouped by %s", AddressLabel),
 			AddressLabel,
 		),
+		AccountBalanceBelowThreshold: NewGaugeDesc(
+			"solana_account_balance_below_threshold",
+			fmt.Sprintf(
+				"Whether the balance of an account (represented by %s) is below the threshold of its %s (%s or %s)",
+				AddressLabel, PurposeLabel, PurposeIdentity, PurposeVote,
+			),
+			AddressLabel, PurposeLabel,
+		),
 		NodeVersion: NewGaugeDesc(
 			"solana_node_version",
-			"Node version of solana",
-			VersionLabel,
+			fmt.Sprintf(
+				"Node version of solana, and the %s (%s or %s) it is detected to run",
+				ClientLabel, ClientAgave, ClientFiredancer,
+			),
+			VersionLabel, ClientLabel,
+		),
+		NodeMinRequiredVersion: NewGaugeDesc(
+			"solana_node_min_required_version_info",
+			fmt.Sprintf("Minimum version SFDP participants of the %s are required to run", ClusterLabel),
+			VersionLabel, ClusterLabel,
+		),
+		NodeVersionOutdated: NewGaugeDesc(
+			"solana_node_version_outdated",
+			"Whether the node's (Agave) version is older than the minimum version SFDP participants are required to run",
 		),
 		NodeIdentity: NewGaugeDesc(
 			"solana_node_identity",
@@ -143,6 +382,23 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			"solana_node_first_available_block",
 			"The slot of the lowest confirmed block that has not been purged from the node's ledger.",
 		),
+		NodeLedgerRetainedSlots: NewGaugeDesc(
+			"solana_node_ledger_retained_slots",
+			"The number of slots of history in the node's ledger, from its lowest slot to its finalized slot.",
+		),
+		NodeLedgerRetentionHours: NewGaugeDesc(
+			"solana_node_ledger_retention_hours",
+			"The estimated time (in hours) of history in the node's ledger, from the block time of its lowest "+
+				"confirmed block to now.",
+		),
+		NodeMaxRetransmitSlot: NewGaugeDesc(
+			"solana_node_max_retransmit_slot",
+			"The highest slot the node has received shreds of from turbine (the retransmit stage).",
+		),
+		NodeMaxShredInsertSlot: NewGaugeDesc(
+			"solana_node_max_shred_insert_slot",
+			"The highest slot the node has inserted shreds of into its blockstore.",
+		),
 		NodeIsActive: NewGaugeDesc(
 			"solana_node_is_active",
 			fmt.Sprintf("Whether the node is active and participating in consensus (using %s pubkey)", IdentityLabel),
@@ -163,6 +419,55 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			"Validator commission percentage rate (0-100)",
 			NodekeyLabel,
 		),
+		ValidatorCommissionPercentile: NewGaugeDesc(
+			"solana_validator_commission_percentile",
+			"Percentile rank (0-100) of the validator's commission amongst all validators in the cluster",
+			NodekeyLabel,
+		),
+		ValidatorCommissionLastChangeEpoch: NewGaugeDesc(
+			"solana_validator_commission_last_change_epoch",
+			"Epoch the last change of the validator's commission was observed in, since the exporter started",
+			NodekeyLabel,
+		),
+		ValidatorCommissionLastChangeSlot: NewGaugeDesc(
+			"solana_validator_commission_last_change_slot",
+			"Slot the last change of the validator's commission was observed at, since the exporter started",
+			NodekeyLabel,
+		),
+		ValidatorStakeRank: NewGaugeDesc(
+			"solana_validator_stake_rank",
+			fmt.Sprintf(
+				"Rank of the validator (represented by %s and %s) by active stake in the cluster, 1 being the largest",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel,
+		),
+		ValidatorStakeGap: NewGaugeDesc(
+			"solana_validator_stake_gap",
+			fmt.Sprintf(
+				"Difference in active stake (in SOL) between the validator (represented by %s and %s) and the "+
+					"validator ranked right %s ('%s' or '%s') it",
+				VotekeyLabel, NodekeyLabel, DirectionLabel, DirectionAbove, DirectionBelow,
+			),
+			VotekeyLabel, NodekeyLabel, DirectionLabel,
+		),
+		ValidatorLastVoteAge: NewGaugeDesc(
+			"solana_validator_last_vote_age_seconds",
+			fmt.Sprintf(
+				"Estimated time since the last voted-on slot of a validator (represented by %s and %s), from its "+
+					"distance to the cluster's confirmed slot at the average slot duration",
+				VotekeyLabel, NodekeyLabel,
+			),
+			VotekeyLabel, NodekeyLabel,
+		),
+		ClusterMeanCommission: NewGaugeDesc(
+			"solana_cluster_mean_commission",
+			"Mean commission percentage rate (0-100) of all validators in the cluster",
+		),
+		ClusterMedianCommission: NewGaugeDesc(
+			"solana_cluster_median_commission",
+			"Median commission percentage rate (0-100) of all validators in the cluster",
+		),
 		ValidatorVoteDistance: NewGaugeDesc(
 			"solana_validator_vote_distance",
 			"Gap between current slot and last vote (lower is better)",
@@ -173,9 +478,414 @@ func NewSolanaCollector(rpcClient *rpc.Client, config *ExporterConfig) *SolanaCo
 			"Gap between last vote and root slot (tower stability metric)",
 			IdentityLabel,
 		),
-		fastMetricsCh: nil,
+		ValidatorVoteLagAlert: NewGaugeDesc(
+			"solana_validator_vote_lag_alert",
+			"Whether the vote or root distance crossed its alert threshold, and has not been back at (or below) "+
+				"its clear threshold since (1 if so, 0 otherwise)",
+			IdentityLabel,
+		),
+		ValidatorIdentityMismatch: NewGaugeDesc(
+			"solana_validator_vote_account_identity_mismatch",
+			fmt.Sprintf(
+				"Whether the configured vote account (%s) votes on behalf of a different node than the configured %s",
+				VotekeyLabel, IdentityLabel,
+			),
+			VotekeyLabel, IdentityLabel,
+		),
+		NodeClockDrift: NewGaugeDesc(
+			"solana_node_clock_drift_seconds",
+			"Difference between the on-chain Clock sysvar unix timestamp and the exporter host's wall clock",
+		),
+		NodeBlockTime: NewGaugeDesc(
+			"solana_node_block_time_seconds",
+			"Unix timestamp of the latest finalized block of the node",
+		),
+		NodeBlockTimeLag: NewGaugeDesc(
+			"solana_node_block_time_lag_seconds",
+			"Time between the latest finalized block of the node and the exporter host's wall clock, which keeps "+
+				"growing if the node is stuck",
+		),
+		NodeBlockhashLastValidBlockHeight: NewGaugeDesc(
+			"solana_node_latest_blockhash_last_valid_block_height",
+			"Last block height at which the latest blockhash of the node is valid for transactions",
+		),
+		NodeBlockhashUnchangedSeconds: NewGaugeDesc(
+			"solana_node_latest_blockhash_unchanged_seconds",
+			"Time since the latest blockhash of the node was last observed changing, at most the exporter's uptime. "+
+				"A stale blockhash means the node's bank is frozen, even if it reports healthy",
+		),
+		ClusterSlotTimestampDrift: NewGaugeDesc(
+			"solana_cluster_slot_timestamp_drift_seconds",
+			"Difference between the on-chain Clock sysvar unix timestamp and the timestamp estimated from the "+
+				"epoch start timestamp and the target slot duration",
+		),
+		NodeEpochProgress: NewGaugeDesc(
+			"solana_node_epoch_progress",
+			"Percentage (0-100) of the slots of the current epoch the node has confirmed",
+		),
+		NodeEpochSlotsRemaining: NewGaugeDesc(
+			"solana_node_epoch_slots_remaining",
+			"Number of slots of the current epoch after the node's confirmed slot",
+		),
+		NodeEpochEndTimestamp: NewGaugeDesc(
+			"solana_node_epoch_end_timestamp_seconds",
+			"Estimated unix timestamp of the end of the current epoch, at the average slot duration",
+		),
+		NodeAverageSlotDuration: NewGaugeDesc(
+			"solana_node_average_slot_duration_seconds",
+			fmt.Sprintf(
+				"Average slot duration over the node's performance samples of the last %d minutes",
+				performanceSamplesLimit,
+			),
+		),
+		ClusterTransactionRate: NewGaugeDesc(
+			"solana_cluster_transactions_per_second",
+			fmt.Sprintf(
+				"Transactions per second of a %s (%s or %s) processed by the cluster, over the node's performance "+
+					"samples of the last %d minutes",
+				TransactionTypeLabel, TransactionTypeVote, TransactionTypeNonVote, performanceSamplesLimit,
+			),
+			TransactionTypeLabel,
+		),
+		AccountRentExempt: NewGaugeDesc(
+			"solana_account_rent_exempt",
+			fmt.Sprintf("Whether a tracked account (represented by %s) is rent exempt", AddressLabel),
+			AddressLabel,
+		),
+		AccountRentExemptMargin: NewGaugeDesc(
+			"solana_account_rent_exempt_margin",
+			fmt.Sprintf(
+				"Balance (in SOL) of a tracked account (represented by %s) above its rent-exempt minimum",
+				AddressLabel,
+			),
+			AddressLabel,
+		),
+		AccountLastWriteSlot: NewGaugeDesc(
+			"solana_account_last_write_slot",
+			fmt.Sprintf(
+				"Slot at which the current state of a tracked account (represented by %s) was first observed, "+
+					"i.e., an upper bound of the slot it was last written to",
+				AddressLabel,
+			),
+			AddressLabel,
+		),
+		AccountUnchangedSeconds: NewGaugeDesc(
+			"solana_account_unchanged_seconds",
+			fmt.Sprintf(
+				"Time since the state of a tracked account (represented by %s) was last observed changing, "+
+					"at most the exporter's uptime",
+				AddressLabel,
+			),
+			AddressLabel,
+		),
+		NonceAccountBalance: NewGaugeDesc(
+			"solana_nonce_account_balance",
+			fmt.Sprintf("Balance (in SOL) of a tracked durable nonce account (represented by %s)", AddressLabel),
+			AddressLabel,
+		),
+		TokenAccountBalance: NewGaugeDesc(
+			"solana_token_account_balance",
+			fmt.Sprintf(
+				"Balance (in whole tokens) of the SPL token accounts of a mint (represented by %s) held by a tracked "+
+					"owner (represented by %s)",
+				MintLabel, OwnerLabel,
+			),
+			OwnerLabel, MintLabel,
+		),
+		AccountRecentTransactions: NewGaugeDesc(
+			"solana_account_recent_transactions",
+			fmt.Sprintf(
+				"Number of transactions involving a watched address (represented by %s) over the last "+
+					"-watch-address-slots confirmed slots, counting at most %d",
+				AddressLabel, rpc.MaxSignaturesLimit,
+			),
+			AddressLabel,
+		),
+		AccountLastActivitySlot: NewGaugeDesc(
+			"solana_account_last_activity_slot",
+			fmt.Sprintf(
+				"Slot of the latest transaction involving a watched address (represented by %s)", AddressLabel,
+			),
+			AddressLabel,
+		),
+		TransactionConfirmed: NewGaugeDesc(
+			"solana_transaction_confirmed",
+			fmt.Sprintf(
+				"Whether a watched transaction (represented by %s) is confirmed at the %s (confirmed or finalized)",
+				SignatureLabel, CommitmentLabel,
+			),
+			SignatureLabel, CommitmentLabel,
+		),
+		TransactionSlot: NewGaugeDesc(
+			"solana_transaction_slot",
+			fmt.Sprintf("Slot at which a confirmed watched transaction (represented by %s) landed", SignatureLabel),
+			SignatureLabel,
+		),
+		TransactionFailed: NewGaugeDesc(
+			"solana_transaction_failed",
+			fmt.Sprintf(
+				"Whether a confirmed watched transaction (represented by %s) failed to execute", SignatureLabel,
+			),
+			SignatureLabel,
+		),
+		NonceAccountAuthority: NewGaugeDesc(
+			"solana_nonce_account_authority",
+			fmt.Sprintf(
+				"Authority (represented by %s) allowed to advance a tracked durable nonce account (represented by %s)",
+				AuthorityLabel, AddressLabel,
+			),
+			AddressLabel, AuthorityLabel,
+		),
+		NonceLastAdvanceSlot: NewGaugeDesc(
+			"solana_nonce_account_last_advance_slot",
+			fmt.Sprintf(
+				"Slot at which the current nonce of a tracked durable nonce account (represented by %s) was first "+
+					"observed, i.e., an upper bound of the slot it was last advanced at",
+				AddressLabel,
+			),
+			AddressLabel,
+		),
+		NonceUnadvancedSeconds: NewGaugeDesc(
+			"solana_nonce_account_unadvanced_seconds",
+			fmt.Sprintf(
+				"Time since a tracked durable nonce account (represented by %s) was last observed advancing, at "+
+					"most the exporter's uptime",
+				AddressLabel,
+			),
+			AddressLabel,
+		),
+		CollectorRpcCalls: NewGaugeDesc(
+			"solana_exporter_collector_rpc_calls",
+			fmt.Sprintf(
+				"Number of RPC calls (including retries) made by a collector (represented by %s) in the last "+
+					"collection cycle",
+				CollectorLabel,
+			),
+			CollectorLabel,
+		),
+		CollectorRpcResponseBytes: NewGaugeDesc(
+			"solana_exporter_collector_rpc_response_bytes",
+			fmt.Sprintf(
+				"Bytes of RPC responses received by a collector (represented by %s) in the last collection cycle",
+				CollectorLabel,
+			),
+			CollectorLabel,
+		),
+		CollectorStaleness: NewGaugeDesc(
+			"solana_exporter_collector_staleness_seconds",
+			fmt.Sprintf(
+				"Time since the last collection of a collector (represented by %s) without failures, during which "+
+					"its last-known-good values are served for up to its error tolerance",
+				CollectorLabel,
+			),
+			CollectorLabel,
+		),
+		FastMetricAge: NewGaugeDesc(
+			"solana_exporter_fast_metric_age_seconds",
+			fmt.Sprintf(
+				"Age of the oldest cached sample of a fast-path metric (represented by %s), which grows while "+
+					"fast collections fail",
+				MetricLabel,
+			),
+			MetricLabel,
+		),
+		ValidatorAuthorizedVoter: NewGaugeDesc(
+			"solana_validator_authorized_voter",
+			fmt.Sprintf(
+				"Authorized voter (represented by %s) of a vote account (represented by %s) from %s onwards, "+
+					"including scheduled voters",
+				AuthorizedVoterLabel, VotekeyLabel, EpochLabel,
+			),
+			VotekeyLabel, AuthorizedVoterLabel, EpochLabel,
+		),
+		ValidatorVoterRotationPending: NewGaugeDesc(
+			"solana_validator_authorized_voter_rotation_pending",
+			fmt.Sprintf(
+				"Whether a vote account (represented by %s) has a different authorized voter scheduled for a future "+
+					"epoch",
+				VotekeyLabel,
+			),
+			VotekeyLabel,
+		),
+		ValidatorVoterRotationApplied: NewGaugeDesc(
+			"solana_validator_authorized_voter_rotation_applied",
+			fmt.Sprintf(
+				"Whether the last authorized voter rotation scheduled for a vote account (represented by %s) took "+
+					"effect at the start of its %s",
+				VotekeyLabel, EpochLabel,
+			),
+			VotekeyLabel, EpochLabel,
+		),
+		NodeGossipPeers: NewGaugeDesc(
+			"solana_node_gossip_peers",
+			"Number of cluster nodes visible in the node's gossip",
+		),
+		NodeGossipVisibleStake: NewGaugeDesc(
+			"solana_node_gossip_visible_stake_ratio",
+			"Share (0-1) of the cluster's active stake held by validators visible in the node's gossip, where a "+
+				"minority indicates the node is partitioned",
+		),
+		NodeGossipPeersByVersion: NewGaugeDesc(
+			"solana_node_gossip_peers_by_version",
+			fmt.Sprintf(
+				"Number of cluster nodes visible in the node's gossip, grouped by their advertised %s (%s if none)",
+				VersionLabel, UnknownVersion,
+			),
+			VersionLabel,
+		),
+		NodeGossipIdentityPresent: NewGaugeDesc(
+			"solana_node_gossip_identity_present",
+			fmt.Sprintf(
+				"Whether the node's own %s is visible in its gossip, i.e., whether it participates in the network",
+				IdentityLabel,
+			),
+			IdentityLabel,
+		),
+		NodeGossipAdvertisedPort: NewGaugeDesc(
+			"solana_node_gossip_advertised_port",
+			fmt.Sprintf(
+				"Port the node (represented by %s) advertises in gossip for a %s (gossip, tpu or rpc)",
+				IdentityLabel, ServiceLabel,
+			),
+			IdentityLabel, ServiceLabel,
+		),
+		ClusterCurrentLeader: NewGaugeDesc(
+			"solana_cluster_current_leader_info",
+			fmt.Sprintf("The leader (represented by %s) of the node's current processed slot", IdentityLabel),
+			IdentityLabel,
+		),
+		ClusterNextLeader: NewGaugeDesc(
+			"solana_cluster_next_leader_info",
+			fmt.Sprintf("The leader (represented by %s) of the leader window after the current one", IdentityLabel),
+			IdentityLabel,
+		),
+		ValidatorIsLeader: NewGaugeDesc(
+			"solana_validator_is_leader",
+			fmt.Sprintf(
+				"Whether a validator (represented by %s) is the leader of the node's current processed slot",
+				NodekeyLabel,
+			),
+			NodekeyLabel,
+		),
+		NodeRpcMethodLatency: NewGaugeDesc(
+			"solana_node_rpc_method_latency_seconds",
+			fmt.Sprintf("Latency of the last probe call of an RPC method (represented by %s) against the node", MethodLabel),
+			MethodLabel,
+		),
+		NodeSlotsBehindReference: NewGaugeDesc(
+			"solana_node_slots_behind_reference",
+			fmt.Sprintf(
+				"Number of slots the node's slot is behind that of the reference RPC, at a %s level",
+				CommitmentLabel,
+			),
+			CommitmentLabel,
+		),
+		NodeBlockHeightBehindReference: NewGaugeDesc(
+			"solana_node_block_height_behind_reference",
+			fmt.Sprintf(
+				"Number of blocks the node's block height is behind that of the reference RPC, at a %s level",
+				CommitmentLabel,
+			),
+			CommitmentLabel,
+		),
+		NodeAccountIndexHealthy: NewGaugeDesc(
+			"solana_node_account_index_healthy",
+			fmt.Sprintf("Whether the node serves the account info of a sampled account (represented by %s)", AddressLabel),
+			AddressLabel,
+		),
+		NodeIdentityChanges: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "solana_node_identity_changes_total",
+			Help: "Number of changes of the node's identity observed between collections, e.g., by failovers",
+		}),
+		NodeIdentityLastChange: NewGaugeDesc(
+			"solana_node_identity_last_change_timestamp_seconds",
+			"Unix time the node's identity was last observed changing, since the exporter started",
+		),
+		NodeHealthLastTransition: NewGaugeDesc(
+			"solana_node_health_last_transition_timestamp_seconds",
+			fmt.Sprintf("Unix time the node last transitioned between %s and %s", StateHealthy, StateUnhealthy),
+		),
+		ValidatorDelinquencyLastTransition: NewGaugeDesc(
+			"solana_validator_delinquency_last_transition_timestamp_seconds",
+			fmt.Sprintf(
+				"Unix time a validator (represented by %s) last transitioned between %s and %s",
+				NodekeyLabel, StateCurrent, StateDelinquent,
+			),
+			NodekeyLabel,
+		),
+		NodeHealthStateDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "solana_node_health_state_duration_seconds",
+				Help: fmt.Sprintf(
+					"Time the node spent in a health %s (%s or %s), observed when it left it",
+					StateLabel, StateHealthy, StateUnhealthy,
+				),
+				Buckets: stateDurationBuckets,
+			},
+			[]string{StateLabel},
+		),
+		ValidatorDelinquencyStateDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "solana_validator_delinquency_state_duration_seconds",
+				Help: fmt.Sprintf(
+					"Time a validator (represented by %s) spent %s or %s, observed when it left the %s",
+					NodekeyLabel, StateCurrent, StateDelinquent, StateLabel,
+				),
+				Buckets: stateDurationBuckets,
+			},
+			[]string{NodekeyLabel, StateLabel},
+		),
+		ValidatorCommissionChanges: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_commission_changes_total",
+				Help: fmt.Sprintf(
+					"Number of changes of a validator's (represented by %s) commission observed between collections, "+
+						"by %s (%s or %s)",
+					NodekeyLabel, DirectionLabel, DirectionIncrease, DirectionDecrease,
+				),
+			},
+			[]string{NodekeyLabel, DirectionLabel},
+		),
+		transitions: NewStateTracker(),
+		commissions: NewCommissionTracker(),
+		scheduledVoters: make(map[string]rpc.AuthorizedVoter),
+		accountWrites: NewAccountWriteTracker(),
+		nonceAdvances: NewAccountWriteTracker(),
+		signatures: NewSignatureTracker(),
+		blockhashChanges: NewAccountWriteTracker(),
+		errorTolerance: NewErrorTolerance(config.CollectorErrorTolerance, config.CollectorErrorTolerances),
+		lagAlert: NewLagAlert(config.VoteDistanceAlert, config.RootDistanceAlert),
 		stopFastCollection: make(chan struct{}),
 	}
+	collector.fastMetrics = NewFastMetricsCache(
+		collector.ValidatorVoteDistance, collector.ValidatorRootDistance, collector.ValidatorVoteLagAlert,
+	)
+	if config.MinRequiredVersion {
+		collector.apiClient = api.NewClient(api.BaseURL, config.HttpTimeout)
+	}
+	collector.keyedDescs = []*GaugeDesc{
+		collector.ValidatorActiveStake, collector.ValidatorLastVote, collector.ValidatorRootSlot,
+		collector.ValidatorDelinquent, collector.AccountBalances, collector.AccountBalanceBelowThreshold,
+		collector.NodeIdentity, collector.NodeIsActive, collector.ValidatorCurrentEpochCredits,
+		collector.ValidatorTotalCredits, collector.ValidatorCommission, collector.ValidatorCommissionLastChangeEpoch,
+		collector.ValidatorCommissionLastChangeSlot, collector.ValidatorCommissionPercentile,
+		collector.ValidatorStakeRank, collector.ValidatorStakeGap, collector.ValidatorLastVoteAge,
+		collector.ValidatorVoteDistance, collector.ValidatorRootDistance, collector.ValidatorVoteLagAlert,
+		collector.ValidatorIdentityMismatch, collector.AccountRentExempt, collector.AccountRentExemptMargin,
+		collector.AccountLastWriteSlot, collector.AccountUnchangedSeconds, collector.NonceAccountBalance,
+		collector.AccountRecentTransactions, collector.AccountLastActivitySlot, collector.NonceAccountAuthority,
+		collector.NonceLastAdvanceSlot, collector.NonceUnadvancedSeconds, collector.ValidatorAuthorizedVoter,
+		collector.ValidatorVoterRotationPending, collector.ValidatorVoterRotationApplied,
+		collector.NodeGossipIdentityPresent, collector.NodeGossipAdvertisedPort, collector.ClusterCurrentLeader,
+		collector.ClusterNextLeader, collector.ValidatorIsLeader, collector.NodeAccountIndexHealthy,
+		collector.ValidatorDelinquencyLastTransition,
+	}
+	if config.TenantsByKey != nil {
+		collector.SetTenants(config.TenantsByKey)
+	}
+	if config.ValidatorNames != nil || config.ValidatorInfoInterval > 0 {
+		collector.SetValidatorNames(NewValidatorNames(config.ValidatorNames))
+	}
 	return collector
 }
 
@@ -184,16 +894,66 @@ func (c *SolanaCollector) Describe(ch chan<- *prometheus.Desc) {
 	
 	// These metrics are always collected, even in light mode - node-specific metrics only
 	ch <- c.NodeVersion.Desc
+	ch <- c.NodeMinRequiredVersion.Desc
+	ch <- c.NodeVersionOutdated.Desc
 	ch <- c.NodeIdentity.Desc
 	ch <- c.NodeIsHealthy.Desc
 	ch <- c.NodeNumSlotsBehind.Desc
 	ch <- c.NodeMinimumLedgerSlot.Desc
 	ch <- c.NodeFirstAvailableBlock.Desc
+	ch <- c.NodeLedgerRetainedSlots.Desc
+	ch <- c.NodeLedgerRetentionHours.Desc
+	ch <- c.NodeMaxRetransmitSlot.Desc
+	ch <- c.NodeMaxShredInsertSlot.Desc
 	ch <- c.NodeIsActive.Desc
+	ch <- c.NodeClockDrift.Desc
+	ch <- c.NodeBlockTime.Desc
+	ch <- c.NodeBlockTimeLag.Desc
+	ch <- c.NodeBlockhashLastValidBlockHeight.Desc
+	ch <- c.NodeBlockhashUnchangedSeconds.Desc
+	ch <- c.ClusterSlotTimestampDrift.Desc
+	ch <- c.NodeEpochProgress.Desc
+	ch <- c.NodeEpochSlotsRemaining.Desc
+	ch <- c.NodeEpochEndTimestamp.Desc
+	ch <- c.NodeAverageSlotDuration.Desc
+	ch <- c.CollectorRpcCalls.Desc
+	ch <- c.CollectorRpcResponseBytes.Desc
+	ch <- c.FastMetricAge.Desc
+	ch <- c.CollectorStaleness.Desc
+	ch <- c.NodeHealthLastTransition.Desc
+	ch <- c.NodeIdentityLastChange.Desc
+	c.NodeIdentityChanges.Describe(ch)
+	c.NodeHealthStateDuration.Describe(ch)
 	
+	if c.config.RpcNodeMode {
+		ch <- c.NodeRpcMethodLatency.Desc
+		ch <- c.NodeAccountIndexHealthy.Desc
+	}
+	if c.config.ReferenceRpcUrl != "" {
+		ch <- c.NodeSlotsBehindReference.Desc
+		ch <- c.NodeBlockHeightBehindReference.Desc
+	}
+
 	// Vote distance and root distance are also node-specific metrics
 	ch <- c.ValidatorVoteDistance.Desc
 	ch <- c.ValidatorRootDistance.Desc
+	if c.config.VoteDistanceAlert.Enabled() || c.config.RootDistanceAlert.Enabled() {
+		ch <- c.ValidatorVoteLagAlert.Desc
+	}
+
+	if c.config.ValidatorIdentity != "" && c.config.VoteAccountPubkey != "" {
+		ch <- c.ValidatorIdentityMismatch.Desc
+	}
+	ch <- c.NonceAccountBalance.Desc
+	ch <- c.TokenAccountBalance.Desc
+	ch <- c.AccountRecentTransactions.Desc
+	ch <- c.AccountLastActivitySlot.Desc
+	ch <- c.TransactionConfirmed.Desc
+	ch <- c.TransactionSlot.Desc
+	ch <- c.TransactionFailed.Desc
+	ch <- c.NonceAccountAuthority.Desc
+	ch <- c.NonceLastAdvanceSlot.Desc
+	ch <- c.NonceUnadvancedSeconds.Desc
 	
 	// These metrics are only collected in regular mode
 	if !c.config.LightMode {
@@ -203,13 +963,50 @@ func (c *SolanaCollector) Describe(ch chan<- *prometheus.Desc) {
 		ch <- c.ValidatorRootSlot.Desc
 		ch <- c.ValidatorDelinquent.Desc
 		ch <- c.ValidatorCommission.Desc
+		ch <- c.ValidatorCommissionPercentile.Desc
+		ch <- c.ValidatorStakeRank.Desc
+		ch <- c.ValidatorStakeGap.Desc
+		ch <- c.ValidatorLastVoteAge.Desc
 		
 		// Cluster-wide metrics
 		ch <- c.ClusterActiveStake.Desc
+		ch <- c.ClusterTotalSupply.Desc
+		ch <- c.ClusterCirculatingSupply.Desc
+		ch <- c.ClusterInflationInitial.Desc
+		ch <- c.ClusterInflationTerminal.Desc
+		ch <- c.ClusterInflationTaper.Desc
+		ch <- c.ClusterInflationFoundation.Desc
+		ch <- c.ClusterInflationFoundationTerm.Desc
+		ch <- c.ClusterSlotsPerEpoch.Desc
+		ch <- c.ClusterLeaderScheduleSlotOffset.Desc
 		ch <- c.ClusterLastVote.Desc
 		ch <- c.ClusterRootSlot.Desc
 		ch <- c.ClusterValidatorCount.Desc
+		ch <- c.ClusterTransactionRate.Desc
+		ch <- c.ClusterMeanCommission.Desc
+		ch <- c.ClusterMedianCommission.Desc
 		ch <- c.AccountBalances.Desc
+		ch <- c.AccountBalanceBelowThreshold.Desc
+		ch <- c.AccountRentExempt.Desc
+		ch <- c.AccountRentExemptMargin.Desc
+		ch <- c.AccountLastWriteSlot.Desc
+		ch <- c.AccountUnchangedSeconds.Desc
+		ch <- c.NodeGossipPeers.Desc
+		ch <- c.NodeGossipVisibleStake.Desc
+		ch <- c.NodeGossipPeersByVersion.Desc
+		ch <- c.NodeGossipIdentityPresent.Desc
+		ch <- c.NodeGossipAdvertisedPort.Desc
+		ch <- c.ClusterCurrentLeader.Desc
+		ch <- c.ClusterNextLeader.Desc
+		ch <- c.ValidatorIsLeader.Desc
+		ch <- c.ValidatorAuthorizedVoter.Desc
+		ch <- c.ValidatorVoterRotationPending.Desc
+		ch <- c.ValidatorVoterRotationApplied.Desc
+		ch <- c.ValidatorDelinquencyLastTransition.Desc
+		c.ValidatorDelinquencyStateDuration.Describe(ch)
+		ch <- c.ValidatorCommissionLastChangeEpoch.Desc
+		ch <- c.ValidatorCommissionLastChangeSlot.Desc
+		c.ValidatorCommissionChanges.Describe(ch)
 	}
 	
 	// These metrics are available in light mode if we have validator identity configured
@@ -232,7 +1029,7 @@ func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- pro
 		return
 	}
 	c.logger.Info("Collecting vote accounts...")
-	voteAccounts, err := c.rpcClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	voteAccounts, err := c.clusterClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
 	if err != nil {
 		c.logger.Errorf("failed to get vote accounts: %v", err)
 		ch <- c.ValidatorActiveStake.NewInvalidMetric(err)
@@ -243,6 +1040,9 @@ func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- pro
 		ch <- c.ClusterRootSlot.NewInvalidMetric(err)
 		ch <- c.ValidatorDelinquent.NewInvalidMetric(err)
 		ch <- c.ClusterValidatorCount.NewInvalidMetric(err)
+		ch <- c.ValidatorStakeRank.NewInvalidMetric(err)
+		ch <- c.ValidatorStakeGap.NewInvalidMetric(err)
+		ch <- c.ValidatorLastVoteAge.NewInvalidMetric(err)
 		return
 	}
 
@@ -274,11 +1074,19 @@ func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- pro
 			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
 				ch <- c.ValidatorDelinquent.MustNewConstMetric(0, account.VotePubkey, account.NodePubkey)
 			}
+			if slices.Contains(c.config.NodeKeys, account.NodePubkey) {
+				c.annotateDelinquency(account.NodePubkey, false)
+				c.observeDelinquency(ch, account.NodePubkey, false)
+			}
 		}
 		for _, account := range voteAccounts.Delinquent {
 			if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
 				ch <- c.ValidatorDelinquent.MustNewConstMetric(1, account.VotePubkey, account.NodePubkey)
 			}
+			if slices.Contains(c.config.NodeKeys, account.NodePubkey) {
+				c.annotateDelinquency(account.NodePubkey, true)
+				c.observeDelinquency(ch, account.NodePubkey, true)
+			}
 		}
 	}
 
@@ -287,10 +1095,59 @@ func (c *SolanaCollector) collectVoteAccounts(ctx context.Context, ch chan<- pro
 	ch <- c.ClusterRootSlot.MustNewConstMetric(maxRootSlot)
 	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Current)), StateCurrent)
 	ch <- c.ClusterValidatorCount.MustNewConstMetric(float64(len(voteAccounts.Delinquent)), StateDelinquent)
+	c.emitStakeRanks(ch, voteAccounts)
+	c.emitLastVoteAges(ctx, ch, voteAccounts)
 
 	c.logger.Info("Vote accounts collected.")
 }
 
+// emitLastVoteAges emits how long ago the tracked validators last voted, in seconds rather than slots such that
+// alerts on it do not shift in meaning with the slot duration.
+func (c *SolanaCollector) emitLastVoteAges(
+	ctx context.Context, ch chan<- prometheus.Metric, voteAccounts *rpc.VoteAccounts,
+) {
+	slot, err := c.clusterClient.GetSlot(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get slot for last vote ages: %v", err)
+		ch <- c.ValidatorLastVoteAge.NewInvalidMetric(err)
+		return
+	}
+	samples, err := c.clusterClient.GetRecentPerformanceSamples(ctx, performanceSamplesLimit)
+	if err != nil {
+		c.logger.Errorf("failed to get recent performance samples for last vote ages: %v", err)
+		ch <- c.ValidatorLastVoteAge.NewInvalidMetric(err)
+		return
+	}
+	slotDuration := GetAverageSlotDuration(samples)
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
+			age := float64(max(0, slot-int64(account.LastVote))) * slotDuration.Seconds()
+			ch <- c.ValidatorLastVoteAge.MustNewConstMetric(age, account.VotePubkey, account.NodePubkey)
+		}
+	}
+}
+
+// emitStakeRanks emits the stake rank of the tracked validators, and their stake gap to the validators ranked right
+// above and below them.
+func (c *SolanaCollector) emitStakeRanks(ch chan<- prometheus.Metric, voteAccounts *rpc.VoteAccounts) {
+	ranked := SortedStakedVoteAccounts(voteAccounts)
+	for i, account := range ranked {
+		if !slices.Contains(c.config.NodeKeys, account.NodePubkey) {
+			continue
+		}
+		accounts := []string{account.VotePubkey, account.NodePubkey}
+		ch <- c.ValidatorStakeRank.MustNewConstMetric(float64(i+1), accounts...)
+		if i > 0 {
+			gap := float64(ranked[i-1].ActivatedStake-account.ActivatedStake) / rpc.LamportsInSol
+			ch <- c.ValidatorStakeGap.MustNewConstMetric(gap, append(accounts, DirectionAbove)...)
+		}
+		if i < len(ranked)-1 {
+			gap := float64(account.ActivatedStake-ranked[i+1].ActivatedStake) / rpc.LamportsInSol
+			ch <- c.ValidatorStakeGap.MustNewConstMetric(gap, append(accounts, DirectionBelow)...)
+		}
+	}
+}
+
 func (c *SolanaCollector) collectVersion(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Info("Collecting version...")
 	version, err := c.rpcClient.GetVersion(ctx)
@@ -300,10 +1157,93 @@ func (c *SolanaCollector) collectVersion(ctx context.Context, ch chan<- promethe
 		return
 	}
 
-	ch <- c.NodeVersion.MustNewConstMetric(1, version)
+	ch <- c.NodeVersion.MustNewConstMetric(1, version, GetClientType(version))
+	if previous, changed := c.annotator.ObserveChange(VersionLabel, version); changed {
+		c.annotator.Annotate(EventVersionChange, fmt.Sprintf("Node version changed from %s to %s", previous, version))
+	}
+	if c.apiClient != nil {
+		c.collectMinRequiredVersion(ctx, ch, version)
+	}
 	c.logger.Info("Version collected.")
 }
 
+// collectMinRequiredVersion emits the minimum version SFDP participants of the node's cluster are required to run, and
+// whether the node's version is older. Only Agave versions are compared, as Firedancer is versioned independently.
+func (c *SolanaCollector) collectMinRequiredVersion(ctx context.Context, ch chan<- prometheus.Metric, version string) {
+	cluster, err := c.getCluster(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to detect cluster: %v", err)
+		ch <- c.NodeMinRequiredVersion.NewInvalidMetric(err)
+		ch <- c.NodeVersionOutdated.NewInvalidMetric(err)
+		return
+	}
+	minVersion, err := c.apiClient.GetMinRequiredVersion(ctx, cluster)
+	if err != nil {
+		c.logger.Errorf("failed to get minimum required version: %v", err)
+		ch <- c.NodeMinRequiredVersion.NewInvalidMetric(err)
+		ch <- c.NodeVersionOutdated.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeMinRequiredVersion.MustNewConstMetric(1, minVersion, cluster)
+
+	if GetClientType(version) != ClientAgave {
+		return
+	}
+	outdated, err := IsVersionOutdated(version, minVersion)
+	if err != nil {
+		c.logger.Errorf("failed to compare version to the minimum required version: %v", err)
+		ch <- c.NodeVersionOutdated.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeVersionOutdated.MustNewConstMetric(BoolToFloat64(outdated))
+}
+
+// getCluster returns the cluster the node is part of, detecting it from the genesis hash on first use.
+func (c *SolanaCollector) getCluster(ctx context.Context) (string, error) {
+	c.clusterMu.Lock()
+	defer c.clusterMu.Unlock()
+	if c.cluster == "" {
+		genesisHash, err := c.rpcClient.GetGenesisHash(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get genesis hash: %w", err)
+		}
+		cluster, err := rpc.GetClusterFromGenesisHash(genesisHash)
+		if err != nil {
+			return "", err
+		}
+		c.cluster = cluster
+	}
+	return c.cluster, nil
+}
+
+// annotateDelinquency annotates when a tracked validator becomes delinquent or recovers.
+func (c *SolanaCollector) annotateDelinquency(nodekey string, delinquent bool) {
+	if _, changed := c.annotator.ObserveChange("delinquent/"+nodekey, toString(delinquent)); !changed {
+		return
+	}
+	if delinquent {
+		c.annotator.Annotate(EventDelinquencyStart, fmt.Sprintf("Validator %s became delinquent", nodekey))
+	} else {
+		c.annotator.Annotate(EventDelinquencyStop, fmt.Sprintf("Validator %s is no longer delinquent", nodekey))
+	}
+}
+
+// observeDelinquency records the delinquency state of a tracked validator, timing the state it left (if any), and
+// emits its last transition time along with the time spent in each state so far.
+func (c *SolanaCollector) observeDelinquency(ch chan<- prometheus.Metric, nodekey string, delinquent bool) {
+	state := StateCurrent
+	if delinquent {
+		state = StateDelinquent
+	}
+	key := "delinquent/" + nodekey
+	if previous, held, ok := c.transitions.Observe(key, state, time.Now()); ok {
+		c.ValidatorDelinquencyStateDuration.WithLabelValues(nodekey, previous).Observe(held.Seconds())
+	}
+	if transition, ok := c.transitions.LastTransition(key); ok {
+		ch <- c.ValidatorDelinquencyLastTransition.MustNewConstMetric(float64(transition.Unix()), nodekey)
+	}
+}
+
 func (c *SolanaCollector) collectIdentity(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Info("Collecting identity...")
 	identity, err := c.rpcClient.GetIdentity(ctx)
@@ -323,6 +1263,19 @@ func (c *SolanaCollector) collectIdentity(ctx context.Context, ch chan<- prometh
 	}
 
 	ch <- c.NodeIdentity.MustNewConstMetric(1, identity)
+	if previous, changed := c.annotator.ObserveChange(IdentityLabel, identity); changed {
+		c.annotator.Annotate(EventIdentitySwap, fmt.Sprintf("Node identity swapped from %s to %s", previous, identity))
+	}
+	// a swap shows as a transition of the identity "state":
+	lastChange, _ := c.transitions.LastTransition(IdentityLabel)
+	c.transitions.Observe(IdentityLabel, identity, time.Now())
+	if transition, ok := c.transitions.LastTransition(IdentityLabel); ok {
+		if !transition.Equal(lastChange) {
+			c.logger.Warnf("Node identity changed to %s", identity)
+			c.NodeIdentityChanges.Inc()
+		}
+		ch <- c.NodeIdentityLastChange.MustNewConstMetric(float64(transition.Unix()))
+	}
 	c.logger.Info("Identity collected.")
 }
 
@@ -336,6 +1289,14 @@ func (c *SolanaCollector) collectMinimumLedgerSlot(ctx context.Context, ch chan<
 	}
 
 	ch <- c.NodeMinimumLedgerSlot.MustNewConstMetric(float64(slot))
+
+	finalizedSlot, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get finalized slot: %v", err)
+		ch <- c.NodeLedgerRetainedSlots.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeLedgerRetainedSlots.MustNewConstMetric(float64(max(0, finalizedSlot-slot)))
 	c.logger.Info("Minimum ledger slot collected.")
 }
 
@@ -349,9 +1310,530 @@ func (c *SolanaCollector) collectFirstAvailableBlock(ctx context.Context, ch cha
 	}
 
 	ch <- c.NodeFirstAvailableBlock.MustNewConstMetric(float64(block))
+
+	// skipped slots make slot counts overstate the retention, whereas block times are exact:
+	blockTime, err := c.rpcClient.GetBlockTime(ctx, block)
+	if err != nil {
+		c.logger.Errorf("failed to get block time of the first available block %v: %v", block, err)
+		ch <- c.NodeLedgerRetentionHours.NewInvalidMetric(err)
+		return
+	}
+	retention := float64(time.Now().Unix()-blockTime) / time.Hour.Seconds()
+	ch <- c.NodeLedgerRetentionHours.MustNewConstMetric(retention)
 	c.logger.Info("First available block collected.")
 }
 
+// collectMaxSlots emits the node's turbine and blockstore cursors. These running ahead of the confirmed slot while
+// the node falls behind points at replay, whereas them falling behind as well points at turbine (or the network).
+func (c *SolanaCollector) collectMaxSlots(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Info("Collecting max retransmit and shred insert slots...")
+	retransmitSlot, err := c.rpcClient.GetMaxRetransmitSlot(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get max retransmit slot: %v", err)
+		ch <- c.NodeMaxRetransmitSlot.NewInvalidMetric(err)
+	} else {
+		ch <- c.NodeMaxRetransmitSlot.MustNewConstMetric(float64(retransmitSlot))
+	}
+
+	shredInsertSlot, err := c.rpcClient.GetMaxShredInsertSlot(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get max shred insert slot: %v", err)
+		ch <- c.NodeMaxShredInsertSlot.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeMaxShredInsertSlot.MustNewConstMetric(float64(shredInsertSlot))
+	c.logger.Info("Max retransmit and shred insert slots collected.")
+}
+
+func (c *SolanaCollector) collectClockDrift(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Info("Collecting clock drift...")
+	clock, err := c.rpcClient.GetClock(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get clock sysvar: %v", err)
+		ch <- c.NodeClockDrift.NewInvalidMetric(err)
+		ch <- c.ClusterSlotTimestampDrift.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeClockDrift.MustNewConstMetric(float64(clock.UnixTimestamp) - float64(time.Now().UnixMilli())/1000)
+
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info: %v", err)
+		ch <- c.ClusterSlotTimestampDrift.NewInvalidMetric(err)
+		return
+	}
+	// the estimate is relative to the epoch start, so it is meaningless across an epoch boundary:
+	if epochInfo.Epoch != clock.Epoch {
+		c.logger.Debugf("Clock epoch %v does not match epoch %v, skipping slot timestamp drift", clock.Epoch, epochInfo.Epoch)
+		return
+	}
+	firstSlot, _ := solana.GetEpochBounds(epochInfo)
+	ch <- c.ClusterSlotTimestampDrift.MustNewConstMetric(GetSlotTimestampDrift(clock, firstSlot))
+	c.logger.Info("Clock drift collected.")
+}
+
+// collectEpochProgress emits how far the current epoch has progressed, and when it is estimated to end at the
+// average slot duration of the recent performance samples, such that dashboards need not guess at either.
+func (c *SolanaCollector) collectEpochProgress(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Info("Collecting epoch progress...")
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info: %v", err)
+		ch <- c.NodeEpochProgress.NewInvalidMetric(err)
+		ch <- c.NodeEpochSlotsRemaining.NewInvalidMetric(err)
+		ch <- c.NodeEpochEndTimestamp.NewInvalidMetric(err)
+		return
+	}
+	_, lastSlot := solana.GetEpochBounds(epochInfo)
+	slotsRemaining := lastSlot - epochInfo.AbsoluteSlot
+	progress := float64(epochInfo.SlotIndex+1) / float64(epochInfo.SlotsInEpoch) * 100
+	ch <- c.NodeEpochProgress.MustNewConstMetric(progress)
+	ch <- c.NodeEpochSlotsRemaining.MustNewConstMetric(float64(slotsRemaining))
+
+	samples, err := c.rpcClient.GetRecentPerformanceSamples(ctx, performanceSamplesLimit)
+	if err != nil {
+		c.logger.Errorf("failed to get recent performance samples: %v", err)
+		ch <- c.NodeEpochEndTimestamp.NewInvalidMetric(err)
+		ch <- c.NodeAverageSlotDuration.NewInvalidMetric(err)
+		return
+	}
+	slotDuration := GetAverageSlotDuration(samples)
+	end := time.Now().Add(time.Duration(slotsRemaining) * slotDuration)
+	ch <- c.NodeEpochEndTimestamp.MustNewConstMetric(float64(end.UnixMilli()) / 1000)
+	ch <- c.NodeAverageSlotDuration.MustNewConstMetric(slotDuration.Seconds())
+	c.logger.Info("Epoch progress collected.")
+}
+
+// collectTransactionRates emits the vote and non-vote transactions per second of the recent performance samples.
+// Votes make up the bulk of the transactions, so their total says little about the actual usage of the cluster.
+func (c *SolanaCollector) collectTransactionRates(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Info("Collecting transaction rates...")
+	samples, err := c.rpcClient.GetRecentPerformanceSamples(ctx, performanceSamplesLimit)
+	if err != nil {
+		c.logger.Errorf("failed to get recent performance samples: %v", err)
+		ch <- c.ClusterTransactionRate.NewInvalidMetric(err)
+		return
+	}
+	voteRate, nonVoteRate := GetTransactionRates(samples)
+	ch <- c.ClusterTransactionRate.MustNewConstMetric(voteRate, TransactionTypeVote)
+	ch <- c.ClusterTransactionRate.MustNewConstMetric(nonVoteRate, TransactionTypeNonVote)
+	c.logger.Info("Transaction rates collected.")
+}
+
+// collectBlockTime emits the timestamp of the latest finalized block of the node, and how far it lags behind the wall
+// clock. Unlike slot deltas, this tells a stuck node apart even if the cluster it is compared with is stuck as well.
+func (c *SolanaCollector) collectBlockTime(ctx context.Context, ch chan<- prometheus.Metric) {
+	c.logger.Info("Collecting block time...")
+	slot, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get finalized slot: %v", err)
+		ch <- c.NodeBlockTime.NewInvalidMetric(err)
+		ch <- c.NodeBlockTimeLag.NewInvalidMetric(err)
+		return
+	}
+	// the finalized slot is rooted, so it always has a block:
+	blockTime, err := c.rpcClient.GetBlockTime(ctx, slot)
+	if err != nil {
+		c.logger.Errorf("failed to get block time of slot %v: %v", slot, err)
+		ch <- c.NodeBlockTime.NewInvalidMetric(err)
+		ch <- c.NodeBlockTimeLag.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeBlockTime.MustNewConstMetric(float64(blockTime))
+	ch <- c.NodeBlockTimeLag.MustNewConstMetric(float64(time.Now().UnixMilli())/1000 - float64(blockTime))
+	c.logger.Info("Block time collected.")
+}
+
+// collectLatestBlockhash emits the last valid block height of the latest blockhash of the node, and how long since it
+// last changed.
+func (c *SolanaCollector) collectLatestBlockhash(ctx context.Context, ch chan<- prometheus.Metric) {
+	blockhash, slot, err := c.rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get latest blockhash: %v", err)
+		ch <- c.NodeBlockhashLastValidBlockHeight.NewInvalidMetric(err)
+		ch <- c.NodeBlockhashUnchangedSeconds.NewInvalidMetric(err)
+		return
+	}
+	_, changeTime := c.blockhashChanges.ObserveFingerprint(
+		latestBlockhashKey, GetStringFingerprint(blockhash.Blockhash), slot, time.Now(),
+	)
+	ch <- c.NodeBlockhashLastValidBlockHeight.MustNewConstMetric(float64(blockhash.LastValidBlockHeight))
+	ch <- c.NodeBlockhashUnchangedSeconds.MustNewConstMetric(time.Since(changeTime).Seconds())
+}
+
+// collectGossipConnectivity emits the number of peers the node sees in gossip, and the share of the cluster's stake
+// they hold. The gossip view is node-specific, so it always comes from the node, even with a reference RPC.
+func (c *SolanaCollector) collectGossipConnectivity(ctx context.Context, ch chan<- prometheus.Metric) {
+	nodes, err := c.rpcClient.GetClusterNodes(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get cluster nodes: %v", err)
+		ch <- c.NodeGossipPeers.NewInvalidMetric(err)
+		ch <- c.NodeGossipVisibleStake.NewInvalidMetric(err)
+		ch <- c.NodeGossipPeersByVersion.NewInvalidMetric(err)
+		ch <- c.NodeGossipIdentityPresent.NewInvalidMetric(err)
+		ch <- c.NodeGossipAdvertisedPort.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeGossipPeers.MustNewConstMetric(float64(len(nodes)))
+	for version, count := range CountNodesByVersion(nodes) {
+		ch <- c.NodeGossipPeersByVersion.MustNewConstMetric(float64(count), version)
+	}
+	c.collectGossipIdentity(ctx, ch, nodes)
+
+	voteAccounts, err := c.clusterClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get vote accounts: %v", err)
+		ch <- c.NodeGossipVisibleStake.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.NodeGossipVisibleStake.MustNewConstMetric(GetVisibleStakeRatio(nodes, voteAccounts))
+}
+
+// collectGossipIdentity emits whether the node's own identity is in its gossip, along with the ports it advertises.
+func (c *SolanaCollector) collectGossipIdentity(ctx context.Context, ch chan<- prometheus.Metric, nodes []rpc.ClusterNode) {
+	identity, err := c.rpcClient.GetIdentity(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get identity: %v", err)
+		ch <- c.NodeGossipIdentityPresent.NewInvalidMetric(err)
+		ch <- c.NodeGossipAdvertisedPort.NewInvalidMetric(err)
+		return
+	}
+	index := slices.IndexFunc(nodes, func(node rpc.ClusterNode) bool { return node.Pubkey == identity })
+	ch <- c.NodeGossipIdentityPresent.MustNewConstMetric(BoolToFloat64(index >= 0), identity)
+	if index < 0 {
+		return
+	}
+	for service, address := range GetAdvertisedAddresses(&nodes[index]) {
+		port, err := GetAddressPort(address)
+		if err != nil {
+			c.logger.Warnf("invalid %s address %s advertised by %s: %v", service, address, identity, err)
+			continue
+		}
+		ch <- c.NodeGossipAdvertisedPort.MustNewConstMetric(float64(port), identity, service)
+	}
+}
+
+// collectSupply emits the total and circulating supply of the cluster, e.g., for the share of the supply staked.
+func (c *SolanaCollector) collectSupply(ctx context.Context, ch chan<- prometheus.Metric) {
+	supply, err := c.clusterClient.GetSupply(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get supply: %v", err)
+		ch <- c.ClusterTotalSupply.NewInvalidMetric(err)
+		ch <- c.ClusterCirculatingSupply.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ClusterTotalSupply.MustNewConstMetric(float64(supply.Total) / rpc.LamportsInSol)
+	ch <- c.ClusterCirculatingSupply.MustNewConstMetric(float64(supply.Circulating) / rpc.LamportsInSol)
+}
+
+// collectInflationGovernor emits the inflation governor parameters, from which long-term rewards can be projected.
+func (c *SolanaCollector) collectInflationGovernor(ctx context.Context, ch chan<- prometheus.Metric) {
+	descs := []*GaugeDesc{
+		c.ClusterInflationInitial, c.ClusterInflationTerminal, c.ClusterInflationTaper,
+		c.ClusterInflationFoundation, c.ClusterInflationFoundationTerm,
+	}
+	governor, err := c.clusterClient.GetInflationGovernor(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get inflation governor: %v", err)
+		for _, desc := range descs {
+			ch <- desc.NewInvalidMetric(err)
+		}
+		return
+	}
+	values := []float64{
+		governor.Initial, governor.Terminal, governor.Taper, governor.Foundation, governor.FoundationTerm,
+	}
+	for i, desc := range descs {
+		ch <- desc.MustNewConstMetric(values[i])
+	}
+}
+
+// getEpochSchedule returns the epoch schedule of the cluster, fetching it on first use.
+func (c *SolanaCollector) getEpochSchedule(ctx context.Context) (*rpc.EpochSchedule, error) {
+	c.epochScheduleMu.Lock()
+	defer c.epochScheduleMu.Unlock()
+	if c.epochSchedule == nil {
+		schedule, err := c.clusterClient.GetEpochSchedule(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.epochSchedule = schedule
+	}
+	return c.epochSchedule, nil
+}
+
+func (c *SolanaCollector) collectEpochSchedule(ctx context.Context, ch chan<- prometheus.Metric) {
+	schedule, err := c.getEpochSchedule(ctx)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch schedule: %v", err)
+		ch <- c.ClusterSlotsPerEpoch.NewInvalidMetric(err)
+		ch <- c.ClusterLeaderScheduleSlotOffset.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ClusterSlotsPerEpoch.MustNewConstMetric(float64(schedule.SlotsPerEpoch))
+	ch <- c.ClusterLeaderScheduleSlotOffset.MustNewConstMetric(float64(schedule.LeaderScheduleSlotOffset))
+}
+
+// collectLeaders emits the leaders of the current and next leader windows as seen by the node, and whether each
+// tracked validator is currently the leader, for correlating host load with leader windows.
+func (c *SolanaCollector) collectLeaders(ctx context.Context, ch chan<- prometheus.Metric) {
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentProcessed)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info: %v", err)
+		ch <- c.ClusterCurrentLeader.NewInvalidMetric(err)
+		ch <- c.ClusterNextLeader.NewInvalidMetric(err)
+		ch <- c.ValidatorIsLeader.NewInvalidMetric(err)
+		return
+	}
+	// the leaders of the current slot up to the first slot of the next leader window:
+	limit := LeaderRotationSlots - epochInfo.SlotIndex%LeaderRotationSlots + 1
+	leaders, err := c.rpcClient.GetSlotLeaders(ctx, epochInfo.AbsoluteSlot, limit)
+	if err == nil && int64(len(leaders)) != limit {
+		err = fmt.Errorf("expected %d slot leaders, got %d", limit, len(leaders))
+	}
+	if err != nil {
+		c.logger.Errorf("failed to get slot leaders: %v", err)
+		ch <- c.ClusterCurrentLeader.NewInvalidMetric(err)
+		ch <- c.ClusterNextLeader.NewInvalidMetric(err)
+		ch <- c.ValidatorIsLeader.NewInvalidMetric(err)
+		return
+	}
+	current, next := leaders[0], leaders[len(leaders)-1]
+	ch <- c.ClusterCurrentLeader.MustNewConstMetric(1, current)
+	ch <- c.ClusterNextLeader.MustNewConstMetric(1, next)
+	for _, nodekey := range c.config.NodeKeys {
+		ch <- c.ValidatorIsLeader.MustNewConstMetric(BoolToFloat64(nodekey == current), nodekey)
+	}
+}
+
+// collectAuthorizedVoters emits the current and scheduled authorized voters of the tracked vote accounts, flagging
+// pending rotations and whether a scheduled rotation took effect at its epoch boundary.
+func (c *SolanaCollector) collectAuthorizedVoters(ctx context.Context, ch chan<- prometheus.Metric) {
+	epochInfo, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info: %v", err)
+		ch <- c.ValidatorAuthorizedVoter.NewInvalidMetric(err)
+		ch <- c.ValidatorVoterRotationPending.NewInvalidMetric(err)
+		ch <- c.ValidatorVoterRotationApplied.NewInvalidMetric(err)
+		return
+	}
+
+	c.scheduledVotersMu.Lock()
+	defer c.scheduledVotersMu.Unlock()
+	for _, votekey := range c.config.VoteKeys {
+		state, err := c.rpcClient.GetVoteAccountState(ctx, rpc.CommitmentFinalized, votekey)
+		if err != nil {
+			c.logger.Errorf("failed to get vote account state of %s: %v", votekey, err)
+			ch <- c.ValidatorAuthorizedVoter.NewInvalidMetric(err)
+			ch <- c.ValidatorVoterRotationPending.NewInvalidMetric(err)
+			ch <- c.ValidatorVoterRotationApplied.NewInvalidMetric(err)
+			return
+		}
+		current, scheduled := GetAuthorizedVoters(state.AuthorizedVoters, epochInfo.Epoch)
+		for _, voter := range state.AuthorizedVoters {
+			ch <- c.ValidatorAuthorizedVoter.MustNewConstMetric(1, votekey, voter.AuthorizedVoter, toString(voter.Epoch))
+		}
+
+		pending := scheduled != nil && (current == nil || scheduled.AuthorizedVoter != current.AuthorizedVoter)
+		ch <- c.ValidatorVoterRotationPending.MustNewConstMetric(BoolToFloat64(pending), votekey)
+		if pending {
+			c.scheduledVoters[votekey] = *scheduled
+		}
+
+		if rotation, ok := c.scheduledVoters[votekey]; ok && rotation.Epoch <= epochInfo.Epoch {
+			applied := current != nil && current.AuthorizedVoter == rotation.AuthorizedVoter
+			if !applied {
+				c.logger.Warnf(
+					"Authorized voter rotation of %s to %s did not take effect in epoch %d",
+					votekey, rotation.AuthorizedVoter, rotation.Epoch,
+				)
+			}
+			ch <- c.ValidatorVoterRotationApplied.MustNewConstMetric(
+				BoolToFloat64(applied), votekey, toString(rotation.Epoch),
+			)
+		}
+	}
+}
+
+// collectAccountInfos emits whether each tracked account is rent exempt and its margin above the rent-exempt
+// minimum, catching auxiliary accounts which slowly bleed below the threshold. From the same account infos, it also
+// emits how long each account has been unchanged, which for vote and identity accounts is another angle on liveness.
+func (c *SolanaCollector) collectAccountInfos(ctx context.Context, ch chan<- prometheus.Metric) {
+	if c.config.LightMode {
+		return
+	}
+	c.logger.Info("Collecting account infos...")
+	// the rent-exempt minimum only depends on the data size, and most tracked accounts share theirs:
+	minimums := make(map[int64]int64)
+	for _, address := range c.trackedAddresses() {
+		info, slot, err := c.rpcClient.GetAccountInfoWithSlot(ctx, rpc.CommitmentFinalized, address)
+		if errors.Is(err, rpc.ErrAccountNotFound) {
+			c.logger.Warnf("Tracked account %s does not exist, skipping its account info", address)
+			continue
+		}
+		if err != nil {
+			c.logger.Errorf("failed to get account info for %s: %v", address, err)
+			ch <- c.AccountRentExempt.NewInvalidMetric(err)
+			ch <- c.AccountRentExemptMargin.NewInvalidMetric(err)
+			ch <- c.AccountLastWriteSlot.NewInvalidMetric(err)
+			ch <- c.AccountUnchangedSeconds.NewInvalidMetric(err)
+			return
+		}
+		writeSlot, writeTime := c.accountWrites.Observe(address, info, slot, time.Now())
+		ch <- c.AccountLastWriteSlot.MustNewConstMetric(float64(writeSlot), address)
+		ch <- c.AccountUnchangedSeconds.MustNewConstMetric(time.Since(writeTime).Seconds(), address)
+
+		minimum, ok := minimums[info.Space]
+		if !ok {
+			minimum, err = c.rpcClient.GetMinimumBalanceForRentExemption(ctx, rpc.CommitmentFinalized, info.Space)
+			if err != nil {
+				c.logger.Errorf("failed to get minimum balance for rent exemption: %v", err)
+				ch <- c.AccountRentExempt.NewInvalidMetric(err)
+				ch <- c.AccountRentExemptMargin.NewInvalidMetric(err)
+				return
+			}
+			minimums[info.Space] = minimum
+		}
+		margin := info.Lamports - minimum
+		ch <- c.AccountRentExempt.MustNewConstMetric(BoolToFloat64(margin >= 0), address)
+		ch <- c.AccountRentExemptMargin.MustNewConstMetric(float64(margin)/rpc.LamportsInSol, address)
+	}
+	c.logger.Info("Account infos collected.")
+}
+
+// collectNonceAccounts emits the balance and authority of each tracked durable nonce account, and how long since it
+// was last advanced, such that broken automation relying on the nonces (e.g., reward sweeps) is caught quickly.
+func (c *SolanaCollector) collectNonceAccounts(ctx context.Context, ch chan<- prometheus.Metric) {
+	for _, address := range c.config.NonceAccounts {
+		nonce, slot, err := c.rpcClient.GetNonceAccountWithSlot(ctx, rpc.CommitmentFinalized, address)
+		if err != nil {
+			c.logger.Errorf("failed to get nonce account %s: %v", address, err)
+			ch <- c.NonceAccountBalance.NewInvalidMetric(err)
+			ch <- c.NonceAccountAuthority.NewInvalidMetric(err)
+			ch <- c.NonceLastAdvanceSlot.NewInvalidMetric(err)
+			ch <- c.NonceUnadvancedSeconds.NewInvalidMetric(err)
+			continue
+		}
+		ch <- c.NonceAccountBalance.MustNewConstMetric(float64(nonce.Lamports)/rpc.LamportsInSol, address)
+		ch <- c.NonceAccountAuthority.MustNewConstMetric(1, address, nonce.Authority)
+		advanceSlot, advanceTime := c.nonceAdvances.ObserveFingerprint(
+			address, GetStringFingerprint(nonce.Blockhash), slot, time.Now(),
+		)
+		ch <- c.NonceLastAdvanceSlot.MustNewConstMetric(float64(advanceSlot), address)
+		ch <- c.NonceUnadvancedSeconds.MustNewConstMetric(time.Since(advanceTime).Seconds(), address)
+	}
+}
+
+// collectTokenBalances emits the balance of each tracked owner in each tracked SPL token mint, summed over all its
+// token accounts of the mint (zero if it holds none).
+func (c *SolanaCollector) collectTokenBalances(ctx context.Context, ch chan<- prometheus.Metric) {
+	for _, owner := range c.config.TokenOwners {
+		for _, mint := range c.config.TokenMints {
+			balance, err := c.getTokenBalance(ctx, owner, mint)
+			if err != nil {
+				c.logger.Errorf("failed to get %s token balance of %s: %v", mint, owner, err)
+				ch <- c.TokenAccountBalance.NewInvalidMetric(err)
+				continue
+			}
+			ch <- c.TokenAccountBalance.MustNewConstMetric(balance, owner, mint)
+		}
+	}
+}
+
+func (c *SolanaCollector) getTokenBalance(ctx context.Context, owner, mint string) (float64, error) {
+	accounts, err := c.rpcClient.GetTokenAccountsByOwner(ctx, rpc.CommitmentConfirmed, owner, mint)
+	if err != nil {
+		return 0, err
+	}
+	var balance float64
+	for address, account := range accounts {
+		amount, err := account.TokenAmount.UiAmount()
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount of token account %s: %w", address, err)
+		}
+		balance += amount
+	}
+	return balance, nil
+}
+
+// collectWatchedAddresses emits the recent transaction activity of each watched address. Addresses without any
+// transaction have no last activity slot.
+func (c *SolanaCollector) collectWatchedAddresses(ctx context.Context, ch chan<- prometheus.Metric) {
+	if len(c.config.WatchAddresses) == 0 {
+		return
+	}
+	slot, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get confirmed slot: %v", err)
+		ch <- c.AccountRecentTransactions.NewInvalidMetric(err)
+		ch <- c.AccountLastActivitySlot.NewInvalidMetric(err)
+		return
+	}
+	for _, address := range c.config.WatchAddresses {
+		signatures, err := c.rpcClient.GetSignaturesForAddress(
+			ctx, rpc.CommitmentConfirmed, address, rpc.MaxSignaturesLimit,
+		)
+		if err != nil {
+			c.logger.Errorf("failed to get signatures for %s: %v", address, err)
+			ch <- c.AccountRecentTransactions.NewInvalidMetric(err)
+			ch <- c.AccountLastActivitySlot.NewInvalidMetric(err)
+			continue
+		}
+		ch <- c.AccountRecentTransactions.MustNewConstMetric(
+			float64(CountRecentSignatures(signatures, slot-c.config.WatchAddressSlots)), address,
+		)
+		if len(signatures) > 0 {
+			ch <- c.AccountLastActivitySlot.MustNewConstMetric(float64(signatures[0].Slot), address)
+		}
+	}
+}
+
+func (c *SolanaCollector) collectWatchedSignatures(ctx context.Context, ch chan<- prometheus.Metric) {
+	if len(c.config.WatchSignatures) == 0 {
+		return
+	}
+	finalizedSlot, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		c.logger.Errorf("failed to get finalized slot: %v", err)
+		ch <- c.TransactionConfirmed.NewInvalidMetric(err)
+		ch <- c.TransactionSlot.NewInvalidMetric(err)
+		ch <- c.TransactionFailed.NewInvalidMetric(err)
+		return
+	}
+	for _, signature := range c.config.WatchSignatures {
+		status, err := c.signatures.Status(ctx, c.rpcClient, signature, finalizedSlot)
+		if err != nil {
+			c.logger.Errorf("failed to get transaction %s: %v", signature, err)
+			ch <- c.TransactionConfirmed.NewInvalidMetric(err)
+			ch <- c.TransactionSlot.NewInvalidMetric(err)
+			ch <- c.TransactionFailed.NewInvalidMetric(err)
+			continue
+		}
+		ch <- c.TransactionConfirmed.MustNewConstMetric(
+			BoolToFloat64(status.Commitment != ""), signature, string(rpc.CommitmentConfirmed),
+		)
+		ch <- c.TransactionConfirmed.MustNewConstMetric(
+			BoolToFloat64(status.Commitment == rpc.CommitmentFinalized), signature, string(rpc.CommitmentFinalized),
+		)
+		if status.Commitment != "" {
+			ch <- c.TransactionSlot.MustNewConstMetric(float64(status.Slot), signature)
+			ch <- c.TransactionFailed.MustNewConstMetric(BoolToFloat64(status.Failed), signature)
+		}
+	}
+}
+
+// trackedAddresses returns all addresses to track: explicitly provided balance addresses, node keys, vote keys,
+// and the validator identity and vote account if provided.
+func (c *SolanaCollector) trackedAddresses() []string {
+	var validatorAddresses []string
+	for _, address := range []string{c.config.ValidatorIdentity, c.config.VoteAccountPubkey} {
+		if address != "" {
+			validatorAddresses = append(validatorAddresses, address)
+		}
+	}
+	return CombineUnique(c.config.BalanceAddresses, c.config.NodeKeys, c.config.VoteKeys, validatorAddresses)
+}
+
 func (c *SolanaCollector) collectBalances(ctx context.Context, ch chan<- prometheus.Metric) {
 	if c.config.LightMode {
 		c.logger.Debug("Skipping balance collection in light mode.")
@@ -359,39 +1841,67 @@ func (c *SolanaCollector) collectBalances(ctx context.Context, ch chan<- prometh
 	}
 	c.logger.Info("Collecting balances...")
 	
-	// Combine all addresses to track: explicitly provided balance addresses, node keys, vote keys
-	// This allows tracking balances of identity (nodekey) and vote account addresses
-	addressesToTrack := CombineUnique(c.config.BalanceAddresses, c.config.NodeKeys, c.config.VoteKeys)
-	
-	// Add validator identity if provided
-	if c.config.ValidatorIdentity != "" {
-		addressesToTrack = append(addressesToTrack, c.config.ValidatorIdentity)
-	}
-	
-	// Add vote account if provided
-	if c.config.VoteAccountPubkey != "" {
-		addressesToTrack = append(addressesToTrack, c.config.VoteAccountPubkey)
-	}
-	
+	addressesToTrack := c.trackedAddresses()
 	if len(addressesToTrack) == 0 {
 		c.logger.Info("No addresses to track balances for, skipping balance collection.")
 		return
 	}
 	
-	c.logger.Infof("Fetching balances for %d addresses", len(addressesToTrack))
-	balances, err := FetchBalances(ctx, c.rpcClient, addressesToTrack)
+	// balances received through geyser need not be polled:
+	balances := make(map[string]float64)
+	var addressesToFetch []string
+	for _, address := range addressesToTrack {
+		if balance, ok := c.geyserBalances.Get(address); ok {
+			balances[address] = balance
+		} else {
+			addressesToFetch = append(addressesToFetch, address)
+		}
+	}
+
+	c.logger.Infof("Fetching balances for %d addresses", len(addressesToFetch))
+	fetched, err := FetchBalances(ctx, c.rpcClient, addressesToFetch)
 	if err != nil {
 		c.logger.Errorf("failed to get balances: %v", err)
 		ch <- c.AccountBalances.NewInvalidMetric(err)
+		ch <- c.AccountBalanceBelowThreshold.NewInvalidMetric(err)
 		return
 	}
+	for address, balance := range fetched {
+		balances[address] = balance
+	}
 
 	for address, balance := range balances {
 		ch <- c.AccountBalances.MustNewConstMetric(balance, address)
 	}
+	c.emitBalanceThresholds(ch, balances)
 	c.logger.Infof("Balances collected for %d addresses", len(balances))
 }
 
+// emitBalanceThresholds emits whether the balances of the identity and vote accounts are below their thresholds (if
+// set), such that alerts need not hardcode them.
+func (c *SolanaCollector) emitBalanceThresholds(ch chan<- prometheus.Metric, balances map[string]float64) {
+	identities := CombineUnique(c.config.NodeKeys, []string{c.config.ValidatorIdentity})
+	votekeys := CombineUnique(c.config.VoteKeys, []string{c.config.VoteAccountPubkey})
+	for _, purpose := range []struct {
+		name      string
+		threshold float64
+		addresses []string
+	}{
+		{PurposeIdentity, c.config.IdentityBalanceThreshold, identities},
+		{PurposeVote, c.config.VoteBalanceThreshold, votekeys},
+	} {
+		if purpose.threshold == 0 {
+			continue
+		}
+		for _, address := range purpose.addresses {
+			if balance, ok := balances[address]; ok {
+				below := BoolToFloat64(balance < purpose.threshold)
+				ch <- c.AccountBalanceBelowThreshold.MustNewConstMetric(below, address, purpose.name)
+			}
+		}
+	}
+}
+
 func (c *SolanaCollector) collectValidatorCredits(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Info("Starting validator credits collection...")
 	c.logger.Infof("Validator identity: %s", c.config.ValidatorIdentity)
@@ -432,24 +1942,75 @@ func (c *SolanaCollector) collectValidatorCommission(ctx context.Context, ch cha
 	}
 	
 	c.logger.Info("Collecting validator commission rates...")
-	voteAccounts, err := c.rpcClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	voteAccounts, err := c.clusterClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
 	if err != nil {
 		c.logger.Errorf("failed to get vote accounts for commission data: %v", err)
 		ch <- c.ValidatorCommission.NewInvalidMetric(err)
+		ch <- c.ValidatorCommissionPercentile.NewInvalidMetric(err)
+		ch <- c.ClusterMeanCommission.NewInvalidMetric(err)
+		ch <- c.ClusterMedianCommission.NewInvalidMetric(err)
 		return
 	}
 
+	// changes are tracked as of the epoch and slot they are observed at:
+	epochInfo, err := c.clusterClient.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("failed to get epoch info for commission changes: %v", err)
+		ch <- c.ValidatorCommissionLastChangeEpoch.NewInvalidMetric(err)
+		ch <- c.ValidatorCommissionLastChangeSlot.NewInvalidMetric(err)
+	}
+
 	// Collect commission for all configured nodekeys or all validators if comprehensive tracking is enabled
-	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+	accounts := append(voteAccounts.Current, voteAccounts.Delinquent...)
+	for _, account := range accounts {
 		if slices.Contains(c.config.NodeKeys, account.NodePubkey) || c.config.ComprehensiveVoteAccountTracking {
 			ch <- c.ValidatorCommission.MustNewConstMetric(float64(account.Commission), account.NodePubkey)
 			c.logger.Debugf("Collected commission rate %d%% for validator %s", account.Commission, account.NodePubkey)
+			if epochInfo != nil {
+				c.trackCommission(ch, account, epochInfo)
+			}
+		}
+		// the percentile is only meaningful for the validators we are explicitly tracking:
+		if slices.Contains(c.config.NodeKeys, account.NodePubkey) || account.NodePubkey == c.config.ValidatorIdentity {
+			percentile := GetCommissionPercentile(accounts, account.Commission)
+			ch <- c.ValidatorCommissionPercentile.MustNewConstMetric(percentile, account.NodePubkey)
 		}
 	}
+
+	mean, median := GetCommissionStats(accounts)
+	ch <- c.ClusterMeanCommission.MustNewConstMetric(mean)
+	ch <- c.ClusterMedianCommission.MustNewConstMetric(median)
 	
 	c.logger.Info("Validator commission rates collected.")
 }
 
+// trackCommission counts the changes of the commission of the validator, and emits when it last changed.
+func (c *SolanaCollector) trackCommission(
+	ch chan<- prometheus.Metric, account rpc.VoteAccount, epochInfo *rpc.EpochInfo,
+) {
+	nodekey := account.NodePubkey
+	change, changed := c.commissions.Observe(nodekey, account.Commission, epochInfo.Epoch, epochInfo.AbsoluteSlot)
+	// the counters start at zero, such that the first change shows up in increase():
+	increases := c.ValidatorCommissionChanges.WithLabelValues(nodekey, DirectionIncrease)
+	decreases := c.ValidatorCommissionChanges.WithLabelValues(nodekey, DirectionDecrease)
+	if changed {
+		if change.Commission > change.Previous {
+			c.logger.Warnf(
+				"Commission of %s increased from %d%% to %d%% in epoch %d",
+				nodekey, change.Previous, change.Commission, change.Epoch,
+			)
+			increases.Inc()
+		} else {
+			c.logger.Infof("Commission of %s decreased from %d%% to %d%%", nodekey, change.Previous, change.Commission)
+			decreases.Inc()
+		}
+	}
+	if lastChange, ok := c.commissions.LastChange(nodekey); ok {
+		ch <- c.ValidatorCommissionLastChangeEpoch.MustNewConstMetric(float64(lastChange.Epoch), nodekey)
+		ch <- c.ValidatorCommissionLastChangeSlot.MustNewConstMetric(float64(lastChange.Slot), nodekey)
+	}
+}
+
 func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Info("Collecting health...")
 
@@ -460,7 +2021,18 @@ func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheu
 		ch <- c.NodeIsHealthy.NewInvalidMetric(err)
 	} else {
 		ch <- c.NodeIsHealthy.MustNewConstMetric(BoolToFloat64(isHealthy))
+		state := StateUnhealthy
+		if isHealthy {
+			state = StateHealthy
+		}
+		if previous, held, ok := c.transitions.Observe("health", state, time.Now()); ok {
+			c.NodeHealthStateDuration.WithLabelValues(previous).Observe(held.Seconds())
+		}
+	}
+	if transition, ok := c.transitions.LastTransition("health"); ok {
+		ch <- c.NodeHealthLastTransition.MustNewConstMetric(float64(transition.Unix()))
 	}
+	c.NodeHealthStateDuration.Collect(ch)
 
 	if numSlotsBehindErr != nil {
 		c.logger.Errorf("failed to determine number of slots behind: %v", numSlotsBehindErr)
@@ -473,6 +2045,33 @@ func (c *SolanaCollector) collectHealth(ctx context.Context, ch chan<- prometheu
 	return
 }
 
+// CheckVoteAccountIdentity verifies on-chain that the configured vote account votes on behalf of the configured
+// validator identity, catching copy-paste configuration mistakes which would otherwise silently produce wrong
+// validator metrics. It returns whether they mismatch, and is run at startup (for the log) as well as on every
+// collection, such that a failed check (e.g., of a transient RPC error) is not stuck with.
+func (c *SolanaCollector) CheckVoteAccountIdentity(ctx context.Context) (bool, error) {
+	nodekey, err := GetVoteAccountNodePubkey(ctx, c.rpcClient, c.config.VoteAccountPubkey)
+	if err != nil {
+		return false, fmt.Errorf("failed to check vote account identity: %w", err)
+	}
+	return nodekey != c.config.ValidatorIdentity, nil
+}
+
+func (c *SolanaCollector) collectIdentityMismatch(ctx context.Context, ch chan<- prometheus.Metric) {
+	if c.config.ValidatorIdentity == "" || c.config.VoteAccountPubkey == "" {
+		return
+	}
+	mismatch, err := c.CheckVoteAccountIdentity(ctx)
+	if err != nil {
+		c.logger.Error(err)
+		ch <- c.ValidatorIdentityMismatch.NewInvalidMetric(err)
+		return
+	}
+	ch <- c.ValidatorIdentityMismatch.MustNewConstMetric(
+		BoolToFloat64(mismatch), c.config.VoteAccountPubkey, c.config.ValidatorIdentity,
+	)
+}
+
 // Collects both vote distance and root distance in a single call to ensure consistency
 func (c *SolanaCollector) collectVoteAndRootDistance(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Debug("Collecting vote and root distance metrics...")
@@ -492,8 +2091,13 @@ func (c *SolanaCollector) collectVoteAndRootDistance(ctx context.Context, ch cha
 		return
 	}
 	
-	// Get vote accounts to find the last vote and root slot for our validator
-	voteAccounts, err := c.rpcClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	// Get vote accounts to find the last vote and root slot for our validator, just fetching our own if we can:
+	var voteAccounts *rpc.VoteAccounts
+	if c.config.VoteAccountPubkey != "" {
+		voteAccounts, err = c.clusterClient.GetVoteAccountsOf(ctx, rpc.CommitmentConfirmed, c.config.VoteAccountPubkey)
+	} else {
+		voteAccounts, err = c.clusterClient.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	}
 	if err != nil {
 		c.logger.Errorf("failed to get vote accounts: %v", err)
 		ch <- c.ValidatorVoteDistance.NewInvalidMetric(err)
@@ -537,22 +2141,21 @@ func (c *SolanaCollector) collectVoteAndRootDistance(ctx context.Context, ch cha
 	// Export metrics
 	ch <- c.ValidatorVoteDistance.MustNewConstMetric(voteDistance, c.config.ValidatorIdentity)
 	ch <- c.ValidatorRootDistance.MustNewConstMetric(rootDistance, c.config.ValidatorIdentity)
+	if c.config.VoteDistanceAlert.Enabled() || c.config.RootDistanceAlert.Enabled() {
+		firing := c.lagAlert.Observe(currentSlot-lastVote, lastVote-rootSlot)
+		ch <- c.ValidatorVoteLagAlert.MustNewConstMetric(BoolToFloat64(firing), c.config.ValidatorIdentity)
+	}
 	
 	c.logger.Debugf("Collected metrics - Vote distance: %f, Root distance: %f", voteDistance, rootDistance)
 }
 
-// Start a fast collection goroutine for time-sensitive metrics
+// Start a fast collection goroutine for time-sensitive metrics.
+// The samples are cached, and served (along with their age) on every scrape until replaced.
 func (c *SolanaCollector) StartFastMetricsCollection(interval time.Duration) {
-	// Make the fast metrics channel buffered to avoid blocking
-	c.fastMetricsCh = make(chan prometheus.Metric, 100)
-	
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		
-		// Create a map to track the latest metrics by descriptor
-		latestMetrics := make(map[string]prometheus.Metric)
-		
 		for {
 			select {
 			case <-ticker.C:
@@ -562,42 +2165,17 @@ func (c *SolanaCollector) StartFastMetricsCollection(interval time.Duration) {
 				// Create a temporary channel for collecting metrics
 				tempCh := make(chan prometheus.Metric, 10)
 				
-				// Clear previous metrics before collecting new ones
-				latestMetrics = make(map[string]prometheus.Metric)
-				
 				// Collect metrics in a background goroutine to avoid deadlock
 				go func() {
 					defer close(tempCh)
 					c.collectVoteAndRootDistance(ctx, tempCh)
 				}()
 				
-				// Collect metrics from the temporary channel, storing only the latest value for each metric
+				var metrics []prometheus.Metric
 				for m := range tempCh {
-					desc := m.Desc().String()
-					latestMetrics[desc] = m
-				}
-				
-				// Drain the existing fast metrics channel
-				for {
-					select {
-					case <-c.fastMetricsCh:
-						// Just drain, we'll replace with new values
-					default:
-						goto drained
-					}
-				}
-			drained:
-				
-				// Send the latest metrics to the fast metrics channel
-				for _, m := range latestMetrics {
-					select {
-					case c.fastMetricsCh <- m:
-						// Successfully sent
-					default:
-						// Channel full, just log and continue
-						c.logger.Debug("Fast metrics channel full, dropping metric")
-					}
+					metrics = append(metrics, m)
 				}
+				c.fastMetrics.Update(metrics, time.Now())
 				
 				cancel()
 			case <-c.stopFastCollection:
@@ -615,64 +2193,183 @@ func (c *SolanaCollector) StopFastMetricsCollection() {
 	c.logger.Info("Stopped fast metrics collection")
 }
 
+// collectRpcNode emits the RPC-focused metrics of rpc-node mode: the latency of common read methods, and
+// whether its accounts index serves the sampled accounts.
+func (c *SolanaCollector) collectRpcNode(ctx context.Context, ch chan<- prometheus.Metric) {
+	probes := map[string]func() error{
+		"getSlot": func() error {
+			_, err := c.rpcClient.GetSlot(ctx, rpc.CommitmentProcessed)
+			return err
+		},
+		"getEpochInfo": func() error {
+			_, err := c.rpcClient.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+			return err
+		},
+		"getAccountInfo": func() error {
+			_, err := c.rpcClient.GetClock(ctx, rpc.CommitmentConfirmed)
+			return err
+		},
+	}
+	for method, probe := range probes {
+		start := time.Now()
+		if err := probe(); err != nil {
+			// failed probes are left out rather than failing the whole scrape:
+			c.logger.Errorf("%s probe failed: %v", method, err)
+			continue
+		}
+		ch <- c.NodeRpcMethodLatency.MustNewConstMetric(time.Since(start).Seconds(), method)
+	}
+
+	for _, address := range c.config.RpcNodeSampleAccounts {
+		_, err := c.rpcClient.GetAccountInfo(ctx, rpc.CommitmentConfirmed, address)
+		if err != nil {
+			c.logger.Warnf("failed to get account info of sampled account %s: %v", address, err)
+		}
+		ch <- c.NodeAccountIndexHealthy.MustNewConstMetric(BoolToFloat64(err == nil), address)
+	}
+}
+
+// collectReferenceLag emits how far the node's slot and block height are behind those of the reference RPC, at the
+// same commitment. Unlike the node's own health check, this lag is measured against an external source of truth.
+func (c *SolanaCollector) collectReferenceLag(ctx context.Context, ch chan<- prometheus.Metric) {
+	for _, commitment := range []rpc.Commitment{rpc.CommitmentConfirmed, rpc.CommitmentFinalized} {
+		lag, err := GetReferenceLag(ctx, c.rpcClient, c.clusterClient, commitment, (*rpc.Client).GetSlot)
+		if err != nil {
+			c.logger.Errorf("failed to get %s slot lag behind reference: %v", commitment, err)
+			ch <- c.NodeSlotsBehindReference.NewInvalidMetric(err)
+		} else {
+			ch <- c.NodeSlotsBehindReference.MustNewConstMetric(float64(lag), string(commitment))
+		}
+		lag, err = GetReferenceLag(ctx, c.rpcClient, c.clusterClient, commitment, (*rpc.Client).GetBlockHeight)
+		if err != nil {
+			c.logger.Errorf("failed to get %s block height lag behind reference: %v", commitment, err)
+			ch <- c.NodeBlockHeightBehindReference.NewInvalidMetric(err)
+		} else {
+			ch <- c.NodeBlockHeightBehindReference.MustNewConstMetric(float64(lag), string(commitment))
+		}
+	}
+}
+
+// collectWithCost runs a collector, and emits the RPC calls (and response bytes) it made, such that the RPC cost of
+// each enabled feature can be seen. The failed metrics of the collector are passed through its error tolerance.
+func (c *SolanaCollector) collectWithCost(
+	ctx context.Context,
+	ch chan<- prometheus.Metric,
+	name string,
+	collect func(context.Context, chan<- prometheus.Metric),
+) {
+	var stats rpc.CallStats
+	collected := make(chan prometheus.Metric)
+	done := make(chan []prometheus.Metric)
+	go func() {
+		var metrics []prometheus.Metric
+		for metric := range collected {
+			metrics = append(metrics, metric)
+		}
+		done <- metrics
+	}()
+	collect(rpc.WithCallStats(ctx, &stats), collected)
+	close(collected)
+
+	metrics, staleness, ok := c.errorTolerance.Filter(name, <-done, time.Now())
+	for _, metric := range metrics {
+		ch <- metric
+	}
+	if ok {
+		ch <- c.CollectorStaleness.MustNewConstMetric(staleness.Seconds(), name)
+	}
+	ch <- c.CollectorRpcCalls.MustNewConstMetric(float64(stats.Calls()), name)
+	ch <- c.CollectorRpcResponseBytes.MustNewConstMetric(float64(stats.Bytes()), name)
+}
+
 func (c *SolanaCollector) Collect(ch chan<- prometheus.Metric) {
 	c.logger.Info("========== BEGIN COLLECTION ==========")
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	
-	// Drain any metrics from the fast collection channel
-	for {
-		select {
-		case metric := <-c.fastMetricsCh:
-			ch <- metric
-		default:
-			// No more metrics waiting, exit the loop
-			goto done
-		}
-	}
-done:
+	// Emit the latest samples of the fast metrics collection (if running)
+	c.fastMetrics.Collect(ch, c.FastMetricAge, time.Now())
 
 	// Only collect vote/root distance if fast metrics collection is disabled
 	// If fast metrics are enabled, those metrics are ONLY collected via the fast path
 	if c.config.FastMetricsInterval == 0 {
-		c.collectVoteAndRootDistance(ctx, ch)
+		c.collectWithCost(ctx, ch, "vote_and_root_distance", c.collectVoteAndRootDistance)
 	}
 
 	c.logger.Info("Collecting health metrics...")
-	c.collectHealth(ctx, ch)
+	c.collectWithCost(ctx, ch, "health", c.collectHealth)
 	
 	// These are always essential metrics even in light mode
 	c.logger.Info("Collecting minimum ledger slot...")
-	c.collectMinimumLedgerSlot(ctx, ch)
+	c.collectWithCost(ctx, ch, "minimum_ledger_slot", c.collectMinimumLedgerSlot)
 	
 	c.logger.Info("Collecting first available block...")
-	c.collectFirstAvailableBlock(ctx, ch)
+	c.collectWithCost(ctx, ch, "first_available_block", c.collectFirstAvailableBlock)
+	c.collectWithCost(ctx, ch, "max_slots", c.collectMaxSlots)
+
+	c.collectWithCost(ctx, ch, "clock_drift", c.collectClockDrift)
+	c.collectWithCost(ctx, ch, "epoch_progress", c.collectEpochProgress)
+	c.collectWithCost(ctx, ch, "block_time", c.collectBlockTime)
+	c.collectWithCost(ctx, ch, "latest_blockhash", c.collectLatestBlockhash)
 	
+	if c.config.RpcNodeMode {
+		c.logger.Info("Collecting rpc node metrics...")
+		c.collectWithCost(ctx, ch, "rpc_node", c.collectRpcNode)
+	}
+	if c.config.ReferenceRpcUrl != "" {
+		c.collectWithCost(ctx, ch, "reference_lag", c.collectReferenceLag)
+	}
+
 	if !c.config.LightMode {
 		c.logger.Info("Collecting vote accounts...")
-		c.collectVoteAccounts(ctx, ch)
+		c.collectWithCost(ctx, ch, "vote_accounts", c.collectVoteAccounts)
+		c.ValidatorDelinquencyStateDuration.Collect(ch)
+
+		c.logger.Info("Collecting supply...")
+		c.collectWithCost(ctx, ch, "supply", c.collectSupply)
+		c.collectWithCost(ctx, ch, "inflation_governor", c.collectInflationGovernor)
+		c.collectWithCost(ctx, ch, "epoch_schedule", c.collectEpochSchedule)
+		c.collectWithCost(ctx, ch, "transaction_rates", c.collectTransactionRates)
 		
 		c.logger.Info("Collecting validator commission...")
-		c.collectValidatorCommission(ctx, ch)
+		c.collectWithCost(ctx, ch, "validator_commission", c.collectValidatorCommission)
+		c.ValidatorCommissionChanges.Collect(ch)
+
+		c.logger.Info("Collecting gossip connectivity...")
+		c.collectWithCost(ctx, ch, "gossip_connectivity", c.collectGossipConnectivity)
+
+		c.logger.Info("Collecting leaders...")
+		c.collectWithCost(ctx, ch, "leaders", c.collectLeaders)
+
+		c.logger.Info("Collecting authorized voters...")
+		c.collectWithCost(ctx, ch, "authorized_voters", c.collectAuthorizedVoters)
 	}
 	
 	c.logger.Info("Collecting version...")
-	c.collectVersion(ctx, ch)
+	c.collectWithCost(ctx, ch, "version", c.collectVersion)
 	
 	c.logger.Info("Collecting identity...")
-	c.collectIdentity(ctx, ch)
+	c.collectWithCost(ctx, ch, "identity", c.collectIdentity)
+	c.NodeIdentityChanges.Collect(ch)
 	
 	c.logger.Info("Collecting balances...")
-	c.collectBalances(ctx, ch)
+	c.collectWithCost(ctx, ch, "balances", c.collectBalances)
+	c.collectWithCost(ctx, ch, "account_infos", c.collectAccountInfos)
+	c.collectWithCost(ctx, ch, "nonce_accounts", c.collectNonceAccounts)
+	c.collectWithCost(ctx, ch, "token_balances", c.collectTokenBalances)
+	c.collectWithCost(ctx, ch, "watched_addresses", c.collectWatchedAddresses)
+	c.collectWithCost(ctx, ch, "watched_signatures", c.collectWatchedSignatures)
+
+	c.collectWithCost(ctx, ch, "identity_mismatch", c.collectIdentityMismatch)
 	
 	// Validator-specific metrics - credits are available in light mode if identity is configured
 	if c.config.ValidatorIdentity != "" && c.config.VoteAccountPubkey != "" {
 		c.logger.Info("Collecting validator credits...")
-		c.collectValidatorCredits(ctx, ch)
+		c.collectWithCost(ctx, ch, "validator_credits", c.collectValidatorCredits)
 	} else if !c.config.LightMode {
 		// In regular mode without specific validator
 		c.logger.Info("Collecting validator credits...")
-		c.collectValidatorCredits(ctx, ch)
+		c.collectWithCost(ctx, ch, "validator_credits", c.collectValidatorCredits)
 	}
 
 	c.logger.Info("=========== END COLLECTION ===========")
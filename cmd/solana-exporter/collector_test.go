@@ -3,13 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
-	"os"
-	"reflect"
-	"runtime"
+	"net/http"
+	"net/http/httptest"
 	"slices"
 	"strings"
 	"testing"
@@ -17,10 +15,15 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/seedfourtytwo/solana-exporter/pkg/api"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/stretchr/testify/assert"
 )
 
+// SimulatorGenesisTimestamp is the unix timestamp of slot 0 of the simulator
+const SimulatorGenesisTimestamp = 1_700_000_000
+
 type (
 	Simulator struct {
 		Server *rpc.MockServer
@@ -67,6 +70,28 @@ func NewSimulator(t *testing.T, slot int) (*Simulator, *rpc.Client) {
 			"getIdentity":       map[string]string{"identity": "testIdentity"},
 			"getLeaderSchedule": leaderSchedule,
 			"getHealth":         "ok",
+			"getBlockTime":      1_700_000_000,
+			// 120 slots per minute, i.e., 500ms slots:
+			"getRecentPerformanceSamples": []map[string]int{
+				{"slot": 30, "numSlots": 120, "numTransactions": 600, "numNonVoteTransactions": 120, "samplePeriodSecs": 60},
+			},
+			// the simulator's epochs are shorter than the minimum, which only holds without warmup:
+			"getEpochSchedule": map[string]any{"slotsPerEpoch": 24, "leaderScheduleSlotOffset": 24, "warmup": false},
+			"getLatestBlockhash": map[string]any{
+				"context": map[string]int{"slot": 1},
+				"value":   map[string]any{"blockhash": "hash1", "lastValidBlockHeight": 150},
+			},
+			// ccc is not visible in gossip:
+			"getClusterNodes": []map[string]any{{"pubkey": "aaa"}, {"pubkey": "bbb"}, {"pubkey": "xxx"}},
+			// 1.5 SOL, such that "aaa" is not rent exempt:
+			"getMinimumBalanceForRentExemption": rpc.LamportsInSol * 3 / 2,
+			"getInflationGovernor": map[string]float64{
+				"initial": 0.08, "terminal": 0.015, "taper": 0.15, "foundation": 0.05, "foundationTerm": 7,
+			},
+			"getSupply": map[string]any{
+				"context": map[string]int{"slot": 1},
+				"value":   map[string]int{"total": 600 * rpc.LamportsInSol, "circulating": 500 * rpc.LamportsInSol},
+			},
 		},
 		nil,
 		map[string]int{
@@ -85,6 +110,11 @@ func NewSimulator(t *testing.T, slot int) (*Simulator, *rpc.Client) {
 		nil,
 		validatorInfos,
 	)
+	for i, votekey := range votekeys {
+		mockServer.SetOpt(rpc.AccountInfoOpt, votekey, newVoteAccountInfo(
+			nodekeys[i], (i+4)*rpc.LamportsInSol, rpc.AuthorizedVoter{AuthorizedVoter: nodekeys[i], Epoch: 0},
+		))
+	}
 	simulator := Simulator{
 		Slot:                    0,
 		Server:                  mockServer,
@@ -112,6 +142,27 @@ func NewSimulator(t *testing.T, slot int) (*Simulator, *rpc.Client) {
 	return &simulator, client
 }
 
+// newVoteAccountInfo returns the jsonParsed account info of a vote account with the provided authorized voters.
+func newVoteAccountInfo(nodekey string, lamports int, voters ...rpc.AuthorizedVoter) map[string]any {
+	return map[string]any{
+		"lamports": lamports,
+		"owner":    VoteProgram,
+		"space":    3762,
+		"data": map[string]any{
+			"program": "vote",
+			"parsed": map[string]any{
+				"type": "vote",
+				"info": map[string]any{
+					"nodePubkey":           nodekey,
+					"authorizedWithdrawer": nodekey,
+					"commission":           5,
+					"authorizedVoters":     voters,
+				},
+			},
+		},
+	}
+}
+
 func (c *Simulator) Run(ctx context.Context) {
 	for {
 		select {
@@ -130,13 +181,17 @@ func (c *Simulator) Run(ctx context.Context) {
 }
 
 func (c *Simulator) getLeader() string {
-	index := c.Slot % c.EpochSize
+	return c.getLeaderAt(c.Slot)
+}
+
+func (c *Simulator) getLeaderAt(slot int) string {
+	index := slot % c.EpochSize
 	for leader, slots := range c.LeaderSchedule {
 		if slices.Contains(slots, index) {
 			return leader
 		}
 	}
-	panic(fmt.Sprintf("leader not found at slot %d", c.Slot))
+	panic(fmt.Sprintf("leader not found at slot %d", slot))
 }
 
 func (c *Simulator) PopulateSlot(slot int) {
@@ -173,6 +228,13 @@ func (c *Simulator) PopulateSlot(slot int) {
 		"getSlot",
 		slot,
 	)
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getBlockHeight", c.BlockHeight)
+	// the leaders up to the start of the next leader window:
+	var slotLeaders []string
+	for leaderSlot := slot; leaderSlot <= slot-slot%LeaderRotationSlots+LeaderRotationSlots; leaderSlot++ {
+		slotLeaders = append(slotLeaders, c.getLeaderAt(leaderSlot))
+	}
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getSlotLeaders", slotLeaders)
 	c.Server.SetOpt(
 		rpc.EasyResultsOpt,
 		"getEpochInfo",
@@ -185,6 +247,25 @@ func (c *Simulator) PopulateSlot(slot int) {
 			"transactionCount": c.TransactionCount,
 		},
 	)
+	epochStartTimestamp := SimulatorGenesisTimestamp + c.Epoch*c.EpochSize*2/5
+	c.Server.SetOpt(rpc.AccountInfoOpt, rpc.ClockSysvar, map[string]any{
+		"lamports": 1169280,
+		"owner":    "Sysvar1111111111111111111111111111111111111",
+		"data": map[string]any{
+			"program": "sysvar",
+			"parsed": map[string]any{
+				"type": "clock",
+				"info": map[string]any{
+					"slot":                slot,
+					"epoch":               c.Epoch,
+					"epochStartTimestamp": epochStartTimestamp,
+					"leaderScheduleEpoch": c.Epoch + 1,
+					// every slot takes exactly 400ms, rounded down to the second:
+					"unixTimestamp": epochStartTimestamp + (slot%c.EpochSize)*2/5,
+				},
+			},
+		},
+	})
 	c.Server.SetOpt(
 		rpc.EasyResultsOpt,
 		"minimumLedgerSlot",
@@ -195,6 +276,9 @@ func (c *Simulator) PopulateSlot(slot int) {
 		"getFirstAvailableBlock",
 		int(math.Max(0, float64(slot-c.EpochSize))),
 	)
+	// shreds of the next slot are already coming in:
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getMaxRetransmitSlot", slot+1)
+	c.Server.SetOpt(rpc.EasyResultsOpt, "getMaxShredInsertSlot", slot+1)
 }
 
 func newTestConfig(simulator *Simulator, fast bool) *ExporterConfig {
@@ -215,6 +299,8 @@ func newTestConfig(simulator *Simulator, fast bool) *ExporterConfig {
 		LightMode:                        false,
 		SlotPace:                         pace,
 		ActiveIdentity:                   simulator.Nodekeys[0],
+		ValidatorIdentity:                simulator.Nodekeys[0],
+		VoteAccountPubkey:                simulator.Votekeys[0],
 		// we need to set the epoch cleanup time to long enough such that we can test that the final state for the
 		// previous epoch is correct before cleaning it. Ideally I would like a better way of doing this than simply
 		// "waiting long enough", but this should do for now
@@ -227,7 +313,7 @@ func TestSolanaCollector(t *testing.T) {
 	simulator, client := NewSimulator(t, 35)
 	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", rpc.MainnetGenesisHash)
 
-	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
 	prometheus.NewPedanticRegistry().MustRegister(collector)
 
 	stake := float64(1_000_000) / rpc.LamportsInSol
@@ -241,6 +327,21 @@ func TestSolanaCollector(t *testing.T) {
 		collector.ClusterActiveStake.makeCollectionTest(
 			NewLV(3 * stake),
 		),
+		collector.ClusterTotalSupply.makeCollectionTest(
+			NewLV(600),
+		),
+		collector.ClusterCirculatingSupply.makeCollectionTest(
+			NewLV(500),
+		),
+		collector.ClusterInflationInitial.makeCollectionTest(
+			NewLV(0.08),
+		),
+		collector.ClusterInflationTerminal.makeCollectionTest(
+			NewLV(0.015),
+		),
+		collector.ClusterInflationFoundationTerm.makeCollectionTest(
+			NewLV(7),
+		),
 		collector.ValidatorLastVote.makeCollectionTest(
 			NewLV(33, "aaa", "AAA"),
 			NewLV(32, "bbb", "BBB"),
@@ -266,8 +367,65 @@ func TestSolanaCollector(t *testing.T) {
 			NewLV(3, StateCurrent),
 			NewLV(0, StateDelinquent),
 		),
+		collector.ValidatorCommissionPercentile.makeCollectionTest(
+			NewLV(50, "aaa"),
+			NewLV(50, "bbb"),
+			NewLV(50, "ccc"),
+		),
+		collector.ClusterMeanCommission.makeCollectionTest(
+			NewLV(0),
+		),
+		collector.ClusterMedianCommission.makeCollectionTest(
+			NewLV(0),
+		),
 		collector.NodeVersion.makeCollectionTest(
-			NewLV(1, "v1.0.0"),
+			NewLV(1, ClientAgave, "v1.0.0"),
+		),
+		collector.AccountRentExempt.makeCollectionTest(
+			NewLV(0, "aaa"),
+			NewLV(1, "bbb"),
+			NewLV(1, "ccc"),
+			NewLV(1, "AAA"),
+			NewLV(1, "BBB"),
+			NewLV(1, "CCC"),
+		),
+		collector.AccountRentExemptMargin.makeCollectionTest(
+			NewLV(-0.5, "aaa"),
+			NewLV(0.5, "bbb"),
+			NewLV(1.5, "ccc"),
+			NewLV(2.5, "AAA"),
+			NewLV(3.5, "BBB"),
+			NewLV(4.5, "CCC"),
+		),
+		collector.AccountLastWriteSlot.makeCollectionTest(
+			NewLV(1, "aaa"),
+			NewLV(1, "bbb"),
+			NewLV(1, "ccc"),
+			NewLV(1, "AAA"),
+			NewLV(1, "BBB"),
+			NewLV(1, "CCC"),
+		),
+		collector.NodeGossipPeers.makeCollectionTest(
+			NewLV(3),
+		),
+		collector.NodeGossipVisibleStake.makeCollectionTest(
+			NewLV(2.0/3),
+		),
+		collector.NodeGossipPeersByVersion.makeCollectionTest(
+			NewLV(3, UnknownVersion),
+		),
+		collector.NodeGossipIdentityPresent.makeCollectionTest(
+			NewLV(0, "testIdentity"),
+		),
+		collector.ValidatorAuthorizedVoter.makeCollectionTest(
+			NewLV(1, "aaa", "0", "AAA"),
+			NewLV(1, "bbb", "0", "BBB"),
+			NewLV(1, "ccc", "0", "CCC"),
+		),
+		collector.ValidatorVoterRotationPending.makeCollectionTest(
+			NewLV(0, "AAA"),
+			NewLV(0, "BBB"),
+			NewLV(0, "CCC"),
 		),
 		collector.NodeIdentity.makeCollectionTest(
 			NewLV(1, "testIdentity"),
@@ -308,7 +466,7 @@ func TestSolanaCollector(t *testing.T) {
 func TestSolanaCollector_collectHealth(t *testing.T) {
 	simulator, client := NewSimulator(t, 0)
 
-	collector := NewSolanaCollector(client, newTestConfig(simulator, false))
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
 	prometheus.NewPedanticRegistry().MustRegister(collector)
 
 	t.Run("healthy", func(t *testing.T) {
@@ -348,3 +506,576 @@ func TestSolanaCollector_collectHealth(t *testing.T) {
 		}
 	})
 }
+
+func TestSolanaCollector_CheckVoteAccountIdentity(t *testing.T) {
+	simulator, client := NewSimulator(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Run("matching", func(t *testing.T) {
+		collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+		mismatch, err := collector.CheckVoteAccountIdentity(ctx)
+		assert.NoError(t, err)
+		assert.False(t, mismatch)
+		test := collector.ValidatorIdentityMismatch.makeCollectionTest(NewLV(0, "aaa", "AAA"))
+		err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	})
+
+	t.Run("mismatching", func(t *testing.T) {
+		config := newTestConfig(simulator, false)
+		config.VoteAccountPubkey = "BBB"
+		collector := NewSolanaCollector(client, config, nil)
+		mismatch, err := collector.CheckVoteAccountIdentity(ctx)
+		assert.NoError(t, err)
+		assert.True(t, mismatch)
+		test := collector.ValidatorIdentityMismatch.makeCollectionTest(NewLV(1, "aaa", "BBB"))
+		err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed startup check", func(t *testing.T) {
+		collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+		cancelled, cancelCheck := context.WithCancel(ctx)
+		cancelCheck()
+		_, err := collector.CheckVoteAccountIdentity(cancelled)
+		assert.Error(t, err)
+		// the failure does not stick, as the identity is checked again on collection:
+		test := collector.ValidatorIdentityMismatch.makeCollectionTest(NewLV(0, "aaa", "AAA"))
+		err = testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err)
+	})
+}
+
+func TestSolanaCollector_collectAuthorizedVoters(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	collect := func() {
+		ch := make(chan prometheus.Metric, 100)
+		collector.collectAuthorizedVoters(ctx, ch)
+		close(ch)
+	}
+
+	// schedule rotations of BBB and CCC for the next epoch:
+	for _, nodekey := range []string{"bbb", "ccc"} {
+		simulator.Server.SetOpt(rpc.AccountInfoOpt, strings.ToUpper(nodekey), newVoteAccountInfo(
+			nodekey, rpc.LamportsInSol,
+			rpc.AuthorizedVoter{AuthorizedVoter: nodekey, Epoch: 0},
+			rpc.AuthorizedVoter{AuthorizedVoter: "vvv", Epoch: 2},
+		))
+	}
+	collect()
+	assert.Equal(t,
+		map[string]rpc.AuthorizedVoter{
+			"BBB": {AuthorizedVoter: "vvv", Epoch: 2},
+			"CCC": {AuthorizedVoter: "vvv", Epoch: 2},
+		},
+		collector.scheduledVoters,
+	)
+
+	// in the next epoch, only CCC rotated:
+	for simulator.Slot < 50 {
+		simulator.Slot++
+		simulator.PopulateSlot(simulator.Slot)
+	}
+	simulator.Server.SetOpt(rpc.AccountInfoOpt, "BBB", newVoteAccountInfo(
+		"bbb", rpc.LamportsInSol, rpc.AuthorizedVoter{AuthorizedVoter: "bbb", Epoch: 0},
+	))
+	simulator.Server.SetOpt(rpc.AccountInfoOpt, "CCC", newVoteAccountInfo(
+		"ccc", rpc.LamportsInSol, rpc.AuthorizedVoter{AuthorizedVoter: "vvv", Epoch: 2},
+	))
+	test := collector.ValidatorVoterRotationApplied.makeCollectionTest(NewLV(0, "2", "BBB"), NewLV(1, "2", "CCC"))
+	assert.NoError(t, testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_referenceRpc(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	reference, _ := NewSimulator(t, 35)
+	// the local node disagrees with the reference on aaa's stake:
+	info := simulator.Server.GetValidatorInfo("aaa")
+	info.Stake = 2_000_000
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "aaa", info)
+
+	config := newTestConfig(simulator, false)
+	config.ReferenceRpcUrl = reference.Server.URL()
+	collector := NewSolanaCollector(client, config, nil)
+	assert.Equal(t, client, collector.rpcClient)
+	assert.NotEqual(t, client, collector.clusterClient)
+
+	// vote accounts are fetched from the reference:
+	stake := float64(1_000_000) / rpc.LamportsInSol
+	test := collector.ValidatorActiveStake.makeCollectionTest(
+		NewLV(stake, "aaa", "AAA"), NewLV(stake, "bbb", "BBB"), NewLV(stake, "ccc", "CCC"),
+	)
+	assert.NoError(t, testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_emitStakeRanks(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	voteAccounts := rpc.VoteAccounts{
+		Current: []rpc.VoteAccount{
+			{VotePubkey: "AAA", NodePubkey: "aaa", ActivatedStake: 3 * rpc.LamportsInSol},
+			{VotePubkey: "XXX", NodePubkey: "xxx", ActivatedStake: 5 * rpc.LamportsInSol},
+			{VotePubkey: "CCC", NodePubkey: "ccc", ActivatedStake: 1 * rpc.LamportsInSol},
+		},
+	}
+	stakeRanks := collectFunc(func(ch chan<- prometheus.Metric) { collector.emitStakeRanks(ch, &voteAccounts) })
+
+	for _, test := range []collectionTest{
+		collector.ValidatorStakeRank.makeCollectionTest(NewLV(2, "aaa", "AAA"), NewLV(3, "ccc", "CCC")),
+		collector.ValidatorStakeGap.makeCollectionTest(
+			NewLV(2, DirectionAbove, "aaa", "AAA"),
+			NewLV(2, DirectionBelow, "aaa", "AAA"),
+			NewLV(2, DirectionAbove, "ccc", "CCC"),
+		),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(stakeRanks, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+}
+
+func TestSolanaCollector_emitLastVoteAges(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	voteAccounts, err := client.GetVoteAccounts(ctx, rpc.CommitmentConfirmed)
+	assert.NoError(t, err)
+	lastVoteAges := collectFunc(func(ch chan<- prometheus.Metric) { collector.emitLastVoteAges(ctx, ch, voteAccounts) })
+
+	// slot 35 is skipped, so the last votes are 1, 2 and 3 slots behind slot 34, at 500ms slots:
+	test := collector.ValidatorLastVoteAge.makeCollectionTest(
+		NewLV(1, "aaa", "AAA"),
+		NewLV(1.5, "bbb", "BBB"),
+		NewLV(2, "ccc", "CCC"),
+	)
+	assert.NoError(t, testutil.CollectAndCompare(lastVoteAges, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_emitBalanceThresholds(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.IdentityBalanceThreshold = 2.5
+	collector := NewSolanaCollector(client, config, nil)
+	collectBalances := collectFunc(func(ch chan<- prometheus.Metric) {
+		collector.collectBalances(context.Background(), ch)
+	})
+
+	// only the identity threshold is set:
+	test := collector.AccountBalanceBelowThreshold.makeCollectionTest(
+		NewLV(1, "aaa", PurposeIdentity),
+		NewLV(1, "bbb", PurposeIdentity),
+		NewLV(0, "ccc", PurposeIdentity),
+	)
+	assert.NoError(t,
+		testutil.CollectAndCompare(collectBalances, bytes.NewBufferString(test.ExpectedResponse), test.Name),
+	)
+}
+
+// collectFunc adapts a collection function to a prometheus.Collector, to test it in isolation.
+type collectFunc func(ch chan<- prometheus.Metric)
+
+func (f collectFunc) Describe(ch chan<- *prometheus.Desc) { prometheus.DescribeByCollect(f, ch) }
+
+func (f collectFunc) Collect(ch chan<- prometheus.Metric) { f(ch) }
+
+func TestSolanaCollector_trackCommission(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	commission := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectValidatorCommission(ctx, ch) })
+
+	// no changes are observed on the first collection:
+	assert.Equal(t, 0, testutil.CollectAndCount(commission, "solana_validator_commission_last_change_epoch"))
+
+	info := simulator.Server.GetValidatorInfo("aaa")
+	info.Commission = 10
+	simulator.Server.SetOpt(rpc.ValidatorInfoOpt, "aaa", info)
+	for _, test := range []collectionTest{
+		collector.ValidatorCommissionLastChangeEpoch.makeCollectionTest(NewLV(1, "aaa")),
+		collector.ValidatorCommissionLastChangeSlot.makeCollectionTest(NewLV(35, "aaa")),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(commission, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+	assert.Equal(t,
+		float64(1), testutil.ToFloat64(collector.ValidatorCommissionChanges.WithLabelValues("aaa", DirectionIncrease)),
+	)
+	assert.Zero(t, testutil.ToFloat64(collector.ValidatorCommissionChanges.WithLabelValues("aaa", DirectionDecrease)))
+	assert.Zero(t, testutil.ToFloat64(collector.ValidatorCommissionChanges.WithLabelValues("bbb", DirectionIncrease)))
+}
+
+func TestSolanaCollector_collectIdentity_Changes(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	identity := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectIdentity(ctx, ch) })
+
+	// the first observed identity is not a change:
+	assert.Equal(t, 0, testutil.CollectAndCount(identity, "solana_node_identity_last_change_timestamp_seconds"))
+	assert.Zero(t, testutil.ToFloat64(collector.NodeIdentityChanges))
+
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getIdentity", map[string]string{"identity": "swappedIdentity"})
+	assert.Equal(t, 1, testutil.CollectAndCount(identity, "solana_node_identity_last_change_timestamp_seconds"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.NodeIdentityChanges))
+	// which is only counted once:
+	assert.Equal(t, 1, testutil.CollectAndCount(identity, "solana_node_identity_last_change_timestamp_seconds"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.NodeIdentityChanges))
+}
+
+func TestSolanaCollector_collectWithCost(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	costs := collectFunc(func(ch chan<- prometheus.Metric) {
+		collector.collectWithCost(ctx, ch, "version", collector.collectVersion)
+		collector.collectWithCost(ctx, ch, "noop", func(context.Context, chan<- prometheus.Metric) {})
+	})
+
+	test := collector.CollectorRpcCalls.makeCollectionTest(NewLV(0, "noop"), NewLV(1, "version"))
+	assert.NoError(t, testutil.CollectAndCompare(costs, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+
+	// both collectors succeeded, so neither is stale:
+	test = collector.CollectorStaleness.makeCollectionTest(NewLV(0, "noop"), NewLV(0, "version"))
+	assert.NoError(t, testutil.CollectAndCompare(costs, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_collectRpcNode(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.RpcNodeMode = true
+	config.RpcNodeSampleAccounts = []string{"aaa", "zzz"}
+	collector := NewSolanaCollector(client, config, nil)
+	ctx := context.Background()
+	rpcNode := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectRpcNode(ctx, ch) })
+
+	// zzz does not exist:
+	test := collector.NodeAccountIndexHealthy.makeCollectionTest(NewLV(1, "aaa"), NewLV(0, "zzz"))
+	assert.NoError(t, testutil.CollectAndCompare(rpcNode, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	assert.Equal(t, 3, testutil.CollectAndCount(rpcNode, "solana_node_rpc_method_latency_seconds"))
+}
+
+func TestSolanaCollector_collectGossipIdentity(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	gossip, rpcAddress, version := "10.0.0.1:8001", "10.0.0.1:8899", "2.0.1"
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getClusterNodes", []map[string]any{
+		{"pubkey": "testIdentity", "gossip": gossip, "rpc": rpcAddress, "version": version},
+		{"pubkey": "aaa"},
+	})
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+
+	for _, test := range []collectionTest{
+		collector.NodeGossipIdentityPresent.makeCollectionTest(NewLV(1, "testIdentity")),
+		collector.NodeGossipAdvertisedPort.makeCollectionTest(
+			NewLV(8001, "testIdentity", "gossip"), NewLV(8899, "testIdentity", "rpc"),
+		),
+		collector.NodeGossipPeersByVersion.makeCollectionTest(NewLV(1, version), NewLV(1, UnknownVersion)),
+	} {
+		err := testutil.CollectAndCompare(collector, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+		assert.NoError(t, err, test.Name)
+	}
+}
+
+func TestSolanaCollector_collectMinRequiredVersion(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getGenesisHash", rpc.MainnetGenesisHash)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "mainnet-beta", r.URL.Query().Get("cluster"))
+		_, _ = w.Write([]byte(`{"stats":{"config":{"min_version":"2.1.14"}}}`))
+	}))
+	t.Cleanup(server.Close)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	collector.apiClient = api.NewClient(server.URL, time.Second)
+	ctx := context.Background()
+
+	for version, outdated := range map[string]float64{"2.1.13": 1, "v2.1.14": 0, "2.2.0": 0} {
+		minRequiredVersion := collectFunc(func(ch chan<- prometheus.Metric) {
+			collector.collectMinRequiredVersion(ctx, ch, version)
+		})
+		for _, test := range []collectionTest{
+			collector.NodeMinRequiredVersion.makeCollectionTest(NewLV(1, "mainnet-beta", "2.1.14")),
+			collector.NodeVersionOutdated.makeCollectionTest(NewLV(outdated)),
+		} {
+			err := testutil.CollectAndCompare(minRequiredVersion, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err, version)
+		}
+	}
+
+	// firedancer versions are not compared:
+	minRequiredVersion := collectFunc(func(ch chan<- prometheus.Metric) {
+		collector.collectMinRequiredVersion(ctx, ch, "0.503.20214")
+	})
+	assert.Equal(t, 1, testutil.CollectAndCount(minRequiredVersion))
+}
+
+func TestSolanaCollector_collectLedgerRetention(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	minimumLedgerSlot := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectMinimumLedgerSlot(ctx, ch) })
+
+	test := collector.NodeLedgerRetainedSlots.makeCollectionTest(NewLV(24))
+	assert.NoError(t,
+		testutil.CollectAndCompare(minimumLedgerSlot, bytes.NewBufferString(test.ExpectedResponse), test.Name),
+	)
+	// the retention is relative to the wall clock, so only its sign is deterministic:
+	ch := make(chan prometheus.Metric, 2)
+	collector.collectFirstAvailableBlock(ctx, ch)
+	close(ch)
+	for metric := range ch {
+		if metric.Desc() == collector.NodeLedgerRetentionHours.Desc {
+			var retention dto.Metric
+			assert.NoError(t, metric.Write(&retention))
+			assert.Greater(t, retention.GetGauge().GetValue(), float64(0))
+		}
+	}
+}
+
+func TestSolanaCollector_collectMaxSlots(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	maxSlots := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectMaxSlots(ctx, ch) })
+
+	for _, test := range []collectionTest{
+		collector.NodeMaxRetransmitSlot.makeCollectionTest(NewLV(36)),
+		collector.NodeMaxShredInsertSlot.makeCollectionTest(NewLV(36)),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(maxSlots, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+}
+
+func TestSolanaCollector_collectBlockTime(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	blockTime := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectBlockTime(ctx, ch) })
+
+	test := collector.NodeBlockTime.makeCollectionTest(NewLV(1_700_000_000))
+	assert.NoError(t, testutil.CollectAndCompare(blockTime, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	// the lag is relative to the wall clock, so only its sign is deterministic:
+	ch := make(chan prometheus.Metric, 2)
+	collector.collectBlockTime(ctx, ch)
+	close(ch)
+	for metric := range ch {
+		if metric.Desc() == collector.NodeBlockTimeLag.Desc {
+			var lag dto.Metric
+			assert.NoError(t, metric.Write(&lag))
+			assert.Greater(t, lag.GetGauge().GetValue(), float64(0))
+		}
+	}
+
+	// blocks without a timestamp fail the collection:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getBlockTime", nil)
+	assert.Error(t, testutil.CollectAndCompare(blockTime, bytes.NewBufferString(""), "solana_node_block_time_seconds"))
+}
+
+func TestSolanaCollector_collectEpochProgress(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	progress := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectEpochProgress(ctx, ch) })
+
+	// slot 35 is the 12th of the 24 slots of epoch 1:
+	for _, test := range []collectionTest{
+		collector.NodeEpochProgress.makeCollectionTest(NewLV(50)),
+		collector.NodeEpochSlotsRemaining.makeCollectionTest(NewLV(12)),
+		collector.NodeAverageSlotDuration.makeCollectionTest(NewLV(0.5)),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(progress, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+	// the end is relative to the wall clock, so only its range is deterministic:
+	start := time.Now()
+	ch := make(chan prometheus.Metric, 4)
+	collector.collectEpochProgress(ctx, ch)
+	close(ch)
+	for metric := range ch {
+		if metric.Desc() == collector.NodeEpochEndTimestamp.Desc {
+			var end dto.Metric
+			assert.NoError(t, metric.Write(&end))
+			assert.InDelta(t, float64(start.Unix())+6, end.GetGauge().GetValue(), 2)
+		}
+	}
+}
+
+func TestSolanaCollector_collectTransactionRates(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	rates := collectFunc(func(ch chan<- prometheus.Metric) {
+		collector.collectTransactionRates(context.Background(), ch)
+	})
+
+	test := collector.ClusterTransactionRate.makeCollectionTest(
+		NewLV(2, TransactionTypeNonVote),
+		NewLV(8, TransactionTypeVote),
+	)
+	assert.NoError(t, testutil.CollectAndCompare(rates, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_collectLatestBlockhash(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	blockhash := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectLatestBlockhash(ctx, ch) })
+
+	test := collector.NodeBlockhashLastValidBlockHeight.makeCollectionTest(NewLV(150))
+	assert.NoError(t, testutil.CollectAndCompare(blockhash, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+
+	// the change of the blockhash is tracked from the slot it was first observed at:
+	observe := func(hash string) int64 {
+		fingerprint := GetStringFingerprint(hash)
+		slot, _ := collector.blockhashChanges.ObserveFingerprint(latestBlockhashKey, fingerprint, 0, time.Now())
+		return slot
+	}
+	assert.Equal(t, int64(1), observe("hash1"))
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getLatestBlockhash", map[string]any{
+		"context": map[string]int{"slot": 2},
+		"value":   map[string]any{"blockhash": "hash2", "lastValidBlockHeight": 151},
+	})
+	assert.Equal(t, 2, testutil.CollectAndCount(blockhash))
+	assert.Equal(t, int64(2), observe("hash2"))
+}
+
+func TestSolanaCollector_collectEpochSchedule(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	schedule := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectEpochSchedule(ctx, ch) })
+
+	for _, test := range []collectionTest{
+		collector.ClusterSlotsPerEpoch.makeCollectionTest(NewLV(24)),
+		collector.ClusterLeaderScheduleSlotOffset.makeCollectionTest(NewLV(24)),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(schedule, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+
+	// the schedule is only fetched once:
+	simulator.Server.SetOpt(rpc.EasyErrorsOpt, "getEpochSchedule", rpc.Error{Code: rpc.NodeUnhealthyCode})
+	test := collector.ClusterSlotsPerEpoch.makeCollectionTest(NewLV(24))
+	assert.NoError(t, testutil.CollectAndCompare(schedule, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func newTokenAccount(pubkey, owner, mint, uiAmount string) map[string]any {
+	return map[string]any{
+		"pubkey": pubkey,
+		"account": map[string]any{
+			"lamports": 2_039_280,
+			"owner":    "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
+			"space":    165,
+			"data": map[string]any{
+				"program": "spl-token",
+				"parsed": map[string]any{
+					"type": "account",
+					"info": map[string]any{
+						"mint": mint, "owner": owner, "tokenAmount": map[string]any{"uiAmountString": uiAmount},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSolanaCollector_collectTokenBalances(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	// the owner holds the mint in two token accounts:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTokenAccountsByOwner", map[string]any{
+		"context": map[string]int{"slot": 1},
+		"value": []map[string]any{
+			newTokenAccount("account1", "aaa", "mint", "1.5"), newTokenAccount("account2", "aaa", "mint", "2.25"),
+		},
+	})
+	config := newTestConfig(simulator, false)
+	config.TokenOwners, config.TokenMints = []string{"aaa"}, []string{"mint"}
+	collector := NewSolanaCollector(client, config, nil)
+	ctx := context.Background()
+	balances := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectTokenBalances(ctx, ch) })
+
+	test := collector.TokenAccountBalance.makeCollectionTest(NewLV(3.75, "mint", "aaa"))
+	assert.NoError(t, testutil.CollectAndCompare(balances, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+}
+
+func TestSolanaCollector_collectWatchedAddresses(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getSignaturesForAddress", []map[string]any{
+		{"signature": "sig1", "slot": 34}, {"signature": "sig2", "slot": 30, "err": map[string]any{}},
+		{"signature": "sig3", "slot": 20},
+	})
+	config := newTestConfig(simulator, false)
+	config.WatchAddresses, config.WatchAddressSlots = []string{"withdrawer"}, 10
+	collector := NewSolanaCollector(client, config, nil)
+	ctx := context.Background()
+	watched := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectWatchedAddresses(ctx, ch) })
+
+	for _, test := range []collectionTest{
+		// failed transactions are activity too:
+		collector.AccountRecentTransactions.makeCollectionTest(NewLV(2, "withdrawer")),
+		collector.AccountLastActivitySlot.makeCollectionTest(NewLV(34, "withdrawer")),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(watched, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+}
+
+func TestSolanaCollector_collectWatchedSignatures(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	simulator.Server.SetOpt(
+		rpc.EasyResultsOpt, "getTransaction", map[string]any{"slot": 30, "meta": map[string]any{"err": nil}},
+	)
+	config := newTestConfig(simulator, false)
+	config.WatchSignatures = []string{"sig"}
+	collector := NewSolanaCollector(client, config, nil)
+	ctx := context.Background()
+	watched := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectWatchedSignatures(ctx, ch) })
+
+	for _, test := range []collectionTest{
+		collector.TransactionConfirmed.makeCollectionTest(
+			NewLV(1, string(rpc.CommitmentConfirmed), "sig"), NewLV(1, string(rpc.CommitmentFinalized), "sig"),
+		),
+		collector.TransactionSlot.makeCollectionTest(NewLV(30, "sig")),
+		collector.TransactionFailed.makeCollectionTest(NewLV(0, "sig")),
+	} {
+		assert.NoError(t, testutil.CollectAndCompare(watched, bytes.NewBufferString(test.ExpectedResponse), test.Name))
+	}
+}
+
+func TestSolanaCollector_collectLeaders(t *testing.T) {
+	// slot 35 is the last of ccc's leader window, followed by aaa's:
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	ctx := context.Background()
+	leaders := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectLeaders(ctx, ch) })
+
+	tests := []collectionTest{
+		collector.ClusterCurrentLeader.makeCollectionTest(NewLV(1, "ccc")),
+		collector.ClusterNextLeader.makeCollectionTest(NewLV(1, "aaa")),
+		collector.ValidatorIsLeader.makeCollectionTest(NewLV(0, "aaa"), NewLV(0, "bbb"), NewLV(1, "ccc")),
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(leaders, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSolanaCollector_collectReferenceLag(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	reference, _ := NewSimulator(t, 40)
+	config := newTestConfig(simulator, false)
+	config.ReferenceRpcUrl = reference.Server.URL()
+	collector := NewSolanaCollector(client, config, nil)
+	ctx := context.Background()
+	lag := collectFunc(func(ch chan<- prometheus.Metric) { collector.collectReferenceLag(ctx, ch) })
+
+	blocks := float64(reference.BlockHeight - simulator.BlockHeight)
+	tests := []collectionTest{
+		collector.NodeSlotsBehindReference.makeCollectionTest(NewLV(5, "confirmed"), NewLV(5, "finalized")),
+		collector.NodeBlockHeightBehindReference.makeCollectionTest(
+			NewLV(blocks, "confirmed"), NewLV(blocks, "finalized"),
+		),
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := testutil.CollectAndCompare(lag, bytes.NewBufferString(test.ExpectedResponse), test.Name)
+			assert.NoError(t, err)
+		})
+	}
+}
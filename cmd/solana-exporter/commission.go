@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sync"
+)
+
+type (
+	// CommissionTracker tracks the commission of validators between collections, for the changes to it. Commission
+	// increases within an epoch (taking effect on the rewards of that epoch) are a common rug of delegators.
+	CommissionTracker struct {
+		commissions map[string]int
+		lastChanges map[string]CommissionChange
+		mu          sync.Mutex
+	}
+
+	// CommissionChange is a change of the commission of a validator, as of the epoch and slot it was observed at.
+	CommissionChange struct {
+		Previous   int
+		Commission int
+		Epoch      int64
+		Slot       int64
+	}
+)
+
+func NewCommissionTracker() *CommissionTracker {
+	return &CommissionTracker{commissions: make(map[string]int), lastChanges: make(map[string]CommissionChange)}
+}
+
+// Observe records the commission of the validator at the epoch and slot, returning the change (if any) since the
+// commission was last observed. The first observation of a validator is not a change.
+func (t *CommissionTracker) Observe(nodekey string, commission int, epoch, slot int64) (CommissionChange, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	previous, seen := t.commissions[nodekey]
+	t.commissions[nodekey] = commission
+	if !seen || previous == commission {
+		return CommissionChange{}, false
+	}
+	change := CommissionChange{Previous: previous, Commission: commission, Epoch: epoch, Slot: slot}
+	t.lastChanges[nodekey] = change
+	return change, true
+}
+
+// LastChange returns the last change of the commission of the validator, if one was observed.
+func (t *CommissionTracker) LastChange(nodekey string) (CommissionChange, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	change, ok := t.lastChanges[nodekey]
+	return change, ok
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommissionTracker(t *testing.T) {
+	tracker := NewCommissionTracker()
+	// the first observation is not a change:
+	_, changed := tracker.Observe("aaa", 5, 10, 100)
+	assert.False(t, changed)
+	_, ok := tracker.LastChange("aaa")
+	assert.False(t, ok)
+
+	_, changed = tracker.Observe("aaa", 5, 10, 110)
+	assert.False(t, changed)
+
+	change, changed := tracker.Observe("aaa", 100, 11, 120)
+	assert.True(t, changed)
+	expected := CommissionChange{Previous: 5, Commission: 100, Epoch: 11, Slot: 120}
+	assert.Equal(t, expected, change)
+
+	// the last change holds until the next one:
+	_, changed = tracker.Observe("aaa", 100, 12, 130)
+	assert.False(t, changed)
+	lastChange, ok := tracker.LastChange("aaa")
+	assert.True(t, ok)
+	assert.Equal(t, expected, lastChange)
+}
@@ -2,14 +2,28 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
 )
 
+// WsUrlAuto is the -ws-url value which derives the WebSocket url from the rpc url.
+const WsUrlAuto = "auto"
+
+// DefaultRpcNodeSampleAccounts are the accounts sampled in rpc-node mode if none are provided: well-known accounts which
+// any node with a healthy accounts index serves.
+var DefaultRpcNodeSampleAccounts = []string{rpc.ClockSysvar, VoteProgram, rpc.TokenProgram}
+
 type (
 	arrayFlags []string
 
@@ -20,6 +34,8 @@ type (
 		NodeKeys                         []string
 		VoteKeys                         []string
 		BalanceAddresses                 []string
+		IdentityBalanceThreshold         float64
+		VoteBalanceThreshold             float64
 		ComprehensiveSlotTracking        bool
 		ComprehensiveVoteAccountTracking bool
 		MonitorBlockSizes                bool
@@ -30,6 +46,72 @@ type (
 		ValidatorIdentity                string
 		VoteAccountPubkey                string
 		FastMetricsInterval              time.Duration
+		VoteDistanceAlert                LagThreshold
+		RootDistanceAlert                LagThreshold
+		ReconcileBlockProduction         bool
+		JSONLinesSink                    string
+		JSONLinesSinkInterval            time.Duration
+		RpcAcceptEncodings               []string
+		ConfirmedSlotMetrics             bool
+		MinRequiredVersion               bool
+		GrafanaUrl                       *Secret
+		GrafanaApiToken                  *Secret
+		SecretsReloadInterval            time.Duration
+		CounterStateFile                 string
+		ReusePort                        bool
+		AdminListenAddress               string
+		Tenants                          []Tenant
+		TenantsByKey                     map[string]string
+		WsUrl                            string
+		SlotLatencyProbeInterval         time.Duration
+		StakeDelegationScanInterval      time.Duration
+		ValidatorNames                   map[string]string
+		ValidatorInfoInterval            time.Duration
+		VoteSubscription                 bool
+		BlockSubscription                bool
+		GeyserUrl                        string
+		GeyserToken                      *Secret
+		// RpcSecrets are the RPC url and header secrets, whose current values the RpcTransport applies
+		RpcSecrets                       []*Secret
+		FallbackRpcUrls                  []string
+		RpcEndpointRouting               bool
+		RpcEndpointProbeInterval         time.Duration
+		ReferenceRpcUrl                  string
+		MonitorPriorityFees              bool
+		PriorityFeeOutput                string
+		MonitorMevTips                   bool
+		ValidatorSetSnapshotDir          string
+		ValidatorSetSnapshotRetention    int
+		NonceAccounts                    []string
+		TokenOwners                      []string
+		TokenMints                       []string
+		WatchAddresses                   []string
+		WatchAddressSlots                int64
+		WatchSignatures                  []string
+		CollectorErrorTolerance          int
+		CollectorErrorTolerances         map[string]int
+		RpcRetryPolicy                   rpc.RetryPolicy
+		RpcRateLimit                     float64
+		RpcRateLimitBurst                int
+		EpochRebuild                     bool
+		RpcCircuitBreakerThreshold       int
+		RpcCircuitBreakerCooldown        time.Duration
+		RpcSchemaDrift                   bool
+		RpcRecord                        string
+		RpcReplay                        string
+		RpcNodeMode                      bool
+		RpcNodeSampleAccounts            []string
+		VoteInclusionSampleInterval      int64
+		HostMetrics                      bool
+		LedgerPath                       string
+		Plugins                          []Plugin
+		RpcHeaders                       http.Header
+		RpcTransport                     http.RoundTripper
+		RpcTLSConfig                     *tls.Config
+
+		// the rate limiters of each RPC provider (by url), shared by all of its clients:
+		rateLimiters   map[string]*rpc.RateLimiter
+		rateLimitersMu sync.Mutex
 	}
 )
 
@@ -57,12 +139,15 @@ func NewExporterConfig(
 	activeIdentity string,
 	epochCleanupTime time.Duration,
 	validatorIdentity string,
+	rpcHeaders http.Header,
+	rpcTransport http.RoundTripper,
+	registerer prometheus.Registerer,
 ) (*ExporterConfig, error) {
 	logger := slog.Get()
 	logger.Infow(
 		"Setting up export config with ",
 		"httpTimeout", httpTimeout.Seconds(),
-		"rpcUrl", rpcUrl,
+		"rpcUrl", rpc.RedactUrl(rpcUrl),
 		"listenAddress", listenAddress,
 		"nodeKeys", nodeKeys,
 		"balanceAddresses", balanceAddresses,
@@ -100,7 +185,9 @@ func NewExporterConfig(
 	// get votekeys from rpc:
 	ctx, cancel := context.WithTimeout(ctx, httpTimeout)
 	defer cancel()
-	client := rpc.NewRPCClient(rpcUrl, httpTimeout)
+	client := rpc.NewRPCClient(rpcUrl, httpTimeout, registerer)
+	client.Headers = rpcHeaders
+	client.HttpClient.Transport = rpcTransport
 	voteKeys, err := GetAssociatedVoteAccounts(ctx, client, rpc.CommitmentFinalized, nodeKeys)
 	if err != nil {
 		return nil, fmt.Errorf("error getting vote accounts: %w", err)
@@ -123,17 +210,23 @@ func NewExporterConfig(
 		ValidatorIdentity:                validatorIdentity,
 		VoteAccountPubkey:                "",
 		FastMetricsInterval:              0,
+		RpcHeaders:                       rpcHeaders,
+		RpcTransport:                     rpcTransport,
 	}
 	return &config, nil
 }
 
-func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
+// NewExporterConfigFromCLI parses the config from the command line flags, registering the metrics of the RPC clients it
+// uses for startup lookups with the registerer.
+func NewExporterConfigFromCLI(ctx context.Context, registerer prometheus.Registerer) (*ExporterConfig, error) {
 	var (
 		httpTimeout                      int
 		rpcUrl                           string
 		listenAddress                    string
 		nodekeys                         arrayFlags
 		balanceAddresses                 arrayFlags
+		identityBalanceThreshold         float64
+		voteBalanceThreshold             float64
 		comprehensiveSlotTracking        bool
 		comprehensiveVoteAccountTracking bool
 		monitorBlockSizes                bool
@@ -144,6 +237,72 @@ func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
 		validatorIdentity                string
 		voteAccountPubkey                string
 		fastMetricsInterval              int
+		voteDistanceAlert                int
+		voteDistanceClear                int
+		rootDistanceAlert                int
+		rootDistanceClear                int
+		reconcileBlockProduction         bool
+		jsonLinesSink                    string
+		jsonLinesSinkInterval            int
+		rpcAcceptEncodings               arrayFlags
+		confirmedSlotMetrics             bool
+		minRequiredVersion               bool
+		grafanaUrl                       string
+		grafanaApiToken                  string
+		secretsReloadInterval            int
+		counterStateFile                 string
+		reusePort                        bool
+		adminListenAddress               string
+		tenantsConfig                    string
+		wsUrl                            string
+		slotLatencyProbeInterval         int
+		stakeDelegationScanInterval      int
+		validatorNames                   string
+		validatorInfoInterval            int
+		voteSubscription                 bool
+		blockSubscription                bool
+		geyserUrl                        string
+		geyserToken                      string
+		fallbackRpcUrls                  arrayFlags
+		rpcEndpointRouting               bool
+		rpcEndpointProbeInterval         int
+		referenceRpcUrl                  string
+		rpcHeaders                       arrayFlags
+		rpcBearerToken                   string
+		rpcMaxIdleConns                  int
+		rpcIdleConnTimeout               int
+		rpcTLSMinVersion                 string
+		rpcCAFile                        string
+		monitorPriorityFees              bool
+		priorityFeeOutput                string
+		monitorMevTips                   bool
+		validatorSetSnapshotDir          string
+		validatorSetSnapshotRetention    int
+		nonceAccounts                    arrayFlags
+		tokenOwners                      arrayFlags
+		tokenMints                       arrayFlags
+		watchAddresses                   arrayFlags
+		watchAddressSlots                int64
+		watchSignatures                  arrayFlags
+		collectorErrorTolerance          int
+		collectorErrorTolerances         arrayFlags
+		rpcMaxAttempts                   int
+		rpcRetryBackoffMs                int
+		rpcRetryJitter                   float64
+		rpcRateLimit                     float64
+		rpcRateLimitBurst                int
+		epochRebuild                     bool
+		rpcCircuitBreakerThreshold       int
+		rpcCircuitBreakerCooldown        int
+		rpcSchemaDrift                   bool
+		rpcRecord                        string
+		rpcReplay                        string
+		rpcNodeMode                      bool
+		rpcNodeSampleAccounts            arrayFlags
+		voteInclusionSampleInterval      int64
+		hostMetrics                      bool
+		ledgerPath                       string
+		pluginsConfig                    string
 	)
 	flag.IntVar(
 		&httpTimeout,
@@ -175,6 +334,20 @@ func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
 		"Address to monitor SOL balances for, in addition to the identity and vote accounts of the "+
 			"provided nodekeys - can be set multiple times.",
 	)
+	flag.Float64Var(
+		&identityBalanceThreshold,
+		"identity-balance-threshold",
+		0,
+		"Balance (in SOL) of the identity accounts below which solana_account_balance_below_threshold is set, "+
+			"e.g., to alert on before votes can no longer be paid for. 0 (default) disables the threshold.",
+	)
+	flag.Float64Var(
+		&voteBalanceThreshold,
+		"vote-balance-threshold",
+		0,
+		"Balance (in SOL) of the vote accounts below which solana_account_balance_below_threshold is set. "+
+			"0 (default) disables the threshold.",
+	)
 	flag.BoolVar(
 		&comprehensiveSlotTracking,
 		"comprehensive-slot-tracking",
@@ -240,8 +413,544 @@ func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
 		3,
 		"Collection interval in seconds for fast-changing metrics like vote distance and root distance",
 	)
+	flag.IntVar(
+		&voteDistanceAlert,
+		"vote-distance-alert-threshold",
+		0,
+		"Vote distance (in slots) at which solana_validator_vote_lag_alert fires. Set to 0 (default) to not alert "+
+			"on the vote distance.",
+	)
+	flag.IntVar(
+		&voteDistanceClear,
+		"vote-distance-clear-threshold",
+		0,
+		"Vote distance (in slots) the vote distance must be back at (or below) for solana_validator_vote_lag_alert "+
+			"to clear, defaults to half of -vote-distance-alert-threshold.",
+	)
+	flag.IntVar(
+		&rootDistanceAlert,
+		"root-distance-alert-threshold",
+		0,
+		"Root distance (in slots) at which solana_validator_vote_lag_alert fires. Set to 0 (default) to not alert "+
+			"on the root distance.",
+	)
+	flag.IntVar(
+		&rootDistanceClear,
+		"root-distance-clear-threshold",
+		0,
+		"Root distance (in slots) the root distance must be back at (or below) for solana_validator_vote_lag_alert "+
+			"to clear, defaults to half of -root-distance-alert-threshold.",
+	)
+	flag.BoolVar(
+		&reconcileBlockProduction,
+		"reconcile-block-production",
+		false,
+		"Set this flag to cross-check getBlockProduction against getBlocks over each slot-watching range, "+
+			"counting mismatches in solana_node_block_production_mismatches_total.",
+	)
+	flag.StringVar(
+		&jsonLinesSink,
+		"jsonl-sink",
+		"",
+		"Optional target to additionally stream metric samples to as JSON lines, for non-Prometheus consumers. "+
+			"One of 'stdout', 'tcp://<host>:<port>' or 'unix://<path>'.",
+	)
+	flag.IntVar(
+		&jsonLinesSinkInterval,
+		"jsonl-sink-interval",
+		15,
+		"The time (in seconds) between JSON lines sink writes, defaults to 15s.",
+	)
+	flag.Var(
+		&rpcAcceptEncodings,
+		"rpc-accept-encoding",
+		"Compressed content encoding to negotiate with the RPC server ('zstd' or 'gzip'), useful against remote "+
+			"archive providers - can be set multiple times, in order of preference.",
+	)
+	flag.BoolVar(
+		&confirmedSlotMetrics,
+		"confirmed-slot-metrics",
+		false,
+		"Set this flag to additionally emit solana_node_slot_height and solana_node_epoch_number at confirmed "+
+			"commitment, alongside finalized.",
+	)
+	flag.BoolVar(
+		&minRequiredVersion,
+		"min-required-version",
+		false,
+		"Set this flag to export the minimum version SFDP participants of the node's cluster are required to run "+
+			"(fetched from api.solana.org), and whether the node's Agave version is older.",
+	)
+	flag.StringVar(
+		&grafanaUrl,
+		"grafana-url",
+		"",
+		"Optional Grafana base URL to post annotations for detected events (epoch rollovers, delinquency, "+
+			"version changes and identity swaps) to. Can be read from a file or env var with 'file:' or 'env:'.",
+	)
+	flag.StringVar(
+		&grafanaApiToken,
+		"grafana-api-token",
+		"",
+		"Grafana service account token used to post annotations. Can be read from a file or env var with 'file:' "+
+			"or 'env:'.",
+	)
+	flag.IntVar(
+		&secretsReloadInterval,
+		"secrets-reload-interval",
+		60,
+		"The time (in seconds) between reloads of secrets read from files or env vars.",
+	)
+	flag.StringVar(
+		&counterStateFile,
+		"counter-state-file",
+		"",
+		"Optional file to persist restart offsets of monotonic counters in, such that they also survive exporter "+
+			"restarts.",
+	)
+	flag.BoolVar(
+		&reusePort,
+		"reuse-port",
+		false,
+		"Set this flag to bind the listen address with SO_REUSEPORT, such that a new exporter can take over the "+
+			"port before the old one exits. Ignored under systemd socket activation.",
+	)
+	flag.StringVar(
+		&adminListenAddress,
+		"admin-listen-address",
+		"",
+		"Optional address to serve the admin endpoints (/healthz, /-/reload, /debug/pprof/ and /api/status) on, "+
+			"separately from /metrics - e.g., 'localhost:8081' to keep them private.",
+	)
+	flag.StringVar(
+		&tenantsConfig,
+		"tenants-config",
+		"",
+		"Optional YAML file grouping tracked nodekeys and balance addresses into named tenants, whose metrics "+
+			"collected on scrape are labelled with the tenant name (the slot watcher's metrics are not).",
+	)
+	flag.StringVar(
+		&wsUrl,
+		"ws-url",
+		"",
+		"Optional Solana PubSub WebSocket URL to subscribe to slot notifications on, such that the slot height is "+
+			"updated in near real-time (falling back to polling while disconnected). Set to 'auto' to derive it "+
+			"from the rpc url.",
+	)
+	flag.IntVar(
+		&slotLatencyProbeInterval,
+		"slot-latency-probe-interval",
+		0,
+		"The time (in seconds) between getSlot latency probes at each commitment against the node. Set to 0 "+
+			"(default) to disable probing.",
+	)
+	flag.IntVar(
+		&stakeDelegationScanInterval,
+		"stake-delegation-scan-interval",
+		0,
+		"The time (in seconds) between scans of the stake accounts delegated to the tracked vote accounts, for "+
+			"their delegated, activating and deactivating stake. Scans go through getProgramAccounts, which is "+
+			"expensive. Set to 0 (default) to disable scanning.",
+	)
+	flag.StringVar(
+		&validatorNames,
+		"validator-names",
+		"",
+		"Optional YAML file mapping nodekeys and votekeys to validator names, which the metrics keyed by them are "+
+			"labelled with. Takes precedence over the names published on-chain.",
+	)
+	flag.IntVar(
+		&validatorInfoInterval,
+		"validator-info-interval",
+		0,
+		"The time (in seconds) between refreshes of the validator names published on-chain (through validator "+
+			"info), which the metrics keyed by a nodekey or votekey are labelled with. Set to 0 (default) to not "+
+			"use published names.",
+	)
+	flag.BoolVar(
+		&voteSubscription,
+		"vote-subscription",
+		false,
+		"Set this flag to follow the votes of the tracked vote accounts through a voteSubscribe subscription on "+
+			"-ws-url, for faster delinquency detection. The node must run with --rpc-pubsub-enable-vote-subscription.",
+	)
+	flag.BoolVar(
+		&blockSubscription,
+		"block-subscription",
+		false,
+		"Set this flag to emit the fee rewards and block sizes of leader slots from a blockSubscribe subscription on "+
+			"-ws-url as soon as blocks are produced, instead of polling getBlock. The node must run with "+
+			"--rpc-pubsub-enable-block-subscription.",
+	)
+	flag.StringVar(
+		&geyserUrl,
+		"geyser-url",
+		"",
+		"Optional Yellowstone gRPC (Geyser) URL, e.g., 'https://grpc.example.com:443', to stream the slot height, "+
+			"the fee rewards of leader slots and the balances of tracked addresses from, instead of polling them "+
+			"(falling back to polling while disconnected). Fee rewards are only streamed if neither block sizes nor "+
+			"priority fees are monitored.",
+	)
+	flag.StringVar(
+		&geyserToken,
+		"geyser-token",
+		"",
+		"Optional x-token to authenticate with the -geyser-url. Can be read from a file or env var with 'file:' or "+
+			"'env:'.",
+	)
+	flag.Var(
+		&fallbackRpcUrls,
+		"fallback-rpc-url",
+		"Fallback Solana RPC URL to fail over to while -rpc-url is unavailable - can be set multiple times, in order "+
+			"of priority. Calls reporting on the node itself (e.g., getHealth and getIdentity) are never failed over. "+
+			"Can be read from a file or env var with 'file:' or 'env:'.",
+	)
+	flag.BoolVar(
+		&rpcEndpointRouting,
+		"rpc-endpoint-routing",
+		false,
+		"Set this flag to route each RPC call to the best endpoint of -rpc-url and -fallback-rpc-url, instead of in "+
+			"order of priority: heavy (historical) calls go to the endpoint with the deepest history, and light ones "+
+			"to the endpoint with the best latency, error rate and slot freshness score.",
+	)
+	flag.IntVar(
+		&rpcEndpointProbeInterval,
+		"rpc-endpoint-probe-interval",
+		int(rpc.DefaultEndpointProbeInterval.Seconds()),
+		"The time (in seconds) between probes of the slot and history of each RPC endpoint, when -fallback-rpc-url "+
+			"is set. Set to 0 to disable probing.",
+	)
+	flag.StringVar(
+		&referenceRpcUrl,
+		"reference-rpc-url",
+		"",
+		"Optional trusted reference Solana RPC URL (e.g., a public provider) to make cluster-wide calls "+
+			"(getVoteAccounts, getBlockProduction) to, keeping only node-specific calls on -rpc-url. The slot and "+
+			"block height lag of the node behind it are also exported. Can be read from a file or env var with "+
+			"'file:' or 'env:'.",
+	)
+	flag.Var(
+		&rpcHeaders,
+		"rpc-header",
+		"Header to send with every RPC and WebSocket request, as 'Name: value' (e.g., 'x-api-key: <KEY>') - can be "+
+			"set multiple times. The value can be read from a file or env var with 'file:' or 'env:'.",
+	)
+	flag.StringVar(
+		&rpcBearerToken,
+		"rpc-bearer-token",
+		"",
+		"Optional bearer token to authenticate RPC and WebSocket requests with. Can be read from a file or env var "+
+			"with 'file:' or 'env:'.",
+	)
+	flag.IntVar(
+		&rpcMaxIdleConns,
+		"rpc-max-idle-conns",
+		rpc.DefaultMaxIdleConns,
+		"Number of idle (keep-alive) connections to keep open per RPC endpoint, such that polling reuses them "+
+			"rather than re-dialing.",
+	)
+	flag.IntVar(
+		&rpcIdleConnTimeout,
+		"rpc-idle-conn-timeout",
+		int(rpc.DefaultIdleConnTimeout.Seconds()),
+		"The time (in seconds) an idle RPC connection is kept open for.",
+	)
+	flag.StringVar(
+		&rpcTLSMinVersion,
+		"rpc-tls-min-version",
+		rpc.DefaultTLSMinVersion,
+		"Minimum TLS version (1.0, 1.1, 1.2 or 1.3) of https and wss RPC endpoints.",
+	)
+	flag.StringVar(
+		&rpcCAFile,
+		"rpc-ca-file",
+		"",
+		"Optional PEM bundle of CA certificates to trust (in addition to the system ones) for https and wss RPC "+
+			"endpoints, e.g., for nodes behind a private CA.",
+	)
+	flag.BoolVar(
+		&monitorPriorityFees,
+		"monitor-priority-fees",
+		false,
+		"Set this flag to track quantiles of the priority fees paid by the non-vote transactions in the blocks "+
+			"produced by the configured validators. Warning: like -monitor-block-sizes, this fetches full blocks.",
+	)
+	flag.StringVar(
+		&priorityFeeOutput,
+		"priority-fee-output",
+		"",
+		"Optional file to append the raw per-transaction priority fees of each produced block to, as JSON lines. "+
+			"Requires -monitor-priority-fees.",
+	)
+	flag.BoolVar(
+		&monitorMevTips,
+		"monitor-mev-tips",
+		false,
+		"Set this flag to track the Jito MEV tips paid to the Jito tip payment accounts in the blocks produced by "+
+			"the configured validators. Warning: like -monitor-block-sizes, this fetches full blocks.",
+	)
+	flag.StringVar(
+		&validatorSetSnapshotDir,
+		"validator-set-snapshot-dir",
+		"",
+		"Optional directory to write the full getVoteAccounts response to at the start of each epoch, as "+
+			"vote-accounts-<epoch>.json.gz, for a local record of the cluster composition over time.",
+	)
+	flag.IntVar(
+		&validatorSetSnapshotRetention,
+		"validator-set-snapshot-retention",
+		0,
+		"Number of epochs to keep validator set snapshots for, set to 0 (default) to keep them all.",
+	)
+	flag.Var(
+		&nonceAccounts,
+		"nonce-account",
+		"Durable nonce account (e.g., of reward sweep or failover tooling) to export the balance, authority and "+
+			"time since last advanced of - can be set multiple times.",
+	)
+	flag.Var(
+		&tokenOwners,
+		"token-owner",
+		"Owner to export the SPL token balances (of each -token-mint) of - can be set multiple times.",
+	)
+	flag.Var(
+		&tokenMints,
+		"token-mint",
+		"SPL token mint (e.g., USDC, wSOL or JitoSOL) to export the balances of each -token-owner in - can be set "+
+			"multiple times.",
+	)
+	flag.Var(
+		&watchAddresses,
+		"watch-address",
+		"Address (e.g., a withdraw authority or fee payer) to export the recent transaction activity of, for "+
+			"catching unexpected activity - can be set multiple times.",
+	)
+	flag.Int64Var(
+		&watchAddressSlots,
+		"watch-address-slots",
+		9_000,
+		"Number of slots (back from the confirmed slot) the transactions of each -watch-address are counted over.",
+	)
+	flag.Var(
+		&watchSignatures,
+		"watch-signature",
+		"Transaction signature (e.g., of a pending commission change or authority transfer) to export the "+
+			"confirmation status and slot of - can be set multiple times.",
+	)
+	flag.IntVar(
+		&collectorErrorTolerance,
+		"collector-error-tolerance",
+		0,
+		"Number of consecutive failed collections of a collector during which the last-known-good values of its "+
+			"failed metrics are served, before they are marked invalid. Set to 0 (default) to mark them invalid "+
+			"straight away.",
+	)
+	flag.Var(
+		&collectorErrorTolerances,
+		"collector-error-tolerance-override",
+		"Error tolerance of a single collector, formatted as 'collector=N' (see the collector label of "+
+			"solana_exporter_collector_rpc_calls), overriding -collector-error-tolerance - can be set multiple times.",
+	)
+	flag.IntVar(
+		&rpcMaxAttempts,
+		"rpc-max-attempts",
+		rpc.DefaultRetryPolicy.MaxAttempts,
+		"Maximum number of attempts per RPC call. Calls failing transiently (HTTP 429, 5xx or timeouts) are "+
+			"retried with exponential backoff, set to 1 to disable retries.",
+	)
+	flag.IntVar(
+		&rpcRetryBackoffMs,
+		"rpc-retry-backoff-ms",
+		int(rpc.DefaultRetryPolicy.InitialBackoff.Milliseconds()),
+		"Wait before the first RPC retry, in milliseconds, doubling with every subsequent retry.",
+	)
+	flag.Float64Var(
+		&rpcRetryJitter,
+		"rpc-retry-jitter",
+		rpc.DefaultRetryPolicy.Jitter,
+		"Fraction (between 0 and 1) of each RPC retry wait which is randomised.",
+	)
+	flag.Float64Var(
+		&rpcRateLimit,
+		"rpc-rate-limit",
+		0,
+		"Maximum average number of RPC requests per second sent to each RPC provider, for providers which are rate "+
+			"limited. Requests beyond it are queued. 0 means unlimited.",
+	)
+	flag.IntVar(
+		&rpcRateLimitBurst,
+		"rpc-rate-limit-burst",
+		10,
+		"Number of RPC requests which may be sent at once under -rpc-rate-limit.",
+	)
+	flag.BoolVar(
+		&epochRebuild,
+		"epoch-rebuild",
+		true,
+		"Rebuild the current epoch's cumulative values (e.g., fee rewards) from the start of the epoch on startup, "+
+			"such that epoch-labelled counters do not reset mid-epoch on restarts. This fetches every leader block of "+
+			"the tracked validators so far this epoch, disable it (-epoch-rebuild=false) on limited RPC endpoints or "+
+			"ones without the full epoch's ledger.",
+	)
+	flag.IntVar(
+		&rpcCircuitBreakerThreshold,
+		"rpc-circuit-breaker-threshold",
+		5,
+		"Number of consecutive transient failures (HTTP 429, 5xx or timeouts) of an RPC method after which its "+
+			"calls are suspended for -rpc-circuit-breaker-cooldown, to spare a struggling node. 0 disables it.",
+	)
+	flag.IntVar(
+		&rpcCircuitBreakerCooldown,
+		"rpc-circuit-breaker-cooldown",
+		30,
+		"Time (in seconds) calls of a failing RPC method are suspended for, before a probe call is let through.",
+	)
+	flag.BoolVar(
+		&rpcSchemaDrift,
+		"rpc-schema-drift",
+		false,
+		"Whether to compare RPC results against the fields the exporter decodes, exporting unknown and missing "+
+			"fields per method (also served at /debug/schema-drift of the admin endpoints). This decodes every "+
+			"response twice.",
+	)
+	flag.StringVar(
+		&rpcRecord,
+		"rpc-record",
+		"",
+		"Optional file to record the requests and responses of all RPC calls to (as JSON lines), for -rpc-replay "+
+			"to serve back when reproducing the metrics of the exporter. Recordings grow quickly, so this is meant "+
+			"for debugging sessions only.",
+	)
+	flag.StringVar(
+		&rpcReplay,
+		"rpc-replay",
+		"",
+		"Optional file of calls recorded with -rpc-record, whose responses are served instead of calling the RPC "+
+			"(keep the other flags the same as when recording). WebSocket and geyser subscriptions are not replayed.",
+	)
+	flag.BoolVar(
+		&rpcNodeMode,
+		"rpc-node-mode",
+		false,
+		"Set this flag to monitor an RPC node without a vote account. Like light mode, validator and cluster-wide "+
+			"metrics are not reported, but RPC-focused metrics are added: method latency probes and the health of "+
+			"the accounts index. Pair it with -reference-rpc-url to also track the node's lag. Incompatible with "+
+			"-nodekey, -validator-identity and -vote-account-pubkey.",
+	)
+	flag.Var(
+		&rpcNodeSampleAccounts,
+		"rpc-node-sample-account",
+		"Account whose info is sampled in rpc-node mode to check the node's accounts index - can be set multiple "+
+			"times. Defaults to the Clock sysvar, the Vote program and the SPL Token program.",
+	)
+	flag.Int64Var(
+		&voteInclusionSampleInterval,
+		"vote-inclusion-sample-interval",
+		0,
+		"Sample the cluster block of every nth slot, counting which leaders included the votes of the tracked vote "+
+			"accounts, to diagnose vote-landing problems with specific peers. Set to 0 (default) to disable. "+
+			"Warning: this fetches full blocks, and creates metrics per leader.",
+	)
+	flag.BoolVar(
+		&hostMetrics,
+		"host-metrics",
+		false,
+		"Set this flag to export basic host metrics (CPU steal, memory, ledger disk usage and NVMe temperatures) "+
+			"read from procfs and sysfs, labelled with the validator identity, for hosts not running node_exporter.",
+	)
+	flag.StringVar(
+		&ledgerPath,
+		"ledger-path",
+		"",
+		"Path of the validator ledger, whose filesystem usage is exported under -host-metrics.",
+	)
+	flag.StringVar(
+		&pluginsConfig,
+		"plugins-config",
+		"",
+		"Optional YAML file of the plugin collectors (compiled in, or loaded as Go plugins) to export metrics of.",
+	)
 	flag.Parse()
 
+	if err := rpc.ValidateEncodings(rpcAcceptEncodings); err != nil {
+		return nil, err
+	}
+	// the rpc urls may embed an api key, the clients address them by their values at startup, which the transport
+	// swaps for the current ones:
+	var rpcSecrets []*Secret
+	rpcUrlSecret, err := NewSecret(rpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve rpc url: %w", err)
+	}
+	rpcUrl, rpcSecrets = rpcUrlSecret.Value(), append(rpcSecrets, rpcUrlSecret)
+	for i, fallbackRpcUrl := range fallbackRpcUrls {
+		fallbackRpcUrlSecret, err := NewSecret(fallbackRpcUrl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve fallback rpc url: %w", err)
+		}
+		fallbackRpcUrls[i], rpcSecrets = fallbackRpcUrlSecret.Value(), append(rpcSecrets, fallbackRpcUrlSecret)
+	}
+	referenceRpcUrlSecret, err := NewSecret(referenceRpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reference rpc url: %w", err)
+	}
+	referenceRpcUrl, rpcSecrets = referenceRpcUrlSecret.Value(), append(rpcSecrets, referenceRpcUrlSecret)
+	headerSecrets, err := ParseRpcHeaders(rpcHeaders, rpcBearerToken)
+	if err != nil {
+		return nil, err
+	}
+	urlSecrets := slices.Clone(rpcSecrets)
+	for _, header := range headerSecrets {
+		rpcSecrets = append(rpcSecrets, header.Secret)
+	}
+	tlsConfig, err := rpc.NewTLSConfig(rpcTLSMinVersion, rpcCAFile)
+	if err != nil {
+		return nil, err
+	}
+	transport := NewSecretTransport(
+		rpc.NewTransport(rpc.TransportConfig{
+			MaxIdleConns:    rpcMaxIdleConns,
+			IdleConnTimeout: time.Duration(rpcIdleConnTimeout) * time.Second,
+			TLS:             tlsConfig,
+		}),
+		urlSecrets,
+		headerSecrets,
+	)
+	var tenants []Tenant
+	if tenantsConfig != "" {
+		if tenants, err = LoadTenants(tenantsConfig); err != nil {
+			return nil, err
+		}
+		for _, tenant := range tenants {
+			nodekeys = CombineUnique(nodekeys, tenant.NodeKeys)
+			balanceAddresses = CombineUnique(balanceAddresses, tenant.BalanceAddresses)
+		}
+	}
+	grafanaUrlSecret, err := NewSecret(grafanaUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve grafana url: %w", err)
+	}
+	grafanaApiTokenSecret, err := NewSecret(grafanaApiToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve grafana api token: %w", err)
+	}
+	geyserTokenSecret, err := NewSecret(geyserToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve geyser token: %w", err)
+	}
+
+	if rpcNodeMode {
+		if len(nodekeys) > 0 || validatorIdentity != "" || voteAccountPubkey != "" {
+			return nil, fmt.Errorf(
+				"'-rpc-node-mode' is incompatible with '-nodekey', '-validator-identity' and '-vote-account-pubkey'",
+			)
+		}
+		// rpc-node mode is light mode plus rpc metrics:
+		lightMode = true
+		if len(rpcNodeSampleAccounts) == 0 {
+			rpcNodeSampleAccounts = DefaultRpcNodeSampleAccounts
+		}
+	}
 	config, err := NewExporterConfig(
 		ctx,
 		time.Duration(httpTimeout)*time.Second,
@@ -257,18 +966,163 @@ func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
 		activeIdentity,
 		time.Duration(epochCleanupTime)*time.Second,
 		validatorIdentity,
+		HeaderValues(headerSecrets),
+		transport,
+		registerer,
 	)
 	if err != nil {
 		return nil, err
 	}
 	config.FastMetricsInterval = time.Duration(fastMetricsInterval) * time.Second
+	if config.VoteDistanceAlert, err = NewLagThreshold("vote distance", voteDistanceAlert, voteDistanceClear); err != nil {
+		return nil, err
+	}
+	if config.RootDistanceAlert, err = NewLagThreshold("root distance", rootDistanceAlert, rootDistanceClear); err != nil {
+		return nil, err
+	}
+	config.ReconcileBlockProduction = reconcileBlockProduction
+	config.JSONLinesSink = jsonLinesSink
+	config.JSONLinesSinkInterval = time.Duration(jsonLinesSinkInterval) * time.Second
+	config.RpcAcceptEncodings = rpcAcceptEncodings
+	config.ConfirmedSlotMetrics = confirmedSlotMetrics
+	config.MinRequiredVersion = minRequiredVersion
+	config.RpcSecrets = rpcSecrets
+	config.GrafanaUrl = grafanaUrlSecret
+	config.GrafanaApiToken = grafanaApiTokenSecret
+	config.SecretsReloadInterval = time.Duration(secretsReloadInterval) * time.Second
+	config.CounterStateFile = counterStateFile
+	config.ReusePort = reusePort
+	config.AdminListenAddress = adminListenAddress
+	if wsUrl == WsUrlAuto {
+		if wsUrl, err = rpc.WebsocketUrlFromRpcUrl(rpcUrl); err != nil {
+			return nil, err
+		}
+	}
+	config.WsUrl = wsUrl
+	config.SlotLatencyProbeInterval = time.Duration(slotLatencyProbeInterval) * time.Second
+	config.StakeDelegationScanInterval = time.Duration(stakeDelegationScanInterval) * time.Second
+	if validatorNames != "" {
+		if config.ValidatorNames, err = LoadValidatorNames(validatorNames); err != nil {
+			return nil, err
+		}
+	}
+	config.ValidatorInfoInterval = time.Duration(validatorInfoInterval) * time.Second
+	if voteSubscription && config.WsUrl == "" {
+		return nil, fmt.Errorf("-vote-subscription requires -ws-url")
+	}
+	config.VoteSubscription = voteSubscription
+	if blockSubscription && config.WsUrl == "" {
+		return nil, fmt.Errorf("-block-subscription requires -ws-url")
+	}
+	config.BlockSubscription = blockSubscription
+	if geyserToken != "" && geyserUrl == "" {
+		return nil, fmt.Errorf("-geyser-token requires -geyser-url")
+	}
+	config.GeyserUrl = geyserUrl
+	config.GeyserToken = geyserTokenSecret
+	config.RpcTLSConfig = tlsConfig
+	config.FallbackRpcUrls = fallbackRpcUrls
+	if rpcEndpointRouting && len(fallbackRpcUrls) == 0 {
+		return nil, fmt.Errorf("-rpc-endpoint-routing requires -fallback-rpc-url")
+	}
+	config.RpcEndpointRouting = rpcEndpointRouting
+	config.RpcEndpointProbeInterval = time.Duration(rpcEndpointProbeInterval) * time.Second
+	config.ReferenceRpcUrl = referenceRpcUrl
+	if priorityFeeOutput != "" && !monitorPriorityFees {
+		return nil, fmt.Errorf("-priority-fee-output requires -monitor-priority-fees")
+	}
+	config.MonitorPriorityFees = monitorPriorityFees
+	config.PriorityFeeOutput = priorityFeeOutput
+	config.MonitorMevTips = monitorMevTips
+	if validatorSetSnapshotDir != "" {
+		if info, err := os.Stat(validatorSetSnapshotDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("-validator-set-snapshot-dir %s is not a directory", validatorSetSnapshotDir)
+		}
+	}
+	if validatorSetSnapshotRetention < 0 {
+		return nil, fmt.Errorf("-validator-set-snapshot-retention must not be negative")
+	}
+	config.ValidatorSetSnapshotDir = validatorSetSnapshotDir
+	config.ValidatorSetSnapshotRetention = validatorSetSnapshotRetention
+	config.NonceAccounts = nonceAccounts
+	if (len(tokenOwners) == 0) != (len(tokenMints) == 0) {
+		return nil, fmt.Errorf("-token-owner and -token-mint must be set together")
+	}
+	config.TokenOwners = tokenOwners
+	config.TokenMints = tokenMints
+	if watchAddressSlots <= 0 {
+		return nil, fmt.Errorf("-watch-address-slots must be positive")
+	}
+	config.WatchAddresses = watchAddresses
+	config.WatchAddressSlots = watchAddressSlots
+	config.WatchSignatures = watchSignatures
+	if collectorErrorTolerance < 0 {
+		return nil, fmt.Errorf("-collector-error-tolerance must not be negative")
+	}
+	config.CollectorErrorTolerance = collectorErrorTolerance
+	if config.CollectorErrorTolerances, err = ParseCollectorErrorTolerances(collectorErrorTolerances); err != nil {
+		return nil, err
+	}
+	if rpcMaxAttempts < 1 || rpcRetryJitter < 0 || rpcRetryJitter > 1 {
+		return nil, fmt.Errorf("-rpc-max-attempts must be at least 1 and -rpc-retry-jitter within [0, 1]")
+	}
+	config.RpcRetryPolicy = rpc.RetryPolicy{
+		MaxAttempts:    rpcMaxAttempts,
+		InitialBackoff: time.Duration(rpcRetryBackoffMs) * time.Millisecond,
+		MaxBackoff:     rpc.DefaultRetryPolicy.MaxBackoff,
+		Jitter:         rpcRetryJitter,
+	}
+	if rpcRateLimit < 0 || rpcRateLimitBurst < 1 {
+		return nil, fmt.Errorf("-rpc-rate-limit must not be negative and -rpc-rate-limit-burst must be at least 1")
+	}
+	config.RpcRateLimit = rpcRateLimit
+	config.RpcRateLimitBurst = rpcRateLimitBurst
+	config.EpochRebuild = epochRebuild
+	config.RpcCircuitBreakerThreshold = rpcCircuitBreakerThreshold
+	config.RpcCircuitBreakerCooldown = time.Duration(rpcCircuitBreakerCooldown) * time.Second
+	config.RpcSchemaDrift = rpcSchemaDrift
+	if rpcRecord != "" && rpcReplay != "" {
+		return nil, fmt.Errorf("-rpc-record and -rpc-replay are mutually exclusive")
+	}
+	config.RpcRecord = rpcRecord
+	config.RpcReplay = rpcReplay
+	config.RpcNodeMode = rpcNodeMode
+	config.RpcNodeSampleAccounts = rpcNodeSampleAccounts
+	if identityBalanceThreshold < 0 || voteBalanceThreshold < 0 {
+		return nil, fmt.Errorf("-identity-balance-threshold and -vote-balance-threshold must not be negative")
+	}
+	config.IdentityBalanceThreshold = identityBalanceThreshold
+	config.VoteBalanceThreshold = voteBalanceThreshold
+	if voteInclusionSampleInterval < 0 {
+		return nil, fmt.Errorf("-vote-inclusion-sample-interval must not be negative")
+	}
+	config.VoteInclusionSampleInterval = voteInclusionSampleInterval
+	if ledgerPath != "" && !hostMetrics {
+		return nil, fmt.Errorf("-ledger-path requires -host-metrics")
+	}
+	config.HostMetrics = hostMetrics
+	config.LedgerPath = ledgerPath
+	if pluginsConfig != "" {
+		if config.Plugins, err = LoadPlugins(pluginsConfig); err != nil {
+			return nil, err
+		}
+	}
+	if len(tenants) > 0 {
+		config.Tenants = tenants
+		if config.TenantsByKey, err = GetTenantsByKey(tenants, config.NodeKeys, config.VoteKeys); err != nil {
+			return nil, err
+		}
+	}
 	
 	logger := slog.Get()
 	if voteAccountPubkey != "" {
 		config.VoteAccountPubkey = voteAccountPubkey
 	} else if validatorIdentity != "" {
 		logger.Infof("Vote account not provided, trying to find it from validator identity: %s", validatorIdentity)
-		if voteAccountPubkey, err = GetVoteAccountFromIdentity(ctx, rpc.NewRPCClient(rpcUrl, time.Duration(httpTimeout)*time.Second), validatorIdentity); err != nil {
+		client := rpc.NewRPCClient(rpcUrl, time.Duration(httpTimeout)*time.Second, registerer)
+		client.Headers = HeaderValues(headerSecrets)
+		client.HttpClient.Transport = transport
+		if voteAccountPubkey, err = GetVoteAccountFromIdentity(ctx, client, validatorIdentity); err != nil {
 			logger.Warnf("Failed to get vote account for identity %s: %v", validatorIdentity, err)
 		} else if voteAccountPubkey != "" {
 			logger.Infof("Found vote account %s for identity %s", voteAccountPubkey, validatorIdentity)
@@ -277,3 +1131,31 @@ func NewExporterConfigFromCLI(ctx context.Context) (*ExporterConfig, error) {
 	}
 	return config, nil
 }
+
+// ParseRpcHeaders parses the headers to send to the RPC endpoints, formatted as 'Name: value', whose values may be
+// secret references. The bearer token (if any) is sent as the Authorization header.
+func ParseRpcHeaders(headers []string, bearerToken string) ([]HeaderSecret, error) {
+	var parsed []HeaderSecret
+	for _, header := range headers {
+		name, ref, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			// the header is not echoed, as it may be a secret:
+			return nil, fmt.Errorf("rpc headers must be formatted as 'Name: value'")
+		}
+		secret, err := NewSecret(strings.TrimSpace(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve rpc header %s: %w", name, err)
+		}
+		parsed = append(parsed, HeaderSecret{Name: http.CanonicalHeaderKey(name), Secret: secret})
+	}
+	if bearerToken != "" {
+		secret, err := NewSecret(bearerToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve rpc bearer token: %w", err)
+		}
+		parsed = slices.DeleteFunc(parsed, func(header HeaderSecret) bool { return header.Name == "Authorization" })
+		parsed = append(parsed, HeaderSecret{Name: "Authorization", Prefix: "Bearer ", Secret: secret})
+	}
+	return parsed, nil
+}
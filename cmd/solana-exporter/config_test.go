@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,6 +97,10 @@ func TestNewExporterConfig(t *testing.T) {
 				tt.slotPace,
 				tt.activeIdentity,
 				tt.epochCleanupTime,
+				"",
+				nil,
+				nil,
+				prometheus.NewRegistry(),
 			)
 
 			// Check error expectation
@@ -122,3 +127,23 @@ func TestNewExporterConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRpcHeaders(t *testing.T) {
+	t.Setenv("TEST_RPC_API_KEY", "secret")
+
+	headers, err := ParseRpcHeaders([]string{"x-api-key: env:TEST_RPC_API_KEY", "X-Team:  validators "}, "token")
+	assert.NoError(t, err)
+	values := HeaderValues(headers)
+	assert.Equal(t, "secret", values.Get("X-Api-Key"))
+	assert.Equal(t, "validators", values.Get("X-Team"))
+	assert.Equal(t, "Bearer token", values.Get("Authorization"))
+
+	headers, err = ParseRpcHeaders(nil, "")
+	assert.NoError(t, err)
+	assert.Nil(t, HeaderValues(headers))
+
+	_, err = ParseRpcHeaders([]string{"x-api-key"}, "")
+	assert.Error(t, err)
+	_, err = ParseRpcHeaders([]string{": value"}, "")
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"slices"
+
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -10,8 +12,20 @@ type GaugeDesc struct {
 	Name           string
 	Help           string
 	VariableLabels []string
+
+	// tenantsByKey maps tracked keys to their tenant, if the metric is labelled by tenant
+	tenantsByKey map[string]string
+	// names are the validator names, if the metric is labelled by validator name
+	names *ValidatorNames
 }
 
+var (
+	// tenantKeyLabels are the labels identifying the tenant of a metric, in order of precedence
+	tenantKeyLabels = []string{NodekeyLabel, IdentityLabel, VotekeyLabel, AddressLabel}
+	// nameKeyLabels are the labels identifying the validator of a metric, in order of precedence
+	nameKeyLabels = []string{NodekeyLabel, IdentityLabel, VotekeyLabel}
+)
+
 func NewGaugeDesc(name string, description string, variableLabels ...string) *GaugeDesc {
 	return &GaugeDesc{
 		Desc:           prometheus.NewDesc(name, description, variableLabels, nil),
@@ -26,6 +40,12 @@ func (c *GaugeDesc) MustNewConstMetric(value float64, labels ...string) promethe
 	if len(labels) != len(c.VariableLabels) {
 		logger.Fatalf("Provided labels (%v) do not match %s labels (%v)", labels, c.Name, c.VariableLabels)
 	}
+	if c.tenantsByKey != nil {
+		labels = append(labels, c.tenantOf(labels))
+	}
+	if c.names != nil {
+		labels = append(labels, c.nameOf(labels))
+	}
 	logger.Debugf("Emitting %v to %s(%v)", value, labels, c.Name)
 	return prometheus.MustNewConstMetric(c.Desc, prometheus.GaugeValue, value, labels...)
 }
@@ -33,3 +53,57 @@ func (c *GaugeDesc) MustNewConstMetric(value float64, labels ...string) promethe
 func (c *GaugeDesc) NewInvalidMetric(err error) prometheus.Metric {
 	return prometheus.NewInvalidMetric(c.Desc, err)
 }
+
+// SetTenants adds a TenantLabel to the metric if it is keyed by one of the tenantKeyLabels, valued with the tenant
+// owning the key (or empty, if no tenant does).
+func (c *GaugeDesc) SetTenants(tenantsByKey map[string]string) {
+	if !slices.ContainsFunc(c.VariableLabels, func(label string) bool { return slices.Contains(tenantKeyLabels, label) }) {
+		return
+	}
+	c.tenantsByKey = tenantsByKey
+	c.updateDesc()
+}
+
+// SetValidatorNames adds a NameLabel to the metric if it is keyed by one of the nameKeyLabels, valued with the name of
+// the validator (or empty, if it has none).
+func (c *GaugeDesc) SetValidatorNames(names *ValidatorNames) {
+	if !slices.ContainsFunc(c.VariableLabels, func(label string) bool { return slices.Contains(nameKeyLabels, label) }) {
+		return
+	}
+	c.names = names
+	c.updateDesc()
+}
+
+// updateDesc adds the labels the metric is enriched with (tenant and name, in that order) to its variable labels.
+func (c *GaugeDesc) updateDesc() {
+	labels := slices.Clone(c.VariableLabels)
+	if c.tenantsByKey != nil {
+		labels = append(labels, TenantLabel)
+	}
+	if c.names != nil {
+		labels = append(labels, NameLabel)
+	}
+	c.Desc = prometheus.NewDesc(c.Name, c.Help, labels, nil)
+}
+
+func (c *GaugeDesc) tenantOf(labels []string) string {
+	for _, keyLabel := range tenantKeyLabels {
+		if i := slices.Index(c.VariableLabels, keyLabel); i >= 0 {
+			if tenant, ok := c.tenantsByKey[labels[i]]; ok {
+				return tenant
+			}
+		}
+	}
+	return ""
+}
+
+func (c *GaugeDesc) nameOf(labels []string) string {
+	for _, keyLabel := range nameKeyLabels {
+		if i := slices.Index(c.VariableLabels, keyLabel); i >= 0 {
+			if name := c.names.Get(labels[i]); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
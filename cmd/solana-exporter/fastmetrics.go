@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type (
+	// FastMetricsCache holds the latest valid sample of each series collected on the fast path, along with when it was
+	// taken, such that scrapes between (or after failed) fast collections still see the last known value. Each sample
+	// replaces the previous one of its series, so the cache is bounded by the number of fast-path series.
+	FastMetricsCache struct {
+		// descs are the fast-path descs, for labelling the staleness of their samples with the metric name. Their
+		// prometheus.Desc is looked up at update time, as relabelling (e.g., by tenant) replaces it.
+		descs   []*GaugeDesc
+		samples map[string]fastSample
+		mu      sync.Mutex
+	}
+
+	fastSample struct {
+		metric prometheus.Metric
+		name   string
+		time   time.Time
+	}
+)
+
+func NewFastMetricsCache(descs ...*GaugeDesc) *FastMetricsCache {
+	return &FastMetricsCache{descs: descs, samples: make(map[string]fastSample)}
+}
+
+// nameOf returns the metric name of the fast-path desc emitting metrics of desc.
+func (c *FastMetricsCache) nameOf(desc *prometheus.Desc) string {
+	for _, fastDesc := range c.descs {
+		if fastDesc.Desc == desc {
+			return fastDesc.Name
+		}
+	}
+	return ""
+}
+
+// Update caches the valid metrics of a fast collection taken at now. Invalid metrics (i.e., failed collections) are
+// left out, keeping the previous sample of their series, which then ages.
+func (c *FastMetricsCache) Update(metrics []prometheus.Metric, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, metric := range metrics {
+		var written dto.Metric
+		if err := metric.Write(&written); err != nil {
+			continue
+		}
+		key := []string{metric.Desc().String()}
+		for _, label := range written.GetLabel() {
+			key = append(key, label.GetName()+"="+label.GetValue())
+		}
+		c.samples[strings.Join(key, ",")] = fastSample{metric: metric, name: c.nameOf(metric.Desc()), time: now}
+	}
+}
+
+// Collect emits the cached samples, along with the age (as of now) of the oldest sample of each metric.
+func (c *FastMetricsCache) Collect(ch chan<- prometheus.Metric, age *GaugeDesc, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	oldest := make(map[string]time.Time)
+	for _, sample := range c.samples {
+		ch <- sample.metric
+		if taken, ok := oldest[sample.name]; !ok || sample.time.Before(taken) {
+			oldest[sample.name] = sample.time
+		}
+	}
+	for name, taken := range oldest {
+		ch <- age.MustNewConstMetric(now.Sub(taken).Seconds(), name)
+	}
+}
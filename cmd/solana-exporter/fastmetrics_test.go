@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMetricValue(t *testing.T, metric prometheus.Metric) float64 {
+	var written dto.Metric
+	assert.NoError(t, metric.Write(&written))
+	return written.GetGauge().GetValue()
+}
+
+func TestFastMetricsCache(t *testing.T) {
+	voteDistance := NewGaugeDesc("vote_distance", "", IdentityLabel)
+	age := NewGaugeDesc("fast_metric_age_seconds", "", MetricLabel)
+	cache := NewFastMetricsCache(voteDistance)
+	collect := func(now time.Time) []prometheus.Metric {
+		ch := make(chan prometheus.Metric, 10)
+		cache.Collect(ch, age, now)
+		close(ch)
+		var metrics []prometheus.Metric
+		for metric := range ch {
+			metrics = append(metrics, metric)
+		}
+		return metrics
+	}
+
+	// nothing is emitted before the first sample:
+	start := time.Now()
+	assert.Empty(t, collect(start))
+
+	cache.Update([]prometheus.Metric{voteDistance.MustNewConstMetric(3, "aaa")}, start)
+	metrics := collect(start.Add(time.Second))
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, float64(3), getMetricValue(t, metrics[0]))
+	assert.Equal(t, float64(1), getMetricValue(t, metrics[1]))
+
+	// failed collections keep the last sample, which ages:
+	cache.Update([]prometheus.Metric{voteDistance.NewInvalidMetric(errors.New("rpc down"))}, start.Add(time.Minute))
+	metrics = collect(start.Add(time.Minute))
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, float64(3), getMetricValue(t, metrics[0]))
+	assert.Equal(t, float64(60), getMetricValue(t, metrics[1]))
+
+	// and are replaced by the next valid one:
+	cache.Update([]prometheus.Metric{voteDistance.MustNewConstMetric(5, "aaa")}, start.Add(time.Minute))
+	metrics = collect(start.Add(time.Minute))
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, float64(5), getMetricValue(t, metrics[0]))
+	assert.Equal(t, float64(0), getMetricValue(t, metrics[1]))
+}
+
+func TestFastMetricsCache_relabelled(t *testing.T) {
+	voteDistance := NewGaugeDesc("vote_distance", "", IdentityLabel)
+	age := NewGaugeDesc("fast_metric_age_seconds", "", MetricLabel)
+	cache := NewFastMetricsCache(voteDistance)
+	// relabelling (as in multi-tenant mode) replaces the desc after the cache is built:
+	voteDistance.SetTenants(map[string]string{"aaa": "acme"})
+
+	start := time.Now()
+	cache.Update([]prometheus.Metric{voteDistance.MustNewConstMetric(3, "aaa")}, start)
+	ch := make(chan prometheus.Metric, 10)
+	cache.Collect(ch, age, start.Add(time.Second))
+	close(ch)
+	var metrics []prometheus.Metric
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+	assert.Len(t, metrics, 2)
+
+	var written dto.Metric
+	assert.NoError(t, metrics[1].Write(&written))
+	assert.Equal(t, MetricLabel, written.GetLabel()[0].GetName())
+	assert.Equal(t, "vote_distance", written.GetLabel()[0].GetValue())
+}
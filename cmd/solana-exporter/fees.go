@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+// LamportsPerSignature is the base fee charged per transaction signature, anything above it is priority fee.
+const LamportsPerSignature = 5000
+
+// PriorityFeeQuantiles are the quantiles of the per-transaction priority fees emitted for each produced block.
+var PriorityFeeQuantiles = []float64{0.25, 0.5, 0.75, 0.9, 0.99}
+
+// PriorityFeeRecord is the raw priority fee detail of a single produced block.
+type PriorityFeeRecord struct {
+	Slot    int64  `json:"slot"`
+	Epoch   int64  `json:"epoch"`
+	Nodekey string `json:"nodekey"`
+	// PriorityFees are the priority fees (in lamports) of the block's non-vote transactions, in block order
+	PriorityFees []int64 `json:"priority_fees"`
+	// ComputeUnits are the compute units consumed by the same transactions, -1 where the node did not report them
+	ComputeUnits []int64 `json:"compute_units"`
+}
+
+// GetPriorityFeeRecord extracts the priority fee of every non-vote transaction of a block fetched with full
+// transaction details. The priority fee is derived as the transaction fee on top of the base signature fees.
+func GetPriorityFeeRecord(nodekey string, epoch int64, slot int64, block *rpc.Block) (*PriorityFeeRecord, error) {
+	txData, err := json.Marshal(block.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transactions: %w", err)
+	}
+	var transactions []rpc.FullTransaction
+	if err := json.Unmarshal(txData, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	record := PriorityFeeRecord{Slot: slot, Epoch: epoch, Nodekey: nodekey}
+	for _, tx := range transactions {
+		if tx.Meta == nil || slices.Contains(tx.Transaction.Message.AccountKeys, VoteProgram) {
+			continue
+		}
+		priorityFee := tx.Meta.Fee - int64(len(tx.Transaction.Signatures))*LamportsPerSignature
+		record.PriorityFees = append(record.PriorityFees, max(priorityFee, 0))
+		computeUnits := int64(-1)
+		if tx.Meta.ComputeUnitsConsumed != nil {
+			computeUnits = *tx.Meta.ComputeUnitsConsumed
+		}
+		record.ComputeUnits = append(record.ComputeUnits, computeUnits)
+	}
+	return &record, nil
+}
+
+// GetQuantile returns the nearest-rank quantile of the values, which must be sorted in ascending order.
+func GetQuantile(sorted []int64, quantile float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(quantile*float64(len(sorted)))) - 1
+	return sorted[max(min(rank, len(sorted)-1), 0)]
+}
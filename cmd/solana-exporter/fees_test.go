@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeeTransaction(accountKey string, signatures int, fee int64, computeUnits *int64) map[string]any {
+	meta := map[string]any{"fee": fee}
+	if computeUnits != nil {
+		meta["computeUnitsConsumed"] = *computeUnits
+	}
+	return map[string]any{
+		"transaction": map[string]any{
+			"signatures": make([]string, signatures),
+			"message":    map[string]any{"accountKeys": []string{"aaa", accountKey}},
+		},
+		"meta": meta,
+	}
+}
+
+func TestGetPriorityFeeRecord(t *testing.T) {
+	computeUnits := int64(200_000)
+	block := rpc.Block{
+		Transactions: []map[string]any{
+			newFeeTransaction(VoteProgram, 1, 5000, nil),
+			newFeeTransaction("xxx", 1, 25_000, &computeUnits),
+			newFeeTransaction("xxx", 2, 10_000, nil),
+			// missing meta:
+			{"transaction": map[string]any{"signatures": []string{""}}},
+		},
+	}
+
+	record, err := GetPriorityFeeRecord("aaa", 2, 100, &block)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&PriorityFeeRecord{
+			Slot:         100,
+			Epoch:        2,
+			Nodekey:      "aaa",
+			PriorityFees: []int64{20_000, 0},
+			ComputeUnits: []int64{200_000, -1},
+		},
+		record,
+	)
+}
+
+func TestGetQuantile(t *testing.T) {
+	sorted := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, int64(1), GetQuantile(sorted, 0))
+	assert.Equal(t, int64(5), GetQuantile(sorted, 0.5))
+	assert.Equal(t, int64(9), GetQuantile(sorted, 0.9))
+	assert.Equal(t, int64(10), GetQuantile(sorted, 0.99))
+	assert.Equal(t, int64(0), GetQuantile(nil, 0.5))
+}
+
+func TestSlotWatcher_emitPriorityFees(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.MonitorPriorityFees = true
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+	var output bytes.Buffer
+	watcher.SetPriorityFeeOutput(&output)
+
+	block := rpc.Block{
+		Transactions: []map[string]any{
+			newFeeTransaction("xxx", 1, 5000, nil),
+			newFeeTransaction("xxx", 1, 15_000, nil),
+			newFeeTransaction("xxx", 1, 105_000, nil),
+		},
+	}
+	assert.NoError(t, watcher.emitPriorityFees("aaa", 1, 30, &block))
+	for quantile, expected := range map[string]float64{"0.25": 0, "0.5": 10_000, "0.99": 100_000} {
+		assert.Equal(t, expected, testutil.ToFloat64(watcher.PriorityFeeMetric.WithLabelValues("aaa", quantile)))
+	}
+
+	var record PriorityFeeRecord
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &record))
+	assert.Equal(t, int64(30), record.Slot)
+	assert.Equal(t, []int64{0, 10_000, 100_000}, record.PriorityFees)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+)
+
+// finalityWindow is the number of most recent slots the finalization latency is averaged over.
+const finalityWindow = 100
+
+type (
+	// FinalityTracker estimates the time between a slot being optimistically confirmed and it being finalized, from
+	// the times at which the exporter first samples it at each commitment. Its resolution is therefore bound by the
+	// sampling pace.
+	FinalityTracker struct {
+		// pending are the sampled confirmed slots not yet seen finalized, in ascending order
+		pending []slotSample
+		// latencies are the most recent confirmed->finalized times (in seconds)
+		latencies []float64
+	}
+
+	slotSample struct {
+		slot int64
+		time time.Time
+	}
+)
+
+func NewFinalityTracker() *FinalityTracker {
+	return &FinalityTracker{}
+}
+
+// ObserveConfirmed records the time a slot was first sampled at confirmed commitment.
+func (f *FinalityTracker) ObserveConfirmed(slot int64, now time.Time) {
+	if len(f.pending) > 0 && slot <= f.pending[len(f.pending)-1].slot {
+		return
+	}
+	f.pending = append(f.pending, slotSample{slot: slot, time: now})
+}
+
+// ObserveFinalized records the finalization of all pending confirmed slots up to and including the slot.
+func (f *FinalityTracker) ObserveFinalized(slot int64, now time.Time) {
+	finalized := 0
+	for _, sample := range f.pending {
+		if sample.slot > slot {
+			break
+		}
+		f.latencies = append(f.latencies, now.Sub(sample.time).Seconds())
+		finalized++
+	}
+	f.pending = f.pending[finalized:]
+	if len(f.latencies) > finalityWindow {
+		f.latencies = f.latencies[len(f.latencies)-finalityWindow:]
+	}
+}
+
+// AverageLatency returns the average confirmed->finalized time (in seconds) over the window, and whether any slot
+// has been finalized yet.
+func (f *FinalityTracker) AverageLatency() (float64, bool) {
+	if len(f.latencies) == 0 {
+		return 0, false
+	}
+	var total float64
+	for _, latency := range f.latencies {
+		total += latency
+	}
+	return total / float64(len(f.latencies)), true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalityTracker(t *testing.T) {
+	tracker := NewFinalityTracker()
+	start := time.Unix(1_700_000_000, 0)
+
+	_, ok := tracker.AverageLatency()
+	assert.False(t, ok)
+
+	tracker.ObserveConfirmed(100, start)
+	tracker.ObserveConfirmed(110, start.Add(4*time.Second))
+	// already pending:
+	tracker.ObserveConfirmed(105, start.Add(5*time.Second))
+	tracker.ObserveFinalized(68, start.Add(5*time.Second))
+	_, ok = tracker.AverageLatency()
+	assert.False(t, ok)
+
+	// 100 is finalized after 12s:
+	tracker.ObserveFinalized(100, start.Add(12*time.Second))
+	latency, ok := tracker.AverageLatency()
+	assert.True(t, ok)
+	assert.Equal(t, float64(12), latency)
+
+	// 110 is finalized after 14s:
+	tracker.ObserveFinalized(115, start.Add(18*time.Second))
+	latency, _ = tracker.AverageLatency()
+	assert.Equal(t, float64(13), latency)
+	assert.Empty(t, tracker.pending)
+
+	// only the most recent window is averaged:
+	for i := int64(0); i < finalityWindow; i++ {
+		tracker.ObserveConfirmed(200+i, start)
+	}
+	tracker.ObserveFinalized(200+finalityWindow, start.Add(10*time.Second))
+	latency, _ = tracker.AverageLatency()
+	assert.Equal(t, float64(10), latency)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/geyser"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+// NewGeyserClient creates a Yellowstone gRPC client of the -geyser-url, authenticated with the -geyser-token (if any).
+func NewGeyserClient(config *ExporterConfig) (*geyser.Client, error) {
+	client, err := geyser.NewClient(config.GeyserUrl, config.RpcTLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	client.Token = config.GeyserToken.Value
+	return client, nil
+}
+
+// GeyserBalances caches the balances of accounts from Yellowstone gRPC account updates, such that they need not be
+// polled. Updates are only sent on writes, so accounts not written to since subscribing are still polled, and the
+// cache is cleared whenever the subscription drops.
+type GeyserBalances struct {
+	mu       sync.Mutex
+	balances map[string]float64
+	logger   *zap.SugaredLogger
+}
+
+func NewGeyserBalances() *GeyserBalances {
+	return &GeyserBalances{balances: make(map[string]float64), logger: slog.Get()}
+}
+
+// Get returns the balance (in SOL) of the address, if known.
+func (b *GeyserBalances) Get(address string) (float64, bool) {
+	if b == nil {
+		return 0, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	balance, ok := b.balances[address]
+	return balance, ok
+}
+
+// Observe caches the balance of an account update.
+func (b *GeyserBalances) Observe(update geyser.AccountUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balances[update.Pubkey] = float64(update.Lamports) / rpc.LamportsInSol
+}
+
+func (b *GeyserBalances) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.balances)
+}
+
+// Watch subscribes to the account updates of the addresses, resubscribing every retryInterval after the subscription
+// drops, until the context is cancelled. The balances are only served while subscribed, being reset on every exit.
+func (b *GeyserBalances) Watch(
+	ctx context.Context, client *geyser.Client, addresses []string, retryInterval time.Duration,
+) {
+	defer b.reset()
+	subscription := geyser.Subscription{Accounts: addresses, Commitment: geyser.CommitmentConfirmed}
+	for {
+		updates, err := client.Subscribe(ctx, subscription)
+		if err != nil {
+			b.logger.Errorf("Failed to subscribe to account updates, polling instead: %v", err)
+		} else {
+			for update := range updates {
+				if update.Account != nil {
+					b.Observe(*update.Account)
+				}
+			}
+			b.reset()
+		}
+
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Stopping geyser account subscription")
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// SetGeyserBalances sets the balances received through Yellowstone gRPC, which are served instead of being polled.
+func (c *SolanaCollector) SetGeyserBalances(balances *GeyserBalances) {
+	c.geyserBalances = balances
+}
+
+// GetGeyserBlockNotification converts the block meta into the notification of a block produced by one of the
+// nodekeys, as identified by its fee reward.
+func GetGeyserBlockNotification(
+	meta geyser.BlockMeta, nodekeys []string, received time.Time,
+) (nodekeyBlockNotification, bool) {
+	block := rpc.Block{Rewards: make([]rpc.BlockReward, 0, len(meta.Rewards))}
+	var leader string
+	for _, reward := range meta.Rewards {
+		rewardType := ""
+		switch reward.RewardType {
+		case geyser.RewardFee:
+			rewardType = "Fee"
+			leader = reward.Pubkey
+		case geyser.RewardRent:
+			rewardType = "Rent"
+		case geyser.RewardStaking:
+			rewardType = "Staking"
+		case geyser.RewardVoting:
+			rewardType = "Voting"
+		}
+		block.Rewards = append(
+			block.Rewards, rpc.BlockReward{Pubkey: reward.Pubkey, Lamports: reward.Lamports, RewardType: rewardType},
+		)
+	}
+	for _, nodekey := range nodekeys {
+		if nodekey == leader {
+			notification := rpc.BlockNotification{Slot: meta.Slot, Block: &block}
+			return nodekeyBlockNotification{nodekey, notification, received}, true
+		}
+	}
+	return nodekeyBlockNotification{}, false
+}
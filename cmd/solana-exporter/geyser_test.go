@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/geyser"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGeyserBlockNotification(t *testing.T) {
+	received := time.Now()
+	meta := geyser.BlockMeta{
+		Slot: 30,
+		Rewards: []geyser.Reward{
+			{Pubkey: "aaa", Lamports: 10_000, RewardType: geyser.RewardFee},
+			{Pubkey: "bbb", Lamports: 20, RewardType: geyser.RewardRent},
+		},
+	}
+
+	notification, ok := GetGeyserBlockNotification(meta, []string{"aaa", "ccc"}, received)
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		nodekeyBlockNotification{
+			nodekey: "aaa",
+			BlockNotification: rpc.BlockNotification{
+				Slot: 30,
+				Block: &rpc.Block{
+					Rewards: []rpc.BlockReward{
+						{Pubkey: "aaa", Lamports: 10_000, RewardType: "Fee"},
+						{Pubkey: "bbb", Lamports: 20, RewardType: "Rent"},
+					},
+				},
+			},
+			received: received,
+		},
+		notification,
+	)
+
+	// blocks of untracked leaders are ignored:
+	_, ok = GetGeyserBlockNotification(meta, []string{"ccc"}, received)
+	assert.False(t, ok)
+}
+
+func TestGeyserBalances(t *testing.T) {
+	var unset *GeyserBalances
+	_, ok := unset.Get("aaa")
+	assert.False(t, ok)
+
+	balances := NewGeyserBalances()
+	balances.Observe(geyser.AccountUpdate{Pubkey: "aaa", Lamports: 5 * rpc.LamportsInSol})
+	balance, ok := balances.Get("aaa")
+	assert.True(t, ok)
+	assert.Equal(t, float64(5), balance)
+	_, ok = balances.Get("bbb")
+	assert.False(t, ok)
+
+	balances.reset()
+	_, ok = balances.Get("aaa")
+	assert.False(t, ok)
+}
+
+func TestSolanaCollector_collectBalances_Geyser(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+	// the balance received through geyser is served, rather than the polled one:
+	balances := NewGeyserBalances()
+	balances.Observe(geyser.AccountUpdate{Pubkey: "aaa", Lamports: 42 * rpc.LamportsInSol})
+	collector.SetGeyserBalances(balances)
+	collectBalances := collectFunc(func(ch chan<- prometheus.Metric) {
+		collector.collectBalances(context.Background(), ch)
+	})
+
+	test := collector.AccountBalances.makeCollectionTest(
+		NewLV(4, "AAA"),
+		NewLV(5, "BBB"),
+		NewLV(6, "CCC"),
+		NewLV(42, "aaa"),
+		NewLV(2, "bbb"),
+		NewLV(3, "ccc"),
+	)
+	assert.NoError(t,
+		testutil.CollectAndCompare(collectBalances, bytes.NewBufferString(test.ExpectedResponse), test.Name),
+	)
+}
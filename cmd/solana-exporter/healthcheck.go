@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HealthcheckCommand is the subcommand which checks the /healthz endpoint of a running exporter, for container health
+// checks in images without a shell or HTTP utilities.
+const HealthcheckCommand = "healthcheck"
+
+// CheckHealth returns an error unless the /healthz endpoint of the admin listener at the address responds OK within
+// the timeout.
+func CheckHealth(address string, timeout time.Duration) error {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+	url := fmt.Sprintf("http://%s/healthz", address)
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", url, err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// RunHealthcheck parses the healthcheck subcommand args and checks the health of the exporter.
+func RunHealthcheck(args []string) error {
+	var (
+		address string
+		timeout int
+	)
+	flags := flag.NewFlagSet(HealthcheckCommand, flag.ExitOnError)
+	flags.StringVar(
+		&address, "address", "localhost:8081", "Admin listen address (see -admin-listen-address) of the exporter.",
+	)
+	flags.IntVar(&timeout, "timeout", 5, "Timeout of the health check, in seconds.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return CheckHealth(address, time.Duration(timeout)*time.Second)
+}
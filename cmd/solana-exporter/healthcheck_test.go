@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHealth(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	assert.NoError(t, CheckHealth(address, time.Second))
+	// a bare port is checked on localhost:
+	_, port, _ := strings.Cut(address, ":")
+	assert.NoError(t, CheckHealth(":"+port, time.Second))
+
+	healthy = false
+	assert.ErrorContains(t, CheckHealth(address, time.Second), "503")
+
+	server.Close()
+	assert.ErrorContains(t, CheckHealth(address, time.Second), "failed to reach")
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+const (
+	DeviceLabel = "device"
+
+	// userHz is the unit of the cpu times in /proc/stat, which is fixed at 100 on Linux
+	userHz = 100
+)
+
+// HostCollector exports basic host telemetry (CPU steal, memory, ledger disk usage and NVMe temperatures) read from
+// procfs and sysfs, for hosts which do not run node_exporter. All metrics are labelled with the validator identity,
+// such that they can be joined with the validator metrics. Metrics which cannot be read (e.g., on hosts without NVMe
+// sensors) are left out.
+type HostCollector struct {
+	procPath   string
+	sysPath    string
+	ledgerPath string
+	nodekey    string
+	logger     *zap.SugaredLogger
+
+	CpuStealSeconds        *prometheus.Desc
+	MemoryTotalBytes       *prometheus.Desc
+	MemoryAvailableBytes   *prometheus.Desc
+	LedgerDiskTotalBytes   *prometheus.Desc
+	LedgerDiskFreeBytes    *prometheus.Desc
+	NvmeTemperatureCelsius *prometheus.Desc
+}
+
+func NewHostCollector(procPath, sysPath string, config *ExporterConfig) *HostCollector {
+	return &HostCollector{
+		procPath:   procPath,
+		sysPath:    sysPath,
+		ledgerPath: config.LedgerPath,
+		nodekey:    config.ValidatorIdentity,
+		logger:     slog.Get(),
+		CpuStealSeconds: prometheus.NewDesc(
+			"solana_host_cpu_steal_seconds_total",
+			"Time the host's CPUs spent waiting on the hypervisor (steal), summed over all CPUs",
+			[]string{NodekeyLabel},
+			nil,
+		),
+		MemoryTotalBytes: prometheus.NewDesc(
+			"solana_host_memory_total_bytes",
+			"Total usable memory of the host",
+			[]string{NodekeyLabel},
+			nil,
+		),
+		MemoryAvailableBytes: prometheus.NewDesc(
+			"solana_host_memory_available_bytes",
+			"Memory of the host available for new allocations without swapping",
+			[]string{NodekeyLabel},
+			nil,
+		),
+		LedgerDiskTotalBytes: prometheus.NewDesc(
+			"solana_host_ledger_disk_total_bytes",
+			"Size of the filesystem holding the ledger",
+			[]string{NodekeyLabel},
+			nil,
+		),
+		LedgerDiskFreeBytes: prometheus.NewDesc(
+			"solana_host_ledger_disk_free_bytes",
+			"Free space (available to unprivileged users) on the filesystem holding the ledger",
+			[]string{NodekeyLabel},
+			nil,
+		),
+		NvmeTemperatureCelsius: prometheus.NewDesc(
+			"solana_host_nvme_temperature_celsius",
+			fmt.Sprintf("Composite temperature of an NVMe drive (represented by %s)", DeviceLabel),
+			[]string{NodekeyLabel, DeviceLabel},
+			nil,
+		),
+	}
+}
+
+func (c *HostCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.CpuStealSeconds
+	ch <- c.MemoryTotalBytes
+	ch <- c.MemoryAvailableBytes
+	if c.ledgerPath != "" {
+		ch <- c.LedgerDiskTotalBytes
+		ch <- c.LedgerDiskFreeBytes
+	}
+	ch <- c.NvmeTemperatureCelsius
+}
+
+func (c *HostCollector) Collect(ch chan<- prometheus.Metric) {
+	if steal, err := ReadCpuStealSeconds(filepath.Join(c.procPath, "stat")); err != nil {
+		c.logger.Errorf("failed to read cpu steal: %v", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.CpuStealSeconds, prometheus.CounterValue, steal, c.nodekey)
+	}
+
+	if memory, err := ReadMeminfo(filepath.Join(c.procPath, "meminfo")); err != nil {
+		c.logger.Errorf("failed to read meminfo: %v", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(
+			c.MemoryTotalBytes, prometheus.GaugeValue, float64(memory["MemTotal"]), c.nodekey,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.MemoryAvailableBytes, prometheus.GaugeValue, float64(memory["MemAvailable"]), c.nodekey,
+		)
+	}
+
+	if c.ledgerPath != "" {
+		if total, free, err := statDisk(c.ledgerPath); err != nil {
+			c.logger.Errorf("failed to stat ledger disk: %v", err)
+		} else {
+			ch <- prometheus.MustNewConstMetric(c.LedgerDiskTotalBytes, prometheus.GaugeValue, float64(total), c.nodekey)
+			ch <- prometheus.MustNewConstMetric(c.LedgerDiskFreeBytes, prometheus.GaugeValue, float64(free), c.nodekey)
+		}
+	}
+
+	temperatures, err := ReadNvmeTemperatures(filepath.Join(c.sysPath, "class", "hwmon"))
+	if err != nil {
+		c.logger.Errorf("failed to read nvme temperatures: %v", err)
+	}
+	for device, temperature := range temperatures {
+		ch <- prometheus.MustNewConstMetric(
+			c.NvmeTemperatureCelsius, prometheus.GaugeValue, temperature, c.nodekey, device,
+		)
+	}
+}
+
+// ReadCpuStealSeconds returns the total steal time of all CPUs from a /proc/stat file.
+func ReadCpuStealSeconds(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// cpu  user nice system idle iowait irq softirq steal guest guest_nice
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || fields[0] != "cpu" {
+			continue
+		}
+		ticks, err := strconv.ParseFloat(fields[8], 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse steal time: %w", err)
+		}
+		return ticks / userHz, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no cpu line in %s", path)
+}
+
+// ReadMeminfo returns the fields of a /proc/meminfo file, in bytes.
+func ReadMeminfo(path string) (map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	memory := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// e.g., "MemTotal:       16318480 kB"
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		amount, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			amount *= 1024
+		}
+		memory[name] = amount
+	}
+	return memory, scanner.Err()
+}
+
+// ReadNvmeTemperatures returns the composite temperature (in °C) of each NVMe drive exposing a hwmon sensor under the
+// provided /sys/class/hwmon directory, keyed by the drive's device name (e.g., nvme0).
+func ReadNvmeTemperatures(hwmonPath string) (map[string]float64, error) {
+	entries, err := os.ReadDir(hwmonPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// no hardware sensors are exposed at all (e.g., in a VM):
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	temperatures := make(map[string]float64)
+	for _, entry := range entries {
+		sensorPath := filepath.Join(hwmonPath, entry.Name())
+		name, err := os.ReadFile(filepath.Join(sensorPath, "name"))
+		if err != nil || strings.TrimSpace(string(name)) != "nvme" {
+			continue
+		}
+		// temp1 is the composite temperature, in millidegrees:
+		input, err := os.ReadFile(filepath.Join(sensorPath, "temp1_input"))
+		if err != nil {
+			continue
+		}
+		millidegrees, err := strconv.ParseFloat(strings.TrimSpace(string(input)), 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse temperature of %s: %w", entry.Name(), err)
+		}
+		device := entry.Name()
+		if target, err := os.Readlink(filepath.Join(sensorPath, "device")); err == nil {
+			device = filepath.Base(target)
+		}
+		temperatures[device] = millidegrees / 1000
+	}
+	return temperatures, nil
+}
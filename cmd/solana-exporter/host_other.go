@@ -0,0 +1,9 @@
+//go:build !(linux || darwin)
+
+package main
+
+import "fmt"
+
+func statDisk(_ string) (uint64, uint64, error) {
+	return 0, 0, fmt.Errorf("disk usage is not supported on this platform")
+}
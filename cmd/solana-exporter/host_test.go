@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles writes the provided files (keyed by path relative to root), creating their directories.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestHostCollector(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"proc/stat": "cpu  100 0 50 1000 10 0 5 250 0 0\ncpu0 50 0 25 500 5 0 2 125 0 0\n",
+		"proc/meminfo": "MemTotal:       16318480 kB\nMemFree:         1000000 kB\n" +
+			"MemAvailable:    8000000 kB\nHugePages_Total:       0\n",
+		"sys/class/hwmon/hwmon0/name":        "acpitz\n",
+		"sys/class/hwmon/hwmon0/temp1_input": "30000\n",
+		"sys/class/hwmon/hwmon1/name":        "nvme\n",
+		"sys/class/hwmon/hwmon1/temp1_input": "41850\n",
+	})
+	assert.NoError(t, os.Symlink("../../../devices/nvme0", filepath.Join(root, "sys/class/hwmon/hwmon1/device")))
+
+	config := &ExporterConfig{ValidatorIdentity: "aaa", LedgerPath: root}
+	collector := NewHostCollector(filepath.Join(root, "proc"), filepath.Join(root, "sys"), config)
+	expected := `
+# HELP solana_host_cpu_steal_seconds_total Time the host's CPUs spent waiting on the hypervisor (steal), summed over all CPUs
+# TYPE solana_host_cpu_steal_seconds_total counter
+solana_host_cpu_steal_seconds_total{nodekey="aaa"} 2.5
+# HELP solana_host_memory_available_bytes Memory of the host available for new allocations without swapping
+# TYPE solana_host_memory_available_bytes gauge
+solana_host_memory_available_bytes{nodekey="aaa"} 8.192e+09
+# HELP solana_host_memory_total_bytes Total usable memory of the host
+# TYPE solana_host_memory_total_bytes gauge
+solana_host_memory_total_bytes{nodekey="aaa"} 1.671012352e+10
+# HELP solana_host_nvme_temperature_celsius Composite temperature of an NVMe drive (represented by device)
+# TYPE solana_host_nvme_temperature_celsius gauge
+solana_host_nvme_temperature_celsius{device="nvme0",nodekey="aaa"} 41.85
+`
+	names := []string{
+		"solana_host_cpu_steal_seconds_total",
+		"solana_host_memory_total_bytes",
+		"solana_host_memory_available_bytes",
+		"solana_host_nvme_temperature_celsius",
+	}
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), names...))
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "solana_host_ledger_disk_free_bytes"))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// systemd passes activated sockets starting at this file descriptor, see sd_listen_fds(3)
+const listenFdsStart = 3
+
+// NewListener creates the metrics listener. If the exporter was started through systemd socket activation, the
+// passed socket is used, otherwise the address is bound - with SO_REUSEPORT if reusePort is set, such that a new
+// exporter version can bind the same port before the old one exits, avoiding scrape gaps during upgrades.
+func NewListener(ctx context.Context, address string, reusePort bool) (net.Listener, error) {
+	if listener, ok, err := activatedListener(); ok || err != nil {
+		return listener, err
+	}
+
+	config := net.ListenConfig{}
+	if reusePort {
+		config.Control = reusePortControl
+	}
+	return config.Listen(ctx, "tcp", address)
+}
+
+// activatedListener returns the first socket passed by systemd socket activation, if any.
+func activatedListener() (net.Listener, bool, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, false, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, false, fmt.Errorf("invalid LISTEN_FDS %q for socket activation", os.Getenv("LISTEN_FDS"))
+	}
+	// make sure child processes do not inherit the activation:
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+
+	file := os.NewFile(listenFdsStart, "LISTEN_FD_3")
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to use socket activated listener: %w", err)
+	}
+	return listener, true, nil
+}
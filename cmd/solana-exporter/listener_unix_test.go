@@ -0,0 +1,26 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewListener_ReusePort(t *testing.T) {
+	ctx := context.Background()
+	first, err := NewListener(ctx, "127.0.0.1:0", true)
+	assert.NoError(t, err)
+	defer first.Close()
+
+	// a second exporter can bind the same port:
+	second, err := NewListener(ctx, first.Addr().String(), true)
+	assert.NoError(t, err)
+	defer second.Close()
+
+	// ...but not without SO_REUSEPORT:
+	_, err = NewListener(ctx, first.Addr().String(), false)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
@@ -17,11 +18,30 @@ var BuildVersion = "dev"
 func main() {
 	slog.Init()
 	logger := slog.Get()
+	if len(os.Args) > 1 && os.Args[1] == GenerateRulesCommand {
+		if err := RunGenerateRules(os.Args[2:]); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == HealthcheckCommand {
+		if err := RunHealthcheck(os.Args[2:]); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == BackfillCommand {
+		if err := RunBackfill(context.Background(), os.Args[2:]); err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
 	logger.Infof("DEBUG: solana-exporter build version: %s", BuildVersion)
 	logger.Infof("DEBUG: main() started")
 	ctx := context.Background()
 
-	config, err := NewExporterConfigFromCLI(ctx)
+	registerer := prometheus.DefaultRegisterer
+	config, err := NewExporterConfigFromCLI(ctx, registerer)
 	if err != nil {
 		logger.Fatal(err)
 	}
@@ -34,15 +54,94 @@ func main() {
 
 	logger.Infof("DEBUG: VoteKeys at startup: %v", config.VoteKeys)
 
-	rpcClient := rpc.NewRPCClient(config.RpcUrl, config.HttpTimeout)
-	collector := NewSolanaCollector(rpcClient, config)
-	slotWatcher := NewSlotWatcher(rpcClient, config)
+	rpcClient := rpc.NewFailoverRPCClient(
+		append([]string{config.RpcUrl}, config.FallbackRpcUrls...), config.HttpTimeout, registerer,
+	)
+	rpcClient.Headers = config.RpcHeaders
+	rpcClient.HttpClient.Transport = config.RpcTransport
+	rpcClient.AcceptEncodings = config.RpcAcceptEncodings
+	rpcClient.Retry = config.RpcRetryPolicy
+	rpcClient.RateLimiter = NewRateLimiter(config, config.RpcUrl)
+	rpcClient.CircuitBreaker = NewCircuitBreaker(config)
+	if config.RpcSchemaDrift {
+		rpcClient.SchemaDrift = rpc.NewSchemaDrift()
+	}
+	if config.RpcRecord != "" {
+		file, err := os.OpenFile(config.RpcRecord, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			logger.Fatalf("failed to open rpc recording: %v", err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer file.Close()
+		logger.Warnf("Recording all RPC calls to %s", config.RpcRecord)
+		rpcClient.Recorder = rpc.NewRecorder(file)
+	}
+	if config.RpcReplay != "" {
+		file, err := os.Open(config.RpcReplay)
+		if err != nil {
+			logger.Fatalf("failed to open rpc recording: %v", err)
+		}
+		rpcClient.Replayer, err = rpc.NewReplayer(file)
+		_ = file.Close()
+		if err != nil {
+			logger.Fatalf("failed to load rpc recording: %v", err)
+		}
+		logger.Warnf("Replaying the RPC calls recorded in %s", config.RpcReplay)
+	}
+	collector := NewSolanaCollector(rpcClient, config, registerer)
+	if config.ValidatorIdentity != "" && config.VoteAccountPubkey != "" {
+		logger.Infof("Checking vote account %s against identity %s...", config.VoteAccountPubkey, config.ValidatorIdentity)
+		if mismatch, err := collector.CheckVoteAccountIdentity(ctx); err != nil {
+			logger.Errorf("%v, retrying on every collection", err)
+		} else if mismatch {
+			logger.Errorf(
+				"Configured vote account %s does not vote for the configured identity %s!",
+				config.VoteAccountPubkey, config.ValidatorIdentity,
+			)
+		} else {
+			logger.Info("Vote account matches identity.")
+		}
+	}
+	slotWatcher := NewSlotWatcher(rpcClient, config, registerer)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	if rpcClient.Endpoints != nil {
+		rpcClient.Endpoints.Routing = config.RpcEndpointRouting
+		if config.RpcEndpointProbeInterval > 0 {
+			go rpcClient.ProbeEndpoints(ctx, config.RpcEndpointProbeInterval)
+		}
+	}
+	if config.PriorityFeeOutput != "" {
+		file, err := os.OpenFile(config.PriorityFeeOutput, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			logger.Fatalf("failed to open priority fee output: %v", err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer file.Close()
+		slotWatcher.SetPriorityFeeOutput(file)
+	}
 	go slotWatcher.WatchSlots(ctx)
+	if config.WsUrl != "" {
+		go slotWatcher.WatchSlotSubscription(ctx, NewWSClient(config))
+	}
+	if config.BlockSubscription && !config.LightMode {
+		go slotWatcher.WatchBlockSubscription(ctx, NewWSClient(config))
+	}
+	if config.GeyserUrl != "" {
+		geyserClient, err := NewGeyserClient(config)
+		if err != nil {
+			logger.Fatalf("failed to create geyser client: %v", err)
+		}
+		go slotWatcher.WatchGeyser(ctx, geyserClient)
+		if addresses := collector.trackedAddresses(); len(addresses) > 0 && !config.LightMode {
+			balances := NewGeyserBalances()
+			collector.SetGeyserBalances(balances)
+			go balances.Watch(ctx, geyserClient, addresses, config.SlotPace)
+		}
+	}
 	
 	// Start fast metrics collection if configured
-	if config.FastMetricsInterval > 0 {
+	if config.FastMetricsInterval > 0 && !config.RpcNodeMode {
 		logger.Infof("Starting fast metrics collection with interval: %v", config.FastMetricsInterval)
 		collector.StartFastMetricsCollection(config.FastMetricsInterval)
 		
@@ -52,9 +151,72 @@ func main() {
 		defer collector.StopFastMetricsCollection()
 	}
 
-	prometheus.MustRegister(collector)
-	http.Handle("/metrics", promhttp.Handler())
+	registerer.MustRegister(collector)
 
-	logger.Infof("listening on %s", config.ListenAddress)
-	logger.Fatal(http.ListenAndServe(config.ListenAddress, nil))
+	if config.JSONLinesSink != "" {
+		sink := NewJSONLinesSink(prometheus.DefaultGatherer, config.JSONLinesSink, config.JSONLinesSinkInterval)
+		go sink.Run(ctx)
+	}
+	if config.VoteSubscription {
+		voteWatcher := NewVoteWatcher(config.VoteKeys, config.SlotPace)
+		registerer.MustRegister(voteWatcher)
+		go voteWatcher.WatchVotes(ctx, NewWSClient(config))
+	}
+	if config.HostMetrics {
+		registerer.MustRegister(NewHostCollector("/proc", "/sys", config))
+	}
+	for _, p := range config.Plugins {
+		pluginCollector, err := NewPluginCollector(p)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if err := registerer.Register(pluginCollector); err != nil {
+			logger.Fatalf("failed to register metrics of plugin %s: %v", p.Name, err)
+		}
+	}
+	if config.SlotLatencyProbeInterval > 0 {
+		// the probe measures single attempts, retries (or a tripped circuit) would mask the latency and errors it
+		// is after:
+		probeClient := *rpcClient
+		probeClient.Retry = rpc.RetryPolicy{}
+		probeClient.CircuitBreaker = nil
+		prober := NewSlotLatencyProber(&probeClient, config.SlotLatencyProbeInterval)
+		if err := prober.Register(registerer); err != nil {
+			logger.Fatalf("failed to register getSlot latency probe metrics: %v", err)
+		}
+		go prober.Run(ctx)
+	}
+	if config.StakeDelegationScanInterval > 0 {
+		scanner := NewStakeDelegationScanner(
+			NewClusterClient(rpcClient, config, registerer), config.VoteKeys, config.StakeDelegationScanInterval,
+		)
+		if err := scanner.Register(registerer); err != nil {
+			logger.Fatalf("failed to register stake delegation metrics: %v", err)
+		}
+		go scanner.Run(ctx)
+	}
+	if config.ValidatorInfoInterval > 0 {
+		go collector.validatorNames.Watch(ctx, collector.clusterClient, config.ValidatorInfoInterval)
+	}
+	if config.SecretsReloadInterval > 0 {
+		go WatchSecrets(ctx, config.SecretsReloadInterval, config.Secrets()...)
+	}
+	// metrics are served from their own mux rather than http.DefaultServeMux, which net/http/pprof registers the
+	// debug endpoints (and with them the process args) on:
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if config.AdminListenAddress != "" {
+		adminServer := NewAdminServer(config, slotWatcher, rpcClient.SchemaDrift)
+		go func() {
+			logger.Infof("admin endpoints listening on %s", config.AdminListenAddress)
+			logger.Fatal(http.ListenAndServe(config.AdminListenAddress, adminServer.Handler()))
+		}()
+	}
+
+	listener, err := NewListener(ctx, config.ListenAddress, config.ReusePort)
+	if err != nil {
+		logger.Fatalf("failed to listen: %v", err)
+	}
+	logger.Infof("listening on %s", listener.Addr())
+	logger.Fatal(http.Serve(listener, mux))
 }
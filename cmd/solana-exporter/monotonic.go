@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+// monotonicResetRatio is the fraction of the last raw value below which a decrease is taken as a reset. Smaller
+// decreases, e.g., from failing over to an endpoint slightly behind, are ignored rather than inflating the counter by
+// a whole offset.
+const monotonicResetRatio = 0.5
+
+type (
+	// MonotonicCounters converts counters derived from node state, which reset when the validator restarts, into
+	// true monotonic counters by accumulating a restart offset per counter, such that rate() works across validator
+	// restarts. If a state file is configured, the offsets are persisted so they also survive exporter restarts.
+	MonotonicCounters struct {
+		path   string
+		states map[string]*monotonicState
+		mu     sync.Mutex
+		logger *zap.SugaredLogger
+	}
+
+	monotonicState struct {
+		// Last is the last observed raw value
+		Last float64 `json:"last"`
+		// Offset is the sum of the raw values observed just before each reset
+		Offset float64 `json:"offset"`
+	}
+)
+
+// NewMonotonicCounters creates a MonotonicCounters persisted at the provided path, restoring any previous state.
+// An empty path keeps the state in memory only.
+func NewMonotonicCounters(path string) *MonotonicCounters {
+	counters := &MonotonicCounters{path: path, states: make(map[string]*monotonicState), logger: slog.Get()}
+	if path == "" {
+		return counters
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return counters
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &counters.states)
+	}
+	if err != nil {
+		counters.logger.Errorf("Failed to restore counter state from %s, starting from scratch: %v", path, err)
+		counters.states = make(map[string]*monotonicState)
+	}
+	return counters
+}
+
+// Observe records a raw value for the named counter, and returns its monotonic value. A raw value well below the last
+// one is taken as a reset, whereas slightly lower ones are ignored, holding the counter until it catches up.
+func (m *MonotonicCounters) Observe(name string, raw float64) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[name]
+	if !ok {
+		state = &monotonicState{}
+		m.states[name] = state
+	}
+	switch {
+	case raw < state.Last*monotonicResetRatio:
+		m.logger.Warnf("Counter %s reset from %v to %v, adding restart offset", name, state.Last, raw)
+		state.Offset += state.Last
+	case raw < state.Last:
+		m.logger.Debugf("Counter %s decreased from %v to %v, ignoring", name, state.Last, raw)
+		return state.Offset + state.Last
+	}
+	state.Last = raw
+	if err := m.persist(); err != nil {
+		m.logger.Errorf("Failed to persist counter state: %v", err)
+	}
+	return state.Offset + raw
+}
+
+// Value returns the current monotonic value of the named counter.
+func (m *MonotonicCounters) Value(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[name]
+	if !ok {
+		return 0
+	}
+	return state.Offset + state.Last
+}
+
+// persist atomically writes the state file, must be called with the lock held.
+func (m *MonotonicCounters) persist() error {
+	if m.path == "" {
+		return nil
+	}
+	content, err := json.Marshal(m.states)
+	if err != nil {
+		return fmt.Errorf("failed to marshal counter state: %w", err)
+	}
+	tmpPath := m.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", tmpPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonotonicCounters_Observe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counters.json")
+	counters := NewMonotonicCounters(path)
+
+	assert.Equal(t, float64(100), counters.Observe(transactionsCounterName, 100))
+	assert.Equal(t, float64(150), counters.Observe(transactionsCounterName, 150))
+	// a slight decrease (e.g., from another endpoint) is not a restart:
+	assert.Equal(t, float64(150), counters.Observe(transactionsCounterName, 140))
+	assert.Equal(t, float64(150), counters.Observe(transactionsCounterName, 150))
+	// validator restart:
+	assert.Equal(t, float64(160), counters.Observe(transactionsCounterName, 10))
+	assert.Equal(t, float64(170), counters.Observe(transactionsCounterName, 20))
+
+	// exporter restart, followed by another validator restart:
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	restored := NewMonotonicCounters(path)
+	assert.Equal(t, float64(170), restored.Value(transactionsCounterName))
+	assert.Equal(t, float64(175), restored.Observe(transactionsCounterName, 5))
+
+	// in-memory counters still handle validator restarts:
+	inMemory := NewMonotonicCounters("")
+	inMemory.Observe(transactionsCounterName, 50)
+	assert.Equal(t, float64(51), inMemory.Observe(transactionsCounterName, 1))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// ValidatorNames holds the display names of validators by nodekey and votekey, which the metrics keyed by them
+	// are labelled with such that dashboards show human-readable names rather than pubkeys. Names of the static
+	// mapping take precedence over the ones validators publish on-chain.
+	ValidatorNames struct {
+		mu     sync.RWMutex
+		static map[string]string
+		// published are the names validators published through their validator info, by nodekey and votekey
+		published map[string]string
+		logger    *zap.SugaredLogger
+	}
+
+	ValidatorNamesConfig struct {
+		Names map[string]string `yaml:"names"`
+	}
+)
+
+// LoadValidatorNames reads the static mapping of nodekeys and votekeys to validator names.
+func LoadValidatorNames(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validator names: %w", err)
+	}
+	var config ValidatorNamesConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse validator names: %w", err)
+	}
+	for key, name := range config.Names {
+		if name == "" {
+			return nil, fmt.Errorf("validator names %s has an empty name for %s", path, key)
+		}
+	}
+	return config.Names, nil
+}
+
+func NewValidatorNames(static map[string]string) *ValidatorNames {
+	return &ValidatorNames{static: static, published: make(map[string]string), logger: slog.Get()}
+}
+
+// Get returns the name of the validator of the nodekey or votekey, or an empty string if it has none.
+func (n *ValidatorNames) Get(key string) string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return cmp.Or(n.static[key], n.published[key])
+}
+
+// Refresh fetches the names validators published on-chain. Votekeys are named after their nodekey, whether the
+// nodekey is named statically or on-chain.
+func (n *ValidatorNames) Refresh(ctx context.Context, client *rpc.Client) error {
+	infos, err := client.GetValidatorInfos(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("failed to get validator infos: %w", err)
+	}
+	voteAccounts, err := client.GetVoteAccounts(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return fmt.Errorf("failed to get vote accounts: %w", err)
+	}
+
+	published := make(map[string]string)
+	for nodekey, info := range infos {
+		if info.Name != "" {
+			published[nodekey] = info.Name
+		}
+	}
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if name := cmp.Or(n.static[account.NodePubkey], published[account.NodePubkey]); name != "" {
+			published[account.VotePubkey] = name
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.published = published
+	return nil
+}
+
+// Watch refreshes the published names right away and then every interval, until the context is cancelled. The
+// previous names are kept if a refresh fails.
+func (n *ValidatorNames) Watch(ctx context.Context, client *rpc.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	n.logger.Infof("Starting validator info refreshes, running every %vs", interval.Seconds())
+	for {
+		if err := n.Refresh(ctx, client); err != nil {
+			n.logger.Errorf("Failed to refresh validator names: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			n.logger.Info("Stopping validator info refreshes")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetValidatorNames labels the metrics of the collector's keyedDescs which are keyed by a nodekey, votekey or identity
+// (i.e., not by an address) with the name of that validator.
+func (c *SolanaCollector) SetValidatorNames(names *ValidatorNames) {
+	c.validatorNames = names
+	for _, desc := range c.keyedDescs {
+		desc.SetValidatorNames(names)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadValidatorNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+names:
+  aaa: Validator A
+  AAA: Validator A
+`), 0o644))
+	names, err := LoadValidatorNames(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"aaa": "Validator A", "AAA": "Validator A"}, names)
+
+	assert.NoError(t, os.WriteFile(path, []byte("names: {aaa: ''}"), 0o644))
+	_, err = LoadValidatorNames(path)
+	assert.Error(t, err)
+}
+
+func TestValidatorNames_Refresh(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	newValidatorInfo := func(nodekey, name string) map[string]any {
+		return map[string]any{
+			"pubkey": "info-" + nodekey,
+			"account": map[string]any{
+				"owner": rpc.ConfigProgram,
+				"data": map[string]any{
+					"program": "config",
+					"parsed": map[string]any{
+						"type": "validatorInfo",
+						"info": map[string]any{
+							"keys":       []map[string]any{{"pubkey": nodekey, "signer": true}},
+							"configData": map[string]any{"name": name},
+						},
+					},
+				},
+			},
+		}
+	}
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getProgramAccounts", []map[string]any{
+		newValidatorInfo("aaa", "Published A"), newValidatorInfo("bbb", "Published B"),
+	})
+
+	// the static names take precedence, for the votekeys as well:
+	names := NewValidatorNames(map[string]string{"aaa": "Static A"})
+	assert.NoError(t, names.Refresh(context.Background(), client))
+	for key, expected := range map[string]string{
+		"aaa": "Static A", "AAA": "Static A", "bbb": "Published B", "BBB": "Published B", "ccc": "", "CCC": "",
+	} {
+		assert.Equal(t, expected, names.Get(key), key)
+	}
+}
+
+func TestSolanaCollector_SetValidatorNames(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.TenantsByKey = map[string]string{"aaa": "acme", "AAA": "acme"}
+	config.ValidatorNames = map[string]string{"aaa": "Validator A", "BBB": "Validator B"}
+	collector := NewSolanaCollector(client, config, nil)
+	prometheus.NewPedanticRegistry().MustRegister(collector)
+
+	// nodekeys take precedence over votekeys, and the name comes after the tenant:
+	expected := `
+# HELP solana_validator_active_stake Active stake (in SOL) per validator (represented by votekey and nodekey)
+# TYPE solana_validator_active_stake gauge
+solana_validator_active_stake{name="Validator A",nodekey="aaa",tenant="acme",votekey="AAA"} 0.001
+solana_validator_active_stake{name="Validator B",nodekey="bbb",tenant="",votekey="BBB"} 0.001
+solana_validator_active_stake{name="",nodekey="ccc",tenant="",votekey="CCC"} 0.001
+`
+	assert.NoError(t,
+		testutil.CollectAndCompare(collector, bytes.NewBufferString(expected), "solana_validator_active_stake"),
+	)
+	// metrics which are not keyed by a validator remain untouched:
+	assert.Equal(t, []string{VersionLabel, ClientLabel}, collector.NodeVersion.VariableLabels)
+	assert.Nil(t, collector.NodeVersion.names)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"plugin"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+)
+
+// PluginSymbol is the function a Go plugin loaded from a shared object must export, with the signature of a
+// PluginFactory: func NewCollector(options map[string]string) (prometheus.Collector, error).
+const PluginSymbol = "NewCollector"
+
+type (
+	// PluginFactory creates the collector of a plugin from the options configured for it. Plugins are chain-agnostic
+	// collectors (e.g., of internal custody systems or private telemetry), which are exported alongside the validator
+	// metrics without forking the exporter.
+	PluginFactory func(options map[string]string) (prometheus.Collector, error)
+
+	// Plugin configures a plugin: either one compiled in (see RegisterPlugin) by name, or one loaded from the Go
+	// plugin at Path.
+	Plugin struct {
+		Name    string            `yaml:"name"`
+		Path    string            `yaml:"path"`
+		Options map[string]string `yaml:"options"`
+	}
+
+	PluginsConfig struct {
+		Plugins []Plugin `yaml:"plugins"`
+	}
+)
+
+var (
+	pluginFactories   = make(map[string]PluginFactory)
+	pluginFactoriesMu sync.Mutex
+)
+
+// RegisterPlugin makes a compiled-in plugin available under the name, and is meant to be called from the init() of
+// the (site-specific) file defining the plugin. It panics if the name is already taken.
+func RegisterPlugin(name string, factory PluginFactory) {
+	pluginFactoriesMu.Lock()
+	defer pluginFactoriesMu.Unlock()
+	if _, ok := pluginFactories[name]; ok {
+		panic(fmt.Sprintf("plugin %s is already registered", name))
+	}
+	pluginFactories[name] = factory
+}
+
+// LoadPlugins reads the plugins config file.
+func LoadPlugins(path string) ([]Plugin, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins config: %w", err)
+	}
+	var config PluginsConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse plugins config: %w", err)
+	}
+
+	var names []string
+	for _, p := range config.Plugins {
+		if p.Name == "" {
+			return nil, fmt.Errorf("plugins config %s has a plugin without a name", path)
+		}
+		if slices.Contains(names, p.Name) {
+			return nil, fmt.Errorf("plugins config %s has duplicate plugin %s", path, p.Name)
+		}
+		names = append(names, p.Name)
+	}
+	return config.Plugins, nil
+}
+
+// NewPluginCollector creates the collector of the configured plugin, loading it from its Go plugin if it has a path.
+func NewPluginCollector(p Plugin) (prometheus.Collector, error) {
+	factory, err := getPluginFactory(p)
+	if err != nil {
+		return nil, err
+	}
+	collector, err := factory(p.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector of plugin %s: %w", p.Name, err)
+	}
+	return collector, nil
+}
+
+func getPluginFactory(p Plugin) (PluginFactory, error) {
+	if p.Path == "" {
+		pluginFactoriesMu.Lock()
+		defer pluginFactoriesMu.Unlock()
+		factory, ok := pluginFactories[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not compiled in, and has no path to load it from", p.Name)
+		}
+		return factory, nil
+	}
+
+	loaded, err := plugin.Open(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin %s: %w", p.Name, err)
+	}
+	symbol, err := loaded.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin %s: %w", p.Name, err)
+	}
+	// plugins cannot import package main, so the factory is exported with its underlying type:
+	factory, ok := symbol.(func(map[string]string) (prometheus.Collector, error))
+	if !ok {
+		return nil, fmt.Errorf("plugin %s exports %s as %T, not a PluginFactory", p.Name, PluginSymbol, symbol)
+	}
+	return factory, nil
+}
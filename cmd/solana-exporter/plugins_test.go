@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPlugins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugins.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+plugins:
+  - name: custody
+    options: {vault: cold}
+  - name: telemetry
+    path: /opt/plugins/telemetry.so
+`), 0o644))
+
+	plugins, err := LoadPlugins(path)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]Plugin{
+			{Name: "custody", Options: map[string]string{"vault": "cold"}},
+			{Name: "telemetry", Path: "/opt/plugins/telemetry.so"},
+		},
+		plugins,
+	)
+
+	// names must be unique:
+	assert.NoError(t, os.WriteFile(path, []byte("plugins: [{name: custody}, {name: custody}]"), 0o644))
+	_, err = LoadPlugins(path)
+	assert.Error(t, err)
+}
+
+func TestNewPluginCollector(t *testing.T) {
+	RegisterPlugin("test-custody", func(options map[string]string) (prometheus.Collector, error) {
+		if options["vault"] == "" {
+			return nil, errors.New("no vault configured")
+		}
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "custody_vault_up",
+			Help:        "Whether the vault is reachable",
+			ConstLabels: prometheus.Labels{"vault": options["vault"]},
+		})
+		gauge.Set(1)
+		return gauge, nil
+	})
+	assert.Panics(t, func() { RegisterPlugin("test-custody", nil) })
+
+	collector, err := NewPluginCollector(Plugin{Name: "test-custody", Options: map[string]string{"vault": "cold"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "custody_vault_up"))
+
+	// the factory's errors are surfaced:
+	_, err = NewPluginCollector(Plugin{Name: "test-custody"})
+	assert.Error(t, err)
+	// as are unknown plugins, and plugins which cannot be loaded:
+	_, err = NewPluginCollector(Plugin{Name: "unknown"})
+	assert.Error(t, err)
+	_, err = NewPluginCollector(Plugin{Name: "missing", Path: filepath.Join(t.TempDir(), "missing.so")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+// probeCommitments are the commitment levels the node's getSlot latency is probed at.
+var probeCommitments = []rpc.Commitment{rpc.CommitmentProcessed, rpc.CommitmentConfirmed, rpc.CommitmentFinalized}
+
+// SlotLatencyProber periodically times getSlot at each commitment against the node, as a measure of its RPC request
+// handling health: rising finalized-query latency is an early indicator of an overloaded RPC thread pool.
+type SlotLatencyProber struct {
+	client   *rpc.Client
+	interval time.Duration
+	logger   *zap.SugaredLogger
+
+	LatencyHistogram *prometheus.HistogramVec
+	ProbeErrors      *prometheus.CounterVec
+}
+
+func NewSlotLatencyProber(client *rpc.Client, interval time.Duration) *SlotLatencyProber {
+	return &SlotLatencyProber{
+		client:   client,
+		interval: interval,
+		logger:   slog.Get(),
+		LatencyHistogram: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "solana_node_get_slot_latency_seconds",
+				Help:    "Latency of getSlot probes against the node, grouped by commitment",
+				Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+			},
+			[]string{CommitmentLabel},
+		),
+		ProbeErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_node_get_slot_probe_errors_total",
+				Help: "Number of failed getSlot probes against the node, grouped by commitment",
+			},
+			[]string{CommitmentLabel},
+		),
+	}
+}
+
+// Register registers the prober metrics with the registerer.
+func (p *SlotLatencyProber) Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{p.LatencyHistogram, p.ProbeErrors} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run probes every interval until the context is cancelled.
+func (p *SlotLatencyProber) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.logger.Infof("Starting getSlot latency probes, running every %vs", p.interval.Seconds())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Probe(ctx)
+		}
+	}
+}
+
+// Probe times a single getSlot call at each commitment. Failed calls are counted rather than observed, such that
+// timeouts do not skew the latency distribution.
+func (p *SlotLatencyProber) Probe(ctx context.Context) {
+	for _, commitment := range probeCommitments {
+		start := time.Now()
+		if _, err := p.client.GetSlot(ctx, commitment); err != nil {
+			p.logger.Errorf("getSlot probe at %s commitment failed: %v", commitment, err)
+			p.ProbeErrors.WithLabelValues(string(commitment)).Inc()
+			continue
+		}
+		p.LatencyHistogram.WithLabelValues(string(commitment)).Observe(time.Since(start).Seconds())
+	}
+}
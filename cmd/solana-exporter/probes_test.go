@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotLatencyProber_Probe(t *testing.T) {
+	_, client := NewSimulator(t, 35)
+	prober := NewSlotLatencyProber(client, time.Second)
+	assert.NoError(t, prober.Register(prometheus.NewRegistry()))
+
+	prober.Probe(context.Background())
+	prober.Probe(context.Background())
+
+	for _, commitment := range probeCommitments {
+		var metric dto.Metric
+		observer := prober.LatencyHistogram.WithLabelValues(string(commitment))
+		assert.NoError(t, observer.(prometheus.Metric).Write(&metric))
+		assert.Equal(t, uint64(2), metric.GetHistogram().GetSampleCount(), commitment)
+		assert.Equal(t, float64(0), testutil.ToFloat64(prober.ProbeErrors.WithLabelValues(string(commitment))))
+	}
+
+	// failed probes are counted, not observed:
+	unreachable := NewSlotLatencyProber(rpc.NewRPCClient("http://localhost:1", time.Second, nil), time.Second)
+	unreachable.Probe(context.Background())
+	assert.Equal(t, 0, testutil.CollectAndCount(unreachable.LatencyHistogram))
+	for _, commitment := range probeCommitments {
+		assert.Equal(t, float64(1), testutil.ToFloat64(unreachable.ProbeErrors.WithLabelValues(string(commitment))))
+	}
+}
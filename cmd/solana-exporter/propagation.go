@@ -0,0 +1,42 @@
+package main
+
+import (
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+// propagationDelayBuckets span blocks confirmed within a few slots of their start up to badly delayed ones.
+var propagationDelayBuckets = []float64{0.4, 0.6, 0.8, 1, 1.2, 1.6, 2, 2.5, 3, 4, 6, 8, 12, 20}
+
+// SlotClock estimates the theoretical start time of slots, from an anchor slot with a known unix timestamp (from the
+// Clock sysvar) and the target SlotDuration. As actual slot times deviate from the target, it should be re-anchored
+// regularly, such that the estimate only ever extrapolates over a few slots.
+type SlotClock struct {
+	slot      int64
+	timestamp time.Time
+}
+
+// Anchor anchors the slot clock at the current slot of the Clock sysvar.
+func (c *SlotClock) Anchor(clock *rpc.Clock) {
+	c.slot, c.timestamp = clock.Slot, time.Unix(clock.UnixTimestamp, 0)
+}
+
+// SlotStart returns the estimated start time of the slot, and whether the clock has been anchored yet.
+func (c *SlotClock) SlotStart(slot int64) (time.Time, bool) {
+	if c.timestamp.IsZero() {
+		return time.Time{}, false
+	}
+	return c.timestamp.Add(time.Duration(slot-c.slot) * SlotDuration), true
+}
+
+// GetPropagationDelay returns the time between the start of a slot and its block first being observed confirmed at
+// received, which approximates how long the block took to propagate and gather votes. It is clamped to zero, as the
+// (second resolution) clock anchor can place the start of the slot after the block was received.
+func GetPropagationDelay(clock *SlotClock, slot int64, received time.Time) (float64, bool) {
+	start, ok := clock.SlotStart(slot)
+	if !ok {
+		return 0, false
+	}
+	return max(0, received.Sub(start).Seconds()), true
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotClock_SlotStart(t *testing.T) {
+	var clock SlotClock
+	_, ok := clock.SlotStart(100)
+	assert.False(t, ok)
+
+	anchor := time.Unix(1_700_000_000, 0)
+	clock.Anchor(&rpc.Clock{Slot: 100, UnixTimestamp: anchor.Unix()})
+	start, ok := clock.SlotStart(110)
+	assert.True(t, ok)
+	assert.Equal(t, anchor.Add(4*time.Second), start)
+	// slots before the anchor are estimated backwards:
+	start, _ = clock.SlotStart(95)
+	assert.Equal(t, anchor.Add(-2*time.Second), start)
+}
+
+func TestGetPropagationDelay(t *testing.T) {
+	var clock SlotClock
+	anchor := time.Unix(1_700_000_000, 0)
+	_, ok := GetPropagationDelay(&clock, 110, anchor)
+	assert.False(t, ok)
+
+	clock.Anchor(&rpc.Clock{Slot: 100, UnixTimestamp: anchor.Unix()})
+	delay, ok := GetPropagationDelay(&clock, 110, anchor.Add(5200*time.Millisecond))
+	assert.True(t, ok)
+	assert.InDelta(t, 1.2, delay, 1e-9)
+	// blocks received before their estimated start are clamped:
+	delay, _ = GetPropagationDelay(&clock, 110, anchor.Add(3*time.Second))
+	assert.Equal(t, 0.0, delay)
+}
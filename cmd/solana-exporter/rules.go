@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateRulesCommand is the subcommand which writes Prometheus rules matching the exporter configuration.
+const GenerateRulesCommand = "generate-rules"
+
+type (
+	// RulesConfig is the subset of the exporter configuration which determines the generated rules.
+	RulesConfig struct {
+		NodeKeys          []string
+		ValidatorIdentity string
+		BalanceAddresses  []string
+		LightMode         bool
+		MinBalance        float64
+		MaxSkipRate       float64
+		MaxVoteDistance   int
+		For               string
+	}
+
+	RuleFile struct {
+		Groups []RuleGroup `yaml:"groups"`
+	}
+
+	RuleGroup struct {
+		Name     string `yaml:"name"`
+		Interval string `yaml:"interval,omitempty"`
+		Rules    []Rule `yaml:"rules"`
+	}
+
+	Rule struct {
+		Record      string            `yaml:"record,omitempty"`
+		Alert       string            `yaml:"alert,omitempty"`
+		Expr        string            `yaml:"expr"`
+		For         string            `yaml:"for,omitempty"`
+		Labels      map[string]string `yaml:"labels,omitempty"`
+		Annotations map[string]string `yaml:"annotations,omitempty"`
+	}
+)
+
+// GenerateRules produces recording and alerting rules for skip rate, delinquency, balances and vote distance, using
+// the metric names and labels the exporter emits for the provided configuration. Rules for metrics which are not
+// emitted by the configuration are left out.
+func GenerateRules(config RulesConfig) RuleFile {
+	recording := RuleGroup{Name: "solana_exporter_recording_rules", Interval: "30s"}
+	alerting := RuleGroup{Name: "solana_exporter_alerting_rules"}
+	warning := map[string]string{"severity": "warning"}
+	critical := map[string]string{"severity": "critical"}
+
+	recording.Rules = append(recording.Rules, Rule{
+		Record: "solana:cluster_epoch_skip_rate",
+		Expr: fmt.Sprintf(
+			`sum by (epoch) (solana_cluster_slots_by_epoch_total{%s="%s"}) / sum by (epoch) (solana_cluster_slots_by_epoch_total)`,
+			SkipStatusLabel, StatusSkipped,
+		),
+	})
+
+	// validator metrics:
+	nodekeys := CombineUnique(config.NodeKeys)
+	if config.ValidatorIdentity != "" {
+		nodekeys = CombineUnique(nodekeys, []string{config.ValidatorIdentity})
+	}
+	if config.ValidatorIdentity != "" && !config.LightMode {
+		recording.Rules = append(recording.Rules, Rule{
+			Record: "solana:validator_epoch_skip_rate",
+			Expr: "solana_validator_leader_slots_skipped_epoch / " +
+				"(solana_validator_leader_slots_processed_epoch + solana_validator_leader_slots_skipped_epoch)",
+		})
+		alerting.Rules = append(alerting.Rules, Rule{
+			Alert:       "SolanaValidatorHighSkipRate",
+			Expr:        fmt.Sprintf("solana:validator_epoch_skip_rate > %v", config.MaxSkipRate),
+			For:         config.For,
+			Labels:      warning,
+			Annotations: map[string]string{"summary": "Validator skip rate is above " + toString(config.MaxSkipRate)},
+		})
+	}
+	if len(nodekeys) > 0 {
+		alerting.Rules = append(alerting.Rules, Rule{
+			Alert:       "SolanaValidatorDelinquent",
+			Expr:        fmt.Sprintf(`solana_validator_delinquent{%s} == 1`, labelMatcher(NodekeyLabel, nodekeys)),
+			For:         config.For,
+			Labels:      critical,
+			Annotations: map[string]string{"summary": "Validator {{ $labels.nodekey }} is delinquent"},
+		})
+	}
+	if config.ValidatorIdentity != "" {
+		alerting.Rules = append(alerting.Rules, Rule{
+			Alert: "SolanaValidatorVoteDistance",
+			Expr: fmt.Sprintf(
+				`solana_validator_vote_distance{%s} > %d`,
+				labelMatcher(IdentityLabel, []string{config.ValidatorIdentity}), config.MaxVoteDistance,
+			),
+			For:    config.For,
+			Labels: warning,
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("Validator {{ $labels.identity }} is over %d slots behind", config.MaxVoteDistance),
+			},
+		})
+	}
+
+	// balances are not collected in light mode:
+	addresses := CombineUnique(config.BalanceAddresses, nodekeys)
+	if len(addresses) > 0 && !config.LightMode {
+		alerting.Rules = append(alerting.Rules, Rule{
+			Alert: "SolanaAccountLowBalance",
+			Expr: fmt.Sprintf(
+				`solana_account_balance{%s} < %v`, labelMatcher(AddressLabel, addresses), config.MinBalance,
+			),
+			For:    config.For,
+			Labels: warning,
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("Account {{ $labels.address }} balance is below %v SOL", config.MinBalance),
+			},
+		})
+	}
+
+	groups := []RuleGroup{recording}
+	if len(alerting.Rules) > 0 {
+		groups = append(groups, alerting)
+	}
+	return RuleFile{Groups: groups}
+}
+
+// WriteRules writes the rule file as YAML.
+func WriteRules(writer io.Writer, rules RuleFile) error {
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(rules); err != nil {
+		return fmt.Errorf("failed to encode rules: %w", err)
+	}
+	return encoder.Close()
+}
+
+// RunGenerateRules parses the generate-rules subcommand args and writes the rules.
+func RunGenerateRules(args []string) error {
+	var (
+		config     RulesConfig
+		nodekeys   arrayFlags
+		addresses  arrayFlags
+		outputPath string
+	)
+	flags := flag.NewFlagSet(GenerateRulesCommand, flag.ExitOnError)
+	flags.Var(&nodekeys, "nodekey", "Solana nodekey (identity account) representing validator to alert on - can set multiple.")
+	flags.Var(&addresses, "balance-address", "Address to alert on a low balance for - can set multiple.")
+	flags.StringVar(&config.ValidatorIdentity, "validator-identity", "", "Validator identity public key.")
+	flags.BoolVar(&config.LightMode, "light-mode", false, "Whether the exporter runs in light mode.")
+	flags.Float64Var(&config.MinBalance, "min-balance", 1, "Balance (in SOL) below which to alert.")
+	flags.Float64Var(&config.MaxSkipRate, "max-skip-rate", 0.1, "Epoch skip rate (0-1) above which to alert.")
+	flags.IntVar(&config.MaxVoteDistance, "max-vote-distance", 150, "Vote distance (in slots) above which to alert.")
+	flags.StringVar(&config.For, "for", "5m", "Duration a condition must hold before alerting.")
+	flags.StringVar(&outputPath, "output", "", "File to write the rules to, defaults to stdout.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	config.NodeKeys, config.BalanceAddresses = nodekeys, addresses
+
+	writer := io.Writer(os.Stdout)
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", outputPath, err)
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer file.Close()
+		writer = file
+	}
+	return WriteRules(writer, GenerateRules(config))
+}
+
+// labelMatcher builds an exact regex label matcher for the provided values.
+func labelMatcher(label string, values []string) string {
+	return fmt.Sprintf(`%s=~"%s"`, label, strings.Join(values, "|"))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func ruleNames(rules RuleFile) []string {
+	var names []string
+	for _, group := range rules.Groups {
+		for _, rule := range group.Rules {
+			names = append(names, rule.Record+rule.Alert)
+		}
+	}
+	return names
+}
+
+func TestGenerateRules(t *testing.T) {
+	rules := GenerateRules(RulesConfig{
+		NodeKeys:          []string{"aaa", "bbb"},
+		ValidatorIdentity: "aaa",
+		BalanceAddresses:  []string{"ccc"},
+		MinBalance:        1,
+		MaxSkipRate:       0.1,
+		MaxVoteDistance:   150,
+		For:               "5m",
+	})
+	assert.Equal(t,
+		[]string{
+			"solana:cluster_epoch_skip_rate",
+			"solana:validator_epoch_skip_rate",
+			"SolanaValidatorHighSkipRate",
+			"SolanaValidatorDelinquent",
+			"SolanaValidatorVoteDistance",
+			"SolanaAccountLowBalance",
+		},
+		ruleNames(rules),
+	)
+	assert.Equal(t, `solana_validator_delinquent{nodekey=~"aaa|bbb"} == 1`, rules.Groups[1].Rules[1].Expr)
+	assert.Equal(t, `solana_account_balance{address=~"ccc|aaa|bbb"} < 1`, rules.Groups[1].Rules[3].Expr)
+
+	// light mode without a validator only gets the cluster rules:
+	rules = GenerateRules(RulesConfig{LightMode: true})
+	assert.Equal(t, []string{"solana:cluster_epoch_skip_rate"}, ruleNames(rules))
+
+	// make sure the output round-trips:
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteRules(&buffer, rules))
+	var decoded RuleFile
+	assert.NoError(t, yaml.Unmarshal(buffer.Bytes(), &decoded))
+	assert.Equal(t, rules, decoded)
+}
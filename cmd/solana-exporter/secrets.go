@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+)
+
+const (
+	SecretFilePrefix = "file:"
+	SecretEnvPrefix  = "env:"
+)
+
+type (
+	// Secret is a sensitive config value (e.g., an auth token or webhook URL), which can be provided as a literal, or
+	// as a reference to a file ("file:/path/to/secret") or an environment variable ("env:NAME"), such that it never
+	// has to appear in process args. File and env secrets can be reloaded at runtime. A nil *Secret has an empty value.
+	Secret struct {
+		ref   string
+		value string
+		mu    sync.RWMutex
+	}
+
+	// HeaderSecret is an RPC header whose value (after the prefix, e.g., "Bearer ") is a secret.
+	HeaderSecret struct {
+		Name   string
+		Prefix string
+		Secret *Secret
+	}
+
+	// SecretTransport applies the current values of the RPC url and header secrets to every request, such that rotated
+	// API keys are picked up without rebuilding the clients, which keep addressing the urls resolved at startup.
+	SecretTransport struct {
+		Base http.RoundTripper
+		// urls are the url secrets by their value at startup
+		urls    map[string]*Secret
+		headers []HeaderSecret
+	}
+)
+
+// NewSecret resolves the provided reference into a Secret. Secret files accessible by group or others are only warned
+// about, as e.g. Kubernetes projected volumes are world-readable unless their defaultMode is set.
+func NewSecret(ref string) (*Secret, error) {
+	value, err := ResolveSecret(ref)
+	if err != nil {
+		return nil, err
+	}
+	if path, ok := strings.CutPrefix(ref, SecretFilePrefix); ok {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+			slog.Get().Warnf(
+				"Secret file %s has permissions %v, it should not be accessible by group or others",
+				path, info.Mode().Perm(),
+			)
+		}
+	}
+	return &Secret{ref: ref, value: value}, nil
+}
+
+// NewStaticSecret creates a Secret holding a literal value.
+func NewStaticSecret(value string) *Secret {
+	return &Secret{ref: value, value: value}
+}
+
+// ResolveSecret resolves a secret reference into its value.
+func ResolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, SecretFilePrefix):
+		path := strings.TrimPrefix(ref, SecretFilePrefix)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file %s: %w", path, err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	case strings.HasPrefix(ref, SecretEnvPrefix):
+		name := strings.TrimPrefix(ref, SecretEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret environment variable %s is not set", name)
+		}
+		return strings.TrimSpace(value), nil
+	default:
+		return ref, nil
+	}
+}
+
+// Value returns the current value of the secret.
+func (s *Secret) Value() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.value
+}
+
+// IsReloadable returns whether the secret references a file or environment variable.
+func (s *Secret) IsReloadable() bool {
+	return s != nil && (strings.HasPrefix(s.ref, SecretFilePrefix) || strings.HasPrefix(s.ref, SecretEnvPrefix))
+}
+
+// Reload re-resolves the secret, returning whether its value changed. On failure, the previous value is kept.
+func (s *Secret) Reload() (bool, error) {
+	if !s.IsReloadable() {
+		return false, nil
+	}
+	value, err := ResolveSecret(s.ref)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := value != s.value
+	s.value = value
+	return changed, nil
+}
+
+// Secrets returns all secrets of the config, i.e., the ones which are reloaded by the admin endpoint and on the
+// reload interval.
+func (c *ExporterConfig) Secrets() []*Secret {
+	return append([]*Secret{c.GrafanaUrl, c.GrafanaApiToken, c.GeyserToken}, c.RpcSecrets...)
+}
+
+// HeaderValues returns the headers with the current values of their secrets, or nil if there are none.
+func HeaderValues(headers []HeaderSecret) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	values := make(http.Header)
+	for _, header := range headers {
+		values.Add(header.Name, header.Prefix+header.Secret.Value())
+	}
+	return values
+}
+
+// NewSecretTransport wraps the base transport (or the default one if nil), such that requests to the urls are sent
+// to their current values, along with the current values of the headers.
+func NewSecretTransport(base http.RoundTripper, urls []*Secret, headers []HeaderSecret) *SecretTransport {
+	transport := &SecretTransport{Base: base, urls: make(map[string]*Secret), headers: headers}
+	for _, secret := range urls {
+		// keyed as the requests' urls will be formatted:
+		if parsed, err := url.Parse(secret.Value()); err == nil && secret.Value() != "" {
+			transport.urls[parsed.String()] = secret
+		}
+	}
+	return transport
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *SecretTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if secret, ok := t.urls[req.URL.String()]; ok {
+		current, err := url.Parse(secret.Value())
+		if err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			// the error is not wrapped, as it includes the url:
+			return nil, fmt.Errorf("failed to parse the reloaded rpc url")
+		}
+		req.URL, req.Host = current, current.Host
+	}
+	for name, values := range HeaderValues(t.headers) {
+		req.Header[name] = values
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// ReloadSecrets reloads all the provided secrets, returning the joined errors of the ones which failed.
+func ReloadSecrets(secrets ...*Secret) error {
+	logger := slog.Get()
+	var errs []error
+	for _, secret := range secrets {
+		changed, err := secret.Reload()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if changed {
+			logger.Infof("Reloaded secret from %s", secret.ref)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WatchSecrets reloads the provided secrets every interval until the context is cancelled, such that rotated
+// tokens are picked up without a restart.
+func WatchSecrets(ctx context.Context, interval time.Duration, secrets ...*Secret) {
+	logger := slog.Get()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ReloadSecrets(secrets...); err != nil {
+				logger.Errorf("Failed to reload secrets, keeping previous values: %v", err)
+			}
+		}
+	}
+}
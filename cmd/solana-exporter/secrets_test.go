@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("file-token\n"), 0o600))
+	t.Setenv("SOLANA_EXPORTER_TEST_TOKEN", "env-token")
+
+	value, err := ResolveSecret("literal-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "literal-token", value)
+
+	value, err = ResolveSecret(SecretFilePrefix + path)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-token", value)
+
+	value, err = ResolveSecret(SecretEnvPrefix + "SOLANA_EXPORTER_TEST_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", value)
+
+	_, err = ResolveSecret(SecretEnvPrefix + "SOLANA_EXPORTER_TEST_UNSET")
+	assert.Error(t, err)
+
+	// world-readable secret files (e.g., in projected volumes) are only warned about:
+	assert.NoError(t, os.Chmod(path, 0o644))
+	secret, err := NewSecret(SecretFilePrefix + path)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-token", secret.Value())
+}
+
+func TestSecret_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	secret, err := NewSecret(SecretFilePrefix + path)
+	assert.NoError(t, err)
+	assert.True(t, secret.IsReloadable())
+	assert.Equal(t, "old", secret.Value())
+
+	// rotate the secret:
+	assert.NoError(t, os.WriteFile(path, []byte("new"), 0o600))
+	changed, err := secret.Reload()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "new", secret.Value())
+
+	// a failed reload keeps the previous value:
+	assert.NoError(t, os.Remove(path))
+	_, err = secret.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "new", secret.Value())
+
+	var unset *Secret
+	assert.Equal(t, "", unset.Value())
+	assert.False(t, NewStaticSecret("literal").IsReloadable())
+}
+
+func TestSecretTransport(t *testing.T) {
+	received := make(chan string, 2)
+	newServer := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			received <- name + " " + r.URL.Query().Get("api-key") + " " + r.Header.Get("Authorization")
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	oldServer, rotatedServer := newServer("old"), newServer("new")
+
+	dir := t.TempDir()
+	urlPath, tokenPath := filepath.Join(dir, "url"), filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(urlPath, []byte(oldServer.URL+"/?api-key=a"), 0o600))
+	assert.NoError(t, os.WriteFile(tokenPath, []byte("a"), 0o600))
+	urlSecret, err := NewSecret(SecretFilePrefix + urlPath)
+	assert.NoError(t, err)
+	headers, err := ParseRpcHeaders(nil, SecretFilePrefix+tokenPath)
+	assert.NoError(t, err)
+
+	// the client keeps addressing the url resolved at startup:
+	startupUrl := urlSecret.Value()
+	client := &http.Client{Transport: NewSecretTransport(nil, []*Secret{urlSecret}, headers)}
+	send := func() {
+		resp, err := client.Get(startupUrl)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Body.Close())
+	}
+	send()
+	assert.Equal(t, "old a Bearer a", <-received)
+
+	// rotate both secrets:
+	assert.NoError(t, os.WriteFile(urlPath, []byte(rotatedServer.URL+"/?api-key=b"), 0o600))
+	assert.NoError(t, os.WriteFile(tokenPath, []byte("b"), 0o600))
+	assert.NoError(t, ReloadSecrets(append([]*Secret{urlSecret}, headers[0].Secret)...))
+	send()
+	assert.Equal(t, "new b Bearer b", <-received)
+}
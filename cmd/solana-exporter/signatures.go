@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+type (
+	// SignatureTracker tracks the confirmation of transactions by their signature. Once a transaction is finalized its
+	// status can no longer change, so it is no longer looked up.
+	SignatureTracker struct {
+		finalized map[string]SignatureStatus
+		mu        sync.Mutex
+	}
+
+	// SignatureStatus is the status of a tracked transaction.
+	SignatureStatus struct {
+		// Commitment is the highest commitment the transaction is confirmed at, or empty if it is not confirmed (yet)
+		Commitment rpc.Commitment
+		Slot       int64
+		// Failed is whether the transaction landed but failed to execute
+		Failed bool
+	}
+)
+
+func NewSignatureTracker() *SignatureTracker {
+	return &SignatureTracker{finalized: make(map[string]SignatureStatus)}
+}
+
+// Status returns the status of the transaction of the signature. A confirmed transaction is taken as finalized once
+// its slot is at or below the finalized slot.
+func (t *SignatureTracker) Status(
+	ctx context.Context, client *rpc.Client, signature string, finalizedSlot int64,
+) (SignatureStatus, error) {
+	t.mu.Lock()
+	status, ok := t.finalized[signature]
+	t.mu.Unlock()
+	if ok {
+		return status, nil
+	}
+
+	transaction, err := client.GetTransaction(ctx, rpc.CommitmentConfirmed, signature)
+	if err != nil || transaction == nil {
+		return SignatureStatus{}, err
+	}
+	status = SignatureStatus{
+		Commitment: rpc.CommitmentConfirmed,
+		Slot:       transaction.Slot,
+		Failed:     transaction.Meta != nil && transaction.Meta.Err != nil,
+	}
+	if transaction.Slot <= finalizedSlot {
+		status.Commitment = rpc.CommitmentFinalized
+		t.mu.Lock()
+		t.finalized[signature] = status
+		t.mu.Unlock()
+	}
+	return status, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureTracker_Status(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	tracker := NewSignatureTracker()
+	ctx := context.Background()
+
+	// not (yet) confirmed:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTransaction", nil)
+	status, err := tracker.Status(ctx, client, "sig", 25)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureStatus{}, status)
+
+	// confirmed above the finalized slot:
+	simulator.Server.SetOpt(
+		rpc.EasyResultsOpt, "getTransaction", map[string]any{"slot": 30, "meta": map[string]any{"err": nil}},
+	)
+	status, err = tracker.Status(ctx, client, "sig", 25)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureStatus{Commitment: rpc.CommitmentConfirmed, Slot: 30}, status)
+
+	status, err = tracker.Status(ctx, client, "sig", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureStatus{Commitment: rpc.CommitmentFinalized, Slot: 30}, status)
+
+	// finalized transactions are no longer looked up:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getTransaction", nil)
+	status, err = tracker.Status(ctx, client, "sig", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureStatus{Commitment: rpc.CommitmentFinalized, Slot: 30}, status)
+
+	simulator.Server.SetOpt(
+		rpc.EasyResultsOpt,
+		"getTransaction",
+		map[string]any{"slot": 32, "meta": map[string]any{"err": map[string]any{"InstructionError": []any{0, "Custom"}}}},
+	)
+	status, err = tracker.Status(ctx, client, "failed", 30)
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureStatus{Commitment: rpc.CommitmentConfirmed, Slot: 32, Failed: true}, status)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+type (
+	// JSONLinesSink periodically gathers metrics and writes each sample as a single JSON line, for consumers
+	// (e.g., Vector or Fluent Bit pipelines) which do not scrape Prometheus.
+	JSONLinesSink struct {
+		gatherer prometheus.Gatherer
+		target   string
+		interval time.Duration
+		writer   io.WriteCloser
+		logger   *zap.SugaredLogger
+	}
+
+	// JSONSample is the JSON representation of a single metric sample.
+	JSONSample struct {
+		Timestamp int64             `json:"timestamp"`
+		Name      string            `json:"name"`
+		Labels    map[string]string `json:"labels,omitempty"`
+		Value     float64           `json:"value"`
+	}
+)
+
+// NewJSONLinesSink creates a sink writing samples from the gatherer to the provided target, which is one of
+// "stdout", "tcp://<host>:<port>" or "unix://<path>".
+func NewJSONLinesSink(gatherer prometheus.Gatherer, target string, interval time.Duration) *JSONLinesSink {
+	return &JSONLinesSink{gatherer: gatherer, target: target, interval: interval, logger: slog.Get()}
+}
+
+// OpenJSONLinesTarget opens a writer for a JSON lines sink target.
+func OpenJSONLinesTarget(target string) (io.WriteCloser, error) {
+	switch {
+	case target == "stdout":
+		// never close stdout, even if the sink is done with it:
+		return nopWriteCloser{os.Stdout}, nil
+	case strings.HasPrefix(target, "tcp://"):
+		return net.Dial("tcp", strings.TrimPrefix(target, "tcp://"))
+	case strings.HasPrefix(target, "unix://"):
+		return net.Dial("unix", strings.TrimPrefix(target, "unix://"))
+	default:
+		return nil, fmt.Errorf("unsupported json lines sink target %q, must be 'stdout', 'tcp://' or 'unix://'", target)
+	}
+}
+
+// Run writes samples every interval until the context is cancelled.
+func (s *JSONLinesSink) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer s.close()
+
+	s.logger.Infof("Starting json lines sink to %s, running every %vs", s.target, s.interval.Seconds())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if s.writer == nil {
+				writer, err := OpenJSONLinesTarget(s.target)
+				if err != nil {
+					s.logger.Errorf("Failed to open json lines sink %s: %v", s.target, err)
+					continue
+				}
+				s.writer = writer
+			}
+			if err := s.WriteSamples(s.writer, now); err != nil {
+				s.logger.Errorf("Failed to write json lines samples, reopening sink: %v", err)
+				s.close()
+			}
+		}
+	}
+}
+
+func (s *JSONLinesSink) close() {
+	if s.writer != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+}
+
+// WriteSamples gathers all metrics and writes them to the writer as JSON lines, stamped with the provided time.
+func (s *JSONLinesSink) WriteSamples(writer io.Writer, now time.Time) error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		// gathering can partially fail (e.g., invalid metrics), we still write whatever we got:
+		s.logger.Warnf("Gathering metrics for json lines sink failed partially: %v", err)
+	}
+
+	encoder := json.NewEncoder(writer)
+	for _, family := range families {
+		for _, sample := range FlattenMetricFamily(family, now) {
+			if err := encoder.Encode(sample); err != nil {
+				return fmt.Errorf("failed to write %s sample: %w", sample.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FlattenMetricFamily converts a gathered metric family into individual samples. Histograms and summaries are
+// flattened into their _sum and _count samples.
+func FlattenMetricFamily(family *dto.MetricFamily, now time.Time) []JSONSample {
+	var samples []JSONSample
+	for _, metric := range family.GetMetric() {
+		labels := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		newSample := func(suffix string, value float64) JSONSample {
+			return JSONSample{Timestamp: now.UnixMilli(), Name: family.GetName() + suffix, Labels: labels, Value: value}
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			samples = append(samples, newSample("", metric.GetGauge().GetValue()))
+		case dto.MetricType_COUNTER:
+			samples = append(samples, newSample("", metric.GetCounter().GetValue()))
+		case dto.MetricType_UNTYPED:
+			samples = append(samples, newSample("", metric.GetUntyped().GetValue()))
+		case dto.MetricType_HISTOGRAM:
+			samples = append(samples,
+				newSample("_sum", metric.GetHistogram().GetSampleSum()),
+				newSample("_count", float64(metric.GetHistogram().GetSampleCount())),
+			)
+		case dto.MetricType_SUMMARY:
+			samples = append(samples,
+				newSample("_sum", metric.GetSummary().GetSampleSum()),
+				newSample("_count", float64(metric.GetSummary().GetSampleCount())),
+			)
+		}
+	}
+	return samples
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLinesSink_WriteSamples(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"}, []string{NodekeyLabel})
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter_total", Help: "test"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_histogram", Help: "test"})
+	registry.MustRegister(gauge, counter, histogram)
+
+	gauge.WithLabelValues("aaa").Set(3)
+	counter.Add(5)
+	histogram.Observe(2)
+	histogram.Observe(4)
+
+	now := time.UnixMilli(1_700_000_000_000)
+	var buffer bytes.Buffer
+	sink := NewJSONLinesSink(registry, "stdout", time.Second)
+	assert.NoError(t, sink.WriteSamples(&buffer, now))
+
+	var samples []JSONSample
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		var sample JSONSample
+		assert.NoError(t, json.Unmarshal([]byte(line), &sample))
+		samples = append(samples, sample)
+	}
+	assert.Equal(t,
+		[]JSONSample{
+			{Timestamp: now.UnixMilli(), Name: "test_counter_total", Labels: nil, Value: 5},
+			{Timestamp: now.UnixMilli(), Name: "test_gauge", Labels: map[string]string{NodekeyLabel: "aaa"}, Value: 3},
+			{Timestamp: now.UnixMilli(), Name: "test_histogram_sum", Labels: nil, Value: 6},
+			{Timestamp: now.UnixMilli(), Name: "test_histogram_count", Labels: nil, Value: 2},
+		},
+		samples,
+	)
+}
+
+func TestOpenJSONLinesTarget(t *testing.T) {
+	writer, err := OpenJSONLinesTarget("stdout")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	_, err = OpenJSONLinesTarget("udp://localhost:9000")
+	assert.Error(t, err)
+}
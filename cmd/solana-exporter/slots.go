@@ -2,20 +2,78 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"github.com/seedfourtytwo/solana-exporter/pkg/geyser"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"github.com/seedfourtytwo/solana-exporter/pkg/solana"
 	"go.uber.org/zap"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	// transactionsCounterName is the MonotonicCounters name of the node transaction count
+	transactionsCounterName = "transactions"
+	// maxBlockFetchRetries is how many times a leader block which is not available yet is retried before giving up
+	maxBlockFetchRetries = 5
+	// subscriptionStalePaces is the number of SlotPaces without an update after which a subscription feeding the slot
+	// height is taken as stale, and the slot height is polled again
+	subscriptionStalePaces = 3
+)
+
+// stakeConcentrationPercents are the percentages of the largest validators the stake share of is exported
+var stakeConcentrationPercents = []int{10, 20, 33}
+
+// nodekeyBlockNotification is a block notification of the subscription for a tracked nodekey
+type nodekeyBlockNotification struct {
+	nodekey string
+	rpc.BlockNotification
+	// received is when the notification was received, i.e., when the block was first observed confirmed
+	received time.Time
+}
+
+// subscriptionFeed tracks when a subscription last fed the slot height, such that polling resumes once it goes stale,
+// even if its connection never errors (e.g., when half-open).
+type subscriptionFeed struct {
+	// last is the unix nano time of the last update, or zero when unsubscribed
+	last atomic.Int64
+}
+
+func (f *subscriptionFeed) Observe(now time.Time) {
+	f.last.Store(now.UnixNano())
+}
+
+func (f *subscriptionFeed) Reset() {
+	f.last.Store(0)
+}
+
+// Live returns whether the subscription was updated within the staleness at now.
+func (f *subscriptionFeed) Live(now time.Time, staleness time.Duration) bool {
+	last := f.last.Load()
+	return last != 0 && now.Sub(time.Unix(0, last)) < staleness
+}
+
+// pendingBlock is a leader block of a tracked nodekey which was not available yet when fetched
+type pendingBlock struct {
+	nodekey  string
+	epoch    int64
+	attempts int
+}
+
 type SlotWatcher struct {
 	client *rpc.Client
+	// clusterClient serves the cluster-wide calls, which is the reference RPC if configured
+	clusterClient *rpc.Client
 	logger *zap.SugaredLogger
 
 	config *ExporterConfig
@@ -30,52 +88,126 @@ type SlotWatcher struct {
 	slotWatermark int64
 
 	leaderSchedule map[string][]int64
+	// slotLeaders is the leader of every slot of the current epoch, only fetched when sampling vote inclusion
+	slotLeaders map[int64]string
 
 	// for tracking which metrics we have and deleting them accordingly:
 	nodekeyTracker *EpochTrackedValidators
 
+	annotator *GrafanaAnnotator
+
+	// for converting node counters which reset on validator restarts into monotonic counters:
+	monotonicCounters *MonotonicCounters
+
+	// for estimating the time between slots being confirmed and finalized:
+	finalityTracker *FinalityTracker
+
+	// leader blocks received through block subscriptions, which are handled on the WatchSlots goroutine, and the
+	// slots emitted from them, such that polling does not fetch them again:
+	blockNotifications chan nodekeyBlockNotification
+	subscribedBlocks   map[int64]struct{}
+	// slotClock estimates the start of the subscribed blocks' slots, for their propagation delay
+	slotClock SlotClock
+
+	// leader blocks which were not available yet when fetched, to retry on the next slot watermark moves:
+	pendingBlocks map[int64]*pendingBlock
+
+	// the subscriptions feeding the finalized slot height instead of polling, while either is live:
+	slotSubscription   subscriptionFeed
+	geyserSubscription subscriptionFeed
+
 	// prometheus:
 	TotalTransactionsMetric   prometheus.Gauge
-	SlotHeightMetric          prometheus.Gauge
-	EpochNumberMetric         prometheus.Gauge
+	SlotHeightMetric          *prometheus.GaugeVec
+	EpochNumberMetric         *prometheus.GaugeVec
 	EpochFirstSlotMetric      prometheus.Gauge
 	EpochLastSlotMetric       prometheus.Gauge
 	ClusterSlotsByEpochMetric *prometheus.CounterVec
 	InflationRewardsMetric    *prometheus.CounterVec
 	FeeRewardsMetric          *prometheus.CounterVec
 	BlockSizeMetric           *prometheus.GaugeVec
+	PriorityFeeMetric         *prometheus.GaugeVec
+	MevTipsMetric             *prometheus.CounterVec
+	VoteInclusionSampledBlocksMetric *prometheus.CounterVec
+	VotesIncludedMetric              *prometheus.CounterVec
+	BlockFetchesMetric               *prometheus.CounterVec
+	BlockPropagationDelayMetric      *prometheus.HistogramVec
 	BlockHeightMetric         prometheus.Gauge
 	AssignedLeaderSlotsGauge  prometheus.Gauge
 
+	BlockProductionMismatchMetric prometheus.Counter
+	TransactionsMonotonicMetric   prometheus.CounterFunc
+
 	// New per-epoch gauges
 	LeaderSlotsProcessedEpochGauge prometheus.Gauge
 	LeaderSlotsSkippedEpochGauge prometheus.Gauge
+	SkipRateGauge prometheus.Gauge
+	ClusterSkipRateGauge prometheus.Gauge
+	RelativeSkipRateGauge prometheus.Gauge
+	LeaderSlotsByPositionEpochGauge *prometheus.GaugeVec
+	SkipStreakGauge prometheus.Gauge
+	MaxSkipStreakEpochGauge prometheus.Gauge
+	FinalizationLatencyMetric prometheus.Gauge
+	TopStakeShareGauge        *prometheus.GaugeVec
+	NakamotoCoefficientGauge  prometheus.Gauge
+	SuperminoritySizeGauge    prometheus.Gauge
+	InSuperminorityGauge      *prometheus.GaugeVec
+	StakeChangeGauge          *prometheus.GaugeVec
+
+	// nodekeyStakes are the activated stakes of the current epoch by nodekey, for weighting the cluster skip rate
+	nodekeyStakes map[string]int64
+	// votekeyStakes are the activated stakes of the tracked votekeys as of the start of votekeyStakesEpoch, for the
+	// stake change of the next one
+	votekeyStakes      map[string]int64
+	votekeyStakesEpoch int64
 
 	processedLeaderSlots map[int64]struct{}
 	skippedLeaderSlots map[int64]struct{}
 	emittedInflationRewards map[string]struct{} // key: votekey-epoch
+	// inflationRewardsMu guards emittedInflationRewards, which the epoch close-over and the poller both emit through
+	inflationRewardsMu sync.Mutex
+
+	// priorityFeeEncoder writes the raw priority fee records of produced blocks, if -priority-fee-output is set
+	priorityFeeEncoder *json.Encoder
+	// snapshotter snapshots the validator set of each epoch tracked, if -validator-set-snapshot-dir is set
+	snapshotter *ValidatorSetSnapshotter
 }
 
-func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
+// NewSlotWatcher creates a slot watcher, whose metrics (and those of its RPC clients) are registered with the
+// registerer, unless it is nil.
+func NewSlotWatcher(client *rpc.Client, config *ExporterConfig, registerer prometheus.Registerer) *SlotWatcher {
 	logger := slog.Get()
 	watcher := SlotWatcher{
 		client:         client,
+		clusterClient:  NewClusterClient(client, config, registerer),
 		logger:         logger,
 		config:         config,
 		nodekeyTracker: NewEpochTrackedValidators(),
+		annotator:      NewGrafanaAnnotator(config),
+		monotonicCounters: NewMonotonicCounters(config.CounterStateFile),
+		finalityTracker: NewFinalityTracker(),
+		blockNotifications: make(chan nodekeyBlockNotification, 64),
+		subscribedBlocks: make(map[int64]struct{}),
+		pendingBlocks:    make(map[int64]*pendingBlock),
 		// metrics:
 		TotalTransactionsMetric: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "solana_node_transactions_total",
 			Help: "Total number of transactions processed without error since genesis.",
 		}),
-		SlotHeightMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_slot_height",
-			Help: "The current slot number",
-		}),
-		EpochNumberMetric: prometheus.NewGauge(prometheus.GaugeOpts{
-			Name: "solana_node_epoch_number",
-			Help: "The current epoch number.",
-		}),
+		SlotHeightMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_node_slot_height",
+				Help: fmt.Sprintf("The current slot number, grouped by %s", CommitmentLabel),
+			},
+			[]string{CommitmentLabel},
+		),
+		EpochNumberMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_node_epoch_number",
+				Help: fmt.Sprintf("The current epoch number, grouped by %s", CommitmentLabel),
+			},
+			[]string{CommitmentLabel},
+		),
 		EpochFirstSlotMetric: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "solana_node_epoch_first_slot",
 			Help: "Current epoch's first slot [inclusive].",
@@ -115,6 +247,68 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 			},
 			[]string{NodekeyLabel, TransactionTypeLabel},
 		),
+		PriorityFeeMetric: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_validator_block_priority_fee_lamports",
+				Help: fmt.Sprintf(
+					"Priority fee (in lamports) paid by the non-vote transactions of the last produced block, grouped by %s and %s",
+					NodekeyLabel, QuantileLabel,
+				),
+			},
+			[]string{NodekeyLabel, QuantileLabel},
+		),
+		MevTipsMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_mev_tips_total",
+				Help: fmt.Sprintf(
+					"Jito MEV tips (in SOL) paid in the blocks produced, grouped by %s and %s", NodekeyLabel, EpochLabel,
+				),
+			},
+			[]string{NodekeyLabel, EpochLabel},
+		),
+		VoteInclusionSampledBlocksMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_vote_inclusion_sampled_blocks_total",
+				Help: fmt.Sprintf("Number of cluster blocks sampled for vote inclusion, grouped by %s", LeaderLabel),
+			},
+			[]string{LeaderLabel},
+		),
+		VotesIncludedMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_votes_included_total",
+				Help: fmt.Sprintf(
+					"Number of sampled cluster blocks including a vote of the tracked vote account (represented by "+
+						"%s), grouped by the %s which produced them",
+					VotekeyLabel, LeaderLabel,
+				),
+			},
+			[]string{VotekeyLabel, LeaderLabel},
+		),
+		BlockPropagationDelayMetric: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "solana_validator_block_propagation_delay_seconds",
+				Help: fmt.Sprintf(
+					"Time between the theoretical start of a leader slot of a validator (represented by %s) and its "+
+						"block first being observed %s, approximating the propagation and confirmation delay of its "+
+						"blocks. Requires -block-subscription.",
+					NodekeyLabel, rpc.CommitmentConfirmed,
+				),
+				Buckets: propagationDelayBuckets,
+			},
+			[]string{NodekeyLabel},
+		),
+		BlockFetchesMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_block_fetches_total",
+				Help: fmt.Sprintf(
+					"Number of getBlock calls for the leader slots of a validator (represented by %s), grouped by "+
+						"%s (%s, %s, %s i.e., to be retried, %s or %s)",
+					NodekeyLabel, ResultLabel, BlockResultFetched, BlockResultSkipped, BlockResultNotAvailable,
+					BlockResultPruned, BlockResultFailed,
+				),
+			},
+			[]string{NodekeyLabel, ResultLabel},
+		),
 		BlockHeightMetric: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name: "solana_node_block_height",
 			Help: "The current block height of the node",
@@ -131,10 +325,102 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 			Name: "solana_validator_leader_slots_skipped_epoch",
 			Help: "Number of leader slots skipped by this validator in the current epoch.",
 		}),
+		SkipRateGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_validator_skip_rate",
+			Help: "Percentage of the leader slots of this validator elapsed in the current epoch which were skipped.",
+		}),
+		ClusterSkipRateGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_cluster_skip_rate",
+			Help: "Stake-weighted percentage of the leader slots elapsed in the current epoch which were skipped.",
+		}),
+		RelativeSkipRateGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_validator_relative_skip_rate",
+			Help: "Ratio of this validator's skip rate in the current epoch to the stake-weighted cluster skip rate.",
+		}),
+		LeaderSlotsByPositionEpochGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_validator_leader_slots_by_position_epoch",
+				Help: fmt.Sprintf(
+					"Number of leader slots of this validator in the current epoch, grouped by %s within the "+
+						"%d-slot leader rotation (1-%d) and %s ('%s' or '%s')",
+					PositionLabel, LeaderRotationSlots, LeaderRotationSlots, SkipStatusLabel, StatusValid, StatusSkipped,
+				),
+			},
+			[]string{PositionLabel, SkipStatusLabel},
+		),
+		BlockProductionMismatchMetric: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "solana_node_block_production_mismatches_total",
+			Help: "Number of slots where getBlockProduction disagreed with the confirmed blocks returned by getBlocks.",
+		}),
+		SkipStreakGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_validator_leader_slots_skip_streak",
+			Help: "Number of consecutive leader slots of this validator skipped up to its most recent leader slot.",
+		}),
+		MaxSkipStreakEpochGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_validator_leader_slots_max_skip_streak_epoch",
+			Help: "Longest run of consecutive skipped leader slots of this validator in the current epoch.",
+		}),
+		FinalizationLatencyMetric: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_cluster_finalization_latency_seconds",
+			Help: fmt.Sprintf(
+				"Average time between a slot first being sampled at %s and at %s commitment, over the last %d "+
+					"slots, as an estimate of cluster finality latency.",
+				rpc.CommitmentConfirmed, rpc.CommitmentFinalized, finalityWindow,
+			),
+		}),
+		TopStakeShareGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_cluster_top_stake_share",
+				Help: fmt.Sprintf(
+					"Share (0-1) of the active stake held by the largest %s percent of the staked validators, as "+
+						"of the start of the current epoch",
+					TopPercentLabel,
+				),
+			},
+			[]string{TopPercentLabel},
+		),
+		NakamotoCoefficientGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_cluster_nakamoto_coefficient",
+			Help: "Smallest number of validators whose combined active stake exceeds a third of the total (i.e., " +
+				"enough to halt the cluster), as of the start of the current epoch.",
+		}),
+		SuperminoritySizeGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_cluster_superminority_size",
+			Help: "Number of validators in the superminority, i.e., the largest validators whose combined active " +
+				"stake exceeds a third of the total, as of the start of the current epoch.",
+		}),
+		InSuperminorityGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_validator_in_superminority",
+				Help: fmt.Sprintf(
+					"Whether the validator (represented by %s) is part of the superminority in the current epoch",
+					NodekeyLabel,
+				),
+			},
+			[]string{NodekeyLabel},
+		),
+		StakeChangeGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_validator_stake_change_sol",
+				Help: fmt.Sprintf(
+					"Change of the activated stake (in SOL) of a vote account (represented by %s) in the %s, "+
+						"from the previous epoch",
+					VotekeyLabel, EpochLabel,
+				),
+			},
+			[]string{VotekeyLabel, EpochLabel},
+		),
 		processedLeaderSlots: make(map[int64]struct{}),
 		skippedLeaderSlots: make(map[int64]struct{}),
 		emittedInflationRewards: make(map[string]struct{}),
 	}
+	watcher.TransactionsMonotonicMetric = prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: "solana_node_transactions_monotonic_total",
+			Help: "Total number of transactions processed without error, monotonic across validator restarts.",
+		},
+		func() float64 { return watcher.monotonicCounters.Value(transactionsCounterName) },
+	)
 	logger.Info("Registering slot watcher metrics:")
 	var collectorsToRegister []prometheus.Collector
 	collectorsToRegister = append(collectorsToRegister, 
@@ -149,15 +435,53 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 		watcher.BlockSizeMetric,
 		watcher.BlockHeightMetric,
 	)
+	if config.ConfirmedSlotMetrics {
+		collectorsToRegister = append(collectorsToRegister, watcher.FinalizationLatencyMetric)
+	}
 	if !config.LightMode {
 		collectorsToRegister = append(collectorsToRegister,
 			watcher.AssignedLeaderSlotsGauge,
 			watcher.LeaderSlotsProcessedEpochGauge,
 			watcher.LeaderSlotsSkippedEpochGauge,
+			watcher.SkipRateGauge,
+			watcher.ClusterSkipRateGauge,
+			watcher.RelativeSkipRateGauge,
+			watcher.LeaderSlotsByPositionEpochGauge,
+			watcher.SkipStreakGauge,
+			watcher.MaxSkipStreakEpochGauge,
+			watcher.TransactionsMonotonicMetric,
+			watcher.BlockFetchesMetric,
+			watcher.TopStakeShareGauge,
+			watcher.NakamotoCoefficientGauge,
+			watcher.SuperminoritySizeGauge,
+			watcher.InSuperminorityGauge,
+			watcher.StakeChangeGauge,
 		)
+		if config.ReconcileBlockProduction {
+			collectorsToRegister = append(collectorsToRegister, watcher.BlockProductionMismatchMetric)
+		}
+		if config.BlockSubscription {
+			collectorsToRegister = append(collectorsToRegister, watcher.BlockPropagationDelayMetric)
+		}
+		if config.MonitorPriorityFees {
+			collectorsToRegister = append(collectorsToRegister, watcher.PriorityFeeMetric)
+		}
+		if config.MonitorMevTips {
+			collectorsToRegister = append(collectorsToRegister, watcher.MevTipsMetric)
+		}
+		if config.VoteInclusionSampleInterval > 0 {
+			collectorsToRegister = append(collectorsToRegister,
+				watcher.VoteInclusionSampledBlocksMetric,
+				watcher.VotesIncludedMetric,
+			)
+		}
+	}
+	if registerer == nil {
+		// the metrics are left for the caller to collect (or not):
+		collectorsToRegister = nil
 	}
 	for _, collector := range collectorsToRegister {
-		if err := prometheus.Register(collector); err != nil {
+		if err := registerer.Register(collector); err != nil {
 			var (
 				alreadyRegisteredErr *prometheus.AlreadyRegisteredError
 				duplicateErr         = strings.Contains(err.Error(), "duplicate metrics collector registration attempted")
@@ -173,6 +497,11 @@ func NewSlotWatcher(client *rpc.Client, config *ExporterConfig) *SlotWatcher {
 	for _, collector := range collectorsToRegister {
 		logger.Debugf("Registered collector type: %T", collector)
 	}
+	if config.ValidatorSetSnapshotDir != "" {
+		watcher.snapshotter = NewValidatorSetSnapshotter(
+			watcher.clusterClient, config.ValidatorSetSnapshotDir, config.ValidatorSetSnapshotRetention,
+		)
+	}
 	go watcher.pollInflationRewards(context.Background())
 	return &watcher
 }
@@ -188,8 +517,9 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 		case <-ctx.Done():
 			c.logger.Infof("Stopping WatchSlots() at slot %v", c.slotWatermark)
 			return
-		default:
-			<-ticker.C
+		case notification := <-c.blockNotifications:
+			c.emitSubscribedBlock(notification)
+		case <-ticker.C:
 			// TODO: separate fee-rewards watching from general slot watching, such that general slot watching commitment level can be dropped to confirmed
 			commitment := rpc.CommitmentFinalized
 			epochInfo, err := c.client.GetEpochInfo(ctx, commitment)
@@ -205,12 +535,21 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 
 			c.logger.Infof("Current slot: %v", epochInfo.AbsoluteSlot)
 			// These metrics are essential even in light mode
-			c.SlotHeightMetric.Set(float64(epochInfo.AbsoluteSlot))
-			c.EpochNumberMetric.Set(float64(epochInfo.Epoch))
+			if !c.subscriptionLive(time.Now()) {
+				c.SlotHeightMetric.WithLabelValues(string(commitment)).Set(float64(epochInfo.AbsoluteSlot))
+			}
+			if c.config.ConfirmedSlotMetrics {
+				c.finalityTracker.ObserveFinalized(epochInfo.AbsoluteSlot, time.Now())
+				c.emitConfirmedSlotMetrics(ctx)
+			}
+			if c.config.BlockSubscription && !c.config.LightMode {
+				c.anchorSlotClock(ctx)
+			}
 			
 			// In light mode, skip transaction count and block height metrics
 			if !c.config.LightMode {
 				c.TotalTransactionsMetric.Set(float64(epochInfo.TransactionCount))
+				c.monotonicCounters.Observe(transactionsCounterName, float64(epochInfo.TransactionCount))
 				c.BlockHeightMetric.Set(float64(epochInfo.BlockHeight))
 			}
 
@@ -224,6 +563,8 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 			if epochInfo.Epoch > c.currentEpoch {
 				c.closeCurrentEpoch(ctx, epochInfo)
 			}
+			// the epoch number only moves on once the previous epoch's metrics are final:
+			c.EpochNumberMetric.WithLabelValues(string(commitment)).Set(float64(epochInfo.Epoch))
 
 			// update block production metrics up until the current slot:
 			// Only move the slot watermark in light mode if we need to for epoch tracking
@@ -238,11 +579,179 @@ func (c *SlotWatcher) WatchSlots(ctx context.Context) {
 	}
 }
 
+// subscriptionLive returns whether either subscription is feeding the finalized slot height, such that it needn't be
+// polled. Each feed is tracked separately, as one dropping must not stop the other from being relied upon (or vice
+// versa).
+func (c *SlotWatcher) subscriptionLive(now time.Time) bool {
+	staleness := subscriptionStalePaces * c.config.SlotPace
+	return c.slotSubscription.Live(now, staleness) || c.geyserSubscription.Live(now, staleness)
+}
+
+// WatchSlotSubscription feeds the finalized slot height from a WebSocket slotSubscribe subscription, such that it
+// updates in near real-time rather than every SlotPace. Whenever the subscription drops (or goes without notifications
+// for a few SlotPaces), WatchSlots falls back to polling the slot height until it is re-established.
+func (c *SlotWatcher) WatchSlotSubscription(ctx context.Context, wsClient *rpc.WSClient) {
+	c.logger.Infof("Starting slot subscription on %s", rpc.RedactUrl(wsClient.WsUrl))
+	finalized := c.SlotHeightMetric.WithLabelValues(string(rpc.CommitmentFinalized))
+	for {
+		notifications, err := wsClient.SubscribeSlots(ctx)
+		if err != nil {
+			c.logger.Errorf("Failed to subscribe to slots, polling instead: %v", err)
+		} else {
+			for notification := range notifications {
+				c.slotSubscription.Observe(time.Now())
+				finalized.Set(float64(notification.Root))
+			}
+			c.slotSubscription.Reset()
+		}
+
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Stopping WatchSlotSubscription()")
+			return
+		case <-time.After(c.config.SlotPace):
+		}
+	}
+}
+
+// WatchBlockSubscription subscribes to the blocks mentioning each tracked nodekey, such that the fee rewards and block
+// sizes of leader slots are emitted as soon as the blocks are produced, instead of being polled through getBlock.
+// Leader slots missed by the subscription (e.g., while it is reconnecting) are still polled.
+func (c *SlotWatcher) WatchBlockSubscription(ctx context.Context, wsClient *rpc.WSClient) {
+	transactionDetails := c.transactionDetails()
+	for _, nodekey := range c.config.NodeKeys {
+		go func(nodekey string) {
+			c.logger.Infof("Starting block subscription on %s for %s", rpc.RedactUrl(wsClient.WsUrl), nodekey)
+			for {
+				notifications, err := wsClient.SubscribeBlocks(ctx, rpc.CommitmentConfirmed, nodekey, transactionDetails)
+				if err != nil {
+					c.logger.Errorf("Failed to subscribe to blocks of %s, polling instead: %v", nodekey, err)
+				} else {
+					for notification := range notifications {
+						select {
+						case c.blockNotifications <- nodekeyBlockNotification{nodekey, notification, time.Now()}:
+						case <-ctx.Done():
+						}
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					c.logger.Infof("Stopping block subscription for %s", nodekey)
+					return
+				case <-time.After(c.config.SlotPace):
+				}
+			}
+		}(nodekey)
+	}
+}
+
+// WatchGeyser feeds the finalized slot height from a Yellowstone gRPC subscription, as WatchSlotSubscription does.
+// Unless block sizes or priority fees (which need the transactions) are monitored, the fee rewards of leader slots are
+// also emitted from its block metas, as WatchBlockSubscription does.
+func (c *SlotWatcher) WatchGeyser(ctx context.Context, client *geyser.Client) {
+	c.logger.Infof("Starting geyser subscription on %s", rpc.RedactUrl(client.Url))
+	subscription := geyser.Subscription{
+		Slots:      true,
+		BlocksMeta: !c.config.LightMode && len(c.config.NodeKeys) > 0 && c.transactionDetails() == "none",
+		Commitment: geyser.CommitmentConfirmed,
+	}
+	finalized := c.SlotHeightMetric.WithLabelValues(string(rpc.CommitmentFinalized))
+	for {
+		updates, err := client.Subscribe(ctx, subscription)
+		if err != nil {
+			c.logger.Errorf("Failed to subscribe through geyser, polling instead: %v", err)
+		} else {
+			for update := range updates {
+				switch {
+				case update.Slot != nil && update.Slot.Status == geyser.SlotFinalized:
+					c.geyserSubscription.Observe(time.Now())
+					finalized.Set(float64(update.Slot.Slot))
+				case update.BlockMeta != nil:
+					notification, ok := GetGeyserBlockNotification(*update.BlockMeta, c.config.NodeKeys, time.Now())
+					if !ok {
+						continue
+					}
+					select {
+					case c.blockNotifications <- notification:
+					case <-ctx.Done():
+					}
+				}
+			}
+			c.geyserSubscription.Reset()
+		}
+
+		select {
+		case <-ctx.Done():
+			c.logger.Info("Stopping WatchGeyser()")
+			return
+		case <-time.After(c.config.SlotPace):
+		}
+	}
+}
+
+// emitSubscribedBlock emits the fee reward and block size of a subscribed block, if it is a leader slot of the
+// nodekey within the current epoch which has not been polled yet.
+func (c *SlotWatcher) emitSubscribedBlock(notification nodekeyBlockNotification) {
+	slot := notification.Slot
+	if notification.Block == nil || c.currentEpoch == 0 || slot <= c.slotWatermark || slot > c.lastSlot {
+		return
+	}
+	// blocks merely mentioning the nodekey (e.g., through a transfer) are not its leader slots:
+	if !slices.Contains(c.leaderSchedule[notification.nodekey], slot) {
+		return
+	}
+	if _, ok := c.subscribedBlocks[slot]; ok {
+		return
+	}
+	if err := c.emitBlockInfo(notification.nodekey, c.currentEpoch, slot, notification.Block); err != nil {
+		c.logger.Errorf("Failed to emit subscribed block info for %v at %v: %v", notification.nodekey, slot, err)
+		return
+	}
+	c.subscribedBlocks[slot] = struct{}{}
+	if delay, ok := GetPropagationDelay(&c.slotClock, slot, notification.received); ok {
+		c.BlockPropagationDelayMetric.WithLabelValues(notification.nodekey).Observe(delay)
+	}
+}
+
+// anchorSlotClock re-anchors the slot clock at the current confirmed slot, such that the start of the slots of
+// subscribed blocks is estimated from a recent timestamp.
+func (c *SlotWatcher) anchorSlotClock(ctx context.Context) {
+	clock, err := c.client.GetClock(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("Failed to get clock sysvar: %v", err)
+		return
+	}
+	c.slotClock.Anchor(clock)
+}
+
+// emitConfirmedSlotMetrics emits the slot height and epoch number at confirmed commitment, alongside the finalized
+// series, since alerting on finalized alone lags ~30 slots and hides short stalls. The confirmed samples also feed the
+// finalization latency estimate.
+func (c *SlotWatcher) emitConfirmedSlotMetrics(ctx context.Context) {
+	epochInfo, err := c.client.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		c.logger.Errorf("Failed to get confirmed epoch info: %v", err)
+		return
+	}
+	commitment := string(rpc.CommitmentConfirmed)
+	c.SlotHeightMetric.WithLabelValues(commitment).Set(float64(epochInfo.AbsoluteSlot))
+	c.EpochNumberMetric.WithLabelValues(commitment).Set(float64(epochInfo.Epoch))
+
+	c.finalityTracker.ObserveConfirmed(epochInfo.AbsoluteSlot, time.Now())
+	if latency, ok := c.finalityTracker.AverageLatency(); ok {
+		c.FinalizationLatencyMetric.Set(latency)
+	}
+}
+
 // trackEpoch takes in a new rpc.EpochInfo and sets the SlotWatcher tracking metrics accordingly,
 // and updates the prometheus gauges associated with those metrics.
 func (c *SlotWatcher) trackEpoch(ctx context.Context, epoch *rpc.EpochInfo) {
 	c.logger.Infof("Tracking epoch %v (from %v)", epoch.Epoch, c.currentEpoch)
-	firstSlot, lastSlot := GetEpochBounds(epoch)
+	if c.snapshotter != nil {
+		go c.snapshotter.Run(ctx, epoch.Epoch)
+	}
+	firstSlot, lastSlot := solana.GetEpochBounds(epoch)
 	// if we haven't yet set c.currentEpoch, that (hopefully) means this is the initial setup,
 	// and so we can simply store the tracking numbers
 	if c.currentEpoch == 0 {
@@ -252,6 +761,12 @@ func (c *SlotWatcher) trackEpoch(ctx context.Context, epoch *rpc.EpochInfo) {
 		// we don't backfill on startup. we set the watermark to current slot minus 1,
 		//such that the current slot is the first slot tracked
 		c.slotWatermark = epoch.AbsoluteSlot - 1
+		if c.config.EpochRebuild && !c.config.LightMode {
+			// unless rebuilding the epoch to date, in which case the whole epoch so far is tracked, such that
+			// epoch-labelled counters continue from their values before the restart:
+			c.logger.Infof("Rebuilding epoch %v from slot %v", epoch.Epoch, firstSlot)
+			c.slotWatermark = firstSlot - 1
+		}
 	} else {
 		// if c.currentEpoch is already set, then, just in case, run some checks
 		// to make sure that we make sure that we are tracking consistently
@@ -279,7 +794,7 @@ func (c *SlotWatcher) trackEpoch(ctx context.Context, epoch *rpc.EpochInfo) {
 
 	// emit epoch bounds:
 	c.logger.Infof("Emitting epoch bounds: %v (slots %v -> %v)", c.currentEpoch, c.firstSlot, c.lastSlot)
-	c.EpochNumberMetric.Set(float64(c.currentEpoch))
+	c.EpochNumberMetric.WithLabelValues(string(rpc.CommitmentFinalized)).Set(float64(c.currentEpoch))
 	
 	// These metrics are not essential in light mode
 	if !c.config.LightMode {
@@ -295,6 +810,25 @@ func (c *SlotWatcher) trackEpoch(ctx context.Context, epoch *rpc.EpochInfo) {
 			c.logger.Errorf("Failed to get trimmed leader schedule, bailing out: %v", err)
 		}
 		c.leaderSchedule = leaderSchedule
+
+		if c.config.VoteInclusionSampleInterval > 0 {
+			slotLeaders, err := GetSlotLeaders(ctx, c.client, epoch.AbsoluteSlot, c.firstSlot)
+			if err != nil {
+				c.logger.Errorf("Failed to get slot leaders, vote inclusion will not be sampled: %v", err)
+			}
+			c.slotLeaders = slotLeaders
+		}
+
+		// stake only changes at epoch boundaries, so its concentration (and the weights of the skip rate) are too:
+		voteAccounts, err := c.clusterClient.GetVoteAccounts(ctx, rpc.CommitmentFinalized)
+		if err != nil {
+			c.logger.Errorf("Failed to get vote accounts for the epoch stakes: %v", err)
+		} else {
+			c.emitStakeConcentration(voteAccounts)
+			c.nodekeyStakes = GetStakesByNodekey(voteAccounts)
+			c.emitSuperminority()
+			c.emitStakeChanges(voteAccounts, epoch.Epoch)
+		}
 	}
 
 	// Light mode leader slot tracking
@@ -321,7 +855,11 @@ func (c *SlotWatcher) cleanEpoch(ctx context.Context, epoch int64) {
 	// rewards:
 	for i, nodekey := range c.config.NodeKeys {
 		c.deleteMetricLabelValues(c.FeeRewardsMetric, "fee-rewards", nodekey, epochStr)
+		if c.config.MonitorMevTips {
+			c.deleteMetricLabelValues(c.MevTipsMetric, "mev-tips", nodekey, epochStr)
+		}
 		c.deleteMetricLabelValues(c.InflationRewardsMetric, "inflation-rewards", c.config.VoteKeys[i], epochStr)
+		c.StakeChangeGauge.DeleteLabelValues(c.config.VoteKeys[i], epochStr)
 	}
 	// slots:
 	for _, status := range []string{StatusValid, StatusSkipped} {
@@ -335,10 +873,16 @@ func (c *SlotWatcher) cleanEpoch(ctx context.Context, epoch int64) {
 // remaining slots in the "current" epoch before we start tracking the new one.
 func (c *SlotWatcher) closeCurrentEpoch(ctx context.Context, newEpoch *rpc.EpochInfo) {
 	c.logger.Infof("Closing current epoch %v, moving into epoch %v", c.currentEpoch, newEpoch.Epoch)
+	c.annotator.Annotate(EventEpochRollover, fmt.Sprintf("Epoch %d started", newEpoch.Epoch))
 
 	// On epoch transition, reset the per-epoch gauges and slot sets
 	c.LeaderSlotsProcessedEpochGauge.Set(0)
 	c.LeaderSlotsSkippedEpochGauge.Set(0)
+	c.SkipRateGauge.Set(0)
+	c.ClusterSkipRateGauge.Set(0)
+	c.RelativeSkipRateGauge.Set(0)
+	c.LeaderSlotsByPositionEpochGauge.Reset()
+	c.MaxSkipStreakEpochGauge.Set(0)
 	c.processedLeaderSlots = make(map[int64]struct{})
 	c.skippedLeaderSlots = make(map[int64]struct{})
 
@@ -351,6 +895,7 @@ func (c *SlotWatcher) closeCurrentEpoch(ctx context.Context, newEpoch *rpc.Epoch
 			}
 		}
 		c.moveSlotWatermark(ctx, c.lastSlot)
+		clear(c.subscribedBlocks)
 		go c.cleanEpoch(ctx, c.currentEpoch)
 	}
 	c.trackEpoch(ctx, newEpoch)
@@ -358,7 +903,7 @@ func (c *SlotWatcher) closeCurrentEpoch(ctx context.Context, newEpoch *rpc.Epoch
 
 // checkValidSlotRange makes sure that the slot range we are going to query is within the current epoch we are tracking.
 func (c *SlotWatcher) checkValidSlotRange(from, to int64) error {
-	if from < c.firstSlot || to > c.lastSlot {
+	if !solana.IsSlotRangeWithin(from, to, c.firstSlot, c.lastSlot) {
 		return fmt.Errorf(
 			"start-end slots (%v -> %v) is not contained within current epoch %v range (%v -> %v)",
 			from,
@@ -376,7 +921,15 @@ func (c *SlotWatcher) moveSlotWatermark(ctx context.Context, to int64) {
 	c.logger.Infof("Moving watermark %v -> %v", c.slotWatermark, to)
 	startSlot := c.slotWatermark + 1
 	c.processLeaderSlotsForValidator(ctx, startSlot, to)
+	c.fetchAndEmitBlockProduction(ctx, startSlot, to)
+	c.emitClusterSkipRate(ctx, to)
 	c.fetchAndEmitBlockInfos(ctx, startSlot, to)
+	if c.config.ReconcileBlockProduction {
+		c.reconcileBlockProduction(ctx, startSlot, to)
+	}
+	if c.config.VoteInclusionSampleInterval > 0 {
+		c.sampleVoteInclusion(ctx, startSlot, to)
+	}
 	c.slotWatermark = to
 }
 
@@ -429,7 +982,7 @@ func (c *SlotWatcher) processLeaderSlotsForValidator(ctx context.Context, startS
 		if slot > endSlot {
 			continue // skip slots beyond the allowed range
 		}
-		blockProduction, err := c.client.GetBlockProduction(ctx, rpc.CommitmentFinalized, slot, slot)
+		blockProduction, err := c.clusterClient.GetBlockProduction(ctx, rpc.CommitmentFinalized, slot, slot)
 		if err != nil {
 			c.logger.Errorf("Failed to get block production for slot %d: %v", slot, err)
 			continue
@@ -447,9 +1000,49 @@ func (c *SlotWatcher) processLeaderSlotsForValidator(ctx context.Context, startS
 	}
 	c.LeaderSlotsProcessedEpochGauge.Set(float64(len(c.processedLeaderSlots)))
 	c.LeaderSlotsSkippedEpochGauge.Set(float64(len(c.skippedLeaderSlots)))
+	c.emitSkipRate()
+	c.emitLeaderSlotsByPosition()
+	c.emitSkipStreaks()
 	c.logger.Infof("Updated per-epoch leader slot gauges: processed=%d, skipped=%d", len(c.processedLeaderSlots), len(c.skippedLeaderSlots))
 }
 
+// emitSkipRate emits the percentage of the validator's leader slots skipped this epoch.
+func (c *SlotWatcher) emitSkipRate() {
+	// slots of the previous epoch can still be processed after the rollover:
+	processed := CountSlotsFrom(c.processedLeaderSlots, c.firstSlot)
+	skipped := CountSlotsFrom(c.skippedLeaderSlots, c.firstSlot)
+	c.SkipRateGauge.Set(GetSkipRate(processed, skipped))
+}
+
+// emitLeaderSlotsByPosition emits the per-epoch leader slot outcomes bucketed by position within the leader rotation,
+// as first-slot skips point to different root causes (fork choice, previous leader) than fourth-slot skips.
+func (c *SlotWatcher) emitLeaderSlotsByPosition() {
+	for status, slots := range map[string]map[int64]struct{}{
+		StatusValid:   c.processedLeaderSlots,
+		StatusSkipped: c.skippedLeaderSlots,
+	} {
+		counts := make([]int, LeaderRotationSlots)
+		for slot := range slots {
+			// slots of the previous epoch can still be processed after the rollover:
+			if slot < c.firstSlot {
+				continue
+			}
+			counts[GetLeaderSlotPosition(slot, c.firstSlot)-1]++
+		}
+		for i, count := range counts {
+			c.LeaderSlotsByPositionEpochGauge.WithLabelValues(toString(i+1), status).Set(float64(count))
+		}
+	}
+}
+
+// emitSkipStreaks emits the current and longest runs of consecutive skipped leader slots this epoch, since isolated
+// skips are normal, but streaks point to an outage.
+func (c *SlotWatcher) emitSkipStreaks() {
+	current, longest := GetSkipStreaks(c.processedLeaderSlots, c.skippedLeaderSlots, c.firstSlot)
+	c.SkipStreakGauge.Set(float64(current))
+	c.MaxSkipStreakEpochGauge.Set(float64(longest))
+}
+
 // fetchAndEmitBlockProduction fetches block production from startSlot up to the provided endSlot [inclusive],
 // and emits the prometheus metrics,
 func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot, endSlot int64) {
@@ -465,7 +1058,7 @@ func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot
 	}
 
 	// fetch block production:
-	blockProduction, err := c.client.GetBlockProduction(ctx, rpc.CommitmentFinalized, startSlot, endSlot)
+	blockProduction, err := c.clusterClient.GetBlockProduction(ctx, rpc.CommitmentFinalized, startSlot, endSlot)
 	if err != nil {
 		c.logger.Errorf("Failed to get block production, bailing out: %v", err)
 		return
@@ -495,6 +1088,110 @@ func (c *SlotWatcher) fetchAndEmitBlockProduction(ctx context.Context, startSlot
 	c.logger.Debugf("Fetched block production in [%v -> %v]", startSlot, endSlot)
 }
 
+// emitClusterSkipRate emits the stake-weighted skip rate of the cluster over the current epoch up to endSlot, and the
+// skip rate of the validator relative to it.
+func (c *SlotWatcher) emitClusterSkipRate(ctx context.Context, endSlot int64) {
+	if c.config.LightMode || len(c.nodekeyStakes) == 0 {
+		return
+	}
+	blockProduction, err := c.clusterClient.GetBlockProduction(ctx, rpc.CommitmentFinalized, c.firstSlot, endSlot)
+	if err != nil {
+		c.logger.Errorf("Failed to get block production for the cluster skip rate: %v", err)
+		return
+	}
+	clusterSkipRate, ok := GetStakeWeightedSkipRate(blockProduction.ByIdentity, c.nodekeyStakes)
+	if !ok {
+		return
+	}
+	c.ClusterSkipRateGauge.Set(clusterSkipRate)
+
+	production, ok := blockProduction.ByIdentity[c.config.ValidatorIdentity]
+	if !ok || production.LeaderSlots == 0 || clusterSkipRate == 0 {
+		return
+	}
+	skipRate := GetSkipRate(int(production.BlocksProduced), int(production.LeaderSlots-production.BlocksProduced))
+	c.RelativeSkipRateGauge.Set(skipRate / clusterSkipRate)
+}
+
+// emitSuperminority emits the size of the superminority of the epoch stakes, and which tracked nodekeys are part of it.
+func (c *SlotWatcher) emitSuperminority() {
+	superminority := GetSuperminority(c.nodekeyStakes)
+	c.SuperminoritySizeGauge.Set(float64(len(superminority)))
+	for _, nodekey := range c.config.NodeKeys {
+		c.InSuperminorityGauge.WithLabelValues(nodekey).Set(BoolToFloat64(slices.Contains(superminority, nodekey)))
+	}
+}
+
+// emitStakeChanges records the activated stakes of the tracked votekeys as of the start of the epoch, and emits their
+// change from the previous epoch. Changes are only emitted if the previous epoch's stakes were recorded (i.e., not for
+// the first epoch tracked, nor after missing an epoch) and only for the votekeys present in both epochs, as a missing
+// vote account is no evidence of the stake having changed.
+func (c *SlotWatcher) emitStakeChanges(voteAccounts *rpc.VoteAccounts, epoch int64) {
+	stakes := make(map[string]int64)
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if slices.Contains(c.config.VoteKeys, account.VotePubkey) {
+			stakes[account.VotePubkey] = account.ActivatedStake
+		}
+	}
+	if c.votekeyStakes != nil && c.votekeyStakesEpoch == epoch-1 {
+		for _, votekey := range c.config.VoteKeys {
+			previous, wasStaked := c.votekeyStakes[votekey]
+			current, isStaked := stakes[votekey]
+			if !wasStaked || !isStaked {
+				continue
+			}
+			change := float64(current-previous) / rpc.LamportsInSol
+			c.StakeChangeGauge.WithLabelValues(votekey, toString(epoch)).Set(change)
+		}
+	}
+	c.votekeyStakes, c.votekeyStakesEpoch = stakes, epoch
+}
+
+// emitStakeConcentration emits the share of the stake held by the largest validators, and the Nakamoto coefficient.
+func (c *SlotWatcher) emitStakeConcentration(voteAccounts *rpc.VoteAccounts) {
+	stakes := SortedActiveStakes(voteAccounts)
+	for _, percent := range stakeConcentrationPercents {
+		c.TopStakeShareGauge.WithLabelValues(strconv.Itoa(percent)).Set(TopStakeShare(stakes, percent))
+	}
+	c.NakamotoCoefficientGauge.Set(float64(NakamotoCoefficient(stakes)))
+}
+
+// reconcileBlockProduction cross-checks getBlockProduction against getBlocks between startSlot and endSlot
+// [inclusive], and counts any disagreement. Some RPC providers return incomplete block production data, which
+// would otherwise silently skew the skip metrics.
+func (c *SlotWatcher) reconcileBlockProduction(ctx context.Context, startSlot, endSlot int64) {
+	if c.config.LightMode {
+		c.logger.Debug("Skipping block-production reconciliation in light mode.")
+		return
+	}
+	c.logger.Debugf("Reconciling block production in [%v -> %v]", startSlot, endSlot)
+
+	if err := c.checkValidSlotRange(startSlot, endSlot); err != nil {
+		c.logger.Fatalf("invalid slot range: %v", err)
+	}
+
+	blockProduction, err := c.clusterClient.GetBlockProduction(ctx, rpc.CommitmentFinalized, startSlot, endSlot)
+	if err != nil {
+		c.logger.Errorf("Failed to get block production for reconciliation, bailing out: %v", err)
+		return
+	}
+	blocks, err := c.clusterClient.GetBlocks(ctx, rpc.CommitmentFinalized, startSlot, endSlot)
+	if err != nil {
+		c.logger.Errorf("Failed to get blocks for reconciliation, bailing out: %v", err)
+		return
+	}
+
+	mismatches := CountBlockProductionMismatches(blockProduction, blocks, startSlot, endSlot)
+	if mismatches > 0 {
+		c.logger.Warnf(
+			"Block production in [%v -> %v] disagrees with getBlocks by %v slots", startSlot, endSlot, mismatches,
+		)
+		c.BlockProductionMismatchMetric.Add(float64(mismatches))
+	}
+
+	c.logger.Debugf("Reconciled block production in [%v -> %v]", startSlot, endSlot)
+}
+
 // fetchAndEmitBlockInfos fetches and emits all the fee rewards (+ block sizes) for the tracked addresses between the
 // startSlot and endSlot [inclusive]
 func (c *SlotWatcher) fetchAndEmitBlockInfos(ctx context.Context, startSlot, endSlot int64) {
@@ -507,14 +1204,30 @@ func (c *SlotWatcher) fetchAndEmitBlockInfos(ctx context.Context, startSlot, end
 	if err := c.checkValidSlotRange(startSlot, endSlot); err != nil {
 		c.logger.Fatalf("invalid slot range: %v", err)
 	}
-	scheduleToFetch := SelectFromSchedule(c.leaderSchedule, startSlot, endSlot)
+	c.retryPendingBlocks(ctx)
+	scheduleToFetch := solana.SelectFromSchedule(c.leaderSchedule, startSlot, endSlot)
+	blocks, firstAvailableBlock := c.getProducedBlocks(ctx, scheduleToFetch, startSlot, endSlot)
 	for nodekey, leaderSlots := range scheduleToFetch {
+		// skipped slots have no block to fetch:
+		producedSlots := FilterProducedSlots(leaderSlots, blocks, firstAvailableBlock)
+		if len(producedSlots) < len(leaderSlots) {
+			c.logger.Infof(
+				"%v skipped %v leader slots in [%v -> %v], no fee rewards.",
+				nodekey, len(leaderSlots)-len(producedSlots), startSlot, endSlot,
+			)
+			leaderSlots = producedSlots
+		}
 		if len(leaderSlots) == 0 {
 			continue
 		}
 
 		c.logger.Infof("Fetching fee rewards for %v in [%v -> %v]: %v ...", nodekey, startSlot, endSlot, leaderSlots)
 		for _, slot := range leaderSlots {
+			if _, ok := c.subscribedBlocks[slot]; ok {
+				// already emitted from the block subscription:
+				delete(c.subscribedBlocks, slot)
+				continue
+			}
 			err := c.fetchAndEmitSingleBlockInfo(ctx, nodekey, c.currentEpoch, slot)
 			if err != nil {
 				c.logger.Errorf("Failed to fetch fee rewards for %v at %v: %v", nodekey, slot, err)
@@ -525,27 +1238,101 @@ func (c *SlotWatcher) fetchAndEmitBlockInfos(ctx context.Context, startSlot, end
 	c.logger.Debugf("Fetched fee rewards in [%v -> %v]", startSlot, endSlot)
 }
 
-// fetchAndEmitSingleBlockInfo fetches and emits the fee reward + block size for a single block.
+// getProducedBlocks returns the confirmed blocks between startSlot and endSlot [inclusive] if there are any leader
+// slots to fetch in the schedule, so that skipped slots can be pruned before getBlock, along with the node's first
+// available block, below which nothing can be pruned. This makes a single getBlocks call instead of a getBlock call
+// per skipped slot. No blocks are returned if either call fails, i.e., nothing is pruned.
+func (c *SlotWatcher) getProducedBlocks(
+	ctx context.Context, schedule map[string][]int64, startSlot, endSlot int64,
+) ([]int64, int64) {
+	leaderSlots := 0
+	for _, slots := range schedule {
+		leaderSlots += len(slots)
+	}
+	if leaderSlots == 0 {
+		return nil, 0
+	}
+	firstAvailableBlock, err := c.client.GetFirstAvailableBlock(ctx)
+	if err != nil {
+		c.logger.Warnf("Failed to get first available block, fetching every leader slot: %v", err)
+		return nil, 0
+	}
+	blocks, err := c.client.GetBlocks(ctx, rpc.CommitmentConfirmed, startSlot, endSlot)
+	if err != nil {
+		c.logger.Warnf("Failed to get blocks in [%v -> %v], fetching every leader slot: %v", startSlot, endSlot, err)
+		return nil, 0
+	}
+	return blocks, firstAvailableBlock
+}
+
+// fetchAndEmitSingleBlockInfo fetches and emits the fee reward + block size for a single block. Blocks which are not
+// available yet are retried on the next slot watermark moves, instead of losing their fee rewards.
 func (c *SlotWatcher) fetchAndEmitSingleBlockInfo(
 	ctx context.Context, nodekey string, epoch int64, slot int64,
 ) error {
-	transactionDetails := "none"
-	if c.config.MonitorBlockSizes {
-		transactionDetails = "full"
-	}
-	block, err := c.client.GetBlock(ctx, rpc.CommitmentConfirmed, slot, transactionDetails)
-	if err != nil {
-		var rpcError *rpc.Error
-		if errors.As(err, &rpcError) {
-			// this is the error code for slot was skipped:
-			if rpcError.Code == rpc.SlotSkippedCode && strings.Contains(rpcError.Message, "skipped") {
-				c.logger.Infof("slot %v was skipped, no fee rewards.", slot)
-				return nil
-			}
+	block, err := c.client.GetBlock(ctx, rpc.CommitmentConfirmed, slot, c.transactionDetails())
+	result := GetBlockFetchResult(err)
+	c.BlockFetchesMetric.WithLabelValues(nodekey, result).Inc()
+	switch result {
+	case BlockResultFetched:
+		delete(c.pendingBlocks, slot)
+		return c.emitBlockInfo(nodekey, epoch, slot, block)
+	case BlockResultSkipped:
+		c.logger.Infof("slot %v was skipped, no fee rewards.", slot)
+		delete(c.pendingBlocks, slot)
+		return nil
+	case BlockResultNotAvailable:
+		pending, ok := c.pendingBlocks[slot]
+		if !ok {
+			pending = &pendingBlock{nodekey: nodekey, epoch: epoch}
+			c.pendingBlocks[slot] = pending
+		}
+		pending.attempts++
+		if pending.attempts > maxBlockFetchRetries {
+			delete(c.pendingBlocks, slot)
+			return fmt.Errorf("block still not available after %d retries: %w", maxBlockFetchRetries, err)
 		}
+		c.logger.Infof("block %v is not available yet, retrying later: %v", slot, err)
+		return nil
+	default:
+		delete(c.pendingBlocks, slot)
 		return err
 	}
+}
+
+// retryPendingBlocks fetches and emits the blocks which were not available yet when last fetched.
+func (c *SlotWatcher) retryPendingBlocks(ctx context.Context) {
+	for slot, pending := range c.pendingBlocks {
+		if err := c.fetchAndEmitSingleBlockInfo(ctx, pending.nodekey, pending.epoch, slot); err != nil {
+			c.logger.Errorf("Failed to fetch fee rewards for %v at %v: %v", pending.nodekey, slot, err)
+		}
+	}
+}
+
+// GetBlockFetchResult classifies the error of a getBlock call: skipped slots have no block at all, blocks which are
+// not available yet may be once the node catches up, and pruned blocks are gone from the node's ledger for good.
+func GetBlockFetchResult(err error) string {
+	if err == nil {
+		return BlockResultFetched
+	}
+	var rpcError *rpc.Error
+	if !errors.As(err, &rpcError) {
+		return BlockResultFailed
+	}
+	switch rpcError.Code {
+	case rpc.SlotSkippedCode, rpc.LongTermStorageSlotSkippedCode:
+		return BlockResultSkipped
+	case rpc.BlockNotAvailableCode, rpc.BlockStatusNotYetAvailableCode:
+		return BlockResultNotAvailable
+	case rpc.BlockCleanedUpCode:
+		return BlockResultPruned
+	default:
+		return BlockResultFailed
+	}
+}
 
+// emitBlockInfo emits the fee reward + block size (+ MEV tips) of a block produced by the nodekey.
+func (c *SlotWatcher) emitBlockInfo(nodekey string, epoch int64, slot int64, block *rpc.Block) error {
 	foundFeeReward := false
 	for _, reward := range block.Rewards {
 		if strings.ToLower(reward.RewardType) == "fee" {
@@ -577,9 +1364,91 @@ func (c *SlotWatcher) fetchAndEmitSingleBlockInfo(
 		nonVoteCount := len(block.Transactions) - voteCount
 		c.BlockSizeMetric.WithLabelValues(nodekey, TransactionTypeNonVote).Set(float64(nonVoteCount))
 	}
+
+	if c.config.MonitorMevTips {
+		tips, err := GetMevTips(block)
+		if err != nil {
+			return err
+		}
+		c.MevTipsMetric.WithLabelValues(nodekey, toString(epoch)).Add(float64(tips) / rpc.LamportsInSol)
+	}
+
+	if c.config.MonitorPriorityFees {
+		return c.emitPriorityFees(nodekey, epoch, slot, block)
+	}
 	return nil
 }
 
+// sampleVoteInclusion samples every VoteInclusionSampleInterval-th cluster block in [startSlot -> endSlot], counting
+// which of their leaders included the votes of the tracked vote accounts. Leaders which rarely include them point at
+// vote-landing problems with specific peers (e.g., TPU connectivity) rather than at the validator itself.
+func (c *SlotWatcher) sampleVoteInclusion(ctx context.Context, startSlot, endSlot int64) {
+	if c.config.LightMode || c.slotLeaders == nil {
+		return
+	}
+	interval := c.config.VoteInclusionSampleInterval
+	for slot := (startSlot + interval - 1) / interval * interval; slot <= endSlot; slot += interval {
+		leader, ok := c.slotLeaders[slot]
+		if !ok {
+			continue
+		}
+		block, err := c.client.GetBlock(ctx, rpc.CommitmentConfirmed, slot, "full")
+		if err != nil {
+			var rpcError *rpc.Error
+			if !errors.As(err, &rpcError) || rpcError.Code != rpc.SlotSkippedCode {
+				c.logger.Errorf("Failed to sample vote inclusion at %v: %v", slot, err)
+			}
+			continue
+		}
+		voters, err := GetVoteTransactionAccounts(block)
+		if err != nil {
+			c.logger.Errorf("Failed to sample vote inclusion at %v: %v", slot, err)
+			continue
+		}
+		c.VoteInclusionSampledBlocksMetric.WithLabelValues(leader).Inc()
+		for _, votekey := range c.config.VoteKeys {
+			if _, ok := voters[votekey]; ok {
+				c.VotesIncludedMetric.WithLabelValues(votekey, leader).Inc()
+			}
+		}
+	}
+}
+
+// transactionDetails returns the level of transaction details to fetch blocks with, only fetching full transactions
+// when any of the block metrics need them.
+func (c *SlotWatcher) transactionDetails() string {
+	if c.config.MonitorBlockSizes || c.config.MonitorPriorityFees || c.config.MonitorMevTips {
+		return "full"
+	}
+	return "none"
+}
+
+// emitPriorityFees emits the priority fee quantiles of a block produced by the nodekey, and writes its raw priority
+// fees to the priority fee output (if any).
+func (c *SlotWatcher) emitPriorityFees(nodekey string, epoch int64, slot int64, block *rpc.Block) error {
+	record, err := GetPriorityFeeRecord(nodekey, epoch, slot, block)
+	if err != nil {
+		return err
+	}
+	sorted := slices.Clone(record.PriorityFees)
+	slices.Sort(sorted)
+	for _, quantile := range PriorityFeeQuantiles {
+		c.PriorityFeeMetric.WithLabelValues(nodekey, toString(quantile)).Set(float64(GetQuantile(sorted, quantile)))
+	}
+
+	if c.priorityFeeEncoder != nil {
+		if err := c.priorityFeeEncoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write priority fees of slot %d: %w", slot, err)
+		}
+	}
+	return nil
+}
+
+// SetPriorityFeeOutput makes the watcher write the raw priority fee record of every produced block to the writer.
+func (c *SlotWatcher) SetPriorityFeeOutput(writer io.Writer) {
+	c.priorityFeeEncoder = json.NewEncoder(writer)
+}
+
 // fetchAndEmitInflationRewards fetches and emits the inflation rewards for the configured inflationRewardAddresses
 // at the provided epoch
 func (c *SlotWatcher) fetchAndEmitInflationRewards(ctx context.Context, epoch int64) error {
@@ -604,6 +1473,11 @@ func (c *SlotWatcher) fetchAndEmitInflationRewards(ctx context.Context, epoch in
 			c.logger.Debugf("Reward info is zero value for address %s at index %d", address, i)
 			continue
 		}
+		// the poller may have emitted it already:
+		if !c.markInflationRewardEmitted(address, epoch) {
+			c.logger.Debugf("Already emitted reward for %s in epoch %s", address, toString(epoch))
+			continue
+		}
 		reward := float64(rewardInfo.Amount) / rpc.LamportsInSol
 		c.logger.Debugf("About to add reward %f SOL for address %s in epoch %s", reward, address, toString(epoch))
 		func() {
@@ -627,6 +1501,19 @@ func (c *SlotWatcher) deleteMetricLabelValues(metric *prometheus.CounterVec, nam
 	}
 }
 
+// markInflationRewardEmitted marks the inflation reward of the votekey at the epoch as emitted, returning false if it
+// already was, such that each reward is only added to the counter once.
+func (c *SlotWatcher) markInflationRewardEmitted(votekey string, epoch int64) bool {
+	c.inflationRewardsMu.Lock()
+	defer c.inflationRewardsMu.Unlock()
+	key := votekey + "-" + toString(epoch)
+	if _, ok := c.emittedInflationRewards[key]; ok {
+		return false
+	}
+	c.emittedInflationRewards[key] = struct{}{}
+	return true
+}
+
 // Polling goroutine for inflation rewards
 func (c *SlotWatcher) pollInflationRewards(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Minute)
@@ -673,15 +1560,14 @@ func (c *SlotWatcher) fetchAndEmitInflationRewardsWithDedup(ctx context.Context,
 			continue
 		}
 		address := c.config.VoteKeys[i]
-		key := address + "-" + toString(epoch)
-		if _, already := c.emittedInflationRewards[key]; already {
-			c.logger.Debugf("Polling: Already emitted reward for %s in epoch %s", address, toString(epoch))
-			continue
-		}
 		if rewardInfo.Amount == 0 && rewardInfo.Epoch == 0 {
 			c.logger.Debugf("Polling: Reward info is zero value for address %s at index %d", address, i)
 			continue
 		}
+		if !c.markInflationRewardEmitted(address, epoch) {
+			c.logger.Debugf("Polling: Already emitted reward for %s in epoch %s", address, toString(epoch))
+			continue
+		}
 		reward := float64(rewardInfo.Amount) / rpc.LamportsInSol
 		c.logger.Debugf("Polling: About to add reward %f SOL for address %s in epoch %s", reward, address, toString(epoch))
 		func() {
@@ -692,7 +1578,6 @@ func (c *SlotWatcher) fetchAndEmitInflationRewardsWithDedup(ctx context.Context,
 			}()
 			c.InflationRewardsMetric.WithLabelValues(address, toString(epoch)).Add(reward)
 		}()
-		c.emittedInflationRewards[key] = struct{}{}
 		c.logger.Debugf("Polling: Added reward metric with labels address=%s, epoch=%s", address, toString(epoch))
 	}
 	c.logger.Infof("Polling: Fetched inflation reward for epoch %v.", epoch)
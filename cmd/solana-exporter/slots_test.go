@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/solana"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -22,9 +23,9 @@ type slotMetricValues struct {
 
 func getSlotMetricValues(watcher *SlotWatcher) slotMetricValues {
 	return slotMetricValues{
-		SlotHeight:        testutil.ToFloat64(watcher.SlotHeightMetric),
+		SlotHeight:        testutil.ToFloat64(watcher.SlotHeightMetric.WithLabelValues(string(rpc.CommitmentFinalized))),
 		TotalTransactions: testutil.ToFloat64(watcher.TotalTransactionsMetric),
-		EpochNumber:       testutil.ToFloat64(watcher.EpochNumberMetric),
+		EpochNumber:       testutil.ToFloat64(watcher.EpochNumberMetric.WithLabelValues(string(rpc.CommitmentFinalized))),
 		EpochFirstSlot:    testutil.ToFloat64(watcher.EpochFirstSlotMetric),
 		EpochLastSlot:     testutil.ToFloat64(watcher.EpochLastSlotMetric),
 		BlockHeight:       testutil.ToFloat64(watcher.BlockHeightMetric),
@@ -71,16 +72,28 @@ func assertSlotMetricsChangeCorrectly(t *testing.T, initial slotMetricValues, fi
 	)
 }
 
+func TestNewSlotWatcher_registerer(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+
+	// each watcher registers its metrics with its own registry, without clashing with the others:
+	for range 2 {
+		registry := prometheus.NewPedanticRegistry()
+		watcher := NewSlotWatcher(client, config, registry)
+		watcher.SlotHeightMetric.WithLabelValues(string(rpc.CommitmentFinalized)).Set(42)
+		count, err := testutil.GatherAndCount(registry, "solana_node_slot_height")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	}
+}
+
 func TestSlotWatcher_WatchSlots_Static(t *testing.T) {
 	// TODO: is this test necessary? If not - remove, else, could definitely do with a clean.
 
 	ctx := context.Background()
 
 	simulator, client := NewSimulator(t, 35)
-	watcher := NewSlotWatcher(client, newTestConfig(simulator, true))
-	// reset metrics before running tests:
-	watcher.LeaderSlotsMetric.Reset()
-	watcher.LeaderSlotsByEpochMetric.Reset()
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
 
 	go watcher.WatchSlots(ctx)
 
@@ -98,11 +111,12 @@ func TestSlotWatcher_WatchSlots_Static(t *testing.T) {
 	}
 
 	// epoch info tests:
-	firstSlot, lastSlot := GetEpochBounds(epochInfo)
+	firstSlot, lastSlot := solana.GetEpochBounds(epochInfo)
+	finalized := string(rpc.CommitmentFinalized)
 	tests := []testCase{
-		{"slot_height", float64(epochInfo.AbsoluteSlot), watcher.SlotHeightMetric},
+		{"slot_height", float64(epochInfo.AbsoluteSlot), watcher.SlotHeightMetric.WithLabelValues(finalized)},
 		{"total_transactions", float64(epochInfo.TransactionCount), watcher.TotalTransactionsMetric},
-		{"epoch_number", float64(epochInfo.Epoch), watcher.EpochNumberMetric},
+		{"epoch_number", float64(epochInfo.Epoch), watcher.EpochNumberMetric.WithLabelValues(finalized)},
 		{"epoch_first_slot", float64(firstSlot), watcher.EpochFirstSlotMetric},
 		{"epoch_last_slot", float64(lastSlot), watcher.EpochLastSlotMetric},
 	}
@@ -134,10 +148,7 @@ func TestSlotWatcher_WatchSlots_Dynamic(t *testing.T) {
 
 	// create clients:
 	simulator, client := NewSimulator(t, 23)
-	watcher := NewSlotWatcher(client, newTestConfig(simulator, true))
-	// reset metrics before running tests:
-	watcher.LeaderSlotsMetric.Reset()
-	watcher.LeaderSlotsByEpochMetric.Reset()
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
 
 	// start client/collector and wait a bit:
 	ctx, cancel := context.WithCancel(context.Background())
@@ -218,24 +229,6 @@ func TestSlotWatcher_WatchSlots_Dynamic(t *testing.T) {
 			// now test per validator:
 			leaderSlotsPerEpoch := simulator.EpochSize / len(simulator.Nodekeys)
 			for i, nodekey := range simulator.Nodekeys {
-				// leader slots per epoch:
-				assert.Equalf(t,
-					float64(leaderSlotsPerEpoch*3/4),
-					testutil.ToFloat64(
-						watcher.LeaderSlotsByEpochMetric.WithLabelValues(nodekey, epochStr, StatusValid),
-					),
-					"Incorrect %s leader slots for %s at epoch %s",
-					StatusValid, nodekey, epochStr,
-				)
-				assert.Equalf(t,
-					float64(leaderSlotsPerEpoch*1/4),
-					testutil.ToFloat64(
-						watcher.LeaderSlotsByEpochMetric.WithLabelValues(nodekey, epochStr, StatusSkipped),
-					),
-					"Incorrect %s leader slots for %s at epoch %s",
-					StatusSkipped, nodekey, epochStr,
-				)
-
 				// inflation rewards:
 				votekey := simulator.Votekeys[i]
 				assert.Equalf(t,
@@ -273,10 +266,7 @@ func TestSlotWatcher_cleanUpEpoch(t *testing.T) {
 	// set the cleanup time to 0 such that epochs are instantly cleaned up.
 	config := newTestConfig(simulator, true)
 	config.EpochCleanupTime = time.Duration(0)
-	watcher := NewSlotWatcher(client, config)
-	// reset metrics before running tests:
-	watcher.LeaderSlotsMetric.Reset()
-	watcher.LeaderSlotsByEpochMetric.Reset()
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
 
 	// start client/collector and wait a bit:
 	ctx, cancel := context.WithCancel(context.Background())
@@ -288,12 +278,12 @@ func TestSlotWatcher_cleanUpEpoch(t *testing.T) {
 	time.Sleep(time.Second)
 
 	var (
-		initialEpoch = testutil.ToFloat64(watcher.EpochNumberMetric)
+		initialEpoch = testutil.ToFloat64(watcher.EpochNumberMetric.WithLabelValues(string(rpc.CommitmentFinalized)))
 		currentEpoch float64
 	)
 	for {
 		time.Sleep(10 * time.Millisecond)
-		currentEpoch = testutil.ToFloat64(watcher.EpochNumberMetric)
+		currentEpoch = testutil.ToFloat64(watcher.EpochNumberMetric.WithLabelValues(string(rpc.CommitmentFinalized)))
 		if currentEpoch > initialEpoch {
 			break
 		}
@@ -312,9 +302,6 @@ func TestSlotWatcher_cleanUpEpoch(t *testing.T) {
 		// rewards:
 		counters = append(counters, watcher.FeeRewardsMetric.WithLabelValues(nodekey, epochStr))
 		counters = append(counters, watcher.InflationRewardsMetric.WithLabelValues(simulator.Votekeys[i], epochStr))
-		// validator leader slots:
-		counters = append(counters, watcher.LeaderSlotsByEpochMetric.WithLabelValues(nodekey, epochStr, StatusValid))
-		counters = append(counters, watcher.LeaderSlotsByEpochMetric.WithLabelValues(nodekey, epochStr, StatusSkipped))
 	}
 
 	var expected float64
@@ -322,3 +309,348 @@ func TestSlotWatcher_cleanUpEpoch(t *testing.T) {
 		assert.Equal(t, expected, testutil.ToFloat64(counter))
 	}
 }
+
+func TestSlotWatcher_reconcileBlockProduction(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	config.ReconcileBlockProduction = true
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	watcher.firstSlot, watcher.lastSlot = solana.GetEpochBounds(epochInfo)
+
+	// the simulator serves consistent block production and blocks:
+	watcher.reconcileBlockProduction(ctx, watcher.firstSlot, epochInfo.AbsoluteSlot)
+	assert.Equal(t, float64(0), testutil.ToFloat64(watcher.BlockProductionMismatchMetric))
+}
+
+func TestSlotWatcher_EpochRebuild(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	ctx := context.Background()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+
+	for _, rebuild := range []bool{false, true} {
+		t.Run(fmt.Sprintf("rebuild=%v", rebuild), func(t *testing.T) {
+			config := newTestConfig(simulator, true)
+			config.EpochRebuild = rebuild
+			watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+			watcher.trackEpoch(ctx, epochInfo)
+			watcher.moveSlotWatermark(ctx, epochInfo.AbsoluteSlot)
+
+			// aaa produced slots 24-26 (and skipped 27) before the exporter started at slot 35:
+			expected := 0.0
+			if rebuild {
+				expected = float64(3*simulator.FeeRewardLamports) / rpc.LamportsInSol
+			}
+			fees := watcher.FeeRewardsMetric.WithLabelValues("aaa", "1")
+			assert.InDelta(t, expected, testutil.ToFloat64(fees), 1e-12)
+		})
+	}
+}
+
+func TestSubscriptionFeed_Live(t *testing.T) {
+	var feed subscriptionFeed
+	now := time.Unix(1_700_000_000, 0)
+	assert.False(t, feed.Live(now, 3*time.Second))
+
+	feed.Observe(now)
+	assert.True(t, feed.Live(now.Add(2*time.Second), 3*time.Second))
+	// a subscription which stopped notifying without dropping is stale:
+	assert.False(t, feed.Live(now.Add(3*time.Second), 3*time.Second))
+
+	feed.Observe(now)
+	feed.Reset()
+	assert.False(t, feed.Live(now, 3*time.Second))
+}
+
+func TestSlotWatcher_subscriptionLive(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	now := time.Now()
+	assert.False(t, watcher.subscriptionLive(now))
+
+	watcher.slotSubscription.Observe(now)
+	watcher.geyserSubscription.Observe(now)
+	// the geyser subscription dropping mustn't resume polling while the slot subscription is live:
+	watcher.geyserSubscription.Reset()
+	assert.True(t, watcher.subscriptionLive(now))
+	watcher.slotSubscription.Reset()
+	assert.False(t, watcher.subscriptionLive(now))
+}
+
+func TestSlotWatcher_emitConfirmedSlotMetrics(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, true)
+	config.ConfirmedSlotMetrics = true
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+
+	ctx := context.Background()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentConfirmed)
+	assert.NoError(t, err)
+
+	watcher.emitConfirmedSlotMetrics(ctx)
+	confirmed := string(rpc.CommitmentConfirmed)
+	assert.Equal(t,
+		float64(epochInfo.AbsoluteSlot), testutil.ToFloat64(watcher.SlotHeightMetric.WithLabelValues(confirmed)),
+	)
+	assert.Equal(t, float64(epochInfo.Epoch), testutil.ToFloat64(watcher.EpochNumberMetric.WithLabelValues(confirmed)))
+}
+
+func TestSlotWatcher_emitSkipRate(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	watcher.firstSlot = 100
+	// the last leader slots of the previous epoch are ignored:
+	watcher.processedLeaderSlots = map[int64]struct{}{96: {}, 100: {}, 101: {}, 102: {}}
+	watcher.skippedLeaderSlots = map[int64]struct{}{97: {}, 98: {}, 103: {}}
+
+	watcher.emitSkipRate()
+	assert.Equal(t, float64(25), testutil.ToFloat64(watcher.SkipRateGauge))
+}
+
+func TestSlotWatcher_emitLeaderSlotsByPosition(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	watcher.firstSlot = 100
+	watcher.processedLeaderSlots = map[int64]struct{}{100: {}, 101: {}, 102: {}, 104: {}}
+	watcher.skippedLeaderSlots = map[int64]struct{}{103: {}, 107: {}}
+
+	watcher.emitLeaderSlotsByPosition()
+	for position, expected := range map[string][]float64{"1": {2, 0}, "2": {1, 0}, "3": {1, 0}, "4": {0, 2}} {
+		assert.Equal(t,
+			expected[0], testutil.ToFloat64(watcher.LeaderSlotsByPositionEpochGauge.WithLabelValues(position, StatusValid)),
+		)
+		assert.Equal(t,
+			expected[1], testutil.ToFloat64(watcher.LeaderSlotsByPositionEpochGauge.WithLabelValues(position, StatusSkipped)),
+		)
+	}
+}
+
+func TestSlotWatcher_emitSkipStreaks(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, false), prometheus.NewRegistry())
+	watcher.firstSlot = 100
+	watcher.processedLeaderSlots = map[int64]struct{}{100: {}, 104: {}}
+	watcher.skippedLeaderSlots = map[int64]struct{}{101: {}, 102: {}, 103: {}, 105: {}}
+
+	watcher.emitSkipStreaks()
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.SkipStreakGauge))
+	assert.Equal(t, float64(3), testutil.ToFloat64(watcher.MaxSkipStreakEpochGauge))
+}
+
+func TestSlotWatcher_emitSubscribedBlock(t *testing.T) {
+	simulator, client := NewSimulator(t, 40)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	ctx := context.Background()
+	watcher.currentEpoch, watcher.firstSlot, watcher.lastSlot, watcher.slotWatermark = 1, 24, 47, 35
+	schedule, err := GetTrimmedLeaderSchedule(ctx, client, simulator.Nodekeys, 24, 24)
+	assert.NoError(t, err)
+	watcher.leaderSchedule = schedule
+
+	feeReward := func(slot int64) nodekeyBlockNotification {
+		block := &rpc.Block{
+			Rewards: []rpc.BlockReward{{Pubkey: "aaa", Lamports: int64(simulator.FeeRewardLamports), RewardType: "fee"}},
+		}
+		return nodekeyBlockNotification{"aaa", rpc.BlockNotification{Slot: slot, Block: block}, time.Now()}
+	}
+	fees := watcher.FeeRewardsMetric.WithLabelValues("aaa", "1")
+	fee := float64(simulator.FeeRewardLamports) / rpc.LamportsInSol
+
+	watcher.slotClock.Anchor(&rpc.Clock{Slot: 35, UnixTimestamp: time.Now().Unix()})
+	watcher.emitSubscribedBlock(feeReward(36))
+	assert.Equal(t, fee, testutil.ToFloat64(fees))
+	assert.Equal(t, 1, testutil.CollectAndCount(watcher.BlockPropagationDelayMetric))
+	// duplicates, already polled slots and other validators' slots are ignored:
+	watcher.emitSubscribedBlock(feeReward(36))
+	watcher.emitSubscribedBlock(feeReward(25))
+	watcher.emitSubscribedBlock(feeReward(40))
+	assert.Equal(t, fee, testutil.ToFloat64(fees))
+
+	// polling skips the subscribed block, and only fetches the remaining one:
+	watcher.fetchAndEmitBlockInfos(ctx, 36, 37)
+	assert.Equal(t, 2*fee, testutil.ToFloat64(fees))
+	assert.Empty(t, watcher.subscribedBlocks)
+}
+
+func TestSlotWatcher_fetchAndEmitBlockInfos(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	ctx := context.Background()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	config := newTestConfig(simulator, true)
+	// every leader slot is a request, which mustn't time out when the whole suite is running:
+	config.HttpTimeout = 10 * time.Second
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+	watcher.trackEpoch(ctx, epochInfo)
+
+	// the skipped slot 27 is pruned by getBlocks, rather than fetched:
+	watcher.fetchAndEmitBlockInfos(ctx, 24, 29)
+	var fetched float64
+	for _, nodekey := range simulator.Nodekeys {
+		fetched += testutil.ToFloat64(watcher.BlockFetchesMetric.WithLabelValues(nodekey, BlockResultFetched))
+		skipped := watcher.BlockFetchesMetric.WithLabelValues(nodekey, BlockResultSkipped)
+		assert.Equal(t, float64(0), testutil.ToFloat64(skipped))
+	}
+	assert.Equal(t, float64(5), fetched)
+}
+
+func TestSlotWatcher_emitStakeConcentration(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	voteAccounts, err := client.GetVoteAccounts(context.Background(), rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	watcher.emitStakeConcentration(voteAccounts)
+
+	// the simulated validators are equally staked:
+	for _, percent := range []string{"10", "20", "33"} {
+		share := testutil.ToFloat64(watcher.TopStakeShareGauge.WithLabelValues(percent))
+		assert.InDelta(t, 1.0/3, share, 1e-9)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(watcher.NakamotoCoefficientGauge))
+}
+
+func TestSlotWatcher_emitStakeChanges(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	newVoteAccounts := func(stakes ...int64) *rpc.VoteAccounts {
+		var voteAccounts rpc.VoteAccounts
+		for i, stake := range stakes {
+			voteAccounts.Current = append(
+				voteAccounts.Current,
+				rpc.VoteAccount{VotePubkey: simulator.Votekeys[i], ActivatedStake: stake * rpc.LamportsInSol},
+			)
+		}
+		return &voteAccounts
+	}
+
+	// the first epoch tracked has nothing to compare with:
+	watcher.emitStakeChanges(newVoteAccounts(10, 20, 30), 1)
+	assert.Equal(t, 0, testutil.CollectAndCount(watcher.StakeChangeGauge))
+
+	// the last vote account is missing from the vote accounts, which is no evidence of its stake having changed:
+	watcher.emitStakeChanges(newVoteAccounts(15, 5), 2)
+	for votekey, expected := range map[string]float64{"AAA": 5, "BBB": -15} {
+		assert.Equal(t, expected, testutil.ToFloat64(watcher.StakeChangeGauge.WithLabelValues(votekey, "2")))
+	}
+	assert.Equal(t, 2, testutil.CollectAndCount(watcher.StakeChangeGauge))
+
+	// after missing an epoch, there is nothing to compare with either:
+	watcher.emitStakeChanges(newVoteAccounts(20, 10, 30), 4)
+	assert.Equal(t, 2, testutil.CollectAndCount(watcher.StakeChangeGauge))
+	watcher.emitStakeChanges(newVoteAccounts(25, 10, 30), 5)
+	for votekey, expected := range map[string]float64{"AAA": 5, "BBB": 0, "CCC": 0} {
+		assert.Equal(t, expected, testutil.ToFloat64(watcher.StakeChangeGauge.WithLabelValues(votekey, "5")))
+	}
+}
+
+func TestSlotWatcher_emitSuperminority(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	watcher.nodekeyStakes = map[string]int64{"aaa": 100, "bbb": 300, "ccc": 200, "xxx": 400}
+
+	watcher.emitSuperminority()
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.SuperminoritySizeGauge))
+	for nodekey, expected := range map[string]float64{"aaa": 0, "bbb": 0, "ccc": 0} {
+		assert.Equal(t, expected, testutil.ToFloat64(watcher.InSuperminorityGauge.WithLabelValues(nodekey)))
+	}
+
+	watcher.nodekeyStakes["bbb"] = 500
+	watcher.emitSuperminority()
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.InSuperminorityGauge.WithLabelValues("bbb")))
+}
+
+func TestSlotWatcher_emitClusterSkipRate(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, true), prometheus.NewRegistry())
+	ctx := context.Background()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	watcher.firstSlot, watcher.lastSlot = solana.GetEpochBounds(epochInfo)
+
+	// without the epoch stakes, there is nothing to weight by:
+	watcher.emitClusterSkipRate(ctx, epochInfo.AbsoluteSlot)
+	assert.Equal(t, float64(0), testutil.ToFloat64(watcher.ClusterSkipRateGauge))
+
+	// every validator skips the last of its 4 leader slots:
+	watcher.nodekeyStakes = map[string]int64{"aaa": 100, "bbb": 200, "ccc": 300}
+	watcher.emitClusterSkipRate(ctx, epochInfo.AbsoluteSlot)
+	assert.Equal(t, float64(25), testutil.ToFloat64(watcher.ClusterSkipRateGauge))
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.RelativeSkipRateGauge))
+}
+
+func TestSlotWatcher_sampleVoteInclusion(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	ctx := context.Background()
+	epochInfo, err := client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	assert.NoError(t, err)
+	config := newTestConfig(simulator, true)
+	config.VoteInclusionSampleInterval = 3
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+	watcher.trackEpoch(ctx, epochInfo)
+
+	// samples slots 24 (aaa), 27 (skipped), 30 (bbb) and 33 (ccc), all of which include every vote:
+	watcher.sampleVoteInclusion(ctx, 24, 35)
+	for _, leader := range []string{"aaa", "bbb", "ccc"} {
+		assert.Equal(t, float64(1), testutil.ToFloat64(watcher.VoteInclusionSampledBlocksMetric.WithLabelValues(leader)))
+		for _, votekey := range simulator.Votekeys {
+			included := watcher.VotesIncludedMetric.WithLabelValues(votekey, leader)
+			assert.Equal(t, float64(1), testutil.ToFloat64(included))
+		}
+	}
+}
+
+func TestGetBlockFetchResult(t *testing.T) {
+	assert.Equal(t, BlockResultFetched, GetBlockFetchResult(nil))
+	assert.Equal(t, BlockResultSkipped, GetBlockFetchResult(&rpc.Error{Code: rpc.SlotSkippedCode}))
+	assert.Equal(t, BlockResultSkipped, GetBlockFetchResult(&rpc.Error{Code: rpc.LongTermStorageSlotSkippedCode}))
+	assert.Equal(t, BlockResultNotAvailable, GetBlockFetchResult(&rpc.Error{Code: rpc.BlockNotAvailableCode}))
+	assert.Equal(t, BlockResultNotAvailable, GetBlockFetchResult(&rpc.Error{Code: rpc.BlockStatusNotYetAvailableCode}))
+	assert.Equal(t, BlockResultPruned, GetBlockFetchResult(&rpc.Error{Code: rpc.BlockCleanedUpCode}))
+	assert.Equal(t, BlockResultFailed, GetBlockFetchResult(&rpc.Error{Code: rpc.NodeUnhealthyCode}))
+	assert.Equal(t, BlockResultFailed, GetBlockFetchResult(context.DeadlineExceeded))
+}
+
+func TestSlotWatcher_fetchAndEmitSingleBlockInfo(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	ctx := context.Background()
+	watcher := NewSlotWatcher(client, newTestConfig(simulator, false), prometheus.NewRegistry())
+	fetches := func(nodekey, result string) float64 {
+		return testutil.ToFloat64(watcher.BlockFetchesMetric.WithLabelValues(nodekey, result))
+	}
+
+	assert.NoError(t, watcher.fetchAndEmitSingleBlockInfo(ctx, "aaa", 1, 24))
+	assert.NoError(t, watcher.fetchAndEmitSingleBlockInfo(ctx, "aaa", 1, 27))
+	// slots the simulator has no block for are reported as cleaned up:
+	assert.Error(t, watcher.fetchAndEmitSingleBlockInfo(ctx, "ccc", 1, 1000))
+	assert.Equal(t, float64(1), fetches("aaa", BlockResultFetched))
+	assert.Equal(t, float64(1), fetches("aaa", BlockResultSkipped))
+	assert.Equal(t, float64(1), fetches("ccc", BlockResultPruned))
+	assert.Empty(t, watcher.pendingBlocks)
+
+	// blocks which are not available yet are retried, up to a limit:
+	_, unavailableClient := rpc.NewMockClient(t,
+		nil, map[string]*rpc.Error{"getBlock": {Code: rpc.BlockNotAvailableCode, Message: "Block not available"}},
+		nil, nil, nil, nil,
+	)
+	watcher.client = unavailableClient
+	assert.NoError(t, watcher.fetchAndEmitSingleBlockInfo(ctx, "bbb", 1, 28))
+	assert.Equal(t, &pendingBlock{nodekey: "bbb", epoch: 1, attempts: 1}, watcher.pendingBlocks[28])
+	for range maxBlockFetchRetries - 1 {
+		watcher.retryPendingBlocks(ctx)
+	}
+	assert.Contains(t, watcher.pendingBlocks, int64(28))
+	watcher.retryPendingBlocks(ctx)
+	assert.Empty(t, watcher.pendingBlocks)
+	assert.Equal(t, float64(maxBlockFetchRetries+1), fetches("bbb", BlockResultNotAvailable))
+
+	// and emitted once available:
+	watcher.client = client
+	watcher.pendingBlocks[28] = &pendingBlock{nodekey: "bbb", epoch: 1, attempts: 1}
+	watcher.retryPendingBlocks(ctx)
+	assert.Empty(t, watcher.pendingBlocks)
+	assert.Equal(t, float64(1), fetches("bbb", BlockResultFetched))
+}
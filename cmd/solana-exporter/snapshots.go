@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+const (
+	snapshotPrefix = "vote-accounts-"
+	snapshotSuffix = ".json.gz"
+)
+
+type (
+	// ValidatorSetSnapshotter writes the full getVoteAccounts response at the start of each epoch to a gzipped JSON
+	// file in a directory, as a local record of the cluster composition over time (which would be far too high in
+	// cardinality as metrics). Only the snapshots of the latest retention epochs are kept, or all if retention is 0.
+	ValidatorSetSnapshotter struct {
+		client    *rpc.Client
+		dir       string
+		retention int
+		logger    *zap.SugaredLogger
+	}
+
+	// ValidatorSetSnapshot is the content of a snapshot file.
+	ValidatorSetSnapshot struct {
+		Epoch     int64 `json:"epoch"`
+		Timestamp int64 `json:"timestamp"`
+		// VoteAccounts is the getVoteAccounts result as returned by the RPC, including fields the exporter ignores
+		VoteAccounts json.RawMessage `json:"voteAccounts"`
+	}
+)
+
+func NewValidatorSetSnapshotter(client *rpc.Client, dir string, retention int) *ValidatorSetSnapshotter {
+	return &ValidatorSetSnapshotter{client: client, dir: dir, retention: retention, logger: slog.Get()}
+}
+
+// SnapshotPath returns the path of the snapshot of the epoch.
+func (s *ValidatorSetSnapshotter) SnapshotPath(epoch int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%d%s", snapshotPrefix, epoch, snapshotSuffix))
+}
+
+// Run snapshots the epoch (unless it already was, e.g., before a restart) and prunes the expired snapshots, logging
+// any failure.
+func (s *ValidatorSetSnapshotter) Run(ctx context.Context, epoch int64) {
+	if _, err := os.Stat(s.SnapshotPath(epoch)); err == nil {
+		s.logger.Infof("Validator set of epoch %d already snapshotted", epoch)
+	} else if err := s.Snapshot(ctx, epoch, time.Now()); err != nil {
+		s.logger.Errorf("Failed to snapshot validator set of epoch %d: %v", epoch, err)
+	}
+	if err := s.Prune(); err != nil {
+		s.logger.Errorf("Failed to prune validator set snapshots: %v", err)
+	}
+}
+
+// Snapshot writes the current validator set as the snapshot of the epoch. The file is written under a temporary
+// name and then renamed, such that readers never see a partial snapshot.
+func (s *ValidatorSetSnapshotter) Snapshot(ctx context.Context, epoch int64, now time.Time) error {
+	config := map[string]string{"commitment": string(rpc.CommitmentFinalized)}
+	voteAccounts, err := rpc.Call[json.RawMessage](ctx, s.client, "getVoteAccounts", []any{config})
+	if err != nil {
+		return fmt.Errorf("failed to get vote accounts: %w", err)
+	}
+
+	file, err := os.CreateTemp(s.dir, snapshotPrefix+"*.tmp")
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.Remove(file.Name())
+	writer := gzip.NewWriter(file)
+	snapshot := ValidatorSetSnapshot{Epoch: epoch, Timestamp: now.Unix(), VoteAccounts: voteAccounts}
+	if err := json.NewEncoder(writer).Encode(snapshot); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(file.Name(), s.SnapshotPath(epoch)); err != nil {
+		return err
+	}
+	s.logger.Infof("Snapshotted validator set of epoch %d to %s", epoch, s.SnapshotPath(epoch))
+	return nil
+}
+
+// Prune deletes the snapshots of all but the latest retention epochs.
+func (s *ValidatorSetSnapshotter) Prune() error {
+	if s.retention == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	var epochs []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+		epoch, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		epochs = append(epochs, epoch)
+	}
+	if len(epochs) <= s.retention {
+		return nil
+	}
+	slices.Sort(epochs)
+	for _, epoch := range epochs[:len(epochs)-s.retention] {
+		if err := os.Remove(s.SnapshotPath(epoch)); err != nil {
+			return err
+		}
+		s.logger.Infof("Deleted expired validator set snapshot of epoch %d", epoch)
+	}
+	return nil
+}
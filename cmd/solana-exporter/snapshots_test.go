@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorSetSnapshotter(t *testing.T) {
+	_, client := NewSimulator(t, 35)
+	snapshotter := NewValidatorSetSnapshotter(client, t.TempDir(), 2)
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+
+	assert.NoError(t, snapshotter.Snapshot(ctx, 10, now))
+	file, err := os.Open(snapshotter.SnapshotPath(10))
+	assert.NoError(t, err)
+	//goland:noinspection GoUnhandledErrorResult
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	assert.NoError(t, err)
+	var snapshot ValidatorSetSnapshot
+	assert.NoError(t, json.NewDecoder(reader).Decode(&snapshot))
+	assert.Equal(t, int64(10), snapshot.Epoch)
+	assert.Equal(t, now.Unix(), snapshot.Timestamp)
+	var voteAccounts rpc.VoteAccounts
+	assert.NoError(t, json.Unmarshal(snapshot.VoteAccounts, &voteAccounts))
+	assert.Len(t, voteAccounts.Current, 3)
+
+	// only the latest retention epochs are kept:
+	for _, epoch := range []int64{11, 12} {
+		snapshotter.Run(ctx, epoch)
+	}
+	for epoch, kept := range map[int64]bool{10: false, 11: true, 12: true} {
+		_, err := os.Stat(snapshotter.SnapshotPath(epoch))
+		assert.Equal(t, kept, err == nil, "epoch %d", epoch)
+	}
+}
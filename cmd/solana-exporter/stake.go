@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+type (
+	// StakeDelegationScanner periodically scans the stake accounts delegated to the tracked vote accounts, for the
+	// stake moving in and out of them, which getVoteAccounts (only reporting the active stake) does not show. As
+	// scanning the stake accounts is one of the most expensive RPC calls, it runs on its own interval rather than on
+	// every scrape.
+	StakeDelegationScanner struct {
+		client   *rpc.Client
+		votekeys []string
+		interval time.Duration
+		logger   *zap.SugaredLogger
+
+		DelegatedStake    *prometheus.GaugeVec
+		ActivatingStake   *prometheus.GaugeVec
+		DeactivatingStake *prometheus.GaugeVec
+		NextEpochStake    *prometheus.GaugeVec
+		DelegationCount   *prometheus.GaugeVec
+	}
+
+	// DelegationSummary sums up the delegations to a vote account, in lamports.
+	DelegationSummary struct {
+		// Delegated is all stake which is not (fully) deactivated, including the activating and deactivating stake
+		Delegated    int64
+		Activating   int64
+		Deactivating int64
+		// Count is the number of delegations which are not (fully) deactivated
+		Count int
+	}
+)
+
+func NewStakeDelegationScanner(client *rpc.Client, votekeys []string, interval time.Duration) *StakeDelegationScanner {
+	newGaugeVec := func(name, help string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Name: name, Help: fmt.Sprintf(help, VotekeyLabel)}, []string{VotekeyLabel},
+		)
+	}
+	return &StakeDelegationScanner{
+		client:   client,
+		votekeys: votekeys,
+		interval: interval,
+		logger:   slog.Get(),
+		DelegatedStake: newGaugeVec(
+			"solana_validator_delegated_stake",
+			"Stake (in SOL) delegated to a vote account (represented by %s) which is not deactivated, including "+
+				"activating and deactivating stake",
+		),
+		ActivatingStake: newGaugeVec(
+			"solana_validator_activating_stake",
+			"Stake (in SOL) delegated to a vote account (represented by %s) in the current epoch",
+		),
+		DeactivatingStake: newGaugeVec(
+			"solana_validator_deactivating_stake",
+			"Stake (in SOL) deactivated from a vote account (represented by %s) in the current epoch",
+		),
+		NextEpochStake: newGaugeVec(
+			"solana_validator_next_epoch_stake",
+			"Stake (in SOL) expected to be active on a vote account (represented by %s) in the next epoch, "+
+				"once the activating stake warmed up and the deactivating stake cooled down",
+		),
+		DelegationCount: newGaugeVec(
+			"solana_validator_delegation_count",
+			"Number of stake accounts delegated to a vote account (represented by %s) which are not deactivated",
+		),
+	}
+}
+
+// Register registers the scanner metrics with the registerer.
+func (s *StakeDelegationScanner) Register(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{
+		s.DelegatedStake, s.ActivatingStake, s.DeactivatingStake, s.NextEpochStake, s.DelegationCount,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run scans right away and then every interval, until the context is cancelled.
+func (s *StakeDelegationScanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.logger.Infof("Starting stake delegation scans, running every %vs", s.interval.Seconds())
+	for {
+		s.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan scans the delegations to each tracked vote account. The metrics of a vote account are left as they are if its
+// scan fails.
+func (s *StakeDelegationScanner) Scan(ctx context.Context) {
+	epochInfo, err := s.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		s.logger.Errorf("failed to get epoch info for the stake delegation scan: %v", err)
+		return
+	}
+	for _, votekey := range s.votekeys {
+		delegations, err := s.client.GetStakeDelegations(ctx, rpc.CommitmentFinalized, votekey)
+		if err != nil {
+			s.logger.Errorf("failed to scan the stake delegations to %s: %v", votekey, err)
+			continue
+		}
+		summary := SummarizeDelegations(delegations, uint64(epochInfo.Epoch))
+		s.DelegatedStake.WithLabelValues(votekey).Set(float64(summary.Delegated) / rpc.LamportsInSol)
+		s.ActivatingStake.WithLabelValues(votekey).Set(float64(summary.Activating) / rpc.LamportsInSol)
+		s.DeactivatingStake.WithLabelValues(votekey).Set(float64(summary.Deactivating) / rpc.LamportsInSol)
+		s.NextEpochStake.WithLabelValues(votekey).Set(float64(summary.NextEpoch()) / rpc.LamportsInSol)
+		s.DelegationCount.WithLabelValues(votekey).Set(float64(summary.Count))
+	}
+}
+
+// SummarizeDelegations sums up the delegations as of the epoch. Stake delegated (or deactivated) in the epoch is
+// taken as activating (or deactivating) in full, i.e., ignoring the cluster-wide limit on how much stake warms up or
+// cools down per epoch, which only holds back activations of an unusually large share of the cluster's stake.
+func SummarizeDelegations(delegations map[string]*rpc.StakeDelegation, epoch uint64) DelegationSummary {
+	var summary DelegationSummary
+	for _, delegation := range delegations {
+		deactivated := delegation.DeactivationEpoch != math.MaxUint64
+		// skip stake which is fully deactivated, or was deactivated before it ever became active:
+		if deactivated &&
+			(delegation.DeactivationEpoch < epoch || delegation.DeactivationEpoch == delegation.ActivationEpoch) {
+			continue
+		}
+		summary.Delegated += delegation.Stake
+		summary.Count++
+		switch {
+		case deactivated:
+			summary.Deactivating += delegation.Stake
+		// stake active since genesis has the max activation epoch:
+		case delegation.ActivationEpoch >= epoch && delegation.ActivationEpoch != math.MaxUint64:
+			summary.Activating += delegation.Stake
+		}
+	}
+	return summary
+}
+
+// NextEpoch returns the stake which is active in the next epoch, i.e., the delegated stake less the deactivating stake.
+func (s DelegationSummary) NextEpoch() int64 {
+	return s.Delegated - s.Deactivating
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStakeAccount(pubkey, voter string, lamports int64, activationEpoch, deactivationEpoch uint64) map[string]any {
+	return map[string]any{
+		"pubkey": pubkey,
+		"account": map[string]any{
+			"lamports": lamports,
+			"owner":    rpc.StakeProgram,
+			"space":    200,
+			"data": map[string]any{
+				"program": "stake",
+				"parsed": map[string]any{
+					"type": "delegated",
+					"info": map[string]any{
+						"stake": map[string]any{
+							"delegation": map[string]any{
+								"voter":             voter,
+								"stake":             strconv.FormatInt(lamports, 10),
+								"activationEpoch":   strconv.FormatUint(activationEpoch, 10),
+								"deactivationEpoch": strconv.FormatUint(deactivationEpoch, 10),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSummarizeDelegations(t *testing.T) {
+	const epoch = 10
+	delegations := map[string]*rpc.StakeDelegation{
+		"active":       {Stake: 1, ActivationEpoch: 5, DeactivationEpoch: math.MaxUint64},
+		"genesis":      {Stake: 2, ActivationEpoch: math.MaxUint64, DeactivationEpoch: math.MaxUint64},
+		"activating":   {Stake: 4, ActivationEpoch: epoch, DeactivationEpoch: math.MaxUint64},
+		"deactivating": {Stake: 8, ActivationEpoch: 5, DeactivationEpoch: epoch},
+		"deactivated":  {Stake: 16, ActivationEpoch: 5, DeactivationEpoch: epoch - 1},
+		// deactivated in the epoch it was delegated in, so it never becomes active:
+		"cancelled": {Stake: 32, ActivationEpoch: epoch, DeactivationEpoch: epoch},
+	}
+	summary := SummarizeDelegations(delegations, epoch)
+	assert.Equal(t, DelegationSummary{Delegated: 15, Activating: 4, Deactivating: 8, Count: 4}, summary)
+	assert.Equal(t, int64(7), summary.NextEpoch())
+}
+
+func TestStakeDelegationScanner_Scan(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	// the simulator is in epoch 1:
+	simulator.Server.SetOpt(rpc.EasyResultsOpt, "getProgramAccounts", []map[string]any{
+		newStakeAccount("stake1", "AAA", 3*rpc.LamportsInSol, 0, math.MaxUint64),
+		newStakeAccount("stake2", "AAA", 2*rpc.LamportsInSol, 1, math.MaxUint64),
+	})
+	scanner := NewStakeDelegationScanner(client, []string{"AAA"}, time.Minute)
+	assert.NoError(t, scanner.Register(prometheus.NewRegistry()))
+
+	scanner.Scan(context.Background())
+	assert.Equal(t, float64(5), testutil.ToFloat64(scanner.DelegatedStake.WithLabelValues("AAA")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(scanner.ActivatingStake.WithLabelValues("AAA")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(scanner.DeactivatingStake.WithLabelValues("AAA")))
+	assert.Equal(t, float64(5), testutil.ToFloat64(scanner.NextEpochStake.WithLabelValues("AAA")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(scanner.DelegationCount.WithLabelValues("AAA")))
+}
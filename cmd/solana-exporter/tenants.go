@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Tenant is a named group (e.g., a staking provider's customer) of tracked keys, whose metrics are labelled with
+	// the tenant name such that dashboards and alerts can be sliced per tenant.
+	Tenant struct {
+		Name string `yaml:"name"`
+		// Enabled defaults to true, disabled tenants' keys are not tracked at all
+		Enabled          *bool    `yaml:"enabled"`
+		NodeKeys         []string `yaml:"nodekeys"`
+		BalanceAddresses []string `yaml:"balance_addresses"`
+	}
+
+	TenantsConfig struct {
+		Tenants []Tenant `yaml:"tenants"`
+	}
+)
+
+// LoadTenants reads the tenants config file, returning only the enabled tenants.
+func LoadTenants(path string) ([]Tenant, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants config: %w", err)
+	}
+	var config TenantsConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants config: %w", err)
+	}
+
+	var (
+		tenants []Tenant
+		names   []string
+	)
+	for _, tenant := range config.Tenants {
+		if tenant.Name == "" {
+			return nil, fmt.Errorf("tenants config %s has a tenant without a name", path)
+		}
+		if slices.Contains(names, tenant.Name) {
+			return nil, fmt.Errorf("tenants config %s has duplicate tenant %s", path, tenant.Name)
+		}
+		names = append(names, tenant.Name)
+		if tenant.Enabled == nil || *tenant.Enabled {
+			tenants = append(tenants, tenant)
+		}
+	}
+	return tenants, nil
+}
+
+// GetTenantsByKey maps every key of the tenants (nodekeys, their associated votekeys and balance addresses) to the
+// tenant name. The votekeys are provided in the same order as the nodekeys.
+func GetTenantsByKey(tenants []Tenant, nodekeys, votekeys []string) (map[string]string, error) {
+	tenantsByKey := make(map[string]string)
+	for _, tenant := range tenants {
+		for _, key := range CombineUnique(tenant.NodeKeys, tenant.BalanceAddresses) {
+			if other, ok := tenantsByKey[key]; ok && other != tenant.Name {
+				return nil, fmt.Errorf("key %s belongs to both tenant %s and %s", key, other, tenant.Name)
+			}
+			tenantsByKey[key] = tenant.Name
+		}
+	}
+	for i, nodekey := range nodekeys {
+		if tenant, ok := tenantsByKey[nodekey]; ok && i < len(votekeys) {
+			tenantsByKey[votekeys[i]] = tenant
+		}
+	}
+	return tenantsByKey, nil
+}
+
+// SetTenants labels the metrics of the collector's keyedDescs with the tenant owning their key. Only the collector's
+// own descriptors are labelled, not the vecs which the slot, vote and stake watchers update in the background.
+func (c *SolanaCollector) SetTenants(tenantsByKey map[string]string) {
+	for _, desc := range c.keyedDescs {
+		desc.SetTenants(tenantsByKey)
+	}
+}
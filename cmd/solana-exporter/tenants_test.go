@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+tenants:
+  - name: acme
+    nodekeys: [aaa, bbb]
+    balance_addresses: [xxx]
+  - name: globex
+    enabled: false
+    nodekeys: [ccc]
+`), 0o644))
+
+	tenants, err := LoadTenants(path)
+	assert.NoError(t, err)
+	assert.Len(t, tenants, 1)
+	assert.Equal(t, "acme", tenants[0].Name)
+	assert.Equal(t, []string{"aaa", "bbb"}, tenants[0].NodeKeys)
+
+	tenantsByKey, err := GetTenantsByKey(tenants, []string{"aaa", "bbb"}, []string{"AAA", "BBB"})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		map[string]string{"aaa": "acme", "bbb": "acme", "xxx": "acme", "AAA": "acme", "BBB": "acme"},
+		tenantsByKey,
+	)
+
+	// keys cannot be shared between tenants:
+	_, err = GetTenantsByKey(
+		[]Tenant{{Name: "acme", NodeKeys: []string{"aaa"}}, {Name: "globex", NodeKeys: []string{"aaa"}}}, nil, nil,
+	)
+	assert.Error(t, err)
+}
+
+func TestSolanaCollector_SetTenants(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.TenantsByKey = map[string]string{"aaa": "acme", "AAA": "acme", "bbb": "globex", "BBB": "globex"}
+	collector := NewSolanaCollector(client, config, nil)
+	prometheus.NewPedanticRegistry().MustRegister(collector)
+
+	expected := `
+# HELP solana_validator_active_stake Active stake (in SOL) per validator (represented by votekey and nodekey)
+# TYPE solana_validator_active_stake gauge
+solana_validator_active_stake{nodekey="aaa",tenant="acme",votekey="AAA"} 0.001
+solana_validator_active_stake{nodekey="bbb",tenant="globex",votekey="BBB"} 0.001
+solana_validator_active_stake{nodekey="ccc",tenant="",votekey="CCC"} 0.001
+`
+	assert.NoError(t,
+		testutil.CollectAndCompare(collector, bytes.NewBufferString(expected), "solana_validator_active_stake"),
+	)
+	// metrics which are not keyed remain untouched:
+	assert.Equal(t, []string{VersionLabel, ClientLabel}, collector.NodeVersion.VariableLabels)
+	assert.Nil(t, collector.NodeVersion.tenantsByKey)
+}
+
+func TestNewSolanaCollector_keyedDescs(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	collector := NewSolanaCollector(client, newTestConfig(simulator, false), nil)
+
+	// every descriptor keyed by a tenant key must be listed, to be labelled by tenant (and name):
+	value := reflect.ValueOf(collector).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Field(i).CanInterface() {
+			continue
+		}
+		desc, ok := value.Field(i).Interface().(*GaugeDesc)
+		if !ok || !slices.ContainsFunc(desc.VariableLabels, func(label string) bool {
+			return slices.Contains(tenantKeyLabels, label)
+		}) {
+			continue
+		}
+		assert.Contains(t, collector.keyedDescs, desc, "%s is not in the keyed descs", value.Type().Field(i).Name)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+// JitoTipPaymentAccounts are the accounts searchers pay Jito MEV tips to. The Jito tip payment program hands the tips
+// collected in them over to the tip receiver of the leader, which is why the tips paid in a block are the MEV tips
+// earned by its leader.
+var JitoTipPaymentAccounts = []string{
+	"96gYZGLnJYVFmbjzopPSU6QiEV5fGqZNyN9nmNhvrZU5",
+	"HFqU5x63VTqvQss8hp11i4wVV8bD44PvwucfZ2bU7gRe",
+	"Cw8CFyM9FkoMi7K7Crf6HNQqf4uEMzpKw6QNghXLvLkY",
+	"ADaUMid9yfUytqMBgopwjb2DTLSokTSzL1zt6iGPaS49",
+	"DfXygSm4jCyNCybVYYK6DwvWqjKee8pbDmJGcLWNDXjh",
+	"ADuUkR4vqLUMWXxW9gh6D6L8pMSawimctcNZ5pGwDcEt",
+	"DttWaMuVvTiduZRnguLF7jNxTgiMBZ1hyAumKUiL2KRL",
+	"3AVi9Tg9Uo68tJfuvoKvqKNWKc5wPdSSdeBnizKZ6jT",
+}
+
+// GetMevTips returns the Jito MEV tips (in lamports) paid in a block fetched with full transaction details, as the
+// balance increases of the tip payment accounts. Decreases are the tip payment program handing tips over to the tip
+// receiver, and are not tips.
+func GetMevTips(block *rpc.Block) (int64, error) {
+	txData, err := json.Marshal(block.Transactions)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal transactions: %w", err)
+	}
+	var transactions []rpc.FullTransaction
+	if err := json.Unmarshal(txData, &transactions); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	var tips int64
+	for _, tx := range transactions {
+		if tx.Meta == nil || tx.Meta.Err != nil {
+			continue
+		}
+		accounts := tx.Transaction.Message.AccountKeys
+		if loaded := tx.Meta.LoadedAddresses; loaded != nil {
+			accounts = slices.Concat(accounts, loaded.Writable, loaded.Readonly)
+		}
+		for i, account := range accounts {
+			if i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+				break
+			}
+			if slices.Contains(JitoTipPaymentAccounts, account) {
+				tips += max(tx.Meta.PostBalances[i]-tx.Meta.PreBalances[i], 0)
+			}
+		}
+	}
+	return tips, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTipTransaction(accountKeys []string, preBalances, postBalances []int64, loaded map[string]any) map[string]any {
+	meta := map[string]any{"fee": 5000, "err": nil, "preBalances": preBalances, "postBalances": postBalances}
+	if loaded != nil {
+		meta["loadedAddresses"] = loaded
+	}
+	return map[string]any{
+		"transaction": map[string]any{
+			"signatures": []string{""},
+			"message":    map[string]any{"accountKeys": accountKeys},
+		},
+		"meta": meta,
+	}
+}
+
+func TestGetMevTips(t *testing.T) {
+	tipAccount := JitoTipPaymentAccounts[0]
+	failed := newTipTransaction([]string{"xxx", tipAccount}, []int64{100, 0}, []int64{95, 50}, nil)
+	failed["meta"].(map[string]any)["err"] = map[string]any{"InstructionError": []any{0, "Custom"}}
+	block := rpc.Block{
+		Transactions: []map[string]any{
+			newTipTransaction([]string{"xxx", tipAccount}, []int64{100_000, 0}, []int64{85_000, 10_000}, nil),
+			// tipping an account loaded from an address lookup table:
+			newTipTransaction(
+				[]string{"yyy"}, []int64{100_000, 0, 5}, []int64{75_000, 20_000, 5},
+				map[string]any{"writable": []string{JitoTipPaymentAccounts[1]}, "readonly": []string{"zzz"}},
+			),
+			// the tip payment program handing the tips over to the tip receiver:
+			newTipTransaction([]string{"xxx", tipAccount}, []int64{100, 10_000}, []int64{10_095, 0}, nil),
+			failed,
+		},
+	}
+
+	tips, err := GetMevTips(&block)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30_000), tips)
+}
+
+func TestSlotWatcher_emitBlockInfo_MevTips(t *testing.T) {
+	simulator, client := NewSimulator(t, 35)
+	config := newTestConfig(simulator, false)
+	config.MonitorMevTips = true
+	watcher := NewSlotWatcher(client, config, prometheus.NewRegistry())
+	assert.Equal(t, "full", watcher.transactionDetails())
+
+	block := rpc.Block{
+		Rewards: []rpc.BlockReward{{Pubkey: "aaa", Lamports: 10, RewardType: "fee"}},
+		Transactions: []map[string]any{
+			newTipTransaction(
+				[]string{"xxx", JitoTipPaymentAccounts[0]},
+				[]int64{rpc.LamportsInSol, 0}, []int64{0, rpc.LamportsInSol / 2},
+				nil,
+			),
+		},
+	}
+	assert.NoError(t, watcher.emitBlockInfo("aaa", 1, 30, &block))
+	assert.NoError(t, watcher.emitBlockInfo("aaa", 1, 31, &block))
+	assert.Equal(t, float64(1), testutil.ToFloat64(watcher.MevTipsMetric.WithLabelValues("aaa", "1")))
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type (
+	// ErrorTolerance lets each collector fail up to its tolerance of consecutive collections before its metrics are
+	// marked invalid. Meanwhile, the last-known-good samples of the failed metrics are served in their place, such that
+	// a transient RPC failure does not blank out dashboards (and trigger absent-metric alerts).
+	ErrorTolerance struct {
+		defaultTolerance int
+		tolerances       map[string]int
+		collectors       map[string]*collectorSamples
+		mu               sync.Mutex
+	}
+
+	collectorSamples struct {
+		// failures is the number of consecutive collections with failed metrics
+		failures    int
+		lastSuccess time.Time
+		// samples are the last valid samples of each metric of the collector
+		samples map[*prometheus.Desc][]prometheus.Metric
+	}
+)
+
+func NewErrorTolerance(defaultTolerance int, tolerances map[string]int) *ErrorTolerance {
+	return &ErrorTolerance{
+		defaultTolerance: defaultTolerance,
+		tolerances:       tolerances,
+		collectors:       make(map[string]*collectorSamples),
+	}
+}
+
+func (t *ErrorTolerance) tolerance(collector string) int {
+	if tolerance, ok := t.tolerances[collector]; ok {
+		return tolerance
+	}
+	return t.defaultTolerance
+}
+
+// Filter returns the metrics to emit for a collection of the collector at now. While the collector is within its
+// tolerance, the metrics of each failed descriptor (i.e., with any invalid metric) are replaced by its last valid
+// samples, whereas the other descriptors are emitted fresh. The samples of a descriptor are only kept from collections
+// in which all of its metrics were valid. It also returns how long the collector has been failing for, or false if it
+// never succeeded.
+func (t *ErrorTolerance) Filter(
+	collector string, metrics []prometheus.Metric, now time.Time,
+) ([]prometheus.Metric, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.collectors[collector]
+	if !ok {
+		state = &collectorSamples{samples: make(map[*prometheus.Desc][]prometheus.Metric)}
+		t.collectors[collector] = state
+	}
+
+	// group the metrics by descriptor, in the order the descriptors were first collected:
+	var (
+		descs  []*prometheus.Desc
+		byDesc = make(map[*prometheus.Desc][]prometheus.Metric)
+		failed = make(map[*prometheus.Desc]bool)
+	)
+	for _, metric := range metrics {
+		desc := metric.Desc()
+		if _, ok := byDesc[desc]; !ok {
+			descs = append(descs, desc)
+		}
+		byDesc[desc] = append(byDesc[desc], metric)
+		if err := metric.Write(&dto.Metric{}); err != nil {
+			failed[desc] = true
+		}
+	}
+
+	if len(failed) == 0 {
+		state.failures = 0
+		state.lastSuccess = now
+		state.samples = byDesc
+		return metrics, 0, true
+	}
+
+	state.failures++
+	for _, desc := range descs {
+		if !failed[desc] {
+			state.samples[desc] = byDesc[desc]
+		}
+	}
+	if state.failures > t.tolerance(collector) {
+		return metrics, now.Sub(state.lastSuccess), !state.lastSuccess.IsZero()
+	}
+	filtered := make([]prometheus.Metric, 0, len(metrics))
+	for _, desc := range descs {
+		if samples, ok := state.samples[desc]; ok && failed[desc] {
+			filtered = append(filtered, samples...)
+		} else {
+			filtered = append(filtered, byDesc[desc]...)
+		}
+	}
+	return filtered, now.Sub(state.lastSuccess), !state.lastSuccess.IsZero()
+}
+
+// ParseCollectorErrorTolerances parses per-collector overrides of the error tolerance, formatted as 'collector=N'.
+func ParseCollectorErrorTolerances(overrides []string) (map[string]int, error) {
+	tolerances := make(map[string]int)
+	for _, override := range overrides {
+		collector, value, ok := strings.Cut(override, "=")
+		tolerance, err := strconv.Atoi(value)
+		if !ok || collector == "" || err != nil || tolerance < 0 {
+			return nil, fmt.Errorf("invalid collector error tolerance %q, expected 'collector=N'", override)
+		}
+		tolerances[collector] = tolerance
+	}
+	return tolerances, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorTolerance_Filter(t *testing.T) {
+	balance := NewGaugeDesc("balance", "", AddressLabel)
+	version := NewGaugeDesc("version", "")
+	tolerance := NewErrorTolerance(0, map[string]int{"balances": 2})
+	start := time.Unix(1_700_000_000, 0)
+	failure := errors.New("rpc hiccup")
+
+	// failures before any success are passed through:
+	invalid := balance.NewInvalidMetric(failure)
+	metrics, _, ok := tolerance.Filter("balances", []prometheus.Metric{invalid}, start)
+	assert.Equal(t, []prometheus.Metric{invalid}, metrics)
+	assert.False(t, ok)
+
+	valid := []prometheus.Metric{balance.MustNewConstMetric(1, "aaa"), balance.MustNewConstMetric(2, "bbb")}
+	metrics, staleness, ok := tolerance.Filter("balances", valid, start)
+	assert.Equal(t, valid, metrics)
+	assert.Equal(t, time.Duration(0), staleness)
+	assert.True(t, ok)
+
+	// within the tolerance, the last-known-good samples are served instead:
+	for i := 1; i <= 2; i++ {
+		now := start.Add(time.Duration(i) * time.Minute)
+		metrics, staleness, ok = tolerance.Filter("balances", []prometheus.Metric{invalid}, now)
+		assert.ElementsMatch(t, valid, metrics)
+		assert.Equal(t, time.Duration(i)*time.Minute, staleness)
+		assert.True(t, ok)
+	}
+
+	// until it is exceeded:
+	metrics, staleness, _ = tolerance.Filter("balances", []prometheus.Metric{invalid}, start.Add(3*time.Minute))
+	assert.Equal(t, []prometheus.Metric{invalid}, metrics)
+	assert.Equal(t, 3*time.Minute, staleness)
+
+	// a success resets the failures:
+	metrics, staleness, _ = tolerance.Filter("balances", valid, start.Add(4*time.Minute))
+	assert.Equal(t, valid, metrics)
+	assert.Equal(t, time.Duration(0), staleness)
+
+	// other collectors have the default tolerance:
+	_, _, _ = tolerance.Filter("version", []prometheus.Metric{version.MustNewConstMetric(1)}, start)
+	invalid = version.NewInvalidMetric(failure)
+	metrics, _, _ = tolerance.Filter("version", []prometheus.Metric{invalid}, start.Add(time.Minute))
+	assert.Equal(t, []prometheus.Metric{invalid}, metrics)
+}
+
+func TestErrorTolerance_Filter_partialFailure(t *testing.T) {
+	balance := NewGaugeDesc("balance", "", AddressLabel)
+	rentExempt := NewGaugeDesc("rent_exempt", "", AddressLabel)
+	tolerance := NewErrorTolerance(1, nil)
+	start := time.Unix(1_700_000_000, 0)
+
+	tolerance.Filter("balances", []prometheus.Metric{
+		balance.MustNewConstMetric(1, "aaa"), rentExempt.MustNewConstMetric(1, "aaa"),
+	}, start)
+
+	// only the failed metric is replaced, while the fresh samples of the others are kept:
+	freshBalance := balance.MustNewConstMetric(2, "aaa")
+	metrics, _, _ := tolerance.Filter("balances", []prometheus.Metric{
+		freshBalance, rentExempt.NewInvalidMetric(errors.New("rpc hiccup")),
+	}, start.Add(time.Minute))
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, 2.0, getMetricValue(t, metrics[0]))
+	assert.Equal(t, 1.0, getMetricValue(t, metrics[1]))
+	assert.Equal(t, rentExempt.Desc, metrics[1].Desc())
+}
+
+// toleratedCollector collects its metrics through an error tolerance, like SolanaCollector.collectWithCost.
+type toleratedCollector struct {
+	tolerance *ErrorTolerance
+	descs     []*GaugeDesc
+	metrics   []prometheus.Metric
+	now       time.Time
+}
+
+func (c *toleratedCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs {
+		ch <- desc.Desc
+	}
+}
+
+func (c *toleratedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics, _, _ := c.tolerance.Filter("balances", c.metrics, c.now)
+	for _, metric := range metrics {
+		ch <- metric
+	}
+}
+
+func TestErrorTolerance_Filter_registry(t *testing.T) {
+	balance := NewGaugeDesc("solana_account_balance", "Account balance", AddressLabel)
+	rentExempt := NewGaugeDesc("solana_account_rent_exempt", "Account rent exemption", AddressLabel)
+	collector := &toleratedCollector{
+		tolerance: NewErrorTolerance(2, nil),
+		descs:     []*GaugeDesc{balance, rentExempt},
+		now:       time.Unix(1_700_000_000, 0),
+	}
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	collector.metrics = []prometheus.Metric{
+		balance.MustNewConstMetric(1, "aaa"),
+		balance.MustNewConstMetric(2, "bbb"),
+		rentExempt.MustNewConstMetric(1, "aaa"),
+	}
+	_, err := registry.Gather()
+	assert.NoError(t, err)
+
+	// a failure of some of the balances serves all the last-known-good balances (and only those), next to the fresh
+	// rent exemption:
+	collector.metrics = []prometheus.Metric{
+		balance.MustNewConstMetric(10, "aaa"),
+		balance.NewInvalidMetric(errors.New("rpc hiccup")),
+		rentExempt.MustNewConstMetric(0, "aaa"),
+	}
+	collector.now = collector.now.Add(time.Minute)
+	expected := `
+# HELP solana_account_balance Account balance
+# TYPE solana_account_balance gauge
+solana_account_balance{address="aaa"} 1
+solana_account_balance{address="bbb"} 2
+# HELP solana_account_rent_exempt Account rent exemption
+# TYPE solana_account_rent_exempt gauge
+solana_account_rent_exempt{address="aaa"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, bytes.NewBufferString(expected)))
+
+	// the partially failed collection did not overwrite the last-known-good balances:
+	collector.now = collector.now.Add(time.Minute)
+	assert.NoError(t, testutil.GatherAndCompare(registry, bytes.NewBufferString(expected)))
+}
+
+func TestParseCollectorErrorTolerances(t *testing.T) {
+	tolerances, err := ParseCollectorErrorTolerances([]string{"balances=3", "vote_accounts=0"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"balances": 3, "vote_accounts": 0}, tolerances)
+
+	for _, override := range []string{"balances", "=3", "balances=-1", "balances=x"} {
+		_, err = ParseCollectorErrorTolerances([]string{override})
+		assert.Error(t, err, override)
+	}
+}
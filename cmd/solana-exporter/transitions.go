@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stateDurationBuckets spans a minute up to ~45 days, for both short outages and long healthy runs.
+var stateDurationBuckets = prometheus.ExponentialBuckets(60, 4, 9)
+
+type (
+	// StateTracker tracks the state of keys (e.g., the health of the node) over time, recording how long each state
+	// was held once it transitions, for MTTR/MTBF reporting. As the exporter cannot know since when the first observed
+	// state of a key has been held, only the states entered after it are timed.
+	StateTracker struct {
+		states map[string]trackedState
+		mu     sync.Mutex
+	}
+
+	trackedState struct {
+		state string
+		// since is when the state was entered, and is only known (non-zero) after a transition was observed
+		since time.Time
+	}
+)
+
+func NewStateTracker() *StateTracker {
+	return &StateTracker{states: make(map[string]trackedState)}
+}
+
+// Observe records the state of the key at now. If the key transitioned from a state whose start is known, it returns
+// that state and how long it was held.
+func (t *StateTracker) Observe(key, state string, now time.Time) (previous string, held time.Duration, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, seen := t.states[key]
+	if seen && current.state == state {
+		return "", 0, false
+	}
+	if !seen {
+		t.states[key] = trackedState{state: state}
+		return "", 0, false
+	}
+	t.states[key] = trackedState{state: state, since: now}
+	if current.since.IsZero() {
+		return "", 0, false
+	}
+	return current.state, now.Sub(current.since), true
+}
+
+// LastTransition returns when the key last transitioned between states, if it has since the exporter started.
+func (t *StateTracker) LastTransition(key string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, ok := t.states[key]
+	return current.since, ok && !current.since.IsZero()
+}
+
+type (
+	// LagThreshold is a threshold with hysteresis: it is crossed once a value reaches Alert, and only cleared once
+	// the value is back at (or below) Clear. A zero Alert disables the threshold.
+	LagThreshold struct {
+		Alert int64
+		Clear int64
+	}
+
+	// LagAlert is a binary alert on the vote and root distance of a validator, which fires once either distance
+	// crosses its threshold, and clears once both are cleared. Unlike alerting on the raw distances, it does not flap
+	// while a distance jitters around a single threshold.
+	LagAlert struct {
+		voteDistance LagThreshold
+		rootDistance LagThreshold
+		firing       bool
+		mu           sync.Mutex
+	}
+)
+
+// NewLagThreshold returns the threshold of a distance (named for errors), whose clear threshold defaults to half of
+// the alert one.
+func NewLagThreshold(distance string, alert, clear int) (LagThreshold, error) {
+	if alert <= 0 {
+		return LagThreshold{}, nil
+	}
+	if clear == 0 {
+		clear = alert / 2
+	}
+	if clear < 0 || clear >= alert {
+		return LagThreshold{}, fmt.Errorf(
+			"%s clear threshold (%d) must be between 0 and its alert threshold (%d)", distance, clear, alert,
+		)
+	}
+	return LagThreshold{Alert: int64(alert), Clear: int64(clear)}, nil
+}
+
+// Enabled returns whether the threshold is set.
+func (t LagThreshold) Enabled() bool {
+	return t.Alert > 0
+}
+
+func NewLagAlert(voteDistance, rootDistance LagThreshold) *LagAlert {
+	return &LagAlert{voteDistance: voteDistance, rootDistance: rootDistance}
+}
+
+// Observe updates the alert with the current distances, and returns whether it is firing.
+func (a *LagAlert) Observe(voteDistance, rootDistance int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	crossed := func(t LagThreshold, distance int64) bool { return t.Enabled() && distance >= t.Alert }
+	cleared := func(t LagThreshold, distance int64) bool { return !t.Enabled() || distance <= t.Clear }
+	if !a.firing {
+		a.firing = crossed(a.voteDistance, voteDistance) || crossed(a.rootDistance, rootDistance)
+	} else {
+		a.firing = !cleared(a.voteDistance, voteDistance) || !cleared(a.rootDistance, rootDistance)
+	}
+	return a.firing
+}
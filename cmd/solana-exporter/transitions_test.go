@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateTracker_Observe(t *testing.T) {
+	tracker := NewStateTracker()
+	start := time.Unix(1_700_000_000, 0)
+
+	// the start of the first observed state is unknown, so it is not timed:
+	_, _, ok := tracker.Observe("health", StateHealthy, start)
+	assert.False(t, ok)
+	_, ok = tracker.LastTransition("health")
+	assert.False(t, ok)
+
+	_, _, ok = tracker.Observe("health", StateUnhealthy, start.Add(time.Minute))
+	assert.False(t, ok)
+	transition, ok := tracker.LastTransition("health")
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(time.Minute), transition)
+
+	// staying in a state is not a transition:
+	_, _, ok = tracker.Observe("health", StateUnhealthy, start.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	previous, held, ok := tracker.Observe("health", StateHealthy, start.Add(4*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, StateUnhealthy, previous)
+	assert.Equal(t, 3*time.Minute, held)
+	transition, _ = tracker.LastTransition("health")
+	assert.Equal(t, start.Add(4*time.Minute), transition)
+
+	// keys are tracked independently:
+	_, ok = tracker.LastTransition("delinquent/aaa")
+	assert.False(t, ok)
+}
+
+func TestNewLagThreshold(t *testing.T) {
+	threshold, err := NewLagThreshold("vote distance", 150, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, LagThreshold{Alert: 150, Clear: 75}, threshold)
+
+	threshold, err = NewLagThreshold("vote distance", 0, 10)
+	assert.NoError(t, err)
+	assert.False(t, threshold.Enabled())
+
+	_, err = NewLagThreshold("vote distance", 150, 150)
+	assert.Error(t, err)
+}
+
+func TestLagAlert_Observe(t *testing.T) {
+	alert := NewLagAlert(LagThreshold{Alert: 150, Clear: 50}, LagThreshold{})
+
+	// jitter below the threshold does not fire:
+	assert.False(t, alert.Observe(100, 1_000))
+	assert.True(t, alert.Observe(150, 0))
+	// and once firing, jitter between the clear and alert thresholds does not clear it:
+	assert.True(t, alert.Observe(100, 0))
+	assert.True(t, alert.Observe(149, 0))
+	assert.False(t, alert.Observe(50, 0))
+	assert.False(t, alert.Observe(100, 0))
+
+	// either distance fires the alert, and both must clear:
+	alert = NewLagAlert(LagThreshold{Alert: 150, Clear: 50}, LagThreshold{Alert: 100, Clear: 60})
+	assert.True(t, alert.Observe(10, 100))
+	assert.True(t, alert.Observe(10, 70))
+	assert.False(t, alert.Observe(10, 60))
+}
@@ -1,18 +1,34 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"github.com/seedfourtytwo/solana-exporter/pkg/solana"
 )
 
-const VoteProgram = "Vote111111111111111111111111111111111111111"
+const (
+	VoteProgram = "Vote111111111111111111111111111111111111111"
+	// LeaderRotationSlots is the number of consecutive slots assigned to a leader (NUM_CONSECUTIVE_LEADER_SLOTS)
+	LeaderRotationSlots = 4
+	// SlotDuration is the target duration of a slot (DEFAULT_MS_PER_SLOT)
+	SlotDuration = 400 * time.Millisecond
+	// UnknownVersion is the version of nodes which advertise none
+	UnknownVersion = "unknown"
+)
 
 type EpochTrackedValidators struct {
 	trackedNodekeys map[int64]map[string]struct{}
@@ -63,25 +79,52 @@ func assertf(condition bool, format string, args ...any) {
 	}
 }
 
-// toString is just a simple utility function for converting to strings
-func toString(i any) string {
-	return fmt.Sprintf("%v", i)
+// GetLeaderSlotPosition returns the 1-based position of the slot within its leader rotation. Rotations are
+// aligned to the first slot of the epoch.
+func GetLeaderSlotPosition(slot, epochFirstSlot int64) int64 {
+	return solana.ToSlotIndex(slot, epochFirstSlot)%LeaderRotationSlots + 1
 }
 
-// SelectFromSchedule takes a leader-schedule and returns a trimmed leader-schedule
-// containing only the slots within the provided range
-func SelectFromSchedule(schedule map[string][]int64, startSlot, endSlot int64) map[string][]int64 {
-	selected := make(map[string][]int64)
-	for key, values := range schedule {
-		var selectedValues []int64
-		for _, value := range values {
-			if value >= startSlot && value <= endSlot {
-				selectedValues = append(selectedValues, value)
-			}
-		}
-		selected[key] = selectedValues
+// GetSlotTimestampDrift returns the difference (in seconds) between the on-chain unix timestamp of the clock and
+// the timestamp estimated from the epoch start timestamp, assuming every slot since took the target SlotDuration.
+func GetSlotTimestampDrift(clock *rpc.Clock, epochFirstSlot int64) float64 {
+	estimated := float64(clock.EpochStartTimestamp) + float64(clock.Slot-epochFirstSlot)*SlotDuration.Seconds()
+	return float64(clock.UnixTimestamp) - estimated
+}
+
+// GetAverageSlotDuration returns the average duration of the slots of the performance samples, or the target
+// SlotDuration if they hold none.
+func GetAverageSlotDuration(samples []rpc.PerformanceSample) time.Duration {
+	var slots, seconds int64
+	for _, sample := range samples {
+		slots += sample.NumSlots
+		seconds += sample.SamplePeriodSecs
+	}
+	if slots == 0 {
+		return SlotDuration
 	}
-	return selected
+	return time.Duration(seconds) * time.Second / time.Duration(slots)
+}
+
+// GetTransactionRates returns the vote and non-vote transactions per second over the performance samples.
+func GetTransactionRates(samples []rpc.PerformanceSample) (vote float64, nonVote float64) {
+	var transactions, nonVoteTransactions, seconds int64
+	for _, sample := range samples {
+		transactions += sample.NumTransactions
+		nonVoteTransactions += sample.NumNonVoteTransactions
+		seconds += sample.SamplePeriodSecs
+	}
+	if seconds == 0 {
+		return 0, 0
+	}
+	vote = float64(transactions-nonVoteTransactions) / float64(seconds)
+	nonVote = float64(nonVoteTransactions) / float64(seconds)
+	return vote, nonVote
+}
+
+// toString is just a simple utility function for converting to strings
+func toString(i any) string {
+	return fmt.Sprintf("%v", i)
 }
 
 // GetTrimmedLeaderSchedule fetches the leader schedule, but only for the validators we are interested in.
@@ -90,7 +133,16 @@ func GetTrimmedLeaderSchedule(
 	ctx context.Context, client *rpc.Client, identities []string, slot, epochFirstSlot int64,
 ) (map[string][]int64, error) {
 	logger := slog.Get()
-	leaderSchedule, err := client.GetLeaderSchedule(ctx, rpc.CommitmentConfirmed, slot)
+	var (
+		leaderSchedule map[string][]int64
+		err            error
+	)
+	if len(identities) == 1 {
+		// a single identity only needs its own schedule, rather than the whole cluster's:
+		leaderSchedule, err = client.GetLeaderScheduleOf(ctx, rpc.CommitmentConfirmed, slot, identities[0])
+	} else {
+		leaderSchedule, err = client.GetLeaderSchedule(ctx, rpc.CommitmentConfirmed, slot)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get leader schedule: %w", err)
 	}
@@ -101,7 +153,7 @@ func GetTrimmedLeaderSchedule(
 			// when you fetch the leader schedule, it gives you slot indexes, we want absolute slots:
 			absoluteSlots := make([]int64, len(leaderSlots))
 			for i, slotIndex := range leaderSlots {
-				absoluteSlots[i] = slotIndex + epochFirstSlot
+				absoluteSlots[i] = solana.ToAbsoluteSlot(slotIndex, epochFirstSlot)
 			}
 			trimmedLeaderSchedule[id] = absoluteSlots
 		} else {
@@ -112,6 +164,21 @@ func GetTrimmedLeaderSchedule(
 	return trimmedLeaderSchedule, nil
 }
 
+// GetSlotLeaders returns the leader of every slot in the epoch of the provided slot.
+func GetSlotLeaders(ctx context.Context, client *rpc.Client, slot, epochFirstSlot int64) (map[int64]string, error) {
+	leaderSchedule, err := client.GetLeaderSchedule(ctx, rpc.CommitmentConfirmed, slot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leader schedule: %w", err)
+	}
+	slotLeaders := make(map[int64]string)
+	for leader, slotIndexes := range leaderSchedule {
+		for _, slotIndex := range slotIndexes {
+			slotLeaders[solana.ToAbsoluteSlot(slotIndex, epochFirstSlot)] = leader
+		}
+	}
+	return slotLeaders, nil
+}
+
 // GetAssociatedVoteAccounts returns the votekeys associated with a given list of nodekeys
 func GetAssociatedVoteAccounts(
 	ctx context.Context, client *rpc.Client, commitment rpc.Commitment, nodekeys []string,
@@ -138,19 +205,166 @@ func GetAssociatedVoteAccounts(
 	return votekeys, nil
 }
 
-// FetchBalances fetches SOL balances for a list of addresses
+// FetchBalances fetches SOL balances for a list of addresses, in batches of up to rpc.MaxMultipleAccounts addresses
+// per call.
 func FetchBalances(ctx context.Context, client *rpc.Client, addresses []string) (map[string]float64, error) {
 	balances := make(map[string]float64)
-	for _, address := range addresses {
-		balance, err := client.GetBalance(ctx, rpc.CommitmentConfirmed, address)
+	for start := 0; start < len(addresses); start += rpc.MaxMultipleAccounts {
+		batch := addresses[start:min(start+rpc.MaxMultipleAccounts, len(addresses))]
+		batchBalances, err := client.GetMultipleBalances(ctx, rpc.CommitmentConfirmed, batch)
 		if err != nil {
 			return nil, err
 		}
-		balances[address] = balance
+		for i, address := range batch {
+			balances[address] = batchBalances[i]
+		}
 	}
 	return balances, nil
 }
 
+// CountRecentSignatures returns the number of signatures (listed newest first) of transactions after slot.
+func CountRecentSignatures(signatures []rpc.SignatureInfo, slot int64) int {
+	for i, signature := range signatures {
+		if signature.Slot <= slot {
+			return i
+		}
+	}
+	return len(signatures)
+}
+
+// CountBlockProductionMismatches compares block production against the confirmed blocks returned by getBlocks
+// between startSlot and endSlot [inclusive], and returns the number of slots the two sources disagree on: both
+// produced blocks which block production did not account for, and leader slots missing from block production.
+func CountBlockProductionMismatches(
+	production *rpc.BlockProduction, blocks []int64, startSlot, endSlot int64,
+) int64 {
+	var leaderSlots, blocksProduced, confirmedBlocks int64
+	for _, hostProduction := range production.ByIdentity {
+		leaderSlots += hostProduction.LeaderSlots
+		blocksProduced += hostProduction.BlocksProduced
+	}
+	for _, block := range blocks {
+		if block >= startSlot && block <= endSlot {
+			confirmedBlocks++
+		}
+	}
+
+	// every slot in the range has a leader, so any shortfall here means block production is incomplete:
+	missingLeaderSlots := max(0, endSlot-startSlot+1-leaderSlots)
+	blockDifference := confirmedBlocks - blocksProduced
+	if blockDifference < 0 {
+		blockDifference = -blockDifference
+	}
+	return max(missingLeaderSlots, blockDifference)
+}
+
+// FilterProducedSlots returns the slots which may have produced a block, given the (ascending) confirmed blocks
+// getBlocks returned over a range containing them: slots without a block before the latest block were skipped,
+// whereas those after it may just not be confirmed yet. Slots before the node's first available block are kept too,
+// as getBlocks does not return purged blocks, such that their absence does not mean they were skipped.
+func FilterProducedSlots(slots, blocks []int64, firstAvailableBlock int64) []int64 {
+	if len(blocks) == 0 {
+		return slots
+	}
+	latestBlock := blocks[len(blocks)-1]
+	var produced []int64
+	for _, slot := range slots {
+		_, found := slices.BinarySearch(blocks, slot)
+		if found || slot < firstAvailableBlock || slot > latestBlock {
+			produced = append(produced, slot)
+		}
+	}
+	return produced
+}
+
+// SortedActiveStakes returns the active stakes of the staked (current and delinquent) vote accounts, largest first.
+func SortedActiveStakes(voteAccounts *rpc.VoteAccounts) []int64 {
+	var stakes []int64
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if account.ActivatedStake > 0 {
+			stakes = append(stakes, account.ActivatedStake)
+		}
+	}
+	slices.SortFunc(stakes, func(a, b int64) int { return cmp.Compare(b, a) })
+	return stakes
+}
+
+// GetStakesByNodekey returns the activated stake of each validator, by nodekey.
+func GetStakesByNodekey(voteAccounts *rpc.VoteAccounts) map[string]int64 {
+	stakes := make(map[string]int64)
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		stakes[account.NodePubkey] += account.ActivatedStake
+	}
+	return stakes
+}
+
+// SortedStakedVoteAccounts returns the staked (current and delinquent) vote accounts, largest stake first. Equally
+// staked accounts are ordered by vote pubkey, for a stable ranking.
+func SortedStakedVoteAccounts(voteAccounts *rpc.VoteAccounts) []rpc.VoteAccount {
+	var accounts []rpc.VoteAccount
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if account.ActivatedStake > 0 {
+			accounts = append(accounts, account)
+		}
+	}
+	slices.SortFunc(accounts, func(a, b rpc.VoteAccount) int {
+		return cmp.Or(cmp.Compare(b.ActivatedStake, a.ActivatedStake), cmp.Compare(a.VotePubkey, b.VotePubkey))
+	})
+	return accounts
+}
+
+// TopStakeShare returns the share (0-1) of the total stake held by the largest percent% of the (descending) stakes,
+// rounding the number of validators up.
+func TopStakeShare(stakes []int64, percent int) float64 {
+	var total, top int64
+	topCount := (len(stakes)*percent + 99) / 100
+	for i, stake := range stakes {
+		total += stake
+		if i < topCount {
+			top += stake
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(top) / float64(total)
+}
+
+// NakamotoCoefficient returns the smallest number of validators (of the descending stakes) whose combined stake
+// exceeds a third of the total, i.e., who could halt the cluster.
+func NakamotoCoefficient(stakes []int64) int {
+	var total, combined int64
+	for _, stake := range stakes {
+		total += stake
+	}
+	for i, stake := range stakes {
+		combined += stake
+		if 3*combined > total {
+			return i + 1
+		}
+	}
+	return len(stakes)
+}
+
+// GetSuperminority returns the nodekeys of the superminority: the fewest validators (largest first) whose combined
+// stake exceeds a third of the total. Equally staked validators are ordered by nodekey, for a stable result.
+func GetSuperminority(stakes map[string]int64) []string {
+	var nodekeys []string
+	for nodekey, stake := range stakes {
+		if stake > 0 {
+			nodekeys = append(nodekeys, nodekey)
+		}
+	}
+	slices.SortFunc(nodekeys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(stakes[b], stakes[a]), cmp.Compare(a, b))
+	})
+	sorted := make([]int64, len(nodekeys))
+	for i, nodekey := range nodekeys {
+		sorted[i] = stakes[nodekey]
+	}
+	return nodekeys[:NakamotoCoefficient(sorted)]
+}
+
 // CombineUnique combines unique items from multiple arrays to a single array.
 func CombineUnique[T comparable](args ...[]T) []T {
 	var uniqueItems []T
@@ -164,12 +378,6 @@ func CombineUnique[T comparable](args ...[]T) []T {
 	return uniqueItems
 }
 
-// GetEpochBounds returns the first slot and last slot within an [inclusive] Epoch
-func GetEpochBounds(info *rpc.EpochInfo) (int64, int64) {
-	firstSlot := info.AbsoluteSlot - info.SlotIndex
-	return firstSlot, firstSlot + info.SlotsInEpoch - 1
-}
-
 func CountVoteTransactions(block *rpc.Block) (int, error) {
 	txData, err := json.Marshal(block.Transactions)
 	if err != nil {
@@ -189,6 +397,29 @@ func CountVoteTransactions(block *rpc.Block) (int, error) {
 	return voteCount, nil
 }
 
+// GetVoteTransactionAccounts returns the accounts referenced by the vote transactions of a (full) block, which include
+// the vote accounts whose votes landed in it.
+func GetVoteTransactionAccounts(block *rpc.Block) (map[string]struct{}, error) {
+	txData, err := json.Marshal(block.Transactions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transactions: %w", err)
+	}
+	var transactions []rpc.FullTransaction
+	if err := json.Unmarshal(txData, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transactions: %w", err)
+	}
+
+	accounts := make(map[string]struct{})
+	for _, tx := range transactions {
+		if slices.Contains(tx.Transaction.Message.AccountKeys, VoteProgram) {
+			for _, account := range tx.Transaction.Message.AccountKeys {
+				accounts[account] = struct{}{}
+			}
+		}
+	}
+	return accounts, nil
+}
+
 // BoolToFloat64 converts a boolean to either 1.0 or 0.0
 func BoolToFloat64(b bool) float64 {
 	if b {
@@ -271,3 +502,320 @@ func GetVoteAccountFromIdentity(ctx context.Context, client *rpc.Client, identit
 	
 	return "", fmt.Errorf("no vote account found for identity %s", identity)
 }
+
+// GetVoteAccountNodePubkey finds the node pubkey (validator identity) which a vote account votes on behalf of
+func GetVoteAccountNodePubkey(ctx context.Context, client *rpc.Client, votekey string) (string, error) {
+	voteAccounts, err := client.GetVoteAccountsOf(ctx, rpc.CommitmentConfirmed, votekey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get vote accounts: %w", err)
+	}
+
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		if account.VotePubkey == votekey {
+			return account.NodePubkey, nil
+		}
+	}
+
+	return "", fmt.Errorf("no vote account found with pubkey %s", votekey)
+}
+
+// GetCommissionStats returns the mean and median commission of the provided vote accounts
+func GetCommissionStats(accounts []rpc.VoteAccount) (mean float64, median float64) {
+	if len(accounts) == 0 {
+		return 0, 0
+	}
+	commissions := make([]int, len(accounts))
+	var total int
+	for i, account := range accounts {
+		commissions[i] = account.Commission
+		total += account.Commission
+	}
+	slices.Sort(commissions)
+
+	mean = float64(total) / float64(len(commissions))
+	middle := len(commissions) / 2
+	if len(commissions)%2 == 0 {
+		median = float64(commissions[middle-1]+commissions[middle]) / 2
+	} else {
+		median = float64(commissions[middle])
+	}
+	return mean, median
+}
+
+// GetCommissionPercentile returns the percentile rank (0-100) of the provided commission amongst the provided
+// vote accounts, counting validators with an equal commission as half below and half above.
+func GetCommissionPercentile(accounts []rpc.VoteAccount, commission int) float64 {
+	if len(accounts) == 0 {
+		return 0
+	}
+	var below, equal int
+	for _, account := range accounts {
+		if account.Commission < commission {
+			below++
+		} else if account.Commission == commission {
+			equal++
+		}
+	}
+	return 100 * (float64(below) + float64(equal)/2) / float64(len(accounts))
+}
+
+// GetAuthorizedVoters returns the authorized voter of the epoch (i.e., the latest one from an epoch up to it), and the
+// last voter scheduled for a later epoch, either of which is nil if there is none.
+func GetAuthorizedVoters(voters []rpc.AuthorizedVoter, epoch int64) (current, scheduled *rpc.AuthorizedVoter) {
+	for i, voter := range voters {
+		if voter.Epoch <= epoch {
+			if current == nil || voter.Epoch >= current.Epoch {
+				current = &voters[i]
+			}
+		} else if scheduled == nil || voter.Epoch >= scheduled.Epoch {
+			scheduled = &voters[i]
+		}
+	}
+	return current, scheduled
+}
+
+// NewClusterClient returns the client for cluster-wide calls (e.g., getVoteAccounts and getBlockProduction): a client
+// of the reference RPC if one is configured, such that these stay off the local node, or the node client otherwise.
+func NewClusterClient(nodeClient *rpc.Client, config *ExporterConfig, registerer prometheus.Registerer) *rpc.Client {
+	if config.ReferenceRpcUrl == "" {
+		return nodeClient
+	}
+	client := rpc.NewRPCClient(config.ReferenceRpcUrl, config.HttpTimeout, registerer)
+	client.Headers = config.RpcHeaders
+	client.HttpClient.Transport = config.RpcTransport
+	client.AcceptEncodings = config.RpcAcceptEncodings
+	client.Retry = config.RpcRetryPolicy
+	client.RateLimiter = NewRateLimiter(config, config.ReferenceRpcUrl)
+	client.CircuitBreaker = NewCircuitBreaker(config)
+	client.SchemaDrift = nodeClient.SchemaDrift
+	client.Recorder = nodeClient.Recorder
+	client.Replayer = nodeClient.Replayer
+	return client
+}
+
+// NewWSClient returns a client of the configured PubSub endpoint.
+func NewWSClient(config *ExporterConfig) *rpc.WSClient {
+	client := rpc.NewWSClient(config.WsUrl)
+	client.Headers = config.RpcHeaders
+	if config.RpcTLSConfig != nil {
+		dialer := *websocket.DefaultDialer
+		dialer.TLSClientConfig = config.RpcTLSConfig
+		client.Dialer = &dialer
+	}
+	return client
+}
+
+// GetReferenceLag returns how far the node is behind the reference, as per the provided height getter (e.g., the slot
+// or block height) at the commitment. Both are queried concurrently, such that request latency does not skew the lag.
+func GetReferenceLag(
+	ctx context.Context,
+	node, reference *rpc.Client,
+	commitment rpc.Commitment,
+	getHeight func(*rpc.Client, context.Context, rpc.Commitment) (int64, error),
+) (int64, error) {
+	var (
+		referenceHeight int64
+		referenceErr    error
+		done            = make(chan struct{})
+	)
+	go func() {
+		defer close(done)
+		referenceHeight, referenceErr = getHeight(reference, ctx, commitment)
+	}()
+	height, err := getHeight(node, ctx, commitment)
+	<-done
+	if err != nil {
+		return 0, err
+	}
+	if referenceErr != nil {
+		return 0, fmt.Errorf("reference: %w", referenceErr)
+	}
+	return referenceHeight - height, nil
+}
+
+// NewRateLimiter returns the rate limiter of the RPC provider at url, or nil if requests are not rate limited. Each
+// provider gets its own limiter, as their limits are independent, which is shared by all clients of that provider.
+func NewRateLimiter(config *ExporterConfig, url string) *rpc.RateLimiter {
+	if config.RpcRateLimit == 0 {
+		return nil
+	}
+	config.rateLimitersMu.Lock()
+	defer config.rateLimitersMu.Unlock()
+	if limiter, ok := config.rateLimiters[url]; ok {
+		return limiter
+	}
+	if config.rateLimiters == nil {
+		config.rateLimiters = make(map[string]*rpc.RateLimiter)
+	}
+	limiter := rpc.NewRateLimiter(config.RpcRateLimit, config.RpcRateLimitBurst)
+	config.rateLimiters[url] = limiter
+	return limiter
+}
+
+// NewCircuitBreaker returns a new circuit breaker for an RPC provider, or nil if it is disabled.
+func NewCircuitBreaker(config *ExporterConfig) *rpc.CircuitBreaker {
+	if config.RpcCircuitBreakerThreshold == 0 {
+		return nil
+	}
+	return rpc.NewCircuitBreaker(config.RpcCircuitBreakerThreshold, config.RpcCircuitBreakerCooldown)
+}
+
+// GetVisibleStakeRatio returns the share of the active stake held by validators whose nodekey is among the nodes.
+func GetVisibleStakeRatio(nodes []rpc.ClusterNode, voteAccounts *rpc.VoteAccounts) float64 {
+	visible := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		visible[node.Pubkey] = struct{}{}
+	}
+	var totalStake, visibleStake int64
+	for _, account := range append(voteAccounts.Current, voteAccounts.Delinquent...) {
+		totalStake += account.ActivatedStake
+		if _, ok := visible[account.NodePubkey]; ok {
+			visibleStake += account.ActivatedStake
+		}
+	}
+	if totalStake == 0 {
+		return 0
+	}
+	return float64(visibleStake) / float64(totalStake)
+}
+
+// GetClientType detects the validator client from its version. Firedancer (and Frankendancer) versions are 0.x.
+// Jito-Agave is reported as Agave: it is built from Agave releases, and reports the same version and feature set.
+func GetClientType(version string) string {
+	if strings.HasPrefix(strings.TrimPrefix(version, "v"), "0.") {
+		return ClientFiredancer
+	}
+	return ClientAgave
+}
+
+// IsVersionOutdated returns whether the version is older than minVersion, as per semver precedence: by major, minor
+// and patch number, with a pre-release (e.g., "2.1.14-rc.1") older than its release. A "v" prefix and build metadata
+// are ignored.
+func IsVersionOutdated(version, minVersion string) (bool, error) {
+	parsed, preRelease, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	parsedMin, minPreRelease, err := parseVersion(minVersion)
+	if err != nil {
+		return false, err
+	}
+	if order := slices.Compare(parsed, parsedMin); order != 0 {
+		return order < 0, nil
+	}
+	return preRelease && !minPreRelease, nil
+}
+
+// parseVersion returns the major, minor and patch numbers of the (semver) version, and whether it is a pre-release.
+func parseVersion(version string) ([]int, bool, error) {
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, preRelease, isPreRelease := strings.Cut(core, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 || (isPreRelease && preRelease == "") {
+		return nil, false, fmt.Errorf("invalid version %q", version)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, false, fmt.Errorf("invalid version %q", version)
+		}
+		numbers[i] = number
+	}
+	return numbers, isPreRelease, nil
+}
+
+// CountNodesByVersion returns the number of nodes advertising each version, with nodes advertising none counted as
+// UnknownVersion.
+func CountNodesByVersion(nodes []rpc.ClusterNode) map[string]int {
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		version := UnknownVersion
+		if node.Version != nil {
+			version = *node.Version
+		}
+		counts[version]++
+	}
+	return counts
+}
+
+// GetAdvertisedAddresses returns the addresses the node advertises in gossip, by service.
+func GetAdvertisedAddresses(node *rpc.ClusterNode) map[string]string {
+	addresses := make(map[string]string)
+	for service, address := range map[string]*string{"gossip": node.Gossip, "tpu": node.Tpu, "rpc": node.Rpc} {
+		if address != nil {
+			addresses[service] = *address
+		}
+	}
+	return addresses
+}
+
+// GetAddressPort returns the port of a 'host:port' address.
+func GetAddressPort(address string) (int, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
+
+// GetSkipRate returns the percentage of the leader slots which were skipped, or 0 if there were none.
+func GetSkipRate(processed, skipped int) float64 {
+	if processed+skipped == 0 {
+		return 0
+	}
+	return 100 * float64(skipped) / float64(processed+skipped)
+}
+
+// CountSlotsFrom returns the number of slots in the set at or after firstSlot.
+func CountSlotsFrom(slots map[int64]struct{}, firstSlot int64) int {
+	var count int
+	for slot := range slots {
+		if slot >= firstSlot {
+			count++
+		}
+	}
+	return count
+}
+
+// GetStakeWeightedSkipRate returns the percentage of the leader slots skipped across the cluster, weighting the skip
+// rate of each validator by its stake, and whether any staked validator had leader slots.
+func GetStakeWeightedSkipRate(production map[string]rpc.HostProduction, stakes map[string]int64) (float64, bool) {
+	var weighted, totalStake float64
+	for nodekey, host := range production {
+		stake := stakes[nodekey]
+		if host.LeaderSlots == 0 || stake == 0 {
+			continue
+		}
+		skipRate := GetSkipRate(int(host.BlocksProduced), int(host.LeaderSlots-host.BlocksProduced))
+		weighted += skipRate * float64(stake)
+		totalStake += float64(stake)
+	}
+	if totalStake == 0 {
+		return 0, false
+	}
+	return weighted / totalStake, true
+}
+
+// GetSkipStreaks returns the number of consecutive skipped slots up to the most recent leader slot, and the longest
+// run of consecutive skipped slots, ignoring slots before firstSlot.
+func GetSkipStreaks(processed, skipped map[int64]struct{}, firstSlot int64) (current int, longest int) {
+	var slots []int64
+	for _, set := range []map[int64]struct{}{processed, skipped} {
+		for slot := range set {
+			if slot >= firstSlot {
+				slots = append(slots, slot)
+			}
+		}
+	}
+	slices.Sort(slots)
+	for _, slot := range slots {
+		if _, ok := skipped[slot]; ok {
+			current++
+			longest = max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return current, longest
+}
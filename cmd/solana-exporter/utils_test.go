@@ -5,28 +5,14 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
 	"github.com/stretchr/testify/assert"
 	"sort"
 	"testing"
+	"time"
 )
 
-func TestSelectFromSchedule(t *testing.T) {
-	selected := SelectFromSchedule(
-		map[string][]int64{
-			"aaa": {0, 3, 6, 9, 12},
-			"bbb": {1, 4, 7, 10, 13},
-			"ccc": {2, 5, 8, 11, 14},
-		},
-		5,
-		10,
-	)
-	assert.Equal(t,
-		map[string][]int64{"aaa": {6, 9}, "bbb": {7, 10}, "ccc": {5, 8}},
-		selected,
-	)
-}
-
 func TestGetTrimmedLeaderSchedule(t *testing.T) {
 	_, client := rpc.NewMockClient(t,
 		map[string]any{
@@ -44,6 +30,11 @@ func TestGetTrimmedLeaderSchedule(t *testing.T) {
 	schedule, err := GetTrimmedLeaderSchedule(ctx, client, []string{"aaa", "bbb"}, 10, 10)
 	assert.NoError(t, err)
 	assert.Equal(t, map[string][]int64{"aaa": {10, 13, 16, 19, 22}, "bbb": {11, 14, 17, 20, 23}}, schedule)
+
+	// a single identity is fetched through the identity filter:
+	schedule, err = GetTrimmedLeaderSchedule(ctx, client, []string{"ccc"}, 10, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]int64{"ccc": {12, 15, 18, 21, 24}}, schedule)
 }
 
 func TestCombineUnique(t *testing.T) {
@@ -59,6 +50,15 @@ func TestCombineUnique(t *testing.T) {
 
 }
 
+func TestCountRecentSignatures(t *testing.T) {
+	signatures := []rpc.SignatureInfo{{Slot: 34}, {Slot: 30}, {Slot: 30}, {Slot: 20}}
+	assert.Equal(t, 4, CountRecentSignatures(signatures, 10))
+	assert.Equal(t, 3, CountRecentSignatures(signatures, 25))
+	assert.Equal(t, 1, CountRecentSignatures(signatures, 30))
+	assert.Equal(t, 0, CountRecentSignatures(signatures, 34))
+	assert.Equal(t, 0, CountRecentSignatures(nil, 34))
+}
+
 func TestFetchBalances(t *testing.T) {
 	simulator, client := NewSimulator(t, 0)
 
@@ -71,6 +71,18 @@ func TestFetchBalances(t *testing.T) {
 		map[string]float64{"aaa": 1, "bbb": 2, "ccc": 3, "AAA": 4, "BBB": 5, "CCC": 6},
 		fetchedBalances,
 	)
+
+	// addresses are fetched in batches, unknown ones having a zero balance:
+	addresses := []string{"aaa"}
+	for i := range rpc.MaxMultipleAccounts {
+		addresses = append(addresses, fmt.Sprintf("unknown%d", i))
+	}
+	fetchedBalances, err = FetchBalances(ctx, client, append(addresses, "CCC"))
+	assert.NoError(t, err)
+	assert.Len(t, fetchedBalances, rpc.MaxMultipleAccounts+2)
+	assert.Equal(t, float64(1), fetchedBalances["aaa"])
+	assert.Equal(t, float64(0), fetchedBalances["unknown0"])
+	assert.Equal(t, float64(6), fetchedBalances["CCC"])
 }
 
 func TestGetAssociatedVoteAccounts(t *testing.T) {
@@ -84,11 +96,156 @@ func TestGetAssociatedVoteAccounts(t *testing.T) {
 	assert.Equal(t, simulator.Votekeys, voteAccounts)
 }
 
-func TestGetEpochBounds(t *testing.T) {
-	epoch := rpc.EpochInfo{AbsoluteSlot: 25, SlotIndex: 5, SlotsInEpoch: 10}
-	first, last := GetEpochBounds(&epoch)
-	assert.Equal(t, int64(20), first)
-	assert.Equal(t, int64(29), last)
+func TestGetVoteAccountNodePubkey(t *testing.T) {
+	_, client := NewSimulator(t, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodekey, err := GetVoteAccountNodePubkey(ctx, client, "BBB")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbb", nodekey)
+
+	_, err = GetVoteAccountNodePubkey(ctx, client, "XXX")
+	assert.Error(t, err)
+}
+
+func TestGetAddressPort(t *testing.T) {
+	port, err := GetAddressPort("10.0.0.1:8001")
+	assert.NoError(t, err)
+	assert.Equal(t, 8001, port)
+	port, err = GetAddressPort("[::1]:8899")
+	assert.NoError(t, err)
+	assert.Equal(t, 8899, port)
+	_, err = GetAddressPort("10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestCountBlockProductionMismatches(t *testing.T) {
+	production := rpc.BlockProduction{
+		ByIdentity: map[string]rpc.HostProduction{
+			"aaa": {LeaderSlots: 4, BlocksProduced: 3},
+			"bbb": {LeaderSlots: 4, BlocksProduced: 4},
+		},
+		Range: rpc.BlockProductionRange{FirstSlot: 10, LastSlot: 17},
+	}
+
+	// consistent:
+	assert.Equal(t, int64(0), CountBlockProductionMismatches(&production, []int64{10, 11, 12, 14, 15, 16, 17}, 10, 17))
+	// getBlocks reports a block which block production does not:
+	assert.Equal(t,
+		int64(1), CountBlockProductionMismatches(&production, []int64{10, 11, 12, 13, 14, 15, 16, 17}, 10, 17),
+	)
+	// blocks outside the range are ignored:
+	assert.Equal(t,
+		int64(0), CountBlockProductionMismatches(&production, []int64{9, 10, 11, 12, 14, 15, 16, 17, 18}, 10, 17),
+	)
+	// block production is missing leader slots:
+	assert.Equal(t,
+		int64(4), CountBlockProductionMismatches(&production, []int64{10, 11, 12, 14, 15, 16, 17}, 10, 21),
+	)
+}
+
+func TestFilterProducedSlots(t *testing.T) {
+	blocks := []int64{10, 11, 13, 14}
+	// 12 was skipped, whereas 15 and 16 may not be confirmed yet:
+	assert.Equal(t, []int64{11, 14, 15, 16}, FilterProducedSlots([]int64{11, 12, 14, 15, 16}, blocks, 0))
+	assert.Empty(t, FilterProducedSlots([]int64{12}, blocks, 0))
+	// without any confirmed blocks, nothing is known to be skipped:
+	assert.Equal(t, []int64{11, 12}, FilterProducedSlots([]int64{11, 12}, nil, 0))
+	// slots before the first available block may have been purged rather than skipped:
+	assert.Equal(t, []int64{8, 9, 11}, FilterProducedSlots([]int64{8, 9, 11, 12}, blocks, 10))
+}
+
+func TestStakeConcentration(t *testing.T) {
+	voteAccounts := rpc.VoteAccounts{
+		Current: []rpc.VoteAccount{
+			{ActivatedStake: 10}, {ActivatedStake: 40}, {ActivatedStake: 0}, {ActivatedStake: 20},
+		},
+		Delinquent: []rpc.VoteAccount{{ActivatedStake: 30}},
+	}
+	// unstaked vote accounts are left out:
+	stakes := SortedActiveStakes(&voteAccounts)
+	assert.Equal(t, []int64{40, 30, 20, 10}, stakes)
+
+	// the number of validators is rounded up:
+	assert.Equal(t, 0.4, TopStakeShare(stakes, 10))
+	assert.Equal(t, 0.4, TopStakeShare(stakes, 25))
+	assert.Equal(t, 0.7, TopStakeShare(stakes, 33))
+	assert.Equal(t, float64(0), TopStakeShare(nil, 10))
+
+	assert.Equal(t, 1, NakamotoCoefficient(stakes))
+	assert.Equal(t, 2, NakamotoCoefficient([]int64{10, 10, 10}))
+	assert.Equal(t, 0, NakamotoCoefficient(nil))
+}
+
+func TestGetStakeWeightedSkipRate(t *testing.T) {
+	production := map[string]rpc.HostProduction{
+		"aaa": {LeaderSlots: 4, BlocksProduced: 4},
+		"bbb": {LeaderSlots: 4, BlocksProduced: 2},
+		// unstaked validators, and validators without leader slots, are left out:
+		"ccc": {LeaderSlots: 4, BlocksProduced: 0},
+		"ddd": {LeaderSlots: 0, BlocksProduced: 0},
+	}
+	stakes := map[string]int64{"aaa": 300, "bbb": 100, "ddd": 600}
+	skipRate, ok := GetStakeWeightedSkipRate(production, stakes)
+	assert.True(t, ok)
+	assert.Equal(t, 12.5, skipRate)
+
+	_, ok = GetStakeWeightedSkipRate(production, nil)
+	assert.False(t, ok)
+
+	voteAccounts := rpc.VoteAccounts{
+		Current:    []rpc.VoteAccount{{NodePubkey: "aaa", ActivatedStake: 10}},
+		Delinquent: []rpc.VoteAccount{{NodePubkey: "bbb", ActivatedStake: 20}},
+	}
+	assert.Equal(t, map[string]int64{"aaa": 10, "bbb": 20}, GetStakesByNodekey(&voteAccounts))
+}
+
+func TestSortedStakedVoteAccounts(t *testing.T) {
+	voteAccounts := rpc.VoteAccounts{
+		Current: []rpc.VoteAccount{
+			{VotePubkey: "AAA", ActivatedStake: 10}, {VotePubkey: "BBB", ActivatedStake: 0},
+			{VotePubkey: "DDD", ActivatedStake: 20},
+		},
+		Delinquent: []rpc.VoteAccount{{VotePubkey: "CCC", ActivatedStake: 20}},
+	}
+	var votekeys []string
+	for _, account := range SortedStakedVoteAccounts(&voteAccounts) {
+		votekeys = append(votekeys, account.VotePubkey)
+	}
+	// unstaked accounts are left out, and equal stakes are ordered by votekey:
+	assert.Equal(t, []string{"CCC", "DDD", "AAA"}, votekeys)
+}
+
+func TestGetSuperminority(t *testing.T) {
+	stakes := map[string]int64{"aaa": 10, "bbb": 40, "ccc": 0, "ddd": 20, "eee": 30}
+	assert.Equal(t, []string{"bbb"}, GetSuperminority(stakes))
+	// equal stakes are ordered by nodekey:
+	assert.Equal(t, []string{"aaa", "bbb"}, GetSuperminority(map[string]int64{"ccc": 10, "bbb": 10, "aaa": 10}))
+	assert.Empty(t, GetSuperminority(nil))
+}
+
+func TestGetCommissionStats(t *testing.T) {
+	accounts := []rpc.VoteAccount{{Commission: 10}, {Commission: 0}, {Commission: 5}, {Commission: 100}}
+	mean, median := GetCommissionStats(accounts)
+	assert.Equal(t, 28.75, mean)
+	assert.Equal(t, 7.5, median)
+
+	mean, median = GetCommissionStats(accounts[:3])
+	assert.Equal(t, float64(5), mean)
+	assert.Equal(t, float64(5), median)
+
+	mean, median = GetCommissionStats(nil)
+	assert.Equal(t, float64(0), mean)
+	assert.Equal(t, float64(0), median)
+}
+
+func TestGetCommissionPercentile(t *testing.T) {
+	accounts := []rpc.VoteAccount{{Commission: 10}, {Commission: 0}, {Commission: 5}, {Commission: 5}}
+	assert.Equal(t, float64(12.5), GetCommissionPercentile(accounts, 0))
+	assert.Equal(t, float64(50), GetCommissionPercentile(accounts, 5))
+	assert.Equal(t, float64(87.5), GetCommissionPercentile(accounts, 10))
+	assert.Equal(t, float64(100), GetCommissionPercentile(accounts, 50))
 }
 
 //go:embed testdata/block-297609329.json
@@ -192,3 +349,131 @@ func TestExtractHealthAndNumSlotsBehind(t *testing.T) {
 		})
 	})
 }
+
+func TestGetLeaderSlotPosition(t *testing.T) {
+	assert.Equal(t, int64(1), GetLeaderSlotPosition(100, 100))
+	assert.Equal(t, int64(4), GetLeaderSlotPosition(103, 100))
+	assert.Equal(t, int64(1), GetLeaderSlotPosition(104, 100))
+	assert.Equal(t, int64(3), GetLeaderSlotPosition(110, 100))
+}
+
+func TestGetSlotTimestampDrift(t *testing.T) {
+	clock := rpc.Clock{Slot: 110, Epoch: 1, EpochStartTimestamp: 1000, UnixTimestamp: 1005}
+	// 10 slots into the epoch should be 4s after the epoch start:
+	assert.Equal(t, float64(1), GetSlotTimestampDrift(&clock, 100))
+}
+
+func TestGetAverageSlotDuration(t *testing.T) {
+	samples := []rpc.PerformanceSample{
+		{Slot: 200, NumSlots: 150, SamplePeriodSecs: 60},
+		{Slot: 50, NumSlots: 100, SamplePeriodSecs: 60},
+	}
+	assert.Equal(t, 480*time.Millisecond, GetAverageSlotDuration(samples))
+	// without samples, the target slot duration is assumed:
+	assert.Equal(t, SlotDuration, GetAverageSlotDuration(nil))
+}
+
+func TestGetTransactionRates(t *testing.T) {
+	samples := []rpc.PerformanceSample{
+		{Slot: 200, NumTransactions: 3000, NumNonVoteTransactions: 600, SamplePeriodSecs: 60},
+		{Slot: 50, NumTransactions: 1800, NumNonVoteTransactions: 600, SamplePeriodSecs: 60},
+	}
+	vote, nonVote := GetTransactionRates(samples)
+	assert.Equal(t, float64(30), vote)
+	assert.Equal(t, float64(10), nonVote)
+
+	vote, nonVote = GetTransactionRates(nil)
+	assert.Zero(t, vote)
+	assert.Zero(t, nonVote)
+}
+
+func TestGetClientType(t *testing.T) {
+	assert.Equal(t, ClientAgave, GetClientType("2.1.14"))
+	assert.Equal(t, ClientAgave, GetClientType("v1.18.26"))
+	assert.Equal(t, ClientFiredancer, GetClientType("0.503.20214"))
+}
+
+func TestIsVersionOutdated(t *testing.T) {
+	for _, test := range []struct {
+		version, minVersion string
+		outdated            bool
+	}{
+		{"2.1.13", "2.1.14", true},
+		{"v2.1.14", "2.1.14", false},
+		{"2.1.14+build.5", "2.1.14", false},
+		{"2.2.0", "2.1.14", false},
+		{"1.18.26", "2.1.14", true},
+		// numbers are compared numerically, not lexically:
+		{"2.1.9", "2.1.14", true},
+		{"2.10.0", "2.9.0", false},
+		// pre-releases precede their release:
+		{"2.1.14-rc.1", "2.1.14", true},
+		{"2.1.14-rc.1", "2.1.14-rc.1", false},
+	} {
+		outdated, err := IsVersionOutdated(test.version, test.minVersion)
+		assert.NoError(t, err)
+		assert.Equal(t, test.outdated, outdated, "%s < %s", test.version, test.minVersion)
+	}
+
+	_, err := IsVersionOutdated("2.1", "2.1.14")
+	assert.Error(t, err)
+	_, err = IsVersionOutdated("2.1.14", "latest")
+	assert.Error(t, err)
+}
+
+func TestGetSkipRate(t *testing.T) {
+	assert.Equal(t, float64(25), GetSkipRate(3, 1))
+	assert.Equal(t, float64(100), GetSkipRate(0, 4))
+	// no leader slots yet:
+	assert.Equal(t, float64(0), GetSkipRate(0, 0))
+}
+
+func TestCountSlotsFrom(t *testing.T) {
+	slots := map[int64]struct{}{96: {}, 100: {}, 101: {}}
+	assert.Equal(t, 2, CountSlotsFrom(slots, 100))
+	assert.Equal(t, 0, CountSlotsFrom(slots, 102))
+}
+
+func TestGetSkipStreaks(t *testing.T) {
+	processed := map[int64]struct{}{96: {}, 100: {}, 101: {}, 104: {}}
+	skipped := map[int64]struct{}{90: {}, 91: {}, 92: {}, 102: {}, 103: {}, 105: {}}
+	// the skips before the epoch started are ignored:
+	current, longest := GetSkipStreaks(processed, skipped, 100)
+	assert.Equal(t, 1, current)
+	assert.Equal(t, 2, longest)
+
+	current, longest = GetSkipStreaks(processed, map[int64]struct{}{}, 100)
+	assert.Equal(t, 0, current)
+	assert.Equal(t, 0, longest)
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	config := ExporterConfig{RpcRateLimitBurst: 5}
+	assert.Nil(t, NewRateLimiter(&config, "http://node"))
+
+	config.RpcRateLimit = 10
+	assert.NotNil(t, NewRateLimiter(&config, "http://node"))
+	// providers are limited independently, but each provider's clients share its limiter:
+	assert.NotSame(t, NewRateLimiter(&config, "http://node"), NewRateLimiter(&config, "http://reference"))
+	assert.Same(t, NewRateLimiter(&config, "http://reference"), NewRateLimiter(&config, "http://reference"))
+}
+
+func TestNewClusterClient_RateLimiter(t *testing.T) {
+	config := ExporterConfig{ReferenceRpcUrl: "http://reference", RpcRateLimit: 10, RpcRateLimitBurst: 5}
+	node := rpc.NewRPCClient("http://node", time.Second, prometheus.NewRegistry())
+	node.RateLimiter = NewRateLimiter(&config, "http://node")
+
+	first := NewClusterClient(node, &config, prometheus.NewRegistry())
+	second := NewClusterClient(node, &config, prometheus.NewRegistry())
+	assert.NotNil(t, first.RateLimiter)
+	assert.Same(t, first.RateLimiter, second.RateLimiter)
+	assert.NotSame(t, node.RateLimiter, first.RateLimiter)
+}
+
+func TestNewCircuitBreaker(t *testing.T) {
+	config := ExporterConfig{RpcCircuitBreakerCooldown: time.Minute}
+	assert.Nil(t, NewCircuitBreaker(&config))
+
+	config.RpcCircuitBreakerThreshold = 5
+	assert.NotNil(t, NewCircuitBreaker(&config))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+// VoteWatcher follows the votes of the tracked vote accounts through a voteSubscribe subscription. Votes are observed
+// as soon as they are gossiped, so a validator which stopped voting shows up well before the vote-account poll
+// marks it delinquent.
+type VoteWatcher struct {
+	votekeys []string
+	pace     time.Duration
+	logger   *zap.SugaredLogger
+
+	// lastVoteTimes are the times at which a vote of each votekey was last observed
+	lastVoteTimes map[string]time.Time
+	mu            sync.Mutex
+
+	ObservedVotesMetric       *prometheus.CounterVec
+	LastObservedVoteAgeMetric *prometheus.Desc
+}
+
+func NewVoteWatcher(votekeys []string, pace time.Duration) *VoteWatcher {
+	return &VoteWatcher{
+		votekeys:      votekeys,
+		pace:          pace,
+		logger:        slog.Get(),
+		lastVoteTimes: make(map[string]time.Time),
+		ObservedVotesMetric: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_validator_observed_votes_total",
+				Help: "Number of votes of the tracked vote accounts observed through the vote subscription",
+			},
+			[]string{VotekeyLabel},
+		),
+		LastObservedVoteAgeMetric: prometheus.NewDesc(
+			"solana_validator_last_observed_vote_age_seconds",
+			"Time since a vote of the tracked vote account was last observed through the vote subscription",
+			[]string{VotekeyLabel},
+			nil,
+		),
+	}
+}
+
+func (w *VoteWatcher) Describe(ch chan<- *prometheus.Desc) {
+	w.ObservedVotesMetric.Describe(ch)
+	ch <- w.LastObservedVoteAgeMetric
+}
+
+func (w *VoteWatcher) Collect(ch chan<- prometheus.Metric) {
+	w.ObservedVotesMetric.Collect(ch)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for votekey, lastVoteTime := range w.lastVoteTimes {
+		ch <- prometheus.MustNewConstMetric(
+			w.LastObservedVoteAgeMetric, prometheus.GaugeValue, time.Since(lastVoteTime).Seconds(), votekey,
+		)
+	}
+}
+
+// ObserveVote records a vote notification, ignoring the votes of untracked vote accounts.
+func (w *VoteWatcher) ObserveVote(vote rpc.VoteNotification, now time.Time) {
+	if !slices.Contains(w.votekeys, vote.VotePubkey) {
+		return
+	}
+	w.ObservedVotesMetric.WithLabelValues(vote.VotePubkey).Inc()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastVoteTimes[vote.VotePubkey] = now
+}
+
+// WatchVotes observes votes until the context is cancelled, re-subscribing every pace whenever the subscription
+// drops.
+func (w *VoteWatcher) WatchVotes(ctx context.Context, wsClient *rpc.WSClient) {
+	w.logger.Infof("Starting vote subscription on %s for %v", rpc.RedactUrl(wsClient.WsUrl), w.votekeys)
+	for {
+		notifications, err := wsClient.SubscribeVotes(ctx)
+		if err != nil {
+			w.logger.Errorf("Failed to subscribe to votes: %v", err)
+		} else {
+			for notification := range notifications {
+				w.ObserveVote(notification, time.Now())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Stopping WatchVotes()")
+			return
+		case <-time.After(w.pace):
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteWatcher_ObserveVote(t *testing.T) {
+	watcher := NewVoteWatcher([]string{"AAA", "BBB"}, time.Second)
+	now := time.Now()
+	watcher.ObserveVote(rpc.VoteNotification{VotePubkey: "AAA", Slots: []int64{10}}, now.Add(-5*time.Second))
+	watcher.ObserveVote(rpc.VoteNotification{VotePubkey: "AAA", Slots: []int64{11}}, now.Add(-2*time.Second))
+	// untracked vote accounts are ignored:
+	watcher.ObserveVote(rpc.VoteNotification{VotePubkey: "CCC", Slots: []int64{11}}, now)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(watcher.ObservedVotesMetric.WithLabelValues("AAA")))
+	assert.Equal(t, 1, testutil.CollectAndCount(watcher.ObservedVotesMetric))
+	// one observed votes series and one vote age series, nothing for BBB which has not voted yet:
+	assert.Equal(t, 2, testutil.CollectAndCount(watcher))
+	assert.InDelta(t, 2, time.Since(watcher.lastVoteTimes["AAA"]).Seconds(), 1)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+const (
+	// BaseURL is the Solana Foundation API, which publishes the SFDP (Solana Foundation Delegation Program) epoch
+	// stats of each cluster
+	BaseURL = "https://api.solana.org"
+	// CacheTimeout is how long the minimum required version of a cluster is cached for, as it only changes between
+	// epochs
+	CacheTimeout = 5 * time.Minute
+)
+
+type (
+	// Client fetches data from the Solana Foundation API.
+	Client struct {
+		HttpClient  http.Client
+		BaseURL     string
+		HttpTimeout time.Duration
+		// minVersions caches the minimum required version of each cluster
+		minVersions map[string]cachedVersion
+		mu          sync.Mutex
+		logger      *zap.SugaredLogger
+	}
+
+	cachedVersion struct {
+		version string
+		fetched time.Time
+	}
+
+	// ValidatorEpochStats are the SFDP stats of a cluster's epoch, of which only the requirements are decoded.
+	ValidatorEpochStats struct {
+		Stats struct {
+			Config struct {
+				// MinVersion is the minimum (Agave) version SFDP participants are required to run
+				MinVersion string `json:"min_version"`
+			} `json:"config"`
+		} `json:"stats"`
+	}
+)
+
+// NewClient creates a client of the Solana Foundation API at baseURL (usually BaseURL).
+func NewClient(baseURL string, httpTimeout time.Duration) *Client {
+	return &Client{
+		HttpClient:  http.Client{},
+		BaseURL:     baseURL,
+		HttpTimeout: httpTimeout,
+		minVersions: make(map[string]cachedVersion),
+		logger:      slog.Get(),
+	}
+}
+
+// GetMinRequiredVersion returns the minimum version SFDP participants of the cluster (e.g., "mainnet-beta") are
+// required to run in the latest epoch, cached for CacheTimeout.
+func (c *Client) GetMinRequiredVersion(ctx context.Context, cluster string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.minVersions[cluster]; ok && time.Since(cached.fetched) < CacheTimeout {
+		return cached.version, nil
+	}
+
+	var stats ValidatorEpochStats
+	query := url.Values{"cluster": {cluster}, "epoch": {"latest"}}
+	if err := c.get(ctx, "/api/validators/epoch-stats?"+query.Encode(), &stats); err != nil {
+		return "", fmt.Errorf("failed to get epoch stats of %s: %w", cluster, err)
+	}
+	version := stats.Stats.Config.MinVersion
+	if version == "" {
+		return "", fmt.Errorf("epoch stats of %s have no minimum version", cluster)
+	}
+	c.logger.Debugf("Fetched minimum required version of %s: %s", cluster, version)
+	c.minVersions[cluster] = cachedVersion{version: version, fetched: time.Now()}
+	return version, nil
+}
+
+// get decodes the JSON response of the API path into v, bound by the http timeout.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.HttpTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server responded with %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetMinRequiredVersion(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/api/validators/epoch-stats", r.URL.Path)
+		assert.Equal(t, "latest", r.URL.Query().Get("epoch"))
+		switch r.URL.Query().Get("cluster") {
+		case "mainnet-beta":
+			_, _ = w.Write([]byte(`{"stats":{"config":{"min_version":"2.1.14"}}}`))
+		case "testnet":
+			_, _ = w.Write([]byte(`{"stats":{"config":{}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL, time.Second)
+	ctx := context.Background()
+
+	version, err := client.GetMinRequiredVersion(ctx, "mainnet-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1.14", version)
+	// the version is cached:
+	version, err = client.GetMinRequiredVersion(ctx, "mainnet-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1.14", version)
+	assert.Equal(t, int64(1), requests.Load())
+
+	_, err = client.GetMinRequiredVersion(ctx, "testnet")
+	assert.Error(t, err)
+	_, err = client.GetMinRequiredVersion(ctx, "devnet")
+	assert.Error(t, err)
+}
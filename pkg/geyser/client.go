@@ -0,0 +1,317 @@
+// Package geyser is a minimal client for the Yellowstone gRPC (Geyser) Subscribe API, streaming slot, block and
+// account updates straight from a validator's Geyser plugin. Only the messages the exporter needs are implemented,
+// encoded by hand on top of HTTP/2, rather than pulling in a gRPC stack and generated code.
+package geyser
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+)
+
+const (
+	subscribePath = "/geyser.Geyser/Subscribe"
+	// filterName is the name the exporter's filters are registered under with the server
+	filterName = "exporter"
+	// maxMessageSize bounds the size of the updates read, which are at most the size of an account
+	maxMessageSize = 64 << 20
+
+	// DefaultReadTimeout is how long a subscription may go without receiving anything (an update or a ping, which
+	// Yellowstone servers send every 15 seconds) before it is taken as stalled and cancelled
+	DefaultReadTimeout = time.Minute
+	// readIdleTimeout and pingTimeout are how long the connection may go without any frames before it is health
+	// checked with an HTTP/2 ping, and how long that ping may take before the connection is closed
+	readIdleTimeout = 30 * time.Second
+	pingTimeout     = 15 * time.Second
+)
+
+// CommitmentLevel is the commitment of the account and block updates of a subscription.
+type CommitmentLevel int
+
+const (
+	CommitmentProcessed CommitmentLevel = iota
+	CommitmentConfirmed
+	CommitmentFinalized
+)
+
+// SlotStatus is the status of a slot in a slot update.
+type SlotStatus int
+
+const (
+	SlotProcessed SlotStatus = iota
+	SlotConfirmed
+	SlotFinalized
+	SlotFirstShredReceived
+	SlotCompleted
+	SlotCreatedBank
+	SlotDead
+)
+
+// RewardType is the type of a block reward.
+type RewardType int
+
+const (
+	RewardUnspecified RewardType = iota
+	RewardFee
+	RewardRent
+	RewardStaking
+	RewardVoting
+)
+
+type (
+	// Client is a client for the Yellowstone gRPC Subscribe API.
+	Client struct {
+		Url string
+		// Token returns the x-token to authenticate with, if set. It is called for every subscription, such that the
+		// token can be rotated.
+		Token func() string
+		// Headers are sent with every subscription, e.g., for authenticating with providers
+		Headers    http.Header
+		HttpClient *http.Client
+		// ReadTimeout is how long a subscription may go without receiving anything before it is cancelled
+		ReadTimeout time.Duration
+		logger      *zap.SugaredLogger
+	}
+
+	// Subscription is what to subscribe to. Slot updates are of every status, whereas account and block updates are
+	// at the commitment.
+	Subscription struct {
+		Slots      bool
+		BlocksMeta bool
+		// Accounts are the addresses to receive the updates of
+		Accounts   []string
+		Commitment CommitmentLevel
+	}
+
+	// Update is an update of a subscription, of which exactly one of the fields is set.
+	Update struct {
+		Slot      *SlotUpdate
+		Account   *AccountUpdate
+		BlockMeta *BlockMeta
+	}
+
+	SlotUpdate struct {
+		Slot   int64
+		Parent int64
+		Status SlotStatus
+	}
+
+	AccountUpdate struct {
+		Pubkey   string
+		Lamports int64
+		Owner    string
+		Data     []byte
+		// Slot is the slot of the write
+		Slot int64
+	}
+
+	// BlockMeta is the metadata of a block, i.e., the block without its transactions.
+	BlockMeta struct {
+		Slot                     int64
+		Blockhash                string
+		Rewards                  []Reward
+		BlockTime                int64
+		BlockHeight              int64
+		ParentSlot               int64
+		ExecutedTransactionCount int64
+	}
+
+	Reward struct {
+		Pubkey     string
+		Lamports   int64
+		RewardType RewardType
+	}
+
+	// StatusError is a non-OK gRPC status returned by the server.
+	StatusError struct {
+		Code    string
+		Message string
+	}
+)
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("grpc status %s: %s", e.Code, e.Message)
+}
+
+// NewClient creates a client of the Yellowstone gRPC endpoint, over TLS for https urls, and over cleartext HTTP/2
+// for http urls. tlsConfig may be nil.
+func NewClient(endpoint string, tlsConfig *tls.Config) (*Client, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse geyser url: %w", err)
+	}
+	// the HTTP/2 pings detect dead connections, which would otherwise hang the stream until the OS gives up:
+	transport := &http2.Transport{TLSClientConfig: tlsConfig, ReadIdleTimeout: readIdleTimeout, PingTimeout: pingTimeout}
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		// gRPC servers accept HTTP/2 with prior knowledge:
+		transport.AllowHTTP = true
+		transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geyser url scheme %q", parsed.Scheme)
+	}
+	return &Client{
+		Url:         strings.TrimSuffix(endpoint, "/"),
+		HttpClient:  &http.Client{Transport: transport},
+		ReadTimeout: DefaultReadTimeout,
+		logger:      slog.Get(),
+	}, nil
+}
+
+// Subscribe subscribes to the updates of the subscription, which are sent on the returned channel until the context
+// is cancelled or the stream drops, at which point the channel is closed. Pings from the server are answered, keeping
+// the stream alive through load balancers. A stream which receives neither updates nor pings within the read timeout
+// (e.g., over a half-open connection) is cancelled too.
+func (c *Client) Subscribe(ctx context.Context, subscription Subscription) (<-chan Update, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	body, bodyWriter := io.Pipe()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Url+subscribePath, body)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create subscribe request: %w", err)
+	}
+	for name, values := range c.Headers {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/grpc")
+	request.Header.Set("Te", "trailers")
+	if c.Token != nil {
+		if token := c.Token(); token != "" {
+			request.Header.Set("X-Token", token)
+		}
+	}
+
+	// the request stream stays open (until the context is cancelled) for answering pings:
+	requests := make(chan []byte, 1)
+	requests <- encodeSubscribeRequest(subscription)
+	go func() {
+		defer bodyWriter.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message := <-requests:
+				if err := writeMessage(bodyWriter, message); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	resp, err := c.HttpClient.Do(request)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to subscribe through %s: %w", rpc.RedactUrl(c.Url), err)
+	}
+	if err := responseError(resp, resp.Header); err != nil {
+		_ = resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to subscribe through %s: %w", rpc.RedactUrl(c.Url), err)
+	}
+	c.logger.Infof("Subscribed through %s", rpc.RedactUrl(c.Url))
+
+	updates := make(chan Update)
+	watchdog := time.AfterFunc(c.ReadTimeout, func() {
+		c.logger.Errorf("geyser subscription stalled, nothing received within %v", c.ReadTimeout)
+		cancel()
+	})
+	go func() {
+		defer close(updates)
+		defer cancel()
+		defer watchdog.Stop()
+		//goland:noinspection GoUnhandledErrorResult
+		defer resp.Body.Close()
+		for {
+			message, err := readMessage(resp.Body)
+			if err != nil {
+				// the status of a stream ended by the server is in the trailers:
+				if errors.Is(err, io.EOF) {
+					err = responseError(resp, resp.Trailer)
+				}
+				if err != nil && ctx.Err() == nil {
+					c.logger.Errorf("geyser subscription dropped: %v", err)
+				}
+				return
+			}
+			watchdog.Reset(c.ReadTimeout)
+			update, ping, err := decodeUpdate(message)
+			if err != nil {
+				c.logger.Errorf("geyser subscription dropped: failed to decode update: %v", err)
+				return
+			}
+			if ping {
+				select {
+				case requests <- encodePingRequest(1):
+				default:
+				}
+				continue
+			}
+			if update.Slot == nil && update.Account == nil && update.BlockMeta == nil {
+				continue
+			}
+			select {
+			case updates <- *update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates, nil
+}
+
+// responseError returns the error of a non-OK HTTP or gRPC status of the response, the latter being in the headers
+// (or trailers) provided.
+func responseError(resp *http.Response, headers http.Header) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http status %s", resp.Status)
+	}
+	if code := headers.Get("Grpc-Status"); code != "" && code != "0" {
+		return &StatusError{Code: code, Message: headers.Get("Grpc-Message")}
+	}
+	return nil
+}
+
+// writeMessage writes the message as a (length-prefixed, uncompressed) gRPC message.
+func writeMessage(w io.Writer, message []byte) error {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	_, err := w.Write(append(frame, message...))
+	return err
+}
+
+// readMessage reads a (length-prefixed) gRPC message.
+func readMessage(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, fmt.Errorf("compressed messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the maximum of %d", size, maxMessageSize)
+	}
+	message := make([]byte, size)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return message, nil
+}
@@ -0,0 +1,146 @@
+package geyser
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// newTestServer starts a cleartext HTTP/2 server, with handler serving the Subscribe stream.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(subscribePath, handler)
+	server := httptest.NewServer(h2c.NewHandler(mux, &http2.Server{}))
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	return client
+}
+
+func writeFlushed(t *testing.T, w http.ResponseWriter, message []byte) {
+	require.NoError(t, writeMessage(w, message))
+	w.(http.Flusher).Flush()
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("https://geyser.example.com", nil)
+	assert.NoError(t, err)
+	_, err = NewClient("ftp://geyser.example.com", nil)
+	assert.Error(t, err)
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	pinged := make(chan struct{})
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/grpc", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		request, err := readMessage(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, encodeSubscribeRequest(Subscription{Slots: true}), request)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+
+		// the ping should be answered:
+		writeFlushed(t, w, appendMessage(nil, updatePingField, nil))
+		reply, err := readMessage(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, encodePingRequest(1), reply)
+		close(pinged)
+
+		slot := appendVarint(appendVarint(nil, 1, 100), 3, uint64(SlotConfirmed))
+		writeFlushed(t, w, appendMessage(nil, updateSlotField, slot))
+		w.Header().Set("Grpc-Status", "0")
+	})
+	client.Token = func() string { return "secret" }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, err := client.Subscribe(ctx, Subscription{Slots: true})
+	require.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, &SlotUpdate{Slot: 100, Status: SlotConfirmed}, update.Slot)
+	<-pinged
+	// the stream ending closes the channel:
+	_, ok := <-updates
+	assert.False(t, ok)
+}
+
+func TestClient_Subscribe_stalled(t *testing.T) {
+	release := make(chan struct{})
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, err := readMessage(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.WriteHeader(http.StatusOK)
+		// an update, then nothing, as over a half-open connection:
+		slot := appendVarint(appendVarint(nil, 1, 100), 3, uint64(SlotConfirmed))
+		writeFlushed(t, w, appendMessage(nil, updateSlotField, slot))
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	t.Cleanup(func() { close(release) })
+	client.ReadTimeout = 200 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, err := client.Subscribe(ctx, Subscription{Slots: true})
+	require.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, &SlotUpdate{Slot: 100, Status: SlotConfirmed}, update.Slot)
+	// the stalled stream is cancelled, closing the channel well before the context's deadline:
+	_, ok := <-updates
+	assert.False(t, ok)
+	assert.NoError(t, ctx.Err())
+}
+
+func TestClient_Subscribe_Status(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, io.LimitReader(r.Body, 5))
+		// a trailers-only response:
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "16")
+		w.Header().Set("Grpc-Message", "invalid token")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.Subscribe(ctx, Subscription{Slots: true})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, "16", statusErr.Code)
+	assert.Equal(t, "invalid token", statusErr.Message)
+}
+
+func TestReadMessage(t *testing.T) {
+	var frame []byte
+	frame = append(frame, 0, 0, 0, 0, 3)
+	frame = append(frame, protowire.AppendVarint(nil, 1)...)
+	_, err := readMessage(bytes.NewReader(frame))
+	// truncated:
+	assert.Error(t, err)
+
+	_, err = readMessage(bytes.NewReader([]byte{0, 0xff, 0xff, 0xff, 0xff}))
+	assert.ErrorContains(t, err, "exceeds the maximum")
+
+	_, err = readMessage(bytes.NewReader([]byte{1, 0, 0, 0, 0}))
+	assert.ErrorContains(t, err, "compressed")
+}
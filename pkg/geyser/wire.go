@@ -0,0 +1,259 @@
+package geyser
+
+import (
+	"fmt"
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The field numbers of the Yellowstone geyser.proto messages which are encoded and decoded. Only the fields the
+// exporter needs are handled, any others being skipped when decoding.
+// See: https://github.com/rpcpool/yellowstone-grpc/blob/master/yellowstone-grpc-proto/proto/geyser.proto
+const (
+	// SubscribeRequest
+	requestAccountsField   = 1
+	requestSlotsField      = 2
+	requestBlocksMetaField = 5
+	requestCommitmentField = 6
+	requestPingField       = 9
+
+	// map entries, and SubscribeRequestFilterAccounts
+	mapKeyField            = 1
+	mapValueField          = 2
+	accountsFilterAccounts = 2
+
+	// SubscribeUpdate
+	updateAccountField   = 2
+	updateSlotField      = 3
+	updatePingField      = 6
+	updateBlockMetaField = 7
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// encodeSubscribeRequest encodes a SubscribeRequest for the subscription, filtered under the name "exporter".
+func encodeSubscribeRequest(subscription Subscription) []byte {
+	var request []byte
+	if subscription.Slots {
+		request = appendFilter(request, requestSlotsField, nil)
+	}
+	if subscription.BlocksMeta {
+		request = appendFilter(request, requestBlocksMetaField, nil)
+	}
+	if len(subscription.Accounts) > 0 {
+		var filter []byte
+		for _, account := range subscription.Accounts {
+			filter = protowire.AppendTag(filter, accountsFilterAccounts, protowire.BytesType)
+			filter = protowire.AppendString(filter, account)
+		}
+		request = appendFilter(request, requestAccountsField, filter)
+	}
+	request = protowire.AppendTag(request, requestCommitmentField, protowire.VarintType)
+	return protowire.AppendVarint(request, uint64(subscription.Commitment))
+}
+
+// encodePingRequest encodes a SubscribeRequest which only pings the server, keeping the stream alive.
+func encodePingRequest(id int32) []byte {
+	var ping []byte
+	ping = protowire.AppendTag(ping, 1, protowire.VarintType)
+	ping = protowire.AppendVarint(ping, uint64(id))
+	request := protowire.AppendTag(nil, requestPingField, protowire.BytesType)
+	return protowire.AppendBytes(request, ping)
+}
+
+// appendFilter appends a single-entry filter map field to the request.
+func appendFilter(request []byte, field protowire.Number, filter []byte) []byte {
+	var entry []byte
+	entry = protowire.AppendTag(entry, mapKeyField, protowire.BytesType)
+	entry = protowire.AppendString(entry, filterName)
+	entry = protowire.AppendTag(entry, mapValueField, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, filter)
+	request = protowire.AppendTag(request, field, protowire.BytesType)
+	return protowire.AppendBytes(request, entry)
+}
+
+// decodeUpdate decodes a SubscribeUpdate, and whether it is a ping from the server.
+func decodeUpdate(b []byte) (*Update, bool, error) {
+	var (
+		u    Update
+		ping bool
+	)
+	err := decodeFields(b, func(field protowire.Number, value []byte, _ uint64) error {
+		var err error
+		switch field {
+		case updateSlotField:
+			u.Slot, err = decodeSlotUpdate(value)
+		case updateAccountField:
+			u.Account, err = decodeAccountUpdate(value)
+		case updateBlockMetaField:
+			u.BlockMeta, err = decodeBlockMeta(value)
+		case updatePingField:
+			ping = true
+		}
+		return err
+	})
+	return &u, ping, err
+}
+
+func decodeSlotUpdate(b []byte) (*SlotUpdate, error) {
+	var slot SlotUpdate
+	err := decodeFields(b, func(field protowire.Number, value []byte, varint uint64) error {
+		switch field {
+		case 1:
+			slot.Slot = int64(varint)
+		case 2:
+			slot.Parent = int64(varint)
+		case 3:
+			slot.Status = SlotStatus(varint)
+		}
+		return nil
+	})
+	return &slot, err
+}
+
+func decodeAccountUpdate(b []byte) (*AccountUpdate, error) {
+	var account AccountUpdate
+	err := decodeFields(b, func(field protowire.Number, value []byte, varint uint64) error {
+		switch field {
+		case 1:
+			// SubscribeUpdateAccountInfo:
+			return decodeFields(value, func(field protowire.Number, value []byte, varint uint64) error {
+				switch field {
+				case 1:
+					account.Pubkey = encodeBase58(value)
+				case 2:
+					account.Lamports = int64(varint)
+				case 3:
+					account.Owner = encodeBase58(value)
+				case 6:
+					account.Data = value
+				}
+				return nil
+			})
+		case 2:
+			account.Slot = int64(varint)
+		}
+		return nil
+	})
+	return &account, err
+}
+
+func decodeBlockMeta(b []byte) (*BlockMeta, error) {
+	var meta BlockMeta
+	err := decodeFields(b, func(field protowire.Number, value []byte, varint uint64) error {
+		switch field {
+		case 1:
+			meta.Slot = int64(varint)
+		case 2:
+			meta.Blockhash = string(value)
+		case 3:
+			// Rewards, of repeated Reward:
+			return decodeFields(value, func(field protowire.Number, value []byte, _ uint64) error {
+				if field != 1 {
+					return nil
+				}
+				reward, err := decodeReward(value)
+				meta.Rewards = append(meta.Rewards, reward)
+				return err
+			})
+		case 4:
+			// UnixTimestamp:
+			return decodeFields(value, func(field protowire.Number, _ []byte, varint uint64) error {
+				if field == 1 {
+					meta.BlockTime = int64(varint)
+				}
+				return nil
+			})
+		case 5:
+			// BlockHeight:
+			return decodeFields(value, func(field protowire.Number, _ []byte, varint uint64) error {
+				if field == 1 {
+					meta.BlockHeight = int64(varint)
+				}
+				return nil
+			})
+		case 6:
+			meta.ParentSlot = int64(varint)
+		case 8:
+			meta.ExecutedTransactionCount = int64(varint)
+		}
+		return nil
+	})
+	return &meta, err
+}
+
+func decodeReward(b []byte) (Reward, error) {
+	var reward Reward
+	err := decodeFields(b, func(field protowire.Number, value []byte, varint uint64) error {
+		switch field {
+		case 1:
+			reward.Pubkey = string(value)
+		case 2:
+			reward.Lamports = int64(varint)
+		case 4:
+			reward.RewardType = RewardType(varint)
+		}
+		return nil
+	})
+	return reward, err
+}
+
+// decodeFields walks the fields of the encoded message, passing each to handle with its value: length-delimited
+// values as bytes, and varint (and fixed) values as an integer.
+func decodeFields(b []byte, handle func(field protowire.Number, value []byte, varint uint64) error) error {
+	for len(b) > 0 {
+		field, wireType, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid field tag: %w", protowire.ParseError(n))
+		}
+		b = b[n:]
+		var (
+			value  []byte
+			varint uint64
+		)
+		switch wireType {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			varint, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var fixed uint32
+			fixed, n = protowire.ConsumeFixed32(b)
+			varint = uint64(fixed)
+		default:
+			n = protowire.ConsumeFieldValue(field, wireType, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid value of field %d: %w", field, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if err := handle(field, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeBase58 encodes the bytes (e.g., a pubkey) as base58, as Solana displays them.
+func encodeBase58(b []byte) string {
+	var encoded []byte
+	number, radix, remainder := new(big.Int).SetBytes(b), big.NewInt(58), new(big.Int)
+	for number.Sign() > 0 {
+		number.DivMod(number, radix, remainder)
+		encoded = append(encoded, base58Alphabet[remainder.Int64()])
+	}
+	// leading zero bytes are encoded as leading 1s:
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		encoded = append(encoded, base58Alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
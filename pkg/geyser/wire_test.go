@@ -0,0 +1,155 @@
+package geyser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendMessage(b []byte, field protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendVarint(b []byte, field protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, field, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func TestEncodeBase58(t *testing.T) {
+	assert.Equal(t, "2NEpo7TZRRrLZSi2U", encodeBase58([]byte("Hello World!")))
+	assert.Equal(t, "11111111111111111111111111111111", encodeBase58(make([]byte, 32)))
+	assert.Equal(t, "", encodeBase58(nil))
+}
+
+func TestEncodeSubscribeRequest(t *testing.T) {
+	request := encodeSubscribeRequest(
+		Subscription{Slots: true, Accounts: []string{"aaa", "bbb"}, Commitment: CommitmentConfirmed},
+	)
+
+	var (
+		slotFilters, accounts []string
+		commitment            uint64
+	)
+	require.NoError(t, decodeFields(request, func(field protowire.Number, value []byte, varint uint64) error {
+		switch field {
+		case requestSlotsField:
+			return decodeFields(value, func(field protowire.Number, value []byte, _ uint64) error {
+				if field == mapKeyField {
+					slotFilters = append(slotFilters, string(value))
+				}
+				return nil
+			})
+		case requestAccountsField:
+			return decodeFields(value, func(field protowire.Number, value []byte, _ uint64) error {
+				if field != mapValueField {
+					return nil
+				}
+				return decodeFields(value, func(field protowire.Number, value []byte, _ uint64) error {
+					if field == accountsFilterAccounts {
+						accounts = append(accounts, string(value))
+					}
+					return nil
+				})
+			})
+		case requestBlocksMetaField:
+			t.Error("unexpected blocks meta filter")
+		case requestCommitmentField:
+			commitment = varint
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{filterName}, slotFilters)
+	assert.Equal(t, []string{"aaa", "bbb"}, accounts)
+	assert.Equal(t, uint64(CommitmentConfirmed), commitment)
+}
+
+func TestDecodeUpdate(t *testing.T) {
+	t.Run("slot", func(t *testing.T) {
+		var slot []byte
+		slot = appendVarint(slot, 1, 100)
+		slot = appendVarint(slot, 2, 99)
+		slot = appendVarint(slot, 3, uint64(SlotFinalized))
+		// the filters matched, which are skipped:
+		update := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), filterName)
+		update = appendMessage(update, updateSlotField, slot)
+
+		u, ping, err := decodeUpdate(update)
+		require.NoError(t, err)
+		assert.False(t, ping)
+		assert.Equal(t, &SlotUpdate{Slot: 100, Parent: 99, Status: SlotFinalized}, u.Slot)
+		assert.Nil(t, u.Account)
+		assert.Nil(t, u.BlockMeta)
+	})
+
+	t.Run("account", func(t *testing.T) {
+		pubkey, owner := bytes.Repeat([]byte{0}, 32), []byte("Hello World!")
+		var info []byte
+		info = appendMessage(info, 1, pubkey)
+		info = appendVarint(info, 2, 5_000_000_000)
+		info = appendMessage(info, 3, owner)
+		info = appendMessage(info, 6, []byte{1, 2, 3})
+		account := appendMessage(nil, 1, info)
+		account = appendVarint(account, 2, 42)
+
+		u, _, err := decodeUpdate(appendMessage(nil, updateAccountField, account))
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			&AccountUpdate{
+				Pubkey:   "11111111111111111111111111111111",
+				Lamports: 5_000_000_000,
+				Owner:    "2NEpo7TZRRrLZSi2U",
+				Data:     []byte{1, 2, 3},
+				Slot:     42,
+			},
+			u.Account,
+		)
+	})
+
+	t.Run("block meta", func(t *testing.T) {
+		var reward []byte
+		reward = appendMessage(reward, 1, []byte("aaa"))
+		reward = appendVarint(reward, 2, 10_000)
+		reward = appendVarint(reward, 3, 20_000)
+		reward = appendVarint(reward, 4, uint64(RewardFee))
+		var meta []byte
+		meta = appendVarint(meta, 1, 100)
+		meta = appendMessage(meta, 2, []byte("hash"))
+		meta = appendMessage(meta, 3, appendMessage(nil, 1, reward))
+		meta = appendMessage(meta, 4, appendVarint(nil, 1, 1_700_000_000))
+		meta = appendMessage(meta, 5, appendVarint(nil, 1, 90))
+		meta = appendVarint(meta, 6, 99)
+		meta = appendVarint(meta, 8, 1234)
+
+		u, _, err := decodeUpdate(appendMessage(nil, updateBlockMetaField, meta))
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			&BlockMeta{
+				Slot:                     100,
+				Blockhash:                "hash",
+				Rewards:                  []Reward{{Pubkey: "aaa", Lamports: 10_000, RewardType: RewardFee}},
+				BlockTime:                1_700_000_000,
+				BlockHeight:              90,
+				ParentSlot:               99,
+				ExecutedTransactionCount: 1234,
+			},
+			u.BlockMeta,
+		)
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		_, ping, err := decodeUpdate(appendMessage(nil, updatePingField, nil))
+		require.NoError(t, err)
+		assert.True(t, ping)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := decodeUpdate([]byte{0x1a, 0x05, 0x01})
+		assert.Error(t, err)
+	})
+}
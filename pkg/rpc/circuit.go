@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for calls of a method whose circuit is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type (
+	// CircuitBreaker stops calling RPC methods which keep failing transiently, such that a struggling node is not
+	// hammered by the exporter. Once a method has failed threshold times in a row, its circuit opens and calls fail
+	// fast for the cooldown. After it, a single probe call is let through: its success closes the circuit, and its
+	// failure re-opens it for another cooldown.
+	CircuitBreaker struct {
+		threshold int
+		cooldown  time.Duration
+		circuits  map[string]*circuit
+		mu        sync.Mutex
+	}
+
+	circuit struct {
+		failures  int
+		openUntil time.Time
+		// probing is whether a probe call is in flight (while half-open)
+		probing bool
+	}
+)
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, circuits: make(map[string]*circuit)}
+}
+
+// allow returns an error if calls of the method are currently not allowed.
+func (b *CircuitBreaker) allow(method string, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.circuits[method]
+	if !ok || state.openUntil.IsZero() {
+		return nil
+	}
+	if now.Before(state.openUntil) || state.probing {
+		return fmt.Errorf("%w for %s until %s", ErrCircuitOpen, method, state.openUntil.Format(time.RFC3339))
+	}
+	// half-open, let a probe through:
+	state.probing = true
+	return nil
+}
+
+// record records the outcome of an allowed call of the method, exporting the state of its circuit to the metrics. Only
+// transient failures count towards opening the circuit, as other errors are returned by a healthy node.
+func (b *CircuitBreaker) record(method string, err error, now time.Time, metrics *Metrics) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.circuits[method]
+	if !ok {
+		state = &circuit{}
+		b.circuits[method] = state
+	}
+	wasOpen := !state.openUntil.IsZero()
+	state.probing = false
+	if errors.Is(err, context.Canceled) {
+		// the call was abandoned by the caller, which says nothing about the method's health
+		return
+	}
+	if err == nil || !IsRetryable(err) {
+		state.failures = 0
+		state.openUntil = time.Time{}
+		if wasOpen {
+			metrics.circuitOpen.WithLabelValues(method).Set(0)
+		}
+		return
+	}
+	state.failures++
+	if wasOpen || state.failures >= b.threshold {
+		state.openUntil = now.Add(b.cooldown)
+		metrics.circuitOpen.WithLabelValues(method).Set(1)
+	}
+}
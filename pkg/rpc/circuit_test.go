@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker, metrics := NewCircuitBreaker(2, time.Minute), NewMetrics(nil)
+	now := time.Now()
+	failure := &StatusError{StatusCode: http.StatusServiceUnavailable}
+
+	// rpc errors do not count, as they come from a healthy node:
+	breaker.record("getSlot", &Error{Code: NodeUnhealthyCode}, now, metrics)
+	assert.NoError(t, breaker.allow("getSlot", now))
+
+	// the circuit opens after threshold consecutive failures:
+	breaker.record("getSlot", failure, now, metrics)
+	assert.NoError(t, breaker.allow("getSlot", now))
+	breaker.record("getSlot", failure, now, metrics)
+	assert.ErrorIs(t, breaker.allow("getSlot", now), ErrCircuitOpen)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.circuitOpen.WithLabelValues("getSlot")))
+	// other methods are unaffected:
+	assert.NoError(t, breaker.allow("getEpochInfo", now))
+
+	// after the cooldown, a single probe is let through, and its failure re-opens the circuit:
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("getSlot", now))
+	assert.ErrorIs(t, breaker.allow("getSlot", now), ErrCircuitOpen)
+	breaker.record("getSlot", failure, now, metrics)
+	assert.ErrorIs(t, breaker.allow("getSlot", now.Add(time.Second)), ErrCircuitOpen)
+
+	// whereas its success closes it:
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("getSlot", now))
+	breaker.record("getSlot", nil, now, metrics)
+	assert.NoError(t, breaker.allow("getSlot", now))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.circuitOpen.WithLabelValues("getSlot")))
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	server, requests := newFlakyServer(t, 5, http.StatusServiceUnavailable)
+	client := NewRPCClient(server.URL, time.Second, nil)
+	client.CircuitBreaker = NewCircuitBreaker(2, time.Minute)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := client.GetSlot(ctx, CommitmentFinalized)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	// the node is no longer called while the circuit is open:
+	_, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int64(2), requests.Load())
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
 	"slices"
-	"sync/atomic"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
 	"go.uber.org/zap"
 )
@@ -20,7 +24,31 @@ type (
 		HttpClient  http.Client
 		RpcUrl      string
 		HttpTimeout time.Duration
-		logger      *zap.SugaredLogger
+		// Headers are added to every request, e.g., for authenticating with RPC providers
+		Headers http.Header
+		// AcceptEncodings are the compressed content encodings negotiated with the RPC server, in order of
+		// preference. Responses are decoded according to the encoding the server actually used.
+		AcceptEncodings []string
+		// Endpoints are the RPC endpoints failed over between, if more than one RPC url is configured
+		Endpoints *Endpoints
+		// Retry is the policy transient failures of each call are retried with
+		Retry RetryPolicy
+		// RateLimiter (if set) limits the rate of requests sent, including retries
+		RateLimiter *RateLimiter
+		// CircuitBreaker (if set) suspends calls of methods which keep failing
+		CircuitBreaker *CircuitBreaker
+		// SchemaDrift (if set) records drift between the results of calls and the types they are decoded into
+		SchemaDrift *SchemaDrift
+		// Recorder (if set) records the calls made, for a Replayer to serve back
+		Recorder *Recorder
+		// Replayer (if set) serves recorded responses instead of calling the RPC server
+		Replayer *Replayer
+		// flights deduplicates identical concurrent calls
+		flights *flightGroup
+		// calls counts the calls made per method, which are logged every minute
+		calls   *callCounter
+		metrics *Metrics
+		logger  *zap.SugaredLogger
 	}
 
 	Request struct {
@@ -49,30 +77,32 @@ const (
 	DevnetGenesisHash  = "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG"
 	TestnetGenesisHash = "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3zQawwpjk2NsNY"
 	MainnetGenesisHash = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d"
-)
 
-// Global map to count RPC calls per method
-var rpcCallCounts = make(map[string]*int64)
-var rpcCallCountsLock = make(chan struct{}, 1)
-
-func init() {
-	// Start a goroutine to log the counts every minute
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-		for {
-			<-ticker.C
-			rpcCallCountsLock <- struct{}{} // lock
-			logger := slog.Get()
-			logger.Infof("=== SOLANA RPC CALLS IN LAST MINUTE ===")
-			for method, countPtr := range rpcCallCounts {
-				count := atomic.SwapInt64(countPtr, 0)
-				logger.Infof("%s: %d", method, count)
-			}
-			<-rpcCallCountsLock // unlock
-		}
-	}()
-}
+	// ClockSysvar is the address of the Clock sysvar account
+	ClockSysvar = "SysvarC1ock11111111111111111111111111111111"
+	// SystemProgram is the owner of plain wallet accounts
+	SystemProgram = "11111111111111111111111111111111"
+	// MaxMultipleAccounts is the maximum number of accounts getMultipleAccounts accepts per call
+	MaxMultipleAccounts = 100
+	// TokenProgram is the SPL Token program
+	TokenProgram = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	// StakeProgram is the owner of stake accounts
+	StakeProgram = "Stake11111111111111111111111111111111111111"
+	// stakeAccountVoterOffset is the offset of the vote account a stake account delegates to in its data, after the
+	// state tag (4 bytes), rent-exempt reserve (8), authorities (2 * 32) and lockup (8 + 8 + 32)
+	stakeAccountVoterOffset = 124
+	// ConfigProgram is the owner of config accounts, such as the validator info published by validators
+	ConfigProgram = "Config1111111111111111111111111111111111111"
+
+	// MaxSlotLeadersLimit is the most slot leaders a single getSlotLeaders call returns
+	MaxSlotLeadersLimit = 5_000
+	// MaxSignaturesLimit is the most signatures a single getSignaturesForAddress call returns
+	MaxSignaturesLimit = 1_000
+	// MaxBlocksRange is the widest slot range a single getBlocks call covers
+	MaxBlocksRange = 500_000
+	// MaxPerformanceSamplesLimit is the most performance samples (taken every 60 seconds) the node keeps
+	MaxPerformanceSamplesLimit = 720
+)
 
 // GetClusterFromGenesisHash returns the cluster name based on the genesis hash
 func GetClusterFromGenesisHash(hash string) (string, error) {
@@ -88,50 +118,136 @@ func GetClusterFromGenesisHash(hash string) (string, error) {
 	}
 }
 
-func NewRPCClient(rpcAddr string, httpTimeout time.Duration) *Client {
-	return &Client{HttpClient: http.Client{}, RpcUrl: rpcAddr, HttpTimeout: httpTimeout, logger: slog.Get()}
+// NewRPCClient creates a client of the RPC server, whose metrics are registered with the registerer (if not nil).
+func NewRPCClient(rpcAddr string, httpTimeout time.Duration, registerer prometheus.Registerer) *Client {
+	return &Client{
+		HttpClient:  http.Client{},
+		RpcUrl:      rpcAddr,
+		HttpTimeout: httpTimeout,
+		flights:     newFlightGroup(),
+		calls:       newCallCounter(time.Now()),
+		metrics:     NewMetrics(registerer),
+		logger:      slog.Get(),
+	}
+}
+
+// NewFailoverRPCClient creates a client which sends requests to the first healthy of the provided RPC urls (in order
+// of priority), failing over to the next one on connection errors and server errors.
+func NewFailoverRPCClient(rpcAddrs []string, httpTimeout time.Duration, registerer prometheus.Registerer) *Client {
+	client := NewRPCClient(rpcAddrs[0], httpTimeout, registerer)
+	if len(rpcAddrs) > 1 {
+		client.Endpoints = NewEndpoints(rpcAddrs, DefaultEndpointCooldown, client.metrics)
+	}
+	return client
+}
+
+// post sends the request to the RPC server, failing over between the endpoints if configured. Each endpoint is tried
+// with its own http timeout, such that an endpoint which hangs is failed over from too.
+func (c *Client) post(ctx context.Context, method string, body []byte) (*http.Response, error) {
+	if c.Endpoints == nil {
+		return c.postTo(ctx, c.RpcUrl, body)
+	}
+	var lastErr error
+	for _, ep := range c.Endpoints.candidates(method, time.Now()) {
+		start := time.Now()
+		resp, err := c.postTo(ctx, ep.url, body)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			c.Endpoints.markSucceeded(ep, method, time.Since(start))
+			return resp, nil
+		}
+		if err == nil {
+			_ = resp.Body.Close()
+			err = &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		// only the caller giving up stops the failover, not the endpoint timing out:
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		c.logger.Warnf("%s rpc call to %s failed, failing over: %v", method, ep.label, err)
+		c.Endpoints.markFailed(ep, time.Now())
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// postTo sends the request to the rpc url, bound by the http timeout, which lasts until the response body is closed.
+func (c *Client) postTo(ctx context.Context, rpcUrl string, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.HttpTimeout)
+	ctx = c.metrics.withConnectionTrace(ctx, RedactUrl(rpcUrl))
+	req, err := http.NewRequestWithContext(ctx, "POST", rpcUrl, bytes.NewBuffer(body))
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("content-type", "application/json")
+	if len(c.AcceptEncodings) > 0 {
+		req.Header.Set("accept-encoding", strings.Join(c.AcceptEncodings, ", "))
+	}
+	resp, err := c.HttpClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// transport errors quote the url, which may embed an api key:
+		urlErr.URL = RedactUrl(urlErr.URL)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose is a response body which cancels the context of its request once closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
 
 func getResponse[T any](
 	ctx context.Context, client *Client, method string, params []any, rpcResponse *Response[T],
-) error {
+) (err error) {
+	defer func() {
+		if err != nil {
+			client.metrics.rpcErrors.WithLabelValues(method, ErrorCode(err)).Inc()
+		}
+	}()
 	logger := slog.Get()
 	// Count and log the call
-	rpcCallCountsLock <- struct{}{} // lock
-	if _, ok := rpcCallCounts[method]; !ok {
-		var zero int64
-		rpcCallCounts[method] = &zero
+	if client.calls != nil {
+		if counts := client.calls.count(method, time.Now()); counts != nil {
+			logger.Infof("=== SOLANA RPC CALLS IN LAST MINUTE (%s) ===", RedactUrl(client.RpcUrl))
+			for method, count := range counts {
+				logger.Infof("%s: %d", method, count)
+			}
+		}
 	}
-	atomic.AddInt64(rpcCallCounts[method], 1)
-	<-rpcCallCountsLock // unlock
 	logger.Debugf("SOLANA RPC CALL: method=%s params=%v", method, params)
 	// format request:
 	request := &Request{Jsonrpc: "2.0", Id: 1, Method: method, Params: params}
 	buffer, err := json.Marshal(request)
 	if err != nil {
-		logger.Fatalf("failed to marshal request: %v", err)
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
 	}
 	logger.Debugf("jsonrpc request: %s", string(buffer))
 
-	// make request:
-	ctx, cancel := context.WithTimeout(ctx, client.HttpTimeout)
-	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "POST", client.RpcUrl, bytes.NewBuffer(buffer))
-	if err != nil {
-		logger.Fatalf("failed to create request: %v", err)
-	}
-	req.Header.Set("content-type", "application/json")
-
-	resp, err := client.HttpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("%s rpc call failed: %w", method, err)
+	if client.CircuitBreaker != nil {
+		if err := client.CircuitBreaker.allow(method, time.Now()); err != nil {
+			return err
+		}
 	}
-	//goland:noinspection GoUnhandledErrorResult
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := client.dedupedCall(ctx, method, buffer)
 	if err != nil {
-		return fmt.Errorf("error processing %s rpc call: %w", method, err)
+		return err
 	}
 	// debug log response:
 	logger.Debugf("%s response: %v", method, string(body))
@@ -140,6 +256,9 @@ func getResponse[T any](
 	if err = json.Unmarshal(body, rpcResponse); err != nil {
 		return fmt.Errorf("failed to decode %s response body: %w", method, err)
 	}
+	if client.SchemaDrift != nil && rpcResponse.Error.Code == 0 {
+		client.SchemaDrift.check(method, body, reflect.TypeFor[T](), client.metrics)
+	}
 
 	// check for an actual rpc error
 	if rpcResponse.Error.Code != 0 {
@@ -149,6 +268,117 @@ func getResponse[T any](
 	return nil
 }
 
+// Call calls an RPC method, decoding its result into a T. It is meant for methods the client does not wrap (yet), and
+// goes through the same call counting, deduplication, rate limiting, retries, timeout and error handling as the
+// wrapped methods.
+func Call[T any](ctx context.Context, client *Client, method string, params []any) (T, error) {
+	if params == nil {
+		// sent as an empty array, as some methods reject a null params
+		params = []any{}
+	}
+	var resp Response[T]
+	if err := getResponse(ctx, client, method, params, &resp); err != nil {
+		var zero T
+		return zero, err
+	}
+	return resp.Result, nil
+}
+
+// dedupedCall makes an rpc call, sharing the response of an identical call already in flight (if any).
+func (c *Client) dedupedCall(ctx context.Context, method string, request []byte) ([]byte, error) {
+	call := func(ctx context.Context) ([]byte, error) {
+		body, err := c.call(ctx, method, request)
+		if c.CircuitBreaker != nil {
+			c.CircuitBreaker.record(method, err, time.Now(), c.metrics)
+		}
+		return body, err
+	}
+	if c.flights == nil {
+		return call(ctx)
+	}
+	body, err, shared := c.flights.do(ctx, string(request), c.callTimeout(), call)
+	if shared {
+		c.metrics.deduplicatedCalls.WithLabelValues(method).Inc()
+	}
+	return body, err
+}
+
+// callTimeout bounds a call which no single caller can cancel (i.e., a shared one): every attempt may try each endpoint
+// for up to the http timeout, and back off before the next one.
+func (c *Client) callTimeout() time.Duration {
+	attempts, endpoints := max(c.Retry.MaxAttempts, 1), 1
+	if c.Endpoints != nil {
+		endpoints = max(len(c.Endpoints.endpoints), 1)
+	}
+	timeout := time.Duration(attempts*endpoints) * c.HttpTimeout
+	unjittered := c.Retry
+	unjittered.Jitter = 0
+	for retry := 1; retry < attempts; retry++ {
+		timeout += time.Duration((1 + c.Retry.Jitter) * float64(unjittered.Backoff(retry)))
+	}
+	return timeout
+}
+
+// call makes an rpc call, retrying transient failures as per the retry policy, returning the decoded response body.
+func (c *Client) call(ctx context.Context, method string, request []byte) ([]byte, error) {
+	if c.Replayer != nil {
+		return c.Replayer.replay(RedactUrl(c.RpcUrl), method, request)
+	}
+	for attempt := 1; ; attempt++ {
+		body, err := c.attempt(ctx, method, request)
+		if err == nil {
+			if c.Recorder != nil {
+				if err := c.Recorder.record(RedactUrl(c.RpcUrl), method, request, body, time.Now()); err != nil {
+					c.logger.Errorf("%v", err)
+				}
+			}
+			return body, nil
+		}
+		if attempt >= c.Retry.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		backoff := c.Retry.Backoff(attempt)
+		c.logger.Warnf(
+			"%s rpc call failed (attempt %d/%d), retrying in %v: %v",
+			method, attempt, c.Retry.MaxAttempts, backoff, err,
+		)
+		c.metrics.rpcRetries.WithLabelValues(method).Inc()
+		if sleepErr := sleep(ctx, backoff); sleepErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt makes a single attempt at an rpc call, bound by the http timeout (of each endpoint tried), returning the
+// decoded response body.
+func (c *Client) attempt(ctx context.Context, method string, request []byte) ([]byte, error) {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx, method, c.metrics); err != nil {
+			return nil, fmt.Errorf("%s rpc call cancelled while rate limited: %w", method, err)
+		}
+	}
+	// every attempt counts towards the cost of the caller, even failed ones:
+	var received int
+	defer func() { c.metrics.recordCall(ctx, method, len(request), received) }()
+	resp, err := c.post(ctx, method, request)
+	if err != nil {
+		return nil, fmt.Errorf("%s rpc call failed: %w", method, err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		statusErr := &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		return nil, fmt.Errorf("%s rpc call failed: %w", method, statusErr)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	received = len(body)
+	if err != nil {
+		return nil, fmt.Errorf("error processing %s rpc call: %w", method, err)
+	}
+	return c.metrics.decodeBody(method, resp.Header.Get("content-encoding"), body)
+}
+
 // GetEpochInfo returns information about the current epoch.
 // See API docs: https://solana.com/docs/rpc/http/getepochinfo
 func (c *Client) GetEpochInfo(ctx context.Context, commitment Commitment) (*EpochInfo, error) {
@@ -165,6 +395,20 @@ func (c *Client) GetEpochInfo(ctx context.Context, commitment Commitment) (*Epoc
 func (c *Client) GetVoteAccounts(ctx context.Context, commitment Commitment) (*VoteAccounts, error) {
 	// format params:
 	config := map[string]string{"commitment": string(commitment)}
+	return getVoteAccounts(ctx, c, config)
+}
+
+// GetVoteAccountsOf returns the vote accounts like GetVoteAccounts, filtered (by the node) to the vote account of
+// votePubkey. This is far cheaper than fetching the whole (multi-MB) vote account set when only the one is needed.
+// See API docs: https://solana.com/docs/rpc/http/getvoteaccounts
+func (c *Client) GetVoteAccountsOf(
+	ctx context.Context, commitment Commitment, votePubkey string,
+) (*VoteAccounts, error) {
+	config := map[string]string{"commitment": string(commitment), "votePubkey": votePubkey}
+	return getVoteAccounts(ctx, c, config)
+}
+
+func getVoteAccounts(ctx context.Context, c *Client, config map[string]string) (*VoteAccounts, error) {
 	var resp Response[VoteAccounts]
 	if err := getResponse(ctx, c, "getVoteAccounts", []any{config}, &resp); err != nil {
 		return nil, err
@@ -175,7 +419,7 @@ func (c *Client) GetVoteAccounts(ctx context.Context, commitment Commitment) (*V
 // GetValidatorCredits returns the current epoch credits and total accumulated credits for a validator
 // See API docs: https://solana.com/docs/rpc/http/getvoteaccounts
 func (c *Client) GetValidatorCredits(validatorPubkey string) (*ValidatorCredits, error) {
-	voteAccounts, err := c.GetVoteAccounts(context.Background(), CommitmentConfirmed)
+	voteAccounts, err := c.GetVoteAccountsOf(context.Background(), CommitmentConfirmed, validatorPubkey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vote accounts: %w", err)
 	}
@@ -229,6 +473,17 @@ func (c *Client) GetSlot(ctx context.Context, commitment Commitment) (int64, err
 	return resp.Result, nil
 }
 
+// GetBlockHeight returns the current block height of the node at the given commitment.
+// See API docs: https://solana.com/docs/rpc/http/getblockheight
+func (c *Client) GetBlockHeight(ctx context.Context, commitment Commitment) (int64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getBlockHeight", []any{config}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
 // GetBlockProduction returns recent block production information from the current or previous epoch.
 // See API docs: https://solana.com/docs/rpc/http/getblockproduction
 func (c *Client) GetBlockProduction(
@@ -247,6 +502,50 @@ func (c *Client) GetBlockProduction(
 	return &resp.Result.Value, nil
 }
 
+// GetSupply returns the total and circulating supply of the cluster, leaving out the (long) list of non-circulating
+// accounts.
+// See API docs: https://solana.com/docs/rpc/http/getsupply
+func (c *Client) GetSupply(ctx context.Context, commitment Commitment) (*Supply, error) {
+	config := map[string]any{"commitment": string(commitment), "excludeNonCirculatingAccountsList": true}
+	var resp Response[contextualResult[Supply]]
+	if err := getResponse(ctx, c, "getSupply", []any{config}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result.Value, nil
+}
+
+// GetEpochSchedule returns the epoch schedule of the cluster.
+// See API docs: https://solana.com/docs/rpc/http/getepochschedule
+func (c *Client) GetEpochSchedule(ctx context.Context) (*EpochSchedule, error) {
+	var resp Response[EpochSchedule]
+	if err := getResponse(ctx, c, "getEpochSchedule", []any{}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
+// GetLatestBlockhash returns the latest blockhash of the node, and the slot at which it was read.
+// See API docs: https://solana.com/docs/rpc/http/getlatestblockhash
+func (c *Client) GetLatestBlockhash(ctx context.Context, commitment Commitment) (*LatestBlockhash, int64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[contextualResult[LatestBlockhash]]
+	if err := getResponse(ctx, c, "getLatestBlockhash", []any{config}, &resp); err != nil {
+		return nil, 0, err
+	}
+	return &resp.Result.Value, resp.Result.Context.Slot, nil
+}
+
+// GetInflationGovernor returns the inflation configuration of the cluster.
+// See API docs: https://solana.com/docs/rpc/http/getinflationgovernor
+func (c *Client) GetInflationGovernor(ctx context.Context, commitment Commitment) (*InflationGovernor, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[InflationGovernor]
+	if err := getResponse(ctx, c, "getInflationGovernor", []any{config}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Result, nil
+}
+
 // GetBalance returns the lamport balance of the account of provided pubkey.
 // See API docs:https://solana.com/docs/rpc/http/getbalance
 func (c *Client) GetBalance(ctx context.Context, commitment Commitment, address string) (float64, error) {
@@ -258,6 +557,29 @@ func (c *Client) GetBalance(ctx context.Context, commitment Commitment, address
 	return float64(resp.Result.Value) / float64(LamportsInSol), nil
 }
 
+// GetMultipleBalances returns the balances (in SOL) of up to MaxMultipleAccounts accounts in a single call, in the
+// order of the addresses. Accounts which do not exist have a zero balance, as with getBalance. Only the balances are
+// fetched, leaving out the account data.
+// See API docs: https://solana.com/docs/rpc/http/getmultipleaccounts
+func (c *Client) GetMultipleBalances(ctx context.Context, commitment Commitment, addresses []string) ([]float64, error) {
+	config := map[string]any{
+		"commitment": string(commitment),
+		"encoding":   "base64",
+		"dataSlice":  map[string]int{"offset": 0, "length": 0},
+	}
+	infos, err := getMultipleAccounts(ctx, c, addresses, config)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]float64, len(infos))
+	for i, info := range infos {
+		if info != nil {
+			balances[i] = float64(info.Lamports) / float64(LamportsInSol)
+		}
+	}
+	return balances, nil
+}
+
 // GetInflationReward returns the inflation / staking reward for a list of addresses for an epoch.
 // See API docs: https://solana.com/docs/rpc/http/getinflationreward
 func (c *Client) GetInflationReward(
@@ -276,6 +598,20 @@ func (c *Client) GetInflationReward(
 // See API docs: https://solana.com/docs/rpc/http/getleaderschedule
 func (c *Client) GetLeaderSchedule(ctx context.Context, commitment Commitment, slot int64) (map[string][]int64, error) {
 	config := map[string]any{"commitment": string(commitment)}
+	return getLeaderSchedule(ctx, c, slot, config)
+}
+
+// GetLeaderScheduleOf returns the leader schedule like GetLeaderSchedule, filtered (by the node) to the leader slots
+// of the identity. This is far cheaper than fetching the schedule of the whole cluster when only the one is needed.
+// See API docs: https://solana.com/docs/rpc/http/getleaderschedule
+func (c *Client) GetLeaderScheduleOf(
+	ctx context.Context, commitment Commitment, slot int64, identity string,
+) (map[string][]int64, error) {
+	config := map[string]any{"commitment": string(commitment), "identity": identity}
+	return getLeaderSchedule(ctx, c, slot, config)
+}
+
+func getLeaderSchedule(ctx context.Context, c *Client, slot int64, config map[string]any) (map[string][]int64, error) {
 	var resp Response[map[string][]int64]
 	if err := getResponse(ctx, c, "getLeaderSchedule", []any{slot, config}, &resp); err != nil {
 		return nil, err
@@ -283,6 +619,55 @@ func (c *Client) GetLeaderSchedule(ctx context.Context, commitment Commitment, s
 	return resp.Result, nil
 }
 
+// GetBlocks returns a list of confirmed blocks between two slots [inclusive].
+// See API docs: https://solana.com/docs/rpc/http/getblocks
+func (c *Client) GetBlocks(ctx context.Context, commitment Commitment, startSlot, endSlot int64) ([]int64, error) {
+	if commitment == CommitmentProcessed {
+		// as per https://solana.com/docs/rpc/http/getblocks
+		return nil, fmt.Errorf("%w: commitment '%v' is not supported for GetBlocks", ErrInvalidParams, CommitmentProcessed)
+	}
+	if endSlot < startSlot || endSlot-startSlot >= MaxBlocksRange {
+		return nil, fmt.Errorf(
+			"%w: slot range [%d, %d] must be in order and span at most %d slots",
+			ErrInvalidParams, startSlot, endSlot, MaxBlocksRange,
+		)
+	}
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[[]int64]
+	if err := getResponse(ctx, c, "getBlocks", []any{startSlot, endSlot, config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetRecentPerformanceSamples returns the limit most recent performance samples of the node, newest first.
+// See API docs: https://solana.com/docs/rpc/http/getrecentperformancesamples
+func (c *Client) GetRecentPerformanceSamples(ctx context.Context, limit int) ([]PerformanceSample, error) {
+	if limit < 1 || limit > MaxPerformanceSamplesLimit {
+		return nil, fmt.Errorf(
+			"%w: limit %d must be within [1, %d]", ErrInvalidParams, limit, MaxPerformanceSamplesLimit,
+		)
+	}
+	var resp Response[[]PerformanceSample]
+	if err := getResponse(ctx, c, "getRecentPerformanceSamples", []any{limit}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetSlotLeaders returns the leaders of the limit slots starting at startSlot [inclusive].
+// See API docs: https://solana.com/docs/rpc/http/getslotleaders
+func (c *Client) GetSlotLeaders(ctx context.Context, startSlot, limit int64) ([]string, error) {
+	if limit < 1 || limit > MaxSlotLeadersLimit {
+		return nil, fmt.Errorf("%w: limit %d must be within [1, %d]", ErrInvalidParams, limit, MaxSlotLeadersLimit)
+	}
+	var resp Response[[]string]
+	if err := getResponse(ctx, c, "getSlotLeaders", []any{startSlot, limit}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
 // GetBlock returns identity and transaction information about a confirmed block in the ledger.
 // See API docs: https://solana.com/docs/rpc/http/getblock
 func (c *Client) GetBlock(
@@ -290,13 +675,14 @@ func (c *Client) GetBlock(
 ) (*Block, error) {
 	detailsOptions := []string{"full", "none"}
 	if !slices.Contains(detailsOptions, transactionDetails) {
-		c.logger.Fatalf(
-			"%s is not a valid transaction-details option, must be one of %v", transactionDetails, detailsOptions,
+		return nil, fmt.Errorf(
+			"%w: %s is not a valid transaction-details option, must be one of %v",
+			ErrInvalidParams, transactionDetails, detailsOptions,
 		)
 	}
 	if commitment == CommitmentProcessed {
 		// as per https://solana.com/docs/rpc/http/getblock
-		c.logger.Fatalf("commitment '%v' is not supported for GetBlock", CommitmentProcessed)
+		return nil, fmt.Errorf("%w: commitment '%v' is not supported for GetBlock", ErrInvalidParams, CommitmentProcessed)
 	}
 	config := map[string]any{
 		"commitment":                     commitment,
@@ -343,6 +729,42 @@ func (c *Client) GetFirstAvailableBlock(ctx context.Context) (int64, error) {
 	return resp.Result, nil
 }
 
+// GetMaxRetransmitSlot returns the highest slot the node has seen (shreds of) from the retransmit stage, i.e., from
+// turbine.
+// See API docs: https://solana.com/docs/rpc/http/getmaxretransmitslot
+func (c *Client) GetMaxRetransmitSlot(ctx context.Context) (int64, error) {
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getMaxRetransmitSlot", []any{}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
+// GetMaxShredInsertSlot returns the highest slot the node has inserted shreds of into its blockstore, after shred
+// verification.
+// See API docs: https://solana.com/docs/rpc/http/getmaxshredinsertslot
+func (c *Client) GetMaxShredInsertSlot(ctx context.Context) (int64, error) {
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getMaxShredInsertSlot", []any{}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
+
+// GetBlockTime returns the (estimated) unix timestamp at which the block of the slot was produced. It errors if the
+// node has no timestamp for the block.
+// See API docs: https://solana.com/docs/rpc/http/getblocktime
+func (c *Client) GetBlockTime(ctx context.Context, slot int64) (int64, error) {
+	var resp Response[*int64]
+	if err := getResponse(ctx, c, "getBlockTime", []any{slot}, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Result == nil {
+		return 0, fmt.Errorf("no block time available for slot %d", slot)
+	}
+	return *resp.Result, nil
+}
+
 // GetGenesisHash returns the hash of the genesis block
 // See API docs: https://solana.com/docs/rpc/http/getgenesishash
 func (c *Client) GetGenesisHash(ctx context.Context) (string, error) {
@@ -352,3 +774,236 @@ func (c *Client) GetGenesisHash(ctx context.Context) (string, error) {
 	}
 	return resp.Result, nil
 }
+
+// GetAccountInfo returns all information associated with the account of provided pubkey, with jsonParsed data.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetAccountInfo(ctx context.Context, commitment Commitment, address string) (*AccountInfo, error) {
+	info, _, err := c.GetAccountInfoWithSlot(ctx, commitment, address)
+	return info, err
+}
+
+// GetMultipleAccounts returns the information of up to MaxMultipleAccounts accounts in a single call, with jsonParsed
+// data, in the order of the addresses. Accounts which do not exist are nil.
+// See API docs: https://solana.com/docs/rpc/http/getmultipleaccounts
+func (c *Client) GetMultipleAccounts(
+	ctx context.Context, commitment Commitment, addresses []string,
+) ([]*AccountInfo, error) {
+	config := map[string]string{"commitment": string(commitment), "encoding": "jsonParsed"}
+	return getMultipleAccounts(ctx, c, addresses, config)
+}
+
+func getMultipleAccounts(ctx context.Context, c *Client, addresses []string, config any) ([]*AccountInfo, error) {
+	if len(addresses) > MaxMultipleAccounts {
+		return nil, fmt.Errorf("cannot get more than %d accounts at once, got %d", MaxMultipleAccounts, len(addresses))
+	}
+	var resp Response[contextualResult[[]*AccountInfo]]
+	if err := getResponse(ctx, c, "getMultipleAccounts", []any{addresses, config}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Result.Value) != len(addresses) {
+		return nil, fmt.Errorf("expected %d accounts, got %d", len(addresses), len(resp.Result.Value))
+	}
+	return resp.Result.Value, nil
+}
+
+// GetAccountInfoWithSlot is GetAccountInfo, additionally returning the slot at which the account info was read.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetAccountInfoWithSlot(
+	ctx context.Context, commitment Commitment, address string,
+) (*AccountInfo, int64, error) {
+	config := map[string]string{"commitment": string(commitment), "encoding": "jsonParsed"}
+	var resp Response[contextualResult[*AccountInfo]]
+	if err := getResponse(ctx, c, "getAccountInfo", []any{address, config}, &resp); err != nil {
+		return nil, 0, err
+	}
+	if resp.Result.Value == nil {
+		return nil, 0, fmt.Errorf("%w: %s", ErrAccountNotFound, address)
+	}
+	return resp.Result.Value, resp.Result.Context.Slot, nil
+}
+
+// GetClock returns the Clock sysvar, i.e., the on-chain view of the current slot, epoch and unix timestamp.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetClock(ctx context.Context, commitment Commitment) (*Clock, error) {
+	info, err := c.GetAccountInfo(ctx, commitment, ClockSysvar)
+	if err != nil {
+		return nil, err
+	}
+	var data ParsedAccountData[Clock]
+	if err := json.Unmarshal(info.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode clock sysvar: %w", err)
+	}
+	return &data.Parsed.Info, nil
+}
+
+// GetClusterNodes returns information about all the nodes participating in the cluster, as seen in the node's gossip.
+// See API docs: https://solana.com/docs/rpc/http/getclusternodes
+func (c *Client) GetClusterNodes(ctx context.Context) ([]ClusterNode, error) {
+	var resp Response[[]ClusterNode]
+	if err := getResponse(ctx, c, "getClusterNodes", []any{}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetVoteAccountState returns the on-chain state of a vote account, including its scheduled authorized voters.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetVoteAccountState(ctx context.Context, commitment Commitment, votekey string) (*VoteAccountState, error) {
+	info, err := c.GetAccountInfo(ctx, commitment, votekey)
+	if err != nil {
+		return nil, err
+	}
+	var data ParsedAccountData[VoteAccountState]
+	if err := json.Unmarshal(info.Data, &data); err != nil || data.Parsed.Type != "vote" {
+		return nil, fmt.Errorf("%s is not a vote account", votekey)
+	}
+	return &data.Parsed.Info, nil
+}
+
+// GetNonceAccountWithSlot returns the state of an initialized durable nonce account, and the slot at which it was read.
+// See API docs: https://solana.com/docs/rpc/http/getaccountinfo
+func (c *Client) GetNonceAccountWithSlot(
+	ctx context.Context, commitment Commitment, address string,
+) (*NonceAccount, int64, error) {
+	info, slot, err := c.GetAccountInfoWithSlot(ctx, commitment, address)
+	if err != nil {
+		return nil, 0, err
+	}
+	var data ParsedAccountData[NonceAccount]
+	if err := json.Unmarshal(info.Data, &data); err != nil || data.Program != "nonce" || data.Parsed.Type != "initialized" {
+		return nil, 0, fmt.Errorf("%s is not an initialized nonce account", address)
+	}
+	data.Parsed.Info.Lamports = info.Lamports
+	return &data.Parsed.Info, slot, nil
+}
+
+// GetTokenAccountsByOwner returns the SPL token accounts of the mint held by the owner, by address.
+// See API docs: https://solana.com/docs/rpc/http/gettokenaccountsbyowner
+func (c *Client) GetTokenAccountsByOwner(
+	ctx context.Context, commitment Commitment, owner, mint string,
+) (map[string]*TokenAccount, error) {
+	config := map[string]string{"commitment": string(commitment), "encoding": "jsonParsed"}
+	filter := map[string]string{"mint": mint}
+	var resp Response[contextualResult[[]keyedAccount]]
+	if err := getResponse(ctx, c, "getTokenAccountsByOwner", []any{owner, filter, config}, &resp); err != nil {
+		return nil, err
+	}
+	accounts := make(map[string]*TokenAccount, len(resp.Result.Value))
+	for _, keyed := range resp.Result.Value {
+		var data ParsedAccountData[TokenAccount]
+		if err := json.Unmarshal(keyed.Account.Data, &data); err != nil || data.Parsed.Type != "account" {
+			return nil, fmt.Errorf("%s is not an initialized token account", keyed.Pubkey)
+		}
+		accounts[keyed.Pubkey] = &data.Parsed.Info
+	}
+	return accounts, nil
+}
+
+// GetStakeDelegations returns the delegations of the stake accounts delegated to the vote account, by stake account
+// address. This scans all stake accounts of the cluster, which makes it one of the most expensive RPC calls.
+// See API docs: https://solana.com/docs/rpc/http/getprogramaccounts
+func (c *Client) GetStakeDelegations(
+	ctx context.Context, commitment Commitment, votekey string,
+) (map[string]*StakeDelegation, error) {
+	config := map[string]any{
+		"commitment": string(commitment),
+		"encoding":   "jsonParsed",
+		"filters": []any{
+			map[string]any{"memcmp": map[string]any{"offset": stakeAccountVoterOffset, "bytes": votekey}},
+		},
+	}
+	var resp Response[[]keyedAccount]
+	if err := getResponse(ctx, c, "getProgramAccounts", []any{StakeProgram, config}, &resp); err != nil {
+		return nil, err
+	}
+	delegations := make(map[string]*StakeDelegation, len(resp.Result))
+	for _, keyed := range resp.Result {
+		var data ParsedAccountData[StakeAccountState]
+		if err := json.Unmarshal(keyed.Account.Data, &data); err != nil || data.Program != "stake" {
+			return nil, fmt.Errorf("%s is not a stake account", keyed.Pubkey)
+		}
+		// the filter matches stake accounts delegated to the vote account, but not ones merely initialized:
+		if data.Parsed.Info.Stake == nil || data.Parsed.Info.Stake.Delegation.Voter != votekey {
+			continue
+		}
+		delegations[keyed.Pubkey] = &data.Parsed.Info.Stake.Delegation
+	}
+	return delegations, nil
+}
+
+// GetValidatorInfos returns the validator info published (through `solana validator-info publish`) by validators,
+// by identity.
+// See API docs: https://solana.com/docs/rpc/http/getprogramaccounts
+func (c *Client) GetValidatorInfos(ctx context.Context, commitment Commitment) (map[string]*ValidatorInfo, error) {
+	config := map[string]any{"commitment": string(commitment), "encoding": "jsonParsed"}
+	var resp Response[[]keyedAccount]
+	if err := getResponse(ctx, c, "getProgramAccounts", []any{ConfigProgram, config}, &resp); err != nil {
+		return nil, err
+	}
+	infos := make(map[string]*ValidatorInfo)
+	for _, keyed := range resp.Result {
+		var data ParsedAccountData[validatorInfoConfig]
+		// the config program holds other configs as well (e.g., the stake config), which are skipped:
+		if err := json.Unmarshal(keyed.Account.Data, &data); err != nil || data.Parsed.Type != "validatorInfo" {
+			continue
+		}
+		// the validator info is signed by the identity it describes:
+		for _, key := range data.Parsed.Info.Keys {
+			if key.Signer {
+				infos[key.Pubkey] = &data.Parsed.Info.ConfigData
+				break
+			}
+		}
+	}
+	return infos, nil
+}
+
+// GetSignaturesForAddress returns the signatures of the latest (up to limit) transactions involving the address,
+// newest first.
+// See API docs: https://solana.com/docs/rpc/http/getsignaturesforaddress
+func (c *Client) GetSignaturesForAddress(
+	ctx context.Context, commitment Commitment, address string, limit int,
+) ([]SignatureInfo, error) {
+	config := map[string]any{"commitment": string(commitment), "limit": limit}
+	var resp Response[[]SignatureInfo]
+	if err := getResponse(ctx, c, "getSignaturesForAddress", []any{address, config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetTransaction returns the transaction of the signature, or nil if the node has no such transaction at the
+// commitment (i.e., it is not (yet) confirmed, or has been dropped).
+// See API docs: https://solana.com/docs/rpc/http/gettransaction
+func (c *Client) GetTransaction(ctx context.Context, commitment Commitment, signature string) (*Transaction, error) {
+	if commitment == CommitmentProcessed {
+		// as per https://solana.com/docs/rpc/http/gettransaction
+		return nil, fmt.Errorf(
+			"%w: commitment '%v' is not supported for GetTransaction", ErrInvalidParams, CommitmentProcessed,
+		)
+	}
+	config := map[string]any{
+		"commitment":                     commitment,
+		"encoding":                       "json",
+		"maxSupportedTransactionVersion": 0,
+	}
+	var resp Response[*Transaction]
+	if err := getResponse(ctx, c, "getTransaction", []any{signature, config}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Result, nil
+}
+
+// GetMinimumBalanceForRentExemption returns the minimum balance (in lamports) required to make an account with the
+// provided data size rent exempt.
+// See API docs: https://solana.com/docs/rpc/http/getminimumbalanceforrentexemption
+func (c *Client) GetMinimumBalanceForRentExemption(
+	ctx context.Context, commitment Commitment, dataSize int64,
+) (int64, error) {
+	config := map[string]string{"commitment": string(commitment)}
+	var resp Response[int64]
+	if err := getResponse(ctx, c, "getMinimumBalanceForRentExemption", []any{dataSize, config}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Result, nil
+}
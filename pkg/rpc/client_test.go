@@ -2,7 +2,11 @@ package rpc
 
 import (
 	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -96,6 +100,45 @@ func TestClient_GetBlockProduction(t *testing.T) {
 	)
 }
 
+func TestClient_GetBlocks(t *testing.T) {
+	_, client := newMethodTester(t, "getBlocks", []int{5, 6, 7, 8, 9, 10}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := client.GetBlocks(ctx, CommitmentFinalized, 5, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 6, 7, 8, 9, 10}, blocks)
+
+	_, err = client.GetBlocks(ctx, CommitmentProcessed, 5, 10)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = client.GetBlocks(ctx, CommitmentFinalized, 10, 5)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = client.GetBlocks(ctx, CommitmentFinalized, 0, MaxBlocksRange)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestClient_GetSlotLeaders(t *testing.T) {
+	_, client := newMethodTester(t, "getSlotLeaders", []string{"aaa", "aaa", "bbb"}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaders, err := client.GetSlotLeaders(ctx, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"aaa", "aaa", "bbb"}, leaders)
+
+	_, err = client.GetSlotLeaders(ctx, 2, 0)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = client.GetSlotLeaders(ctx, 2, MaxSlotLeadersLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	// rpc errors (e.g., for slots beyond the known leader schedules) are returned as such:
+	_, client = newMethodTester(t, "getSlotLeaders", nil, &Error{Code: -32602, Message: "Invalid slot range"})
+	_, err = client.GetSlotLeaders(ctx, 1_000_000, 10)
+	var rpcErr *Error
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "getSlotLeaders", rpcErr.Method)
+}
+
 func TestClient_GetEpochInfo(t *testing.T) {
 	_, client := newMethodTester(t,
 		"getEpochInfo",
@@ -137,6 +180,65 @@ func TestClient_GetFirstAvailableBlock(t *testing.T) {
 	assert.Equal(t, 250_000, int(block))
 }
 
+func TestClient_GetMaxRetransmitSlot(t *testing.T) {
+	_, client := newMethodTester(t, "getMaxRetransmitSlot", 1234, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slot, err := client.GetMaxRetransmitSlot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234), slot)
+}
+
+func TestClient_GetMaxShredInsertSlot(t *testing.T) {
+	_, client := newMethodTester(t, "getMaxShredInsertSlot", 1235, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slot, err := client.GetMaxShredInsertSlot(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1235), slot)
+}
+
+func TestClient_GetRecentPerformanceSamples(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getRecentPerformanceSamples",
+		[]map[string]int{
+			{"slot": 348125, "numSlots": 126, "numTransactions": 126, "numNonVoteTransactions": 1, "samplePeriodSecs": 60},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	samples, err := client.GetRecentPerformanceSamples(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]PerformanceSample{
+			{Slot: 348125, NumSlots: 126, NumTransactions: 126, NumNonVoteTransactions: 1, SamplePeriodSecs: 60},
+		},
+		samples,
+	)
+
+	_, err = client.GetRecentPerformanceSamples(ctx, 0)
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestClient_GetBlockTime(t *testing.T) {
+	_, client := newMethodTester(t, "getBlockTime", 1_700_000_000, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockTime, err := client.GetBlockTime(ctx, 250_000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1_700_000_000), blockTime)
+
+	// blocks without a timestamp:
+	_, client = newMethodTester(t, "getBlockTime", nil, nil)
+	_, err = client.GetBlockTime(ctx, 250_000)
+	assert.Error(t, err)
+}
+
 func TestClient_GetHealth(t *testing.T) {
 	// using example responses in the docs: https://solana.com/docs/rpc/http/gethealth
 	t.Run("healthy-node", func(t *testing.T) {
@@ -218,6 +320,14 @@ func TestClient_GetLeaderSchedule(t *testing.T) {
 	schedule, err := client.GetLeaderSchedule(ctx, CommitmentFinalized, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedSchedule, schedule)
+
+	schedule, err = client.GetLeaderScheduleOf(ctx, CommitmentFinalized, 1, "bbb")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]int64{"bbb": {5, 6, 7, 8, 9}}, schedule)
+
+	schedule, err = client.GetLeaderScheduleOf(ctx, CommitmentFinalized, 1, "ddd")
+	assert.NoError(t, err)
+	assert.Empty(t, schedule)
 }
 
 func TestClient_GetMinimumLedgerSlot(t *testing.T) {
@@ -240,6 +350,16 @@ func TestClient_GetSlot(t *testing.T) {
 	assert.Equal(t, int64(1234), slot)
 }
 
+func TestClient_GetBlockHeight(t *testing.T) {
+	_, client := newMethodTester(t, "getBlockHeight", 1200, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	height, err := client.GetBlockHeight(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1200), height)
+}
+
 func TestClient_GetVersion(t *testing.T) {
 	expectedResult := map[string]any{"feature-set": 2891131721, "solana-core": "1.16.7"}
 	_, client := newMethodTester(t, "getVersion", expectedResult, nil)
@@ -279,10 +399,12 @@ func TestClient_GetVoteAccounts(t *testing.T) {
 		&VoteAccounts{
 			Current: []VoteAccount{
 				{
-					NodePubkey:     "B97CCUW3AEZFGy6uUg6zUdnNYvnVq5VG8PUtb2HayTDD",
-					LastVote:       147,
-					ActivatedStake: 42,
-					VotePubkey:     "3ZT31jkAGhUaw8jsy4bTknwBMP8i4Eueh52By4zXcsVw",
+					NodePubkey:       "B97CCUW3AEZFGy6uUg6zUdnNYvnVq5VG8PUtb2HayTDD",
+					LastVote:         147,
+					ActivatedStake:   42,
+					VotePubkey:       "3ZT31jkAGhUaw8jsy4bTknwBMP8i4Eueh52By4zXcsVw",
+					EpochCredits:     [][]int64{{1, 64, 0}, {2, 192, 64}},
+					EpochVoteAccount: true,
 				},
 			},
 		},
@@ -302,3 +424,542 @@ func TestClient_GetIdentity(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "random2r1F4iWqVcb8M1DbAjQuFpebkQuW2DJtestkey", identity)
 }
+
+func TestClient_GetClock(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, nil, nil, nil, nil)
+	server.SetOpt(AccountInfoOpt, ClockSysvar, map[string]any{
+		"lamports": 1169280,
+		"owner":    "Sysvar1111111111111111111111111111111111111",
+		"space":    40,
+		"data": map[string]any{
+			"program": "sysvar",
+			"parsed": map[string]any{
+				"type": "clock",
+				"info": map[string]any{
+					"slot":                100,
+					"epoch":               2,
+					"epochStartTimestamp": 1_700_000_000,
+					"leaderScheduleEpoch": 3,
+					"unixTimestamp":       1_700_000_040,
+				},
+			},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock, err := client.GetClock(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&Clock{
+			Slot:                100,
+			Epoch:               2,
+			EpochStartTimestamp: 1_700_000_000,
+			LeaderScheduleEpoch: 3,
+			UnixTimestamp:       1_700_000_040,
+		},
+		clock,
+	)
+
+	// unknown accounts are errors:
+	_, err = client.GetAccountInfo(ctx, CommitmentFinalized, "aaa")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestClient_GetAccountInfoWithSlot(t *testing.T) {
+	_, client := NewMockClient(t, nil, nil, map[string]int{"aaa": 5}, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	info, slot, err := client.GetAccountInfoWithSlot(ctx, CommitmentFinalized, "aaa")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), info.Lamports)
+	assert.Equal(t, SystemProgram, info.Owner)
+	assert.Equal(t, int64(1), slot)
+
+	_, _, err = client.GetAccountInfoWithSlot(ctx, CommitmentFinalized, "bbb")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestClient_GetMultipleAccounts(t *testing.T) {
+	_, client := NewMockClient(t, nil, nil, map[string]int{"aaa": 5, "bbb": 2 * LamportsInSol}, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infos, err := client.GetMultipleAccounts(ctx, CommitmentFinalized, []string{"aaa", "xxx", "bbb"})
+	assert.NoError(t, err)
+	assert.Len(t, infos, 3)
+	assert.Equal(t, int64(5), infos[0].Lamports)
+	assert.Nil(t, infos[1])
+	assert.Equal(t, SystemProgram, infos[2].Owner)
+
+	balances, err := client.GetMultipleBalances(ctx, CommitmentFinalized, []string{"bbb", "xxx"})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{2, 0}, balances)
+
+	_, err = client.GetMultipleAccounts(ctx, CommitmentFinalized, make([]string, MaxMultipleAccounts+1))
+	assert.Error(t, err)
+}
+
+func TestClient_GetTokenAccountsByOwner(t *testing.T) {
+	const usdc = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	_, client := newMethodTester(t,
+		"getTokenAccountsByOwner",
+		map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": []map[string]any{{
+				"pubkey": "tokenAccount",
+				"account": map[string]any{
+					"lamports": 2_039_280,
+					"owner":    "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA",
+					"space":    165,
+					"data": map[string]any{
+						"program": "spl-token",
+						"parsed": map[string]any{
+							"type": "account",
+							"info": map[string]any{
+								"mint":  usdc,
+								"owner": "aaa",
+								"tokenAmount": map[string]any{
+									"amount": "1500000", "decimals": 6, "uiAmount": 1.5, "uiAmountString": "1.5",
+								},
+							},
+						},
+					},
+				},
+			}},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accounts, err := client.GetTokenAccountsByOwner(ctx, CommitmentFinalized, "aaa", usdc)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		map[string]*TokenAccount{
+			"tokenAccount": {
+				Mint: usdc, Owner: "aaa", TokenAmount: TokenAmount{Amount: "1500000", Decimals: 6, UiAmountString: "1.5"},
+			},
+		},
+		accounts,
+	)
+	amount, err := accounts["tokenAccount"].TokenAmount.UiAmount()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, amount)
+}
+
+func TestClient_GetStakeDelegations(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getProgramAccounts",
+		[]map[string]any{
+			{
+				"pubkey": "stake1",
+				"account": map[string]any{
+					"lamports": 5 * LamportsInSol,
+					"owner":    StakeProgram,
+					"data": map[string]any{
+						"program": "stake",
+						"parsed": map[string]any{
+							"type": "delegated",
+							"info": map[string]any{
+								"stake": map[string]any{
+									"delegation": map[string]any{
+										"voter":             "AAA",
+										"stake":             "4997717120",
+										"activationEpoch":   "100",
+										"deactivationEpoch": "18446744073709551615",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			// initialized, but not delegated:
+			{
+				"pubkey": "stake2",
+				"account": map[string]any{
+					"lamports": LamportsInSol,
+					"owner":    StakeProgram,
+					"data": map[string]any{
+						"program": "stake",
+						"parsed":  map[string]any{"type": "initialized", "info": map[string]any{}},
+					},
+				},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	delegations, err := client.GetStakeDelegations(ctx, CommitmentFinalized, "AAA")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		map[string]*StakeDelegation{
+			"stake1": {
+				Voter: "AAA", Stake: 4_997_717_120, ActivationEpoch: 100, DeactivationEpoch: math.MaxUint64,
+			},
+		},
+		delegations,
+	)
+}
+
+func TestClient_GetValidatorInfos(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getProgramAccounts",
+		[]map[string]any{
+			{
+				"pubkey": "info1",
+				"account": map[string]any{
+					"owner": ConfigProgram,
+					"data": map[string]any{
+						"program": "config",
+						"parsed": map[string]any{
+							"type": "validatorInfo",
+							"info": map[string]any{
+								"keys": []map[string]any{
+									{"pubkey": "Va1idator1nfo111111111111111111111111111111", "signer": false},
+									{"pubkey": "aaa", "signer": true},
+								},
+								"configData": map[string]any{"name": "Validator A", "website": "https://a.example.com"},
+							},
+						},
+					},
+				},
+			},
+			// other configs are skipped:
+			{
+				"pubkey": "StakeConfig11111111111111111111111111111111",
+				"account": map[string]any{
+					"owner": ConfigProgram,
+					"data": map[string]any{
+						"program": "config",
+						"parsed":  map[string]any{"type": "stakeConfig", "info": map[string]any{}},
+					},
+				},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	infos, err := client.GetValidatorInfos(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		map[string]*ValidatorInfo{"aaa": {Name: "Validator A", Website: "https://a.example.com"}},
+		infos,
+	)
+}
+
+func TestClient_GetSignaturesForAddress(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getSignaturesForAddress",
+		[]map[string]any{
+			{
+				"signature":          "5h6xBEauJ3PK6SWCZ1PGjBvj8vDdWG3KpwATGy1ARAXFSDwt8GFXM7W5Ncn16wmqokgpiKRLuS83KUxyZyv2sUYv",
+				"slot":               114,
+				"err":                nil,
+				"memo":               nil,
+				"blockTime":          nil,
+				"confirmationStatus": "finalized",
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signatures, err := client.GetSignaturesForAddress(ctx, CommitmentFinalized, "aaa", 1)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]SignatureInfo{{
+			Signature:          "5h6xBEauJ3PK6SWCZ1PGjBvj8vDdWG3KpwATGy1ARAXFSDwt8GFXM7W5Ncn16wmqokgpiKRLuS83KUxyZyv2sUYv",
+			Slot:               114,
+			ConfirmationStatus: "finalized",
+		}},
+		signatures,
+	)
+}
+
+func TestClient_GetTransaction(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getTransaction",
+		map[string]any{
+			"slot":        430,
+			"blockTime":   nil,
+			"meta":        map[string]any{"err": nil, "fee": 5000, "computeUnitsConsumed": 150},
+			"transaction": map[string]any{},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	transaction, err := client.GetTransaction(ctx, CommitmentConfirmed, "aaa")
+	assert.NoError(t, err)
+	computeUnits := int64(150)
+	assert.Equal(t,
+		&Transaction{Slot: 430, Meta: &TransactionMeta{Fee: 5000, ComputeUnitsConsumed: &computeUnits}},
+		transaction,
+	)
+
+	_, err = client.GetTransaction(ctx, CommitmentProcessed, "aaa")
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
+
+func TestClient_GetSupply(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getSupply",
+		map[string]any{
+			"context": map[string]int{"slot": 1},
+			"value": map[string]any{
+				"total": 600 * LamportsInSol, "circulating": 500 * LamportsInSol,
+				"nonCirculating": 100 * LamportsInSol, "nonCirculatingAccounts": []string{},
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supply, err := client.GetSupply(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&Supply{Total: 600 * LamportsInSol, Circulating: 500 * LamportsInSol, NonCirculating: 100 * LamportsInSol},
+		supply,
+	)
+}
+
+func TestClient_GetEpochSchedule(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getEpochSchedule",
+		map[string]any{
+			"firstNormalEpoch": 8, "firstNormalSlot": 8160, "leaderScheduleSlotOffset": 8192, "slotsPerEpoch": 8192,
+			"warmup": true,
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schedule, err := client.GetEpochSchedule(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&EpochSchedule{
+			SlotsPerEpoch: 8192, LeaderScheduleSlotOffset: 8192, Warmup: true, FirstNormalEpoch: 8, FirstNormalSlot: 8160,
+		},
+		schedule,
+	)
+}
+
+func TestClient_GetLatestBlockhash(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getLatestBlockhash",
+		map[string]any{
+			"context": map[string]int{"slot": 2792},
+			"value": map[string]any{
+				"blockhash": "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", "lastValidBlockHeight": 3090,
+			},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blockhash, slot, err := client.GetLatestBlockhash(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&LatestBlockhash{Blockhash: "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N", LastValidBlockHeight: 3090},
+		blockhash,
+	)
+	assert.Equal(t, int64(2792), slot)
+}
+
+func TestClient_GetInflationGovernor(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getInflationGovernor",
+		map[string]float64{"initial": 0.08, "terminal": 0.015, "taper": 0.15, "foundation": 0.05, "foundationTerm": 7},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	governor, err := client.GetInflationGovernor(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&InflationGovernor{Initial: 0.08, Terminal: 0.015, Taper: 0.15, Foundation: 0.05, FoundationTerm: 7},
+		governor,
+	)
+}
+
+func TestClient_GetMinimumBalanceForRentExemption(t *testing.T) {
+	_, client := newMethodTester(t, "getMinimumBalanceForRentExemption", 890880, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	balance, err := client.GetMinimumBalanceForRentExemption(ctx, CommitmentFinalized, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(890880), balance)
+}
+
+func TestClient_GetVoteAccountState(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, map[string]int{"aaa": 1}, nil, nil, nil)
+	server.SetOpt(AccountInfoOpt, "AAA", map[string]any{
+		"lamports": 27074400,
+		"owner":    "Vote111111111111111111111111111111111111111",
+		"space":    3762,
+		"data": map[string]any{
+			"program": "vote",
+			"parsed": map[string]any{
+				"type": "vote",
+				"info": map[string]any{
+					"nodePubkey":           "aaa",
+					"authorizedWithdrawer": "www",
+					"commission":           5,
+					"authorizedVoters": []map[string]any{
+						{"authorizedVoter": "aaa", "epoch": 2},
+						{"authorizedVoter": "vvv", "epoch": 3},
+					},
+				},
+			},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, err := client.GetVoteAccountState(ctx, CommitmentFinalized, "AAA")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		&VoteAccountState{
+			NodePubkey:           "aaa",
+			AuthorizedWithdrawer: "www",
+			Commission:           5,
+			AuthorizedVoters:     []AuthorizedVoter{{AuthorizedVoter: "aaa", Epoch: 2}, {AuthorizedVoter: "vvv", Epoch: 3}},
+		},
+		state,
+	)
+
+	// system accounts are not vote accounts:
+	_, err = client.GetVoteAccountState(ctx, CommitmentFinalized, "aaa")
+	assert.Error(t, err)
+}
+
+func TestClient_GetNonceAccountWithSlot(t *testing.T) {
+	server, client := NewMockClient(t, nil, nil, map[string]int{"aaa": 1}, nil, nil, nil)
+	server.SetOpt(AccountInfoOpt, "nnn", map[string]any{
+		"lamports": 1_447_680,
+		"owner":    SystemProgram,
+		"space":    80,
+		"data": map[string]any{
+			"program": "nonce",
+			"parsed": map[string]any{
+				"type": "initialized",
+				"info": map[string]any{
+					"authority":     "aaa",
+					"blockhash":     "hhh",
+					"feeCalculator": map[string]any{"lamportsPerSignature": "5000"},
+				},
+			},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nonce, slot, err := client.GetNonceAccountWithSlot(ctx, CommitmentFinalized, "nnn")
+	assert.NoError(t, err)
+	assert.Equal(t, &NonceAccount{Authority: "aaa", Blockhash: "hhh", Lamports: 1_447_680}, nonce)
+	assert.Equal(t, int64(1), slot)
+
+	// plain system accounts are not nonce accounts:
+	_, _, err = client.GetNonceAccountWithSlot(ctx, CommitmentFinalized, "aaa")
+	assert.Error(t, err)
+}
+
+func TestClient_GetClusterNodes(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getClusterNodes",
+		[]map[string]any{
+			{"pubkey": "aaa", "gossip": "10.0.0.1:8001", "tpu": "10.0.0.1:8003", "rpc": nil, "version": "2.0.1"},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodes, err := client.GetClusterNodes(ctx)
+	assert.NoError(t, err)
+	gossip, tpu, version := "10.0.0.1:8001", "10.0.0.1:8003", "2.0.1"
+	assert.Equal(t, []ClusterNode{{Pubkey: "aaa", Gossip: &gossip, Tpu: &tpu, Version: &version}}, nodes)
+}
+
+func TestCall(t *testing.T) {
+	_, client := NewMockClient(t,
+		map[string]any{"getTransactionCount": 268_000_000_000},
+		map[string]*Error{"getStakeMinimumDelegation": {Code: -32601, Message: "Method not found"}},
+		nil, nil, nil, nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count, err := Call[int64](ctx, client, "getTransactionCount", []any{map[string]string{"commitment": "finalized"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(268_000_000_000), count)
+
+	_, err = Call[int64](ctx, client, "getStakeMinimumDelegation", nil)
+	var rpcErr *Error
+	assert.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "getStakeMinimumDelegation", rpcErr.Method)
+}
+
+func TestClient_invalidRequests(t *testing.T) {
+	_, client := NewMockClient(t, map[string]any{"getSlot": 10}, nil, nil, nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// invalid arguments are returned as errors, without calling the node:
+	_, err := client.GetBlock(ctx, CommitmentFinalized, 10, "signatures")
+	assert.ErrorIs(t, err, ErrInvalidParams)
+	_, err = client.GetBlock(ctx, CommitmentProcessed, 10, "none")
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	// as are requests which cannot be encoded:
+	_, err = Call[int64](ctx, client, "getSlot", []any{func() {}})
+	assert.ErrorContains(t, err, "failed to marshal getSlot request")
+
+	// or sent:
+	invalid := NewRPCClient("http://invalid url", time.Second, nil)
+	_, err = invalid.GetSlot(ctx, CommitmentFinalized)
+	assert.ErrorContains(t, err, "failed to create request")
+}
+
+func TestClient_Headers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":10,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewRPCClient(server.URL, time.Second, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.Error(t, err)
+
+	client.Headers = http.Header{"Authorization": {"Bearer token"}, "X-Api-Key": {"key"}}
+	slot, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+}
+
+func TestClient_redactsUrlInErrors(t *testing.T) {
+	client := NewRPCClient("http://localhost:1/secret-key?api-key=secret", time.Second, nil)
+	_, err := client.GetSlot(context.Background(), CommitmentFinalized)
+	assert.ErrorContains(t, err, "http://localhost:1")
+	assert.NotContains(t, err.Error(), "secret")
+}
@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// EncodingIdentity is the default, uncompressed content encoding.
+	EncodingIdentity = "identity"
+	// EncodingZstd is Zstandard compression, supported by some archive RPC providers.
+	EncodingZstd = "zstd"
+	// EncodingGzip is gzip compression, which most RPC providers support.
+	EncodingGzip = "gzip"
+)
+
+// zstdDecoder is safe for concurrent use through DecodeAll, so it is shared by all clients.
+var zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+
+// ValidateEncodings checks that all the provided content encodings can be decoded by the client.
+func ValidateEncodings(encodings []string) error {
+	for _, encoding := range encodings {
+		if encoding != EncodingZstd && encoding != EncodingGzip && encoding != EncodingIdentity {
+			return fmt.Errorf(
+				"unsupported content encoding '%s', must be one of %v", encoding, []string{EncodingZstd, EncodingGzip},
+			)
+		}
+	}
+	return nil
+}
+
+// decodeBody decodes a response body according to its Content-Encoding header, recording the
+// bandwidth saved for compressed responses.
+func (m *Metrics) decodeBody(method, contentEncoding string, body []byte) ([]byte, error) {
+	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
+	var decoded []byte
+	var err error
+	switch encoding {
+	case "", EncodingIdentity:
+		return body, nil
+	case EncodingZstd:
+		decoded, err = zstdDecoder.DecodeAll(body, nil)
+	case EncodingGzip:
+		decoded, err = decodeGzip(body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding '%s' in %s response", contentEncoding, method)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s %s response body: %w", encoding, method, err)
+	}
+	m.compressedResponseBytes.WithLabelValues(method).Add(float64(len(body)))
+	// tiny bodies can grow when compressed, which we do not count as negative savings:
+	m.compressionSavedBytes.WithLabelValues(method).Add(float64(max(0, len(decoded)-len(body))))
+	return decoded, nil
+}
+
+func decodeGzip(body []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,61 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_zstdResponses(t *testing.T) {
+	_, client := newMethodTester(t, "getBlocks", []int{5, 6, 7, 8, 9, 10}, nil)
+	client.AcceptEncodings = []string{EncodingZstd}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := client.GetBlocks(ctx, CommitmentFinalized, 5, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 6, 7, 8, 9, 10}, blocks)
+	assert.Greater(t, testutil.ToFloat64(client.metrics.compressedResponseBytes.WithLabelValues("getBlocks")), float64(0))
+}
+
+func TestClient_gzipResponses(t *testing.T) {
+	_, client := newMethodTester(t, "getBlocks", []int{5, 6, 7, 8, 9, 10}, nil)
+	client.AcceptEncodings = []string{EncodingGzip}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := client.GetBlocks(ctx, CommitmentFinalized, 5, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 6, 7, 8, 9, 10}, blocks)
+	assert.Greater(t, testutil.ToFloat64(client.metrics.compressedResponseBytes.WithLabelValues("getBlocks")), float64(0))
+}
+
+func TestDecodeBody(t *testing.T) {
+	body := []byte(`{"jsonrpc":"2.0","result":[1,2,3],"id":1}`)
+	metrics := NewMetrics(nil)
+
+	decoded, err := metrics.decodeBody("getBlocks", "", body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, decoded)
+
+	decoded, err = metrics.decodeBody("getBlocks", EncodingZstd, mockZstdEncoder.EncodeAll(body, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, body, decoded)
+
+	decoded, err = metrics.decodeBody("getBlocks", EncodingGzip, mockGzipEncode(body))
+	assert.NoError(t, err)
+	assert.Equal(t, body, decoded)
+
+	_, err = metrics.decodeBody("getBlocks", EncodingGzip, body)
+	assert.Error(t, err)
+
+	_, err = metrics.decodeBody("getBlocks", "br", body)
+	assert.Error(t, err)
+}
+
+func TestValidateEncodings(t *testing.T) {
+	assert.NoError(t, ValidateEncodings([]string{EncodingZstd, EncodingGzip}))
+	assert.Error(t, ValidateEncodings([]string{"br"}))
+}
@@ -1,8 +1,19 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
+)
+
+var (
+	// ErrAccountNotFound is returned when querying an account which does not exist (e.g., was never funded)
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidParams is returned for calls with parameters the RPC method does not accept, without calling the node
+	ErrInvalidParams = errors.New("invalid params")
 )
 
 // error codes: https://github.com/anza-xyz/agave/blob/489f483e1d7b30ef114e0123994818b2accfa389/rpc-client-api/src/custom_error.rs#L17
@@ -43,3 +54,34 @@ func UnpackRpcErrorData[T any](rpcErr *Error, formatted T) error {
 	}
 	return nil
 }
+
+// ErrorCode returns a short code classifying a failed rpc call: the JSON-RPC error code for errors returned by the
+// node, "http_<status>" for HTTP errors, or one of "invalid_params", "timeout", "cancelled", "circuit_open",
+// "transport" (connection failures) and "other" (e.g., undecodable responses).
+func ErrorCode(err error) string {
+	var (
+		rpcErr    *Error
+		statusErr *StatusError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &rpcErr):
+		return strconv.FormatInt(rpcErr.Code, 10)
+	case errors.As(err, &statusErr):
+		return "http_" + strconv.Itoa(statusErr.StatusCode)
+	case errors.Is(err, ErrInvalidParams):
+		return "invalid_params"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrNotRecorded):
+		return "not_recorded"
+	case errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "transport"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "-32007", ErrorCode(&Error{Code: SlotSkippedCode}))
+	statusErr := &StatusError{StatusCode: http.StatusServiceUnavailable}
+	assert.Equal(t, "http_503", ErrorCode(fmt.Errorf("wrapped: %w", statusErr)))
+	assert.Equal(t, "circuit_open", ErrorCode(fmt.Errorf("%w for getSlot", ErrCircuitOpen)))
+	assert.Equal(t, "invalid_params", ErrorCode(fmt.Errorf("%w: limit 0", ErrInvalidParams)))
+	assert.Equal(t, "not_recorded", ErrorCode(fmt.Errorf("getSlot rpc call failed: %w", ErrNotRecorded)))
+	assert.Equal(t, "timeout", ErrorCode(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	assert.Equal(t, "cancelled", ErrorCode(context.Canceled))
+	assert.Equal(t, "transport", ErrorCode(&net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}))
+	assert.Equal(t, "other", ErrorCode(fmt.Errorf("failed to decode")))
+}
+
+func TestClient_countsErrors(t *testing.T) {
+	_, client := NewMockClient(t,
+		nil,
+		map[string]*Error{"getHealth": {Code: NodeUnhealthyCode, Message: "Node is unhealthy"}},
+		nil, nil, nil, nil,
+	)
+	_, err := client.GetHealth(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(client.metrics.rpcErrors.WithLabelValues("getHealth", "-32005")))
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+)
+
+// DefaultEndpointCooldown is how long a failed endpoint is passed over before it is tried again.
+const DefaultEndpointCooldown = 30 * time.Second
+
+// nodeMethods are the methods reporting on the node itself rather than on the cluster, which are never failed over,
+// as a fallback's health or identity would pass for the node's.
+var nodeMethods = map[string]bool{
+	"getHealth":             true,
+	"getIdentity":           true,
+	"getVersion":            true,
+	"minimumLedgerSlot":     true,
+	"getMaxRetransmitSlot":  true,
+	"getMaxShredInsertSlot": true,
+}
+
+type (
+	// Endpoints is a prioritized list of RPC endpoints, which fails over to the next healthy endpoint when one fails,
+	// and fails back to the higher-priority ones once their cooldown expires. The endpoints are continuously scored
+	// (see score), and with Routing, requests are sent to the best endpoint for their method category instead.
+	Endpoints struct {
+		// Routing is whether healthy endpoints are tried in order of their score for the method category, rather than
+		// in order of priority
+		Routing   bool
+		endpoints []*endpoint
+		cooldown  time.Duration
+		// active is the index of the endpoint which served the last successful request
+		active  int
+		metrics *Metrics
+		mu      sync.Mutex
+	}
+
+	endpoint struct {
+		url string
+		// label identifies the endpoint in metrics and logs, without any credentials embedded in the url
+		label     string
+		downUntil time.Time
+		endpointStats
+	}
+)
+
+func NewEndpoints(urls []string, cooldown time.Duration, metrics *Metrics) *Endpoints {
+	endpoints := make([]*endpoint, len(urls))
+	for i, rpcUrl := range urls {
+		endpoints[i] = &endpoint{url: rpcUrl, label: RedactUrl(rpcUrl)}
+	}
+	e := &Endpoints{endpoints: endpoints, cooldown: cooldown, metrics: metrics}
+	e.emitActive()
+	return e
+}
+
+// RedactUrl strips everything but the scheme and host from an RPC (or WebSocket) url, as api keys are commonly
+// embedded in the path, query or user info. Urls are redacted wherever they end up in metrics, logs or errors.
+func RedactUrl(rpcUrl string) string {
+	parsed, err := url.Parse(rpcUrl)
+	if err != nil || parsed.Host == "" {
+		return "invalid"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// candidates returns the endpoints to try a request of the method on: the healthy ones first, followed by those still
+// cooling down (as last resorts). Healthy endpoints are in order of priority, or of their score with Routing: heavy
+// methods go to the endpoint with the deepest history (i.e., an archive node), and light ones to the best scoring.
+// Node methods are only ever sent to the primary endpoint.
+func (e *Endpoints) candidates(method string, now time.Time) []*endpoint {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if nodeMethods[method] {
+		return e.endpoints[:1]
+	}
+	var healthy, down []*endpoint
+	for _, ep := range e.endpoints {
+		if now.Before(ep.downUntil) {
+			down = append(down, ep)
+		} else {
+			healthy = append(healthy, ep)
+		}
+	}
+	if e.Routing {
+		highestSlot := e.highestSlot()
+		heavy := GetMethodCategory(method) == MethodCategoryHeavy
+		// stable, such that ties are broken by priority:
+		slices.SortStableFunc(healthy, func(a, b *endpoint) int {
+			if heavy {
+				if byHistory := cmp.Compare(a.historyStart(), b.historyStart()); byHistory != 0 {
+					return byHistory
+				}
+			}
+			return cmp.Compare(b.score(highestSlot), a.score(highestSlot))
+		})
+	}
+	return append(healthy, down...)
+}
+
+// markFailed passes over the endpoint until its cooldown expires.
+func (e *Endpoints) markFailed(ep *endpoint, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ep.downUntil = now.Add(e.cooldown)
+	ep.observeFailure()
+	e.emitScores()
+}
+
+// markSucceeded makes the endpoint the active one, observing the latency of the request (if of a light method).
+func (e *Endpoints) markSucceeded(ep *endpoint, method string, latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ep.downUntil = time.Time{}
+	// the latency of heavy methods is dominated by the work they take, rather than by the endpoint:
+	if GetMethodCategory(method) == MethodCategoryLight {
+		ep.observeLatency(latency)
+	}
+	ep.observeSuccess()
+	e.emitScores()
+	for i, other := range e.endpoints {
+		if other == ep && i != e.active {
+			slog.Get().Warnf("Switching active RPC endpoint from %s to %s", e.endpoints[e.active].label, ep.label)
+			e.active = i
+			e.emitActive()
+		}
+	}
+}
+
+// Active returns the label of the endpoint which served the last successful request.
+func (e *Endpoints) Active() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.endpoints[e.active].label
+}
+
+func (e *Endpoints) emitActive() {
+	for i, ep := range e.endpoints {
+		value := 0.0
+		if i == e.active {
+			value = 1
+		}
+		e.metrics.activeEndpoint.WithLabelValues(ep.label).Set(value)
+	}
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Failover(t *testing.T) {
+	// the primary endpoint fails until it is fixed:
+	var primaryHealthy atomic.Bool
+	mockServer, _ := NewMockClient(t, map[string]any{"getSlot": 10}, nil, nil, nil, nil, nil)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !primaryHealthy.Load() {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":20,"id":1}`))
+	}))
+	t.Cleanup(primary.Close)
+
+	client := NewFailoverRPCClient([]string{primary.URL, mockServer.URL()}, time.Second, nil)
+	client.Endpoints.cooldown = 100 * time.Millisecond
+	ctx := context.Background()
+
+	slot, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+	assert.Equal(t, RedactUrl(mockServer.URL()), client.Endpoints.Active())
+
+	// the primary is passed over during its cooldown, even once healthy:
+	primaryHealthy.Store(true)
+	slot, err = client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+
+	// and fails back once it expires:
+	time.Sleep(150 * time.Millisecond)
+	slot, err = client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), slot)
+	assert.Equal(t, RedactUrl(primary.URL), client.Endpoints.Active())
+}
+
+func TestClient_Failover_timeout(t *testing.T) {
+	mockServer, _ := NewMockClient(t, map[string]any{"getSlot": 10, "getHealth": "ok"}, nil, nil, nil, nil, nil)
+	// the primary hangs, i.e., never responds within the http timeout:
+	release := make(chan struct{})
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(primary.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := NewFailoverRPCClient([]string{primary.URL, mockServer.URL()}, 100*time.Millisecond, nil)
+	ctx := context.Background()
+
+	slot, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+	assert.Equal(t, RedactUrl(mockServer.URL()), client.Endpoints.Active())
+
+	// node methods are never failed over, as the fallback's health is not the node's:
+	_, err = client.GetHealth(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRedactUrl(t *testing.T) {
+	assert.Equal(t, "https://rpc.example.com", RedactUrl("https://rpc.example.com/secret-token?api-key=xyz"))
+	assert.Equal(t, "http://localhost:8899", RedactUrl("http://localhost:8899"))
+	assert.Equal(t, "invalid", RedactUrl("localhost"))
+}
@@ -0,0 +1,239 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	MethodLabel   = "method"
+	EndpointLabel = "endpoint"
+	CodeLabel     = "code"
+	PhaseLabel    = "phase"
+	ReusedLabel   = "reused"
+	KindLabel     = "kind"
+)
+
+// Metrics are the metrics of RPC clients. Clients sharing a registerer share its metrics.
+type Metrics struct {
+	requestBytes            *prometheus.CounterVec
+	responseBytes           *prometheus.CounterVec
+	compressedResponseBytes *prometheus.CounterVec
+	compressionSavedBytes   *prometheus.CounterVec
+	rpcErrors               *prometheus.CounterVec
+	rpcRetries              *prometheus.CounterVec
+	throttledRequests       *prometheus.CounterVec
+	queuedRequests          prometheus.Gauge
+	deduplicatedCalls       *prometheus.CounterVec
+	circuitOpen             *prometheus.GaugeVec
+	activeEndpoint          *prometheus.GaugeVec
+	endpointScore           *prometheus.GaugeVec
+	endpointLatency         *prometheus.GaugeVec
+	endpointErrorRate       *prometheus.GaugeVec
+	endpointSlotsBehind     *prometheus.GaugeVec
+	endpointHistoryStart    *prometheus.GaugeVec
+	connectionPhase         *prometheus.HistogramVec
+	connections             *prometheus.CounterVec
+	schemaDriftFields       *prometheus.GaugeVec
+}
+
+// NewMetrics creates the RPC client metrics and registers them with the registerer, reusing those already registered
+// with it (e.g., by another client). The metrics are not registered at all if the registerer is nil.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	return &Metrics{
+		requestBytes: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_request_bytes_total",
+				Help: fmt.Sprintf("Bytes of RPC request bodies sent, including retries, grouped by %s", MethodLabel),
+			},
+			[]string{MethodLabel},
+		)),
+		responseBytes: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_response_bytes_total",
+				Help: fmt.Sprintf(
+					"Bytes of RPC response bodies received over the wire (i.e., before decompression), including "+
+						"retries, grouped by %s",
+					MethodLabel,
+				),
+			},
+			[]string{MethodLabel},
+		)),
+		compressedResponseBytes: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_compressed_response_bytes_total",
+				Help: fmt.Sprintf("Bytes received over the wire in compressed RPC responses, grouped by %s", MethodLabel),
+			},
+			[]string{MethodLabel},
+		)),
+		compressionSavedBytes: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_compression_saved_bytes_total",
+				Help: fmt.Sprintf(
+					"Bytes saved by compressed RPC responses (decoded size minus received size), grouped by %s",
+					MethodLabel,
+				),
+			},
+			[]string{MethodLabel},
+		)),
+		rpcErrors: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_errors_total",
+				Help: fmt.Sprintf(
+					"Number of failed RPC calls, grouped by %s and %s (the JSON-RPC error code, or the kind of "+
+						"transport failure)",
+					MethodLabel, CodeLabel,
+				),
+			},
+			[]string{MethodLabel, CodeLabel},
+		)),
+		rpcRetries: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_retries_total",
+				Help: fmt.Sprintf("Number of RPC calls retried after a transient failure, grouped by %s", MethodLabel),
+			},
+			[]string{MethodLabel},
+		)),
+		throttledRequests: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_throttled_requests_total",
+				Help: fmt.Sprintf("Number of RPC requests delayed by the client-side rate limiter, grouped by %s", MethodLabel),
+			},
+			[]string{MethodLabel},
+		)),
+		queuedRequests: register(registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "solana_exporter_rpc_queued_requests",
+			Help: "Number of RPC requests currently waiting on the client-side rate limiter",
+		})),
+		deduplicatedCalls: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_deduplicated_calls_total",
+				Help: fmt.Sprintf(
+					"Number of RPC calls served by an identical call already in flight, grouped by %s", MethodLabel,
+				),
+			},
+			[]string{MethodLabel},
+		)),
+		circuitOpen: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_circuit_open",
+				Help: fmt.Sprintf(
+					"Whether the circuit of an RPC method (represented by %s) is open, i.e., calls are suspended "+
+						"after repeated failures",
+					MethodLabel,
+				),
+			},
+			[]string{MethodLabel},
+		)),
+		activeEndpoint: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_active_endpoint",
+				Help: fmt.Sprintf("Whether an RPC endpoint (represented by %s) is the one currently in use", EndpointLabel),
+			},
+			[]string{EndpointLabel},
+		)),
+		endpointScore: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_endpoint_score",
+				Help: fmt.Sprintf(
+					"Score (between 0 and 1) of an RPC endpoint (represented by %s) from its latency, error rate and "+
+						"slot freshness, which light calls are routed by",
+					EndpointLabel,
+				),
+			},
+			[]string{EndpointLabel},
+		)),
+		endpointLatency: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_endpoint_latency_seconds",
+				Help: fmt.Sprintf(
+					"Moving average of the latency of light RPC calls to an endpoint (represented by %s)", EndpointLabel,
+				),
+			},
+			[]string{EndpointLabel},
+		)),
+		endpointErrorRate: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_endpoint_error_rate",
+				Help: fmt.Sprintf(
+					"Moving average of the share of failed requests to an RPC endpoint (represented by %s)",
+					EndpointLabel,
+				),
+			},
+			[]string{EndpointLabel},
+		)),
+		endpointSlotsBehind: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_endpoint_slots_behind",
+				Help: fmt.Sprintf(
+					"Number of slots an RPC endpoint (represented by %s) was behind the most advanced endpoint, as "+
+						"of the last probe",
+					EndpointLabel,
+				),
+			},
+			[]string{EndpointLabel},
+		)),
+		endpointHistoryStart: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_endpoint_first_available_block",
+				Help: fmt.Sprintf(
+					"First available block of an RPC endpoint (represented by %s), which heavy calls are routed by",
+					EndpointLabel,
+				),
+			},
+			[]string{EndpointLabel},
+		)),
+		connectionPhase: register(registerer, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name: "solana_exporter_rpc_connection_phase_seconds",
+				Help: fmt.Sprintf(
+					"Duration of the connection phases (represented by %s: %s, %s, %s or %s, from the request being "+
+						"written) of RPC requests to an endpoint (represented by %s)",
+					PhaseLabel, PhaseDNS, PhaseConnect, PhaseTLSHandshake, PhaseFirstByte, EndpointLabel,
+				),
+				Buckets: connectionPhaseBuckets,
+			},
+			[]string{EndpointLabel, PhaseLabel},
+		)),
+		connections: register(registerer, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "solana_exporter_rpc_connections_total",
+				Help: fmt.Sprintf(
+					"Number of connections RPC requests to an endpoint (represented by %s) were sent on, grouped by "+
+						"whether the connection was %s (kept alive) or newly dialed",
+					EndpointLabel, ReusedLabel,
+				),
+			},
+			[]string{EndpointLabel, ReusedLabel},
+		)),
+		schemaDriftFields: register(registerer, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "solana_exporter_rpc_schema_drift_fields",
+				Help: fmt.Sprintf(
+					"Number of distinct fields of %s results which drifted from the decoded schema, grouped by %s "+
+						"(%s: returned but not decoded, or %s: decoded but not returned)",
+					MethodLabel, KindLabel, DriftUnknown, DriftMissing,
+				),
+			},
+			[]string{MethodLabel, KindLabel},
+		)),
+	}
+}
+
+// register registers the collector with the registerer, returning the equal collector already registered with it, if
+// any. It panics on any other registration error, like prometheus.MustRegister.
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if registerer == nil {
+		return collector
+	}
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegisteredErr prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegisteredErr) {
+			return alreadyRegisteredErr.ExistingCollector.(T)
+		}
+		panic(err)
+	}
+	return collector
+}
@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetrics(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	first, second := NewMetrics(registry), NewMetrics(registry)
+
+	// metrics registered with the same registerer are shared:
+	first.rpcRetries.WithLabelValues("getSlot").Inc()
+	assert.Equal(t, float64(1), testutil.ToFloat64(second.rpcRetries.WithLabelValues("getSlot")))
+	count, err := testutil.GatherAndCount(registry, "solana_exporter_rpc_retries_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// whereas other registerers (or none) get their own:
+	other := NewMetrics(prometheus.NewPedanticRegistry())
+	assert.Equal(t, float64(0), testutil.ToFloat64(other.rpcRetries.WithLabelValues("getSlot")))
+	unregistered := NewMetrics(nil)
+	assert.Equal(t, float64(0), testutil.ToFloat64(unregistered.rpcRetries.WithLabelValues("getSlot")))
+}
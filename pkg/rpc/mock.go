@@ -1,21 +1,36 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
 	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
 	"go.uber.org/zap"
 )
 
 type MockOpt int
 
+var mockZstdEncoder, _ = zstd.NewWriter(nil)
+
+func mockGzipEncode(body []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, _ = writer.Write(body)
+	_ = writer.Close()
+	return buffer.Bytes()
+}
+
 const (
 	BalanceOpt MockOpt = iota
 	InflationRewardsOpt
@@ -23,6 +38,7 @@ const (
 	SlotInfosOpt
 	ValidatorInfoOpt
 	EasyErrorsOpt = 5
+	AccountInfoOpt = 6
 )
 
 type (
@@ -40,6 +56,7 @@ type (
 
 		SlotInfos      map[int]MockSlotInfo
 		validatorInfos map[string]MockValidatorInfo
+		accountInfos   map[string]map[string]any
 	}
 
 	MockBlockInfo struct {
@@ -58,6 +75,7 @@ type (
 		LastVote   int
 		Delinquent bool
 		RootSlot   int
+		Commission int
 	}
 )
 
@@ -151,6 +169,11 @@ func (s *MockServer) SetOpt(opt MockOpt, key any, value any) {
 		}
 		err := value.(Error)
 		s.easyErrors[key.(string)] = &err
+	case AccountInfoOpt:
+		if s.accountInfos == nil {
+			s.accountInfos = make(map[string]map[string]any)
+		}
+		s.accountInfos[key.(string)] = value.(map[string]any)
 	}
 }
 
@@ -160,6 +183,21 @@ func (s *MockServer) GetValidatorInfo(nodekey string) MockValidatorInfo {
 	return s.validatorInfos[nodekey]
 }
 
+// getAccountInfo returns the account info of the address, or nil if the account does not exist. It must be called
+// with the read lock held.
+func (s *MockServer) getAccountInfo(address string) any {
+	if info, ok := s.accountInfos[address]; ok {
+		return info
+	}
+	if balance, ok := s.balances[address]; ok {
+		// default to a system account holding the balance:
+		return map[string]any{
+			"lamports": balance, "owner": SystemProgram, "space": 0, "data": []string{"", "base64"},
+		}
+	}
+	return nil
+}
+
 func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -191,6 +229,20 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 		return rewards, nil
 	}
 
+	if method == "getAccountInfo" && (s.accountInfos != nil || s.balances != nil) {
+		address := params[0].(string)
+		return map[string]any{"context": map[string]int{"slot": 1}, "value": s.getAccountInfo(address)}, nil
+	}
+
+	if method == "getMultipleAccounts" && (s.accountInfos != nil || s.balances != nil) {
+		addresses := params[0].([]any)
+		values := make([]any, len(addresses))
+		for i, address := range addresses {
+			values[i] = s.getAccountInfo(address.(string))
+		}
+		return map[string]any{"context": map[string]int{"slot": 1}, "value": values}, nil
+	}
+
 	if method == "getBlock" && s.SlotInfos != nil {
 		// get params:
 		slot := int(params[0].(float64))
@@ -228,6 +280,19 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 		return map[string]any{"rewards": rewards, "transactions": transactions}, nil
 	}
 
+	if method == "getBlocks" && s.SlotInfos != nil {
+		// get params:
+		startSlot, endSlot := int(params[0].(float64)), int(params[1].(float64))
+
+		blocks := []int{}
+		for slot := startSlot; slot <= endSlot; slot++ {
+			if info, ok := s.SlotInfos[slot]; ok && info.Block != nil {
+				blocks = append(blocks, slot)
+			}
+		}
+		return blocks, nil
+	}
+
 	if method == "getBlockProduction" && s.SlotInfos != nil {
 		// get params:
 		config := params[0].(map[string]any)
@@ -256,14 +321,21 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	}
 
 	if method == "getVoteAccounts" && s.validatorInfos != nil {
+		config := params[0].(map[string]any)
+		votePubkey, _ := config["votePubkey"].(string)
+
 		var currentVoteAccounts, delinquentVoteAccounts []map[string]any
 		for nodekey, info := range s.validatorInfos {
+			if votePubkey != "" && info.Votekey != votePubkey {
+				continue
+			}
 			voteAccount := map[string]any{
 				"activatedStake": int64(info.Stake),
 				"lastVote":       info.LastVote,
 				"nodePubkey":     nodekey,
 				"rootSlot":       info.RootSlot,
 				"votePubkey":     info.Votekey,
+				"commission":     info.Commission,
 			}
 			if info.Delinquent {
 				delinquentVoteAccounts = append(delinquentVoteAccounts, voteAccount)
@@ -283,6 +355,16 @@ func (s *MockServer) getResult(method string, params ...any) (any, *Error) {
 	if !ok {
 		return nil, &Error{Code: -32601, Message: "Method not found"}
 	}
+	if method == "getLeaderSchedule" && len(params) > 1 {
+		// the identity filter only returns the schedule of the identity:
+		if identity, ok := params[1].(map[string]any)["identity"].(string); ok {
+			filtered := make(map[string]any)
+			if slots := reflect.ValueOf(result).MapIndex(reflect.ValueOf(identity)); slots.IsValid() {
+				filtered[identity] = slots.Interface()
+			}
+			return filtered, nil
+		}
+	}
 	return result, nil
 }
 
@@ -307,10 +389,19 @@ func (s *MockServer) handleRPCRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	err := json.NewEncoder(w).Encode(response)
+	body, err := json.Marshal(response)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept-Encoding"), EncodingZstd) {
+		w.Header().Set("Content-Encoding", EncodingZstd)
+		body = mockZstdEncoder.EncodeAll(body, nil)
+	} else if strings.Contains(r.Header.Get("Accept-Encoding"), EncodingGzip) {
+		w.Header().Set("Content-Encoding", EncodingGzip)
+		body = mockGzipEncode(body)
 	}
+	_, _ = w.Write(body)
 }
 
 // NewMockClient creates a new test client with a running mock server
@@ -334,6 +425,6 @@ func NewMockClient(
 		}
 	})
 
-	client := NewRPCClient(server.URL(), time.Second)
+	client := NewRPCClient(server.URL(), time.Second, nil)
 	return server, client
 }
@@ -117,6 +117,27 @@ func TestMockServer_getInflationReward(t *testing.T) {
 	)
 }
 
+func TestMockServer_getBlocks(t *testing.T) {
+	_, client := NewMockClient(t,
+		nil,
+		nil,
+		nil,
+		nil,
+		map[int]MockSlotInfo{
+			1: {"aaa", &MockBlockInfo{Fee: 10}},
+			2: {"aaa", nil},
+			3: {"bbb", &MockBlockInfo{Fee: 5}},
+		},
+		nil,
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, err := client.GetBlocks(ctx, CommitmentFinalized, 1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, blocks)
+}
+
 func TestMockServer_getVoteAccounts(t *testing.T) {
 	_, client := NewMockClient(t,
 		nil,
@@ -125,9 +146,9 @@ func TestMockServer_getVoteAccounts(t *testing.T) {
 		nil,
 		nil,
 		map[string]MockValidatorInfo{
-			"aaa": {"AAA", 1, 2, false, 10},
-			"bbb": {"BBB", 3, 4, false, 11},
-			"ccc": {"CCC", 5, 6, true, 12},
+			"aaa": {"AAA", 1, 2, false, 10, 0},
+			"bbb": {"BBB", 3, 4, false, 11, 0},
+			"ccc": {"CCC", 5, 6, true, 12, 0},
 		},
 	)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -142,11 +163,23 @@ func TestMockServer_getVoteAccounts(t *testing.T) {
 	assert.Equal(t,
 		VoteAccounts{
 			Current: []VoteAccount{
-				{1, 2, "aaa", 10, "AAA"},
-				{3, 4, "bbb", 11, "BBB"},
+				{ActivatedStake: 1, LastVote: 2, NodePubkey: "aaa", RootSlot: 10, VotePubkey: "AAA"},
+				{ActivatedStake: 3, LastVote: 4, NodePubkey: "bbb", RootSlot: 11, VotePubkey: "BBB"},
 			},
 			Delinquent: []VoteAccount{
-				{5, 6, "ccc", 12, "CCC"},
+				{ActivatedStake: 5, LastVote: 6, NodePubkey: "ccc", RootSlot: 12, VotePubkey: "CCC"},
+			},
+		},
+		*voteAccounts,
+	)
+
+	// the votePubkey filter only returns the one vote account:
+	voteAccounts, err = client.GetVoteAccountsOf(ctx, CommitmentFinalized, "CCC")
+	assert.NoError(t, err)
+	assert.Equal(t,
+		VoteAccounts{
+			Delinquent: []VoteAccount{
+				{ActivatedStake: 5, LastVote: 6, NodePubkey: "ccc", RootSlot: 12, VotePubkey: "CCC"},
 			},
 		},
 		*voteAccounts,
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of RPC requests, such that the exporter stays within the limits
+// of rate-limited RPC providers instead of being served 429s (or banned). Requests beyond the rate are queued until
+// a token is available.
+type RateLimiter struct {
+	// rate is the number of tokens added per second
+	rate float64
+	// burst is the capacity of the bucket
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter allowing requestsPerSecond on average, and bursts of up to burst requests.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{rate: requestsPerSecond, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token, returning how long to wait before it may be used.
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token which was not used.
+func (l *RateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.tokens+1, l.burst)
+}
+
+// Wait blocks until a request is allowed, or the context is cancelled. The method is only used to label the metrics.
+func (l *RateLimiter) Wait(ctx context.Context, method string, metrics *Metrics) error {
+	wait := l.reserve(time.Now())
+	if wait == 0 {
+		return nil
+	}
+	metrics.throttledRequests.WithLabelValues(method).Inc()
+	metrics.queuedRequests.Inc()
+	defer metrics.queuedRequests.Dec()
+	if err := sleep(ctx, wait); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	limiter := NewRateLimiter(10, 2)
+	now := time.Now()
+
+	// the burst is allowed straight away:
+	assert.Equal(t, time.Duration(0), limiter.reserve(now))
+	assert.Equal(t, time.Duration(0), limiter.reserve(now))
+	// beyond it, requests queue up at the rate:
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve(now))
+	assert.Equal(t, 200*time.Millisecond, limiter.reserve(now))
+
+	// tokens are refilled over time, up to the burst:
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), limiter.reserve(now))
+	assert.Equal(t, time.Duration(0), limiter.reserve(now))
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve(now))
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter, metrics := NewRateLimiter(1, 1), NewMetrics(nil)
+	ctx := context.Background()
+	assert.NoError(t, limiter.Wait(ctx, "getSlot", metrics))
+
+	// a cancelled wait gives its token back:
+	cancelledCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(cancelledCtx, "getSlot", metrics), context.DeadlineExceeded)
+	assert.InDelta(t, 0, limiter.tokens, 0.1)
+}
+
+func TestClient_RateLimiter(t *testing.T) {
+	_, client := NewMockClient(t, map[string]any{"getSlot": 10}, nil, nil, nil, nil, nil)
+	client.RateLimiter = NewRateLimiter(20, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		_, err := client.GetSlot(ctx, CommitmentFinalized)
+		assert.NoError(t, err)
+	}
+	// the first request uses the burst, the other two wait 50ms each:
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrNotRecorded is returned by replayed calls which were not recorded.
+var ErrNotRecorded = errors.New("call was not recorded")
+
+type (
+	// Recording is a recorded RPC call, of the (redacted) url of the client which made it.
+	Recording struct {
+		Time     time.Time       `json:"time"`
+		Url      string          `json:"url"`
+		Method   string          `json:"method"`
+		Request  json.RawMessage `json:"request"`
+		Response json.RawMessage `json:"response"`
+	}
+
+	// Recorder records the requests and (decoded) responses of successful RPC calls as JSON lines, for a Replayer to
+	// serve back, such that the metrics of an exporter can be reproduced exactly away from the node.
+	Recorder struct {
+		encoder *json.Encoder
+		mu      sync.Mutex
+	}
+
+	// Replayer serves back the responses recorded by a Recorder. The responses recorded for identical requests are
+	// served in the order they were recorded, the last one being served again once they run out.
+	Replayer struct {
+		// responses are the recorded responses, by url and request
+		responses map[[2]string][]json.RawMessage
+		mu        sync.Mutex
+	}
+)
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{encoder: json.NewEncoder(w)}
+}
+
+func (r *Recorder) record(url, method string, request, response []byte, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recording := Recording{Time: now, Url: url, Method: method, Request: request, Response: response}
+	if err := r.encoder.Encode(recording); err != nil {
+		return fmt.Errorf("failed to record %s rpc call: %w", method, err)
+	}
+	return nil
+}
+
+// NewReplayer reads the recordings (as written by a Recorder) to replay.
+func NewReplayer(reader io.Reader) (*Replayer, error) {
+	replayer := Replayer{responses: make(map[[2]string][]json.RawMessage)}
+	scanner := bufio.NewScanner(reader)
+	// responses (e.g., of getBlock) are way larger than the default token size:
+	scanner.Buffer(nil, 256<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var recording Recording
+		if err := json.Unmarshal(scanner.Bytes(), &recording); err != nil {
+			return nil, fmt.Errorf("failed to decode recording on line %d: %w", line, err)
+		}
+		key := [2]string{recording.Url, string(recording.Request)}
+		replayer.responses[key] = append(replayer.responses[key], recording.Response)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recordings: %w", err)
+	}
+	return &replayer, nil
+}
+
+func (r *Replayer) replay(url, method string, request []byte) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := [2]string{url, string(request)}
+	responses := r.responses[key]
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("%s rpc call failed: %w", method, ErrNotRecorded)
+	}
+	if len(responses) > 1 {
+		r.responses[key] = responses[1:]
+	}
+	return responses[0], nil
+}
@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RecordAndReplay(t *testing.T) {
+	server, client := newMethodTester(t, "getSlot", 10, nil)
+	var recordings bytes.Buffer
+	client.Recorder = NewRecorder(&recordings)
+	ctx := context.Background()
+
+	slot, err := client.GetSlot(ctx, CommitmentFinalized)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+	server.SetOpt(EasyResultsOpt, "getSlot", 11)
+	_, err = client.GetSlot(ctx, CommitmentFinalized)
+	require.NoError(t, err)
+
+	// the replayed client never reaches the server:
+	server.SetOpt(EasyResultsOpt, "getSlot", 12)
+	replayer, err := NewReplayer(&recordings)
+	require.NoError(t, err)
+	replayed := NewRPCClient(client.RpcUrl, client.HttpTimeout, nil)
+	replayed.Replayer = replayer
+
+	// the responses are served in order, the last one repeatedly:
+	for _, expected := range []int64{10, 11, 11} {
+		slot, err = replayed.GetSlot(ctx, CommitmentFinalized)
+		require.NoError(t, err)
+		assert.Equal(t, expected, slot)
+	}
+	_, err = replayed.GetSlot(ctx, CommitmentConfirmed)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+
+	// recordings are of the client's url:
+	other := NewRPCClient("http://localhost:1234", client.HttpTimeout, nil)
+	other.Replayer = replayer
+	_, err = other.GetSlot(ctx, CommitmentFinalized)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
+
+func TestNewReplayer(t *testing.T) {
+	_, err := NewReplayer(strings.NewReader("{\"method\":\"getSlot\"}\n\nnot json\n"))
+	assert.ErrorContains(t, err, "line 3")
+}
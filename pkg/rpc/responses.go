@@ -3,6 +3,7 @@ package rpc
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 type (
@@ -87,10 +88,208 @@ type (
 
 	FullTransaction struct {
 		Transaction struct {
-			Message struct {
+			Signatures []string `json:"signatures"`
+			Message    struct {
 				AccountKeys []string `json:"accountKeys"`
 			} `json:"message"`
 		} `json:"transaction"`
+		Meta *TransactionMeta `json:"meta"`
+	}
+
+	TransactionMeta struct {
+		// Fee is the total fee (in lamports) charged for the transaction, including any priority fee
+		Fee                  int64  `json:"fee"`
+		ComputeUnitsConsumed *int64 `json:"computeUnitsConsumed"`
+		// Err is the error the transaction failed with, or nil if it succeeded
+		Err any `json:"err"`
+		// PreBalances and PostBalances are the balances (in lamports) of the transaction's accounts before and after
+		// it, in the order of the account keys followed by the loaded addresses
+		PreBalances     []int64          `json:"preBalances"`
+		PostBalances    []int64          `json:"postBalances"`
+		LoadedAddresses *LoadedAddresses `json:"loadedAddresses"`
+	}
+
+	// LoadedAddresses are the accounts a versioned transaction loaded from address lookup tables.
+	LoadedAddresses struct {
+		Writable []string `json:"writable"`
+		Readonly []string `json:"readonly"`
+	}
+
+	// Transaction is a confirmed transaction, as returned by getTransaction.
+	Transaction struct {
+		Slot      int64            `json:"slot"`
+		BlockTime *int64           `json:"blockTime"`
+		Meta      *TransactionMeta `json:"meta"`
+	}
+
+	AccountInfo struct {
+		Lamports   int64  `json:"lamports"`
+		Owner      string `json:"owner"`
+		Executable bool   `json:"executable"`
+		RentEpoch  uint64 `json:"rentEpoch"`
+		Space      int64  `json:"space"`
+		// Data is the jsonParsed account data, see ParsedAccountData
+		Data json.RawMessage `json:"data"`
+	}
+
+	ParsedAccountData[T any] struct {
+		Program string `json:"program"`
+		Parsed  struct {
+			Type string `json:"type"`
+			Info T      `json:"info"`
+		} `json:"parsed"`
+	}
+
+	Clock struct {
+		Slot                int64 `json:"slot"`
+		Epoch               int64 `json:"epoch"`
+		EpochStartTimestamp int64 `json:"epochStartTimestamp"`
+		LeaderScheduleEpoch int64 `json:"leaderScheduleEpoch"`
+		UnixTimestamp       int64 `json:"unixTimestamp"`
+	}
+
+	// ClusterNode is a node of the cluster as seen through gossip. Addresses are nil if the node does not advertise
+	// the service.
+	ClusterNode struct {
+		Pubkey       string  `json:"pubkey"`
+		Gossip       *string `json:"gossip"`
+		Tpu          *string `json:"tpu"`
+		Rpc          *string `json:"rpc"`
+		Version      *string `json:"version"`
+		FeatureSet   *uint32 `json:"featureSet"`
+		ShredVersion *uint16 `json:"shredVersion"`
+	}
+
+	// VoteAccountState is the jsonParsed state of a vote account.
+	VoteAccountState struct {
+		NodePubkey           string `json:"nodePubkey"`
+		AuthorizedWithdrawer string `json:"authorizedWithdrawer"`
+		Commission           int    `json:"commission"`
+		// AuthorizedVoters are the current authorized voter and any voters scheduled for future epochs
+		AuthorizedVoters []AuthorizedVoter `json:"authorizedVoters"`
+	}
+
+	AuthorizedVoter struct {
+		AuthorizedVoter string `json:"authorizedVoter"`
+		Epoch           int64  `json:"epoch"`
+	}
+
+	// NonceAccount is the state of an initialized durable nonce account.
+	NonceAccount struct {
+		Authority string `json:"authority"`
+		// Blockhash is the stored nonce, which changes whenever the nonce is advanced
+		Blockhash string `json:"blockhash"`
+		// Lamports is the balance of the account, which is not part of its parsed data
+		Lamports int64 `json:"-"`
+	}
+
+	// TokenAccount is the jsonParsed state of an SPL token account.
+	TokenAccount struct {
+		Mint        string      `json:"mint"`
+		Owner       string      `json:"owner"`
+		TokenAmount TokenAmount `json:"tokenAmount"`
+	}
+
+	TokenAmount struct {
+		// Amount is the raw amount, in the smallest unit of the mint, as a string since it can exceed 2^53
+		Amount   string `json:"amount"`
+		Decimals int64  `json:"decimals"`
+		// UiAmountString is the amount in whole tokens, i.e., Amount shifted by Decimals
+		UiAmountString string `json:"uiAmountString"`
+	}
+
+	// PerformanceSample is the number of slots and transactions processed by the node over a sample period.
+	PerformanceSample struct {
+		Slot                   int64 `json:"slot"`
+		NumSlots               int64 `json:"numSlots"`
+		NumTransactions        int64 `json:"numTransactions"`
+		NumNonVoteTransactions int64 `json:"numNonVoteTransactions"`
+		SamplePeriodSecs       int64 `json:"samplePeriodSecs"`
+	}
+
+	// SignatureInfo is a transaction involving an address, as listed by getSignaturesForAddress.
+	SignatureInfo struct {
+		Signature string `json:"signature"`
+		Slot      int64  `json:"slot"`
+		// Err is the error the transaction failed with, or nil if it succeeded
+		Err                any     `json:"err"`
+		Memo               *string `json:"memo"`
+		BlockTime          *int64  `json:"blockTime"`
+		ConfirmationStatus string  `json:"confirmationStatus"`
+	}
+
+	// StakeAccountState is the jsonParsed state of a stake account, Stake being nil unless it is delegated.
+	StakeAccountState struct {
+		Stake *struct {
+			Delegation StakeDelegation `json:"delegation"`
+		} `json:"stake"`
+	}
+
+	// StakeDelegation is the delegation of a stake account. Its u64 amounts and epochs are returned as strings.
+	StakeDelegation struct {
+		Voter string `json:"voter"`
+		// Stake is the delegated stake (in lamports)
+		Stake int64 `json:"stake,string"`
+		// ActivationEpoch is the epoch the stake was delegated in, or the max uint64 for stake active since genesis
+		ActivationEpoch uint64 `json:"activationEpoch,string"`
+		// DeactivationEpoch is the epoch the stake was deactivated in, or the max uint64 if it was not
+		DeactivationEpoch uint64 `json:"deactivationEpoch,string"`
+	}
+
+	// ValidatorInfo is the info a validator publishes about itself, as parsed from its config account.
+	ValidatorInfo struct {
+		Name            string `json:"name"`
+		Website         string `json:"website"`
+		Details         string `json:"details"`
+		KeybaseUsername string `json:"keybaseUsername"`
+	}
+
+	validatorInfoConfig struct {
+		Keys []struct {
+			Pubkey string `json:"pubkey"`
+			Signer bool   `json:"signer"`
+		} `json:"keys"`
+		ConfigData ValidatorInfo `json:"configData"`
+	}
+
+	keyedAccount struct {
+		Pubkey  string      `json:"pubkey"`
+		Account AccountInfo `json:"account"`
+	}
+
+	// InflationGovernor is the inflation configuration of the cluster. Rates are fractions per year, e.g., 0.08 for 8%.
+	InflationGovernor struct {
+		Initial    float64 `json:"initial"`
+		Terminal   float64 `json:"terminal"`
+		Taper      float64 `json:"taper"`
+		Foundation float64 `json:"foundation"`
+		// FoundationTerm is the duration (in years) of the foundation pool inflation
+		FoundationTerm float64 `json:"foundationTerm"`
+	}
+
+	// EpochSchedule is the layout of epochs over slots, fixed at genesis.
+	EpochSchedule struct {
+		SlotsPerEpoch int64 `json:"slotsPerEpoch"`
+		// LeaderScheduleSlotOffset is how many slots before an epoch its leader schedule is computed
+		LeaderScheduleSlotOffset int64 `json:"leaderScheduleSlotOffset"`
+		// Warmup is whether the cluster started with shorter epochs, doubling up to SlotsPerEpoch
+		Warmup           bool  `json:"warmup"`
+		FirstNormalEpoch int64 `json:"firstNormalEpoch"`
+		FirstNormalSlot  int64 `json:"firstNormalSlot"`
+	}
+
+	// LatestBlockhash is the latest blockhash of the node, which transactions are valid with until the block height
+	// exceeds LastValidBlockHeight.
+	LatestBlockhash struct {
+		Blockhash            string `json:"blockhash"`
+		LastValidBlockHeight int64  `json:"lastValidBlockHeight"`
+	}
+
+	// Supply is the SOL supply of the cluster (in lamports).
+	Supply struct {
+		Total          int64 `json:"total"`
+		Circulating    int64 `json:"circulating"`
+		NonCirculating int64 `json:"nonCirculating"`
 	}
 
 	ValidatorCredits struct {
@@ -136,3 +335,8 @@ func (v *VoteAccount) GetValidatorCredits() (int64, int64) {
 
 	return currentEpochCredits, totalCredits
 }
+
+// UiAmount returns the amount in whole tokens.
+func (a TokenAmount) UiAmount() (float64, error) {
+	return strconv.ParseFloat(a.UiAmountString, 64)
+}
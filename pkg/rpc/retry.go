@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+type (
+	// RetryPolicy configures how transient RPC failures (rate limiting, server errors and timeouts) are retried.
+	// The zero value makes a single attempt.
+	RetryPolicy struct {
+		// MaxAttempts is the total number of attempts made per call, including the first one
+		MaxAttempts int
+		// InitialBackoff is the wait before the first retry, which doubles with every subsequent retry
+		InitialBackoff time.Duration
+		// MaxBackoff caps the (pre-jitter) wait between retries
+		MaxBackoff time.Duration
+		// Jitter is the fraction (in [0, 1]) of each wait which is randomised, such that clients hitting the same
+		// hiccup do not retry in lockstep
+		Jitter float64
+	}
+
+	// StatusError is returned when the RPC server responds with a non-successful HTTP status.
+	StatusError struct {
+		StatusCode int
+		Status     string
+	}
+)
+
+// DefaultRetryPolicy retries a call twice, backing off 200ms and then 400ms (±20%).
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Jitter:         0.2,
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("server responded with %s", e.Status)
+}
+
+// Backoff returns the wait before the provided retry (1 being the first retry).
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 {
+		backoff = min(backoff, p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(backoff))
+	}
+	return backoff
+}
+
+// IsRetryable returns whether an error of a call attempt is transient, i.e., whether the call might succeed if
+// retried: the server rate-limiting (429) or failing (5xx), or the attempt timing out. RPC errors are never retried,
+// as they are returned by a healthy server.
+func IsRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sleep waits for the duration, returning early (with an error) if the context is cancelled.
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
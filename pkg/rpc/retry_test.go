@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFlakyServer serves getSlot, failing the first failures requests with the provided HTTP status.
+func newFlakyServer(t *testing.T, failures int64, status int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, "try again", status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":10,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestClient_Retry(t *testing.T) {
+	ctx := context.Background()
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	t.Run("recovers", func(t *testing.T) {
+		server, requests := newFlakyServer(t, 2, http.StatusTooManyRequests)
+		client := NewRPCClient(server.URL, time.Second, nil)
+		client.Retry = policy
+
+		slot, err := client.GetSlot(ctx, CommitmentFinalized)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), slot)
+		assert.Equal(t, int64(3), requests.Load())
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		server, requests := newFlakyServer(t, 5, http.StatusBadGateway)
+		client := NewRPCClient(server.URL, time.Second, nil)
+		client.Retry = policy
+
+		_, err := client.GetSlot(ctx, CommitmentFinalized)
+		var statusErr *StatusError
+		assert.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+		assert.Equal(t, int64(3), requests.Load())
+	})
+
+	t.Run("non-retryable", func(t *testing.T) {
+		server, requests := newFlakyServer(t, 5, http.StatusBadRequest)
+		client := NewRPCClient(server.URL, time.Second, nil)
+		client.Retry = policy
+
+		_, err := client.GetSlot(ctx, CommitmentFinalized)
+		assert.Error(t, err)
+		assert.Equal(t, int64(1), requests.Load())
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+
+	policy.Jitter = 0.5
+	for retry := 1; retry <= 3; retry++ {
+		backoff := policy.Backoff(retry)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 450*time.Millisecond)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&StatusError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusServiceUnavailable})))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	assert.False(t, IsRetryable(&StatusError{StatusCode: http.StatusNotFound}))
+	assert.False(t, IsRetryable(&Error{Code: NodeUnhealthyCode}))
+	assert.False(t, IsRetryable(context.Canceled))
+}
@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// DriftUnknown is the kind of drift of fields returned by the RPC which the client does not decode
+	DriftUnknown = "unknown"
+	// DriftMissing is the kind of drift of fields the client decodes which the RPC did not return
+	DriftMissing = "missing"
+)
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+type (
+	// SchemaDrift compares the results of RPC calls against the types they are decoded into, recording the fields
+	// returned which the client does not know of, and the fields it expects which were not returned. As encoding/json
+	// silently ignores both, this gives early warning of an RPC release changing the shape of its responses before
+	// metric values go (silently) wrong.
+	SchemaDrift struct {
+		// methods are the drifted field paths of each method, by kind of drift
+		methods map[string]map[string]map[string]struct{}
+		mu      sync.Mutex
+	}
+
+	// SchemaDriftReport is the drift recorded for a method, by the paths of the fields (e.g., "value[].foo").
+	SchemaDriftReport struct {
+		Unknown []string `json:"unknown"`
+		Missing []string `json:"missing"`
+	}
+)
+
+func NewSchemaDrift() *SchemaDrift {
+	return &SchemaDrift{methods: make(map[string]map[string]map[string]struct{})}
+}
+
+// check compares the result of the RPC response body against the type it was decoded into, exporting the number of
+// drifted fields of the method to the metrics.
+func (d *SchemaDrift) check(method string, body []byte, resultType reflect.Type, metrics *Metrics) {
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	var result any
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Result) == 0 {
+		return
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return
+	}
+	drift := map[string][]string{}
+	compareSchema(result, resultType, "", drift)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fields, ok := d.methods[method]
+	if !ok {
+		fields = map[string]map[string]struct{}{DriftUnknown: {}, DriftMissing: {}}
+		d.methods[method] = fields
+	}
+	for kind, paths := range fields {
+		for _, path := range drift[kind] {
+			paths[path] = struct{}{}
+		}
+		metrics.schemaDriftFields.WithLabelValues(method, kind).Set(float64(len(paths)))
+	}
+}
+
+// Report returns the drift recorded so far, by method.
+func (d *SchemaDrift) Report() map[string]SchemaDriftReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	report := make(map[string]SchemaDriftReport, len(d.methods))
+	for method, fields := range d.methods {
+		report[method] = SchemaDriftReport{
+			Unknown: sortedKeys(fields[DriftUnknown]),
+			Missing: sortedKeys(fields[DriftMissing]),
+		}
+	}
+	return report
+}
+
+// compareSchema walks the decoded JSON value alongside the type it is decoded into, appending the paths of drifted
+// fields to drift by kind. Types with custom decoding, and untyped values (e.g., any or json.RawMessage) are not
+// compared.
+func compareSchema(value any, t reflect.Type, path string, drift map[string][]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil || t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		for key, fieldValue := range object {
+			field, ok := fields[key]
+			if !ok {
+				drift[DriftUnknown] = append(drift[DriftUnknown], joinPath(path, key))
+				continue
+			}
+			compareSchema(fieldValue, field.Type, joinPath(path, key), drift)
+		}
+		for key, field := range fields {
+			if _, ok := object[key]; !ok && !strings.Contains(field.Tag.Get("json"), ",omitempty") {
+				drift[DriftMissing] = append(drift[DriftMissing], joinPath(path, key))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		elements, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for _, element := range elements {
+			compareSchema(element, t.Elem(), path+"[]", drift)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, element := range object {
+			compareSchema(element, t.Elem(), path+".*", drift)
+		}
+	}
+}
+
+// jsonFields returns the fields of the struct type by their JSON key, as encoding/json decodes them (including the
+// promoted fields of embedded structs).
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SchemaDrift(t *testing.T) {
+	_, client := newMethodTester(t,
+		"getEpochInfo",
+		map[string]any{
+			"absoluteSlot": 166_598,
+			"blockHeight":  166_500,
+			"epoch":        27,
+			"slotIndex":    2_790,
+			"slotsInEpoch": 8_192,
+			// transactionCount dropped, and a new field added:
+			"epochStartSlot": 163_808,
+		},
+		nil,
+	)
+	client.SchemaDrift = NewSchemaDrift()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// drift does not fail the call:
+	epochInfo, err := client.GetEpochInfo(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(27), epochInfo.Epoch)
+
+	assert.Equal(t,
+		map[string]SchemaDriftReport{
+			"getEpochInfo": {Unknown: []string{"epochStartSlot"}, Missing: []string{"transactionCount"}},
+		},
+		client.SchemaDrift.Report(),
+	)
+	metric := client.metrics.schemaDriftFields
+	assert.Equal(t, float64(1), testutil.ToFloat64(metric.WithLabelValues("getEpochInfo", DriftUnknown)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metric.WithLabelValues("getEpochInfo", DriftMissing)))
+
+	// repeated drift is only counted once:
+	_, err = client.GetEpochInfo(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metric.WithLabelValues("getEpochInfo", DriftUnknown)))
+}
+
+func TestCompareSchema(t *testing.T) {
+	type (
+		nested struct {
+			Name string `json:"name"`
+		}
+		result struct {
+			Items    []nested          `json:"items"`
+			ByKey    map[string]nested `json:"byKey"`
+			Optional *nested           `json:"optional"`
+			Omitted  int64             `json:"omitted,omitempty"`
+			Ignored  int64             `json:"-"`
+			// custom decoding is not compared:
+			Production HostProduction `json:"production"`
+			Untyped    any            `json:"untyped"`
+		}
+	)
+	value := map[string]any{
+		"items":      []any{map[string]any{"name": "a"}, map[string]any{"name": "b", "extra": 1}},
+		"byKey":      map[string]any{"x": map[string]any{}},
+		"optional":   nil,
+		"production": []any{1, 2},
+		"untyped":    map[string]any{"anything": true},
+	}
+	drift := map[string][]string{}
+	compareSchema(value, reflect.TypeFor[result](), "", drift)
+	assert.Equal(t, []string{"items[].extra"}, drift[DriftUnknown])
+	assert.Equal(t, []string{"byKey.*.name"}, drift[DriftMissing])
+}
@@ -0,0 +1,176 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const (
+	MethodCategoryHeavy = "heavy"
+	MethodCategoryLight = "light"
+
+	// DefaultEndpointProbeInterval is how often the endpoints are probed for their slot and history
+	DefaultEndpointProbeInterval = 15 * time.Second
+
+	// scoreSmoothing is the weight of each new observation in the moving averages of the endpoint stats
+	scoreSmoothing = 0.2
+	// scoreLatency and scoreSlotsBehind are the latency and lag behind the most advanced endpoint that each halve
+	// the score of an endpoint
+	scoreLatency     = 250 * time.Millisecond
+	scoreSlotsBehind = 10
+)
+
+// heavyMethods are the methods reading historical data (e.g., blocks), which only archive nodes serve in full.
+var heavyMethods = map[string]bool{
+	"getBlock":                true,
+	"getBlocks":               true,
+	"getBlocksWithLimit":      true,
+	"getBlockTime":            true,
+	"getInflationReward":      true,
+	"getSignaturesForAddress": true,
+	"getTransaction":          true,
+}
+
+// endpointStats are the observations an endpoint is scored on. They are guarded by the mutex of the Endpoints.
+type endpointStats struct {
+	// latency (in seconds) and errorRate are moving averages over the requests to the endpoint
+	latency   float64
+	errorRate float64
+	// slot and firstAvailableBlock are as of the last successful probe, and zero before it
+	slot                int64
+	firstAvailableBlock int64
+	probed              bool
+}
+
+// GetMethodCategory returns whether the method is heavy or light, for routing it to the right endpoint.
+func GetMethodCategory(method string) string {
+	if heavyMethods[method] {
+		return MethodCategoryHeavy
+	}
+	return MethodCategoryLight
+}
+
+func (s *endpointStats) observeLatency(latency time.Duration) {
+	if s.latency == 0 {
+		s.latency = latency.Seconds()
+		return
+	}
+	s.latency += scoreSmoothing * (latency.Seconds() - s.latency)
+}
+
+func (s *endpointStats) observeSuccess() {
+	s.errorRate -= scoreSmoothing * s.errorRate
+}
+
+func (s *endpointStats) observeFailure() {
+	s.errorRate += scoreSmoothing * (1 - s.errorRate)
+}
+
+// slotsBehind returns how many slots the endpoint is behind the highest slot of all endpoints.
+func (s *endpointStats) slotsBehind(highestSlot int64) int64 {
+	if !s.probed {
+		return 0
+	}
+	return highestSlot - s.slot
+}
+
+// score returns the score of the endpoint between 0 and 1, halved by every scoreLatency of latency and every
+// scoreSlotsBehind slots behind, and scaled down by its error rate.
+func (s *endpointStats) score(highestSlot int64) float64 {
+	latencyFactor := 1 / (1 + s.latency/scoreLatency.Seconds())
+	freshnessFactor := 1 / (1 + float64(s.slotsBehind(highestSlot))/scoreSlotsBehind)
+	return (1 - s.errorRate) * latencyFactor * freshnessFactor
+}
+
+// historyStart returns the first available block of the endpoint, with unprobed endpoints considered to have none.
+func (s *endpointStats) historyStart() int64 {
+	if !s.probed {
+		return math.MaxInt64
+	}
+	return s.firstAvailableBlock
+}
+
+func (e *Endpoints) highestSlot() int64 {
+	var highest int64
+	for _, ep := range e.endpoints {
+		highest = max(highest, ep.slot)
+	}
+	return highest
+}
+
+func (e *Endpoints) emitScores() {
+	highestSlot := e.highestSlot()
+	for _, ep := range e.endpoints {
+		e.metrics.endpointScore.WithLabelValues(ep.label).Set(ep.score(highestSlot))
+		e.metrics.endpointLatency.WithLabelValues(ep.label).Set(ep.latency)
+		e.metrics.endpointErrorRate.WithLabelValues(ep.label).Set(ep.errorRate)
+		e.metrics.endpointSlotsBehind.WithLabelValues(ep.label).Set(float64(ep.slotsBehind(highestSlot)))
+		if ep.probed {
+			e.metrics.endpointHistoryStart.WithLabelValues(ep.label).Set(float64(ep.firstAvailableBlock))
+		}
+	}
+}
+
+// ProbeEndpoints probes the endpoints every interval until the context is cancelled, such that their slot freshness
+// and history are known (and their latency and error rate kept up to date) even while no requests are routed to them.
+// It returns straight away if the client does not fail over between endpoints.
+func (c *Client) ProbeEndpoints(ctx context.Context, interval time.Duration) {
+	if c.Endpoints == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	c.logger.Infof("Starting RPC endpoint probes, running every %vs", interval.Seconds())
+	for {
+		for _, ep := range c.Endpoints.endpoints {
+			c.probeEndpoint(ctx, ep)
+		}
+		c.Endpoints.mu.Lock()
+		c.Endpoints.emitScores()
+		c.Endpoints.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeEndpoint reads the processed slot and first available block of the endpoint, with single attempts straight to
+// it, bypassing the failover, deduplication and circuit breaker of the client.
+func (c *Client) probeEndpoint(ctx context.Context, ep *endpoint) {
+	probeClient := *c
+	probeClient.RpcUrl = ep.url
+	probeClient.Endpoints = nil
+	probeClient.Retry = RetryPolicy{}
+	probeClient.CircuitBreaker = nil
+	probeClient.flights = nil
+	probeClient.calls = nil
+
+	start := time.Now()
+	slot, err := probeClient.GetSlot(ctx, CommitmentProcessed)
+	latency := time.Since(start)
+	var firstAvailableBlock int64
+	if err == nil {
+		firstAvailableBlock, err = probeClient.GetFirstAvailableBlock(ctx)
+	}
+
+	if ctx.Err() != nil {
+		// the probe was abandoned, which says nothing about the endpoint's health
+		return
+	}
+
+	c.Endpoints.mu.Lock()
+	defer c.Endpoints.mu.Unlock()
+	if err != nil {
+		c.logger.Warnf("Probe of RPC endpoint %s failed: %v", ep.label, err)
+		ep.observeFailure()
+		return
+	}
+	ep.observeLatency(latency)
+	ep.observeSuccess()
+	ep.slot, ep.firstAvailableBlock, ep.probed = slot, firstAvailableBlock, true
+}
@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// newProbedServer returns a server answering getSlot and getFirstAvailableBlock with the provided results, and
+// any other method with its name (to tell which server a request was routed to).
+func newProbedServer(t *testing.T, name string, slot, firstAvailableBlock int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request Request
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		result := fmt.Sprintf("%q", name)
+		switch request.Method {
+		case "getSlot":
+			result = fmt.Sprint(slot)
+		case "getFirstAvailableBlock":
+			result = fmt.Sprint(firstAvailableBlock)
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","result":%s,"id":1}`, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetMethodCategory(t *testing.T) {
+	assert.Equal(t, MethodCategoryHeavy, GetMethodCategory("getBlock"))
+	assert.Equal(t, MethodCategoryLight, GetMethodCategory("getSlot"))
+}
+
+func TestEndpointStats_score(t *testing.T) {
+	fresh := endpointStats{probed: true, slot: 100}
+	assert.Equal(t, 1.0, fresh.score(100))
+
+	// each of the latency and lag halve the score:
+	slow := endpointStats{latency: scoreLatency.Seconds(), probed: true, slot: 100}
+	assert.InDelta(t, 0.5, slow.score(100), 1e-9)
+	behind := endpointStats{probed: true, slot: 100 - scoreSlotsBehind}
+	assert.InDelta(t, 0.5, behind.score(100), 1e-9)
+
+	// and errors scale it down:
+	failing := endpointStats{probed: true, slot: 100}
+	failing.observeFailure()
+	assert.InDelta(t, 1-scoreSmoothing, failing.score(100), 1e-9)
+	failing.observeSuccess()
+	assert.Greater(t, failing.score(100), 1-scoreSmoothing)
+}
+
+func TestClient_ProbeEndpoints(t *testing.T) {
+	// the primary is lagging, and the archive is the only one with deep history:
+	primary := newProbedServer(t, "primary", 90, 1_000)
+	fast := newProbedServer(t, "fast", 100, 1_000)
+	archive := newProbedServer(t, "archive", 100, 0)
+
+	client := NewFailoverRPCClient([]string{primary.URL, fast.URL, archive.URL}, time.Second, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.ProbeEndpoints(ctx, time.Hour)
+	assert.Eventually(t, func() bool {
+		client.Endpoints.mu.Lock()
+		defer client.Endpoints.mu.Unlock()
+		for _, ep := range client.Endpoints.endpoints {
+			if !ep.probed {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(
+		t, float64(10), testutil.ToFloat64(client.metrics.endpointSlotsBehind.WithLabelValues(RedactUrl(primary.URL))),
+	)
+	assert.Equal(
+		t, float64(0), testutil.ToFloat64(client.metrics.endpointHistoryStart.WithLabelValues(RedactUrl(archive.URL))),
+	)
+
+	// without routing, requests go by priority:
+	assert.Equal(t, RedactUrl(primary.URL), client.Endpoints.candidates("getBlock", time.Now())[0].label)
+
+	// with it, heavy requests go to the archive, and light ones to the best scoring:
+	client.Endpoints.Routing = true
+	assert.Equal(t, RedactUrl(archive.URL), client.Endpoints.candidates("getBlock", time.Now())[0].label)
+	assert.NotEqual(t, RedactUrl(primary.URL), client.Endpoints.candidates("getSlot", time.Now())[0].label)
+}
@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// flightGroup coalesces identical concurrent RPC calls (e.g., of overlapping scrapes, or of the fast-metrics
+	// collection and a scrape) into a single in-flight call, whose response is shared by all callers.
+	flightGroup struct {
+		flights map[string]*flight
+		mu      sync.Mutex
+	}
+
+	flight struct {
+		done chan struct{}
+		body []byte
+		err  error
+		// stats are the calls made by the flight, accounted to every caller which receives its response
+		stats CallStats
+	}
+)
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[string]*flight)}
+}
+
+// do calls fn, unless a call with the same key is already in flight, in which case it waits for that call's result
+// instead. It returns whether the result was shared from another call.
+//
+// The call is detached from the cancellation of the caller which started it (bound by the timeout instead), such that
+// one caller giving up does not fail the others. Each caller waits until the call completes or its own ctx is done,
+// and is accounted the calls made (see CallStats) once it receives the response.
+func (g *flightGroup) do(
+	ctx context.Context, key string, timeout time.Duration, fn func(context.Context) ([]byte, error),
+) ([]byte, error, bool) {
+	g.mu.Lock()
+	f, shared := g.flights[key]
+	if !shared {
+		f = &flight{done: make(chan struct{})}
+		g.flights[key] = f
+		go g.run(ctx, key, f, timeout, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		addCallStats(ctx, &f.stats)
+		return f.body, f.err, shared
+	case <-ctx.Done():
+		return nil, ctx.Err(), shared
+	}
+}
+
+func (g *flightGroup) run(
+	ctx context.Context, key string, f *flight, timeout time.Duration, fn func(context.Context) ([]byte, error),
+) {
+	ctx, cancel := context.WithTimeout(WithCallStats(context.WithoutCancel(ctx), &f.stats), timeout)
+	defer cancel()
+	f.body, f.err = fn(ctx)
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+	close(f.done)
+}
@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_deduplicatesConcurrentCalls(t *testing.T) {
+	var requests atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":10,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewRPCClient(server.URL, time.Second, nil)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slot, err := client.GetSlot(ctx, CommitmentFinalized)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(10), slot)
+		}()
+	}
+	// let all calls join the first one before it completes:
+	assert.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(1), requests.Load())
+
+	// responses are only shared while in flight, later calls are made anew:
+	_, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	_, err = client.GetSlot(ctx, CommitmentConfirmed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), requests.Load())
+}
+
+func TestClient_sharedCallOutlivesItsLeader(t *testing.T) {
+	var requests atomic.Int64
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":10,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewRPCClient(server.URL, time.Second, nil)
+
+	var leaderStats, waiterStats, impatientStats CallStats
+	leaderCtx, cancelLeader := context.WithCancel(WithCallStats(context.Background(), &leaderStats))
+	leaderErr := make(chan error)
+	go func() {
+		_, err := client.GetSlot(leaderCtx, CommitmentFinalized)
+		leaderErr <- err
+	}()
+	assert.Eventually(t, func() bool { return requests.Load() == 1 }, time.Second, time.Millisecond)
+
+	waiterResult := make(chan int64)
+	go func() {
+		slot, err := client.GetSlot(WithCallStats(context.Background(), &waiterStats), CommitmentFinalized)
+		assert.NoError(t, err)
+		waiterResult <- slot
+	}()
+	// waiters give up on their own context, without failing the call:
+	impatientCtx, cancelImpatient := context.WithTimeout(
+		WithCallStats(context.Background(), &impatientStats), 50*time.Millisecond,
+	)
+	defer cancelImpatient()
+	_, err := client.GetSlot(impatientCtx, CommitmentFinalized)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the leader giving up does not fail the waiter either:
+	cancelLeader()
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(release)
+	assert.Equal(t, int64(10), <-waiterResult)
+	assert.Equal(t, int64(1), requests.Load())
+
+	// only the caller which received the response is accounted the call:
+	assert.Equal(t, int64(1), waiterStats.Calls())
+	assert.Equal(t, int64(0), leaderStats.Calls())
+	assert.Equal(t, int64(0), impatientStats.Calls())
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// callCountInterval is the interval the calls of a client are counted (and logged) over
+const callCountInterval = time.Minute
+
+type (
+	// CallStats accumulates the RPC calls made (and response bytes received) under a context, such that the RPC cost
+	// of a caller can be attributed to it. Every attempt counts, including retries.
+	CallStats struct {
+		calls atomic.Int64
+		bytes atomic.Int64
+	}
+
+	callStatsKey struct{}
+
+	// callCounter counts the calls of a client per method, over consecutive intervals.
+	callCounter struct {
+		counts map[string]int64
+		since  time.Time
+		mu     sync.Mutex
+	}
+)
+
+// WithCallStats returns a context under which all RPC calls are accounted to the stats.
+func WithCallStats(ctx context.Context, stats *CallStats) context.Context {
+	return context.WithValue(ctx, callStatsKey{}, stats)
+}
+
+// Calls returns the number of RPC calls accounted so far.
+func (s *CallStats) Calls() int64 {
+	return s.calls.Load()
+}
+
+// Bytes returns the number of (possibly compressed) response bytes received so far.
+func (s *CallStats) Bytes() int64 {
+	return s.bytes.Load()
+}
+
+// addCallStats accounts the calls of stats to the stats of the context, if any.
+func addCallStats(ctx context.Context, stats *CallStats) {
+	if ctxStats, ok := ctx.Value(callStatsKey{}).(*CallStats); ok {
+		ctxStats.calls.Add(stats.Calls())
+		ctxStats.bytes.Add(stats.Bytes())
+	}
+}
+
+// recordCall accounts a call of the method, which sent and received the provided bytes, to the per-method payload
+// counters and to the stats of the context, if any.
+func (m *Metrics) recordCall(ctx context.Context, method string, sent, received int) {
+	m.requestBytes.WithLabelValues(method).Add(float64(sent))
+	m.responseBytes.WithLabelValues(method).Add(float64(received))
+	if stats, ok := ctx.Value(callStatsKey{}).(*CallStats); ok {
+		stats.calls.Add(1)
+		stats.bytes.Add(int64(received))
+	}
+}
+
+func newCallCounter(now time.Time) *callCounter {
+	return &callCounter{counts: make(map[string]int64), since: now}
+}
+
+// count counts a call of the method. Once the current interval has elapsed, it returns the counts over it (excluding
+// this call), and starts a new interval.
+func (c *callCounter) count(method string, now time.Time) map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var elapsed map[string]int64
+	if now.Sub(c.since) >= callCountInterval {
+		elapsed, c.counts, c.since = c.counts, make(map[string]int64), now
+	}
+	c.counts[method]++
+	return elapsed
+}
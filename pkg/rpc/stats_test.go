@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCallStats(t *testing.T) {
+	_, client := NewMockClient(t, map[string]any{"getSlot": 10, "getHealth": "ok"}, nil, nil, nil, nil, nil)
+	var stats CallStats
+	ctx := WithCallStats(context.Background(), &stats)
+
+	_, err := client.GetSlot(ctx, CommitmentFinalized)
+	assert.NoError(t, err)
+	_, err = client.GetHealth(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Calls())
+	assert.Greater(t, stats.Bytes(), int64(0))
+
+	// calls outside the context are not accounted:
+	_, err = client.GetSlot(context.Background(), CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Calls())
+}
+
+func TestClient_countsPayloadBytes(t *testing.T) {
+	_, client := NewMockClient(t, map[string]any{"getHealth": "ok"}, nil, nil, nil, nil, nil)
+	sent := client.metrics.requestBytes.WithLabelValues("getHealth")
+	received := client.metrics.responseBytes.WithLabelValues("getHealth")
+
+	var stats CallStats
+	_, err := client.GetHealth(WithCallStats(context.Background(), &stats))
+	assert.NoError(t, err)
+	request := `{"jsonrpc":"2.0","id":1,"method":"getHealth","params":[]}`
+	assert.Equal(t, float64(len(request)), testutil.ToFloat64(sent))
+	assert.Equal(t, float64(stats.Bytes()), testutil.ToFloat64(received))
+}
+
+func TestCallCounter(t *testing.T) {
+	start := time.Now()
+	counter := newCallCounter(start)
+	assert.Nil(t, counter.count("getSlot", start))
+	assert.Nil(t, counter.count("getSlot", start.Add(time.Second)))
+	assert.Nil(t, counter.count("getHealth", start.Add(2*time.Second)))
+
+	// once the interval elapsed, its counts are returned, and the next interval starts with the call:
+	assert.Equal(t, map[string]int64{"getSlot": 2, "getHealth": 1}, counter.count("getSlot", start.Add(time.Minute)))
+	assert.Equal(t, map[string]int64{"getSlot": 1}, counter.counts)
+}
@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	PhaseDNS          = "dns"
+	PhaseConnect      = "connect"
+	PhaseTLSHandshake = "tls_handshake"
+	PhaseFirstByte    = "first_byte"
+)
+
+// connectionPhaseBuckets span sub-millisecond local connections up to slow remote providers.
+var connectionPhaseBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// connectionTrace times the connection phases of a request. Its callbacks may run concurrently (e.g., when dialing
+// several addresses of a host), hence the mutex.
+type connectionTrace struct {
+	metrics  *Metrics
+	endpoint string
+	starts   map[string]time.Time
+	mu       sync.Mutex
+}
+
+// withConnectionTrace returns a context under which the connection phases of requests to the endpoint are observed:
+// DNS lookups, dials and TLS handshakes of new connections, and the time to first byte of every request (from it
+// being written), such that network problems can be told apart from slow RPC processing.
+func (m *Metrics) withConnectionTrace(ctx context.Context, endpoint string) context.Context {
+	t := &connectionTrace{metrics: m, endpoint: endpoint, starts: make(map[string]time.Time)}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.start(PhaseDNS) },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.done(PhaseDNS, info.Err)
+		},
+		ConnectStart: func(_, addr string) { t.start(PhaseConnect + addr) },
+		ConnectDone: func(_, addr string, err error) {
+			t.doneAs(PhaseConnect+addr, PhaseConnect, err)
+		},
+		TLSHandshakeStart: func() { t.start(PhaseTLSHandshake) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.done(PhaseTLSHandshake, err)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			m.connections.WithLabelValues(endpoint, strconv.FormatBool(info.Reused)).Inc()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) { t.start(PhaseFirstByte) },
+		GotFirstResponseByte: func() {
+			t.done(PhaseFirstByte, nil)
+		},
+	})
+}
+
+func (t *connectionTrace) start(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.starts[key] = time.Now()
+}
+
+func (t *connectionTrace) done(phase string, err error) {
+	t.doneAs(phase, phase, err)
+}
+
+// doneAs observes the duration of the phase started under the key, unless it failed (as failures are counted as RPC
+// errors instead).
+func (t *connectionTrace) doneAs(key, phase string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start, ok := t.starts[key]
+	if !ok || err != nil {
+		return
+	}
+	delete(t.starts, key)
+	t.metrics.connectionPhase.WithLabelValues(t.endpoint, phase).Observe(time.Since(start).Seconds())
+}
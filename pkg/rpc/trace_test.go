@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_connectionTrace(t *testing.T) {
+	mockServer, client := NewMockClient(t, map[string]any{"getSlot": 10}, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+	endpoint := RedactUrl(mockServer.URL())
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetSlot(ctx, CommitmentFinalized)
+		assert.NoError(t, err)
+	}
+
+	// the first request dials a connection, which the second reuses:
+	assert.Equal(t, float64(1), testutil.ToFloat64(client.metrics.connections.WithLabelValues(endpoint, "false")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(client.metrics.connections.WithLabelValues(endpoint, "true")))
+	// of the connection phases, only the dial (to an IP) and time to first bytes are observed:
+	assert.Equal(t, 2, testutil.CollectAndCount(client.metrics.connectionPhase))
+}
@@ -0,0 +1,73 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	DefaultMaxIdleConns    = 100
+	DefaultIdleConnTimeout = 90 * time.Second
+	DefaultTLSMinVersion   = "1.2"
+)
+
+// tlsVersions are the TLS versions a minimum can be set to, by name.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TransportConfig tunes the HTTP transport of RPC clients. The defaults of net/http keep only 2 idle connections per
+// host, such that concurrent polling of the same node keeps re-dialing (and re-handshaking) connections.
+type TransportConfig struct {
+	// MaxIdleConns is the number of idle (keep-alive) connections kept per host
+	MaxIdleConns    int
+	IdleConnTimeout time.Duration
+	// TLS (if set) is the TLS configuration of https endpoints
+	TLS *tls.Config
+}
+
+// NewTransport returns an HTTP transport with the defaults of net/http, tuned as per the config.
+func NewTransport(config TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.MaxIdleConnsPerHost = config.MaxIdleConns
+	transport.IdleConnTimeout = config.IdleConnTimeout
+	if config.TLS != nil {
+		transport.TLSClientConfig = config.TLS
+	}
+	return transport
+}
+
+// NewTLSConfig returns a TLS configuration requiring at least the named TLS version (e.g., "1.2"), which trusts the
+// certificates of the PEM bundle at caFile (if set) in addition to the system ones.
+func NewTLSConfig(minVersion string, caFile string) (*tls.Config, error) {
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS version '%s', must be one of 1.0, 1.1, 1.2 or 1.3", minVersion)
+	}
+	config := &tls.Config{MinVersion: version}
+	if caFile == "" {
+		return config, nil
+	}
+
+	bundle, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
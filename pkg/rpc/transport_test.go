@@ -0,0 +1,57 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	config, err := NewTLSConfig("1.3", "")
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Nil(t, config.RootCAs)
+
+	_, err = NewTLSConfig("1.4", "")
+	assert.Error(t, err)
+
+	// bundles without any certificate are rejected:
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+	_, err = NewTLSConfig(DefaultTLSMinVersion, caFile)
+	assert.Error(t, err)
+}
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":10,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, certificate, 0o600))
+
+	// the server's self-signed certificate is only trusted through the CA bundle:
+	client := NewRPCClient(server.URL, time.Second, nil)
+	_, err := client.GetSlot(context.Background(), CommitmentFinalized)
+	assert.Error(t, err)
+
+	tlsConfig, err := NewTLSConfig(DefaultTLSMinVersion, caFile)
+	assert.NoError(t, err)
+	transport := NewTransport(
+		TransportConfig{MaxIdleConns: DefaultMaxIdleConns, IdleConnTimeout: DefaultIdleConnTimeout, TLS: tlsConfig},
+	)
+	assert.Equal(t, DefaultMaxIdleConns, transport.MaxIdleConnsPerHost)
+	client.HttpClient.Transport = transport
+	slot, err := client.GetSlot(context.Background(), CommitmentFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), slot)
+}
@@ -0,0 +1,227 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/seedfourtytwo/solana-exporter/pkg/slog"
+	"go.uber.org/zap"
+)
+
+// DefaultWSReadTimeout is how long a subscription may go without receiving anything (a notification, ping or pong)
+// before its connection is taken as dead.
+const DefaultWSReadTimeout = time.Minute
+
+type (
+	// WSClient is a client for the Solana RPC PubSub WebSocket API.
+	WSClient struct {
+		WsUrl string
+		// Headers are sent with the handshake of every connection, e.g., for authenticating with RPC providers
+		Headers http.Header
+		Dialer  *websocket.Dialer
+		// ReadTimeout is how long a subscription may go without receiving anything before it is dropped. Subscriptions
+		// are pinged every half of it, such that quiet (but live) ones are kept alive by the pongs.
+		ReadTimeout time.Duration
+		logger      *zap.SugaredLogger
+	}
+
+	// SlotNotification is a notification of the slotSubscribe subscription.
+	SlotNotification struct {
+		Parent int64 `json:"parent"`
+		Root   int64 `json:"root"`
+		Slot   int64 `json:"slot"`
+	}
+
+	// VoteNotification is a notification of the voteSubscribe subscription.
+	VoteNotification struct {
+		Hash       string  `json:"hash"`
+		Slots      []int64 `json:"slots"`
+		Timestamp  *int64  `json:"timestamp"`
+		Signature  string  `json:"signature"`
+		VotePubkey string  `json:"votePubkey"`
+	}
+
+	// BlockNotification is a notification of the blockSubscribe subscription. Block is nil if an error occurred
+	// while fetching the block.
+	BlockNotification struct {
+		Slot  int64  `json:"slot"`
+		Block *Block `json:"block"`
+		Err   any    `json:"err"`
+	}
+
+	subscriptionNotification[T any] struct {
+		Method string `json:"method"`
+		Params struct {
+			Result       T     `json:"result"`
+			Subscription int64 `json:"subscription"`
+		} `json:"params"`
+	}
+)
+
+func NewWSClient(wsUrl string) *WSClient {
+	return &WSClient{
+		WsUrl: wsUrl, Dialer: websocket.DefaultDialer, ReadTimeout: DefaultWSReadTimeout, logger: slog.Get(),
+	}
+}
+
+// WebsocketUrlFromRpcUrl derives the default PubSub url of a node from its HTTP RPC url, i.e., the same host
+// with a ws(s) scheme, and the port incremented by one (as the validator binds it by default).
+func WebsocketUrlFromRpcUrl(rpcUrl string) (string, error) {
+	parsed, err := url.Parse(rpcUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse rpc url: %w", err)
+	}
+	switch parsed.Scheme {
+	case "http":
+		parsed.Scheme = "ws"
+	case "https":
+		parsed.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported rpc url scheme %q", parsed.Scheme)
+	}
+	if port := parsed.Port(); port != "" {
+		portNumber, err := strconv.Atoi(port)
+		if err != nil {
+			return "", fmt.Errorf("invalid rpc url port %q: %w", port, err)
+		}
+		parsed.Host = fmt.Sprintf("%s:%d", parsed.Hostname(), portNumber+1)
+	}
+	return parsed.String(), nil
+}
+
+// SubscribeSlots subscribes to slot notifications, which are sent on the returned channel until the context is
+// cancelled or the connection drops, at which point the channel is closed.
+// See API docs: https://solana.com/docs/rpc/websocket/slotsubscribe
+func (c *WSClient) SubscribeSlots(ctx context.Context) (<-chan SlotNotification, error) {
+	return subscribe[SlotNotification](ctx, c, "slotSubscribe", []any{})
+}
+
+// SubscribeVotes subscribes to notifications of the votes observed in gossip, which are sent on the returned channel
+// until the context is cancelled or the connection drops, at which point the channel is closed. The node must run
+// with --rpc-pubsub-enable-vote-subscription.
+// See API docs: https://solana.com/docs/rpc/websocket/votesubscribe
+func (c *WSClient) SubscribeVotes(ctx context.Context) (<-chan VoteNotification, error) {
+	return subscribe[VoteNotification](ctx, c, "voteSubscribe", []any{})
+}
+
+// SubscribeBlocks subscribes to notifications of the blocks which mention the provided account (e.g., a validator
+// identity, which every block it produces mentions), which are sent on the returned channel until the context is
+// cancelled or the connection drops, at which point the channel is closed. The node must run with
+// --rpc-pubsub-enable-block-subscription.
+// See API docs: https://solana.com/docs/rpc/websocket/blocksubscribe
+func (c *WSClient) SubscribeBlocks(
+	ctx context.Context, commitment Commitment, mentions string, transactionDetails string,
+) (<-chan BlockNotification, error) {
+	if commitment == CommitmentProcessed {
+		return nil, fmt.Errorf("commitment '%v' is not supported for blockSubscribe", CommitmentProcessed)
+	}
+	filter := map[string]string{"mentionsAccountOrProgram": mentions}
+	config := map[string]any{
+		"commitment":                     commitment,
+		"encoding":                       "json",
+		"transactionDetails":             transactionDetails,
+		"showRewards":                    true,
+		"maxSupportedTransactionVersion": 0,
+	}
+	results, err := subscribe[contextualResult[BlockNotification]](ctx, c, "blockSubscribe", []any{filter, config})
+	if err != nil {
+		return nil, err
+	}
+	notifications := make(chan BlockNotification)
+	go func() {
+		defer close(notifications)
+		for result := range results {
+			select {
+			case notifications <- result.Value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return notifications, nil
+}
+
+// subscribe dials the PubSub endpoint and subscribes through the provided method, forwarding notifications until
+// the context is cancelled or the connection drops. A connection which receives nothing within the read timeout (e.g.,
+// a half-open one) is dropped too.
+func subscribe[T any](ctx context.Context, c *WSClient, method string, params []any) (<-chan T, error) {
+	conn, _, err := c.Dialer.DialContext(ctx, c.WsUrl, c.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", RedactUrl(c.WsUrl), err)
+	}
+	request := Request{Jsonrpc: "2.0", Id: 1, Method: method, Params: params}
+	if err := conn.WriteJSON(request); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+	var resp Response[int64]
+	if err := conn.ReadJSON(&resp); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if resp.Error.Code != 0 {
+		_ = conn.Close()
+		resp.Error.Method = method
+		return nil, &resp.Error
+	}
+	c.logger.Infof("Subscribed through %s (subscription %d)", method, resp.Result)
+
+	// anything received (including pings and pongs) extends the read deadline:
+	extendDeadline := func() error { return conn.SetReadDeadline(time.Now().Add(c.ReadTimeout)) }
+	conn.SetPongHandler(func(string) error { return extendDeadline() })
+	conn.SetPingHandler(func(data string) error {
+		if err := extendDeadline(); err != nil {
+			return err
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(c.ReadTimeout))
+	})
+	if err := extendDeadline(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to set %s read deadline: %w", method, err)
+	}
+
+	notifications := make(chan T)
+	readCtx, cancel := context.WithCancel(ctx)
+	// unblock the reader when the context is cancelled, and ping the server meanwhile:
+	go func() {
+		ticker := time.NewTicker(c.ReadTimeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-readCtx.Done():
+				_ = conn.Close()
+				return
+			case <-ticker.C:
+				_ = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.ReadTimeout))
+			}
+		}
+	}()
+	go func() {
+		defer close(notifications)
+		defer cancel()
+		for {
+			var notification subscriptionNotification[T]
+			if err := conn.ReadJSON(&notification); err != nil {
+				if ctx.Err() == nil {
+					c.logger.Errorf("%s subscription dropped: %v", method, err)
+				}
+				return
+			}
+			if err := extendDeadline(); err != nil {
+				c.logger.Errorf("%s subscription dropped: %v", method, err)
+				return
+			}
+			select {
+			case notifications <- notification.Params.Result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return notifications, nil
+}
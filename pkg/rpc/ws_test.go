@@ -0,0 +1,213 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSubscriptionServer serves a subscription endpoint which sends the provided notifications and then drops.
+func newSubscriptionServer[T any](t *testing.T, method string, notifications []T) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer conn.Close()
+
+		var request Request
+		if err := conn.ReadJSON(&request); err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return
+		}
+		assert.Equal(t, method, request.Method)
+		_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "result": 7, "id": request.Id})
+		for _, notification := range notifications {
+			_ = conn.WriteJSON(map[string]any{
+				"jsonrpc": "2.0",
+				"method":  strings.TrimSuffix(method, "Subscribe") + "Notification",
+				"params":  map[string]any{"result": notification, "subscription": 7},
+			})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWSClient_SubscribeSlots(t *testing.T) {
+	expected := []SlotNotification{{Parent: 9, Root: 1, Slot: 10}, {Parent: 10, Root: 2, Slot: 11}}
+	server := newSubscriptionServer(t, "slotSubscribe", expected)
+	client := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := client.SubscribeSlots(ctx)
+	assert.NoError(t, err)
+
+	var received []SlotNotification
+	for notification := range notifications {
+		received = append(received, notification)
+	}
+	// the channel is closed once the server drops the connection:
+	assert.Equal(t, expected, received)
+}
+
+func TestWSClient_SubscribeSlots_halfOpen(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	release := make(chan struct{})
+	// the server acknowledges the subscription and then goes silent, neither notifying nor answering pings:
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer conn.Close()
+		var request Request
+		_ = conn.ReadJSON(&request)
+		_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "result": 7, "id": request.Id})
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	client := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	client.ReadTimeout = 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := client.SubscribeSlots(ctx)
+	assert.NoError(t, err)
+	select {
+	case _, ok := <-notifications:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("silent subscription was not dropped")
+	}
+}
+
+func TestWSClient_SubscribeSlots_quiet(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	// the server notifies only after several read timeouts, but answers pings meanwhile:
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer conn.Close()
+		var request Request
+		_ = conn.ReadJSON(&request)
+		_ = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "result": 7, "id": request.Id})
+		// reading is what answers the pings:
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+		time.Sleep(500 * time.Millisecond)
+		_ = conn.WriteJSON(map[string]any{
+			"jsonrpc": "2.0",
+			"method":  "slotNotification",
+			"params":  map[string]any{"result": SlotNotification{Slot: 10}, "subscription": 7},
+		})
+	}))
+	t.Cleanup(server.Close)
+	client := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	client.ReadTimeout = 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := client.SubscribeSlots(ctx)
+	assert.NoError(t, err)
+	notification, ok := <-notifications
+	assert.True(t, ok)
+	assert.Equal(t, int64(10), notification.Slot)
+}
+
+func TestWSClient_SubscribeVotes(t *testing.T) {
+	timestamp := int64(1_700_000_000)
+	expected := []VoteNotification{
+		{Hash: "hash", Slots: []int64{10, 11}, Timestamp: &timestamp, Signature: "sig", VotePubkey: "AAA"},
+	}
+	server := newSubscriptionServer(t, "voteSubscribe", expected)
+	client := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := client.SubscribeVotes(ctx)
+	assert.NoError(t, err)
+
+	var received []VoteNotification
+	for notification := range notifications {
+		received = append(received, notification)
+	}
+	assert.Equal(t, expected, received)
+}
+
+func TestWSClient_SubscribeBlocks(t *testing.T) {
+	server := newSubscriptionServer(t, "blockSubscribe", []map[string]any{
+		{
+			"context": map[string]int{"slot": 12},
+			"value": map[string]any{
+				"slot":  12,
+				"block": map[string]any{"rewards": []map[string]any{{"pubkey": "aaa", "lamports": 10, "rewardType": "fee"}}},
+				"err":   nil,
+			},
+		},
+	})
+	client := NewWSClient("ws" + strings.TrimPrefix(server.URL, "http"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifications, err := client.SubscribeBlocks(ctx, CommitmentConfirmed, "aaa", "none")
+	assert.NoError(t, err)
+
+	var received []BlockNotification
+	for notification := range notifications {
+		received = append(received, notification)
+	}
+	assert.Equal(t,
+		[]BlockNotification{
+			{Slot: 12, Block: &Block{Rewards: []BlockReward{{Pubkey: "aaa", Lamports: 10, RewardType: "fee"}}}},
+		},
+		received,
+	)
+
+	_, err = client.SubscribeBlocks(ctx, CommitmentProcessed, "aaa", "none")
+	assert.Error(t, err)
+}
+
+func TestWebsocketUrlFromRpcUrl(t *testing.T) {
+	tests := []struct {
+		rpcUrl   string
+		expected string
+	}{
+		{"http://localhost:8899", "ws://localhost:8900"},
+		{"https://api.mainnet-beta.solana.com", "wss://api.mainnet-beta.solana.com"},
+		{"https://rpc.example.com:443/path", "wss://rpc.example.com:444/path"},
+	}
+	for _, test := range tests {
+		t.Run(test.rpcUrl, func(t *testing.T) {
+			wsUrl, err := WebsocketUrlFromRpcUrl(test.rpcUrl)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, wsUrl)
+		})
+	}
+
+	_, err := WebsocketUrlFromRpcUrl("ftp://localhost")
+	assert.Error(t, err)
+}
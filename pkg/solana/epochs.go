@@ -0,0 +1,130 @@
+// Package solana holds the domain logic of Solana's slots and epochs, i.e., the conversions between absolute slots,
+// slot indexes and epochs, kept apart from the exporter such that its (easily off-by-one) arithmetic is tested in
+// isolation.
+package solana
+
+import (
+	"math/bits"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+)
+
+// MinimumSlotsPerEpoch is the length of the first epoch when the cluster warms up, each warmup epoch being twice as
+// long as the one before it.
+const MinimumSlotsPerEpoch = 32
+
+// EpochSchedule is the layout of epochs over slots: a warmup period of epochs doubling in length from
+// MinimumSlotsPerEpoch (if enabled), followed by epochs of SlotsPerEpoch slots.
+type EpochSchedule struct {
+	SlotsPerEpoch int64
+	// FirstNormalEpoch and FirstNormalSlot are where the warmup period ends, or both zero without warmup
+	FirstNormalEpoch int64
+	FirstNormalSlot  int64
+}
+
+// NewEpochSchedule returns the epoch schedule of a cluster with epochs of slotsPerEpoch slots, as the validator
+// derives it. slotsPerEpoch must be at least MinimumSlotsPerEpoch.
+func NewEpochSchedule(slotsPerEpoch int64, warmup bool) EpochSchedule {
+	if !warmup {
+		return EpochSchedule{SlotsPerEpoch: slotsPerEpoch}
+	}
+	// the warmup epochs double up to (and excluding) the power of two the normal epochs fit in:
+	firstNormalEpoch := log2(nextPowerOfTwo(slotsPerEpoch)) - log2(MinimumSlotsPerEpoch)
+	firstNormalSlot := (int64(1)<<firstNormalEpoch - 1) * MinimumSlotsPerEpoch
+	return EpochSchedule{
+		SlotsPerEpoch: slotsPerEpoch, FirstNormalEpoch: firstNormalEpoch, FirstNormalSlot: firstNormalSlot,
+	}
+}
+
+// NewEpochScheduleFromRpc returns the epoch schedule of the cluster as returned by the RPC.
+func NewEpochScheduleFromRpc(schedule *rpc.EpochSchedule) EpochSchedule {
+	return EpochSchedule{
+		SlotsPerEpoch:    schedule.SlotsPerEpoch,
+		FirstNormalEpoch: schedule.FirstNormalEpoch,
+		FirstNormalSlot:  schedule.FirstNormalSlot,
+	}
+}
+
+// SlotsInEpoch returns the number of slots in the epoch.
+func (s EpochSchedule) SlotsInEpoch(epoch int64) int64 {
+	if epoch < s.FirstNormalEpoch {
+		return int64(1) << (epoch + log2(MinimumSlotsPerEpoch))
+	}
+	return s.SlotsPerEpoch
+}
+
+// EpochBounds returns the first and last slot [inclusive] of the epoch.
+func (s EpochSchedule) EpochBounds(epoch int64) (int64, int64) {
+	var firstSlot int64
+	if epoch <= s.FirstNormalEpoch {
+		firstSlot = (int64(1)<<epoch - 1) * MinimumSlotsPerEpoch
+	} else {
+		firstSlot = s.FirstNormalSlot + (epoch-s.FirstNormalEpoch)*s.SlotsPerEpoch
+	}
+	return firstSlot, firstSlot + s.SlotsInEpoch(epoch) - 1
+}
+
+// GetEpoch returns the epoch of the slot, and the index of the slot within it.
+func (s EpochSchedule) GetEpoch(slot int64) (epoch int64, slotIndex int64) {
+	if slot < s.FirstNormalSlot {
+		// warmup epoch n spans [(2^n - 1) * MinimumSlotsPerEpoch, (2^(n+1) - 1) * MinimumSlotsPerEpoch):
+		epoch = log2(nextPowerOfTwo(slot+MinimumSlotsPerEpoch+1)) - log2(MinimumSlotsPerEpoch) - 1
+		firstSlot, _ := s.EpochBounds(epoch)
+		return epoch, slot - firstSlot
+	}
+	normalSlotIndex := slot - s.FirstNormalSlot
+	return s.FirstNormalEpoch + normalSlotIndex/s.SlotsPerEpoch, normalSlotIndex % s.SlotsPerEpoch
+}
+
+// GetEpochBounds returns the first slot and last slot within an [inclusive] Epoch
+func GetEpochBounds(info *rpc.EpochInfo) (int64, int64) {
+	firstSlot := ToEpochFirstSlot(info.AbsoluteSlot, info.SlotIndex)
+	return firstSlot, firstSlot + info.SlotsInEpoch - 1
+}
+
+// ToAbsoluteSlot converts the index of a slot within its epoch (e.g., from the leader schedule) to the absolute slot.
+func ToAbsoluteSlot(slotIndex, epochFirstSlot int64) int64 {
+	return epochFirstSlot + slotIndex
+}
+
+// ToSlotIndex converts an absolute slot to its index within its epoch.
+func ToSlotIndex(slot, epochFirstSlot int64) int64 {
+	return slot - epochFirstSlot
+}
+
+// ToEpochFirstSlot returns the first slot of the epoch of the absolute slot with the slot index.
+func ToEpochFirstSlot(slot, slotIndex int64) int64 {
+	return slot - slotIndex
+}
+
+// IsSlotRangeWithin returns whether the slot range [from, to] lies within [first, last], all inclusive. Empty ranges
+// (i.e., to before from, when there is nothing new to process) are not rejected.
+func IsSlotRangeWithin(from, to, first, last int64) bool {
+	return from >= first && to <= last
+}
+
+// SelectFromSchedule takes a leader-schedule and returns a trimmed leader-schedule
+// containing only the slots within the provided range
+func SelectFromSchedule(schedule map[string][]int64, startSlot, endSlot int64) map[string][]int64 {
+	selected := make(map[string][]int64)
+	for key, values := range schedule {
+		var selectedValues []int64
+		for _, value := range values {
+			if value >= startSlot && value <= endSlot {
+				selectedValues = append(selectedValues, value)
+			}
+		}
+		selected[key] = selectedValues
+	}
+	return selected
+}
+
+// nextPowerOfTwo returns the smallest power of two at least n (which must be positive).
+func nextPowerOfTwo(n int64) int64 {
+	return int64(1) << bits.Len64(uint64(n-1))
+}
+
+// log2 returns the base 2 logarithm of the power of two n.
+func log2(n int64) int64 {
+	return int64(bits.TrailingZeros64(uint64(n)))
+}
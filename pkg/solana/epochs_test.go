@@ -0,0 +1,186 @@
+package solana
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/seedfourtytwo/solana-exporter/pkg/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSchedules are the schedules the properties are checked against: mainnet's, a test validator's, and ones with
+// warmup periods ending on and off a power of two.
+var testSchedules = []EpochSchedule{
+	NewEpochSchedule(432_000, true),
+	NewEpochSchedule(432_000, false),
+	NewEpochSchedule(8192, true),
+	NewEpochSchedule(MinimumSlotsPerEpoch, true),
+	NewEpochSchedule(100, true),
+	NewEpochSchedule(100, false),
+}
+
+// slotValues generates slots concentrated around the end of the warmup period of the schedule, where the off-by-one
+// errors lurk, along with arbitrary slots well into the normal epochs.
+func slotValues(schedule EpochSchedule) func([]reflect.Value, *rand.Rand) {
+	return func(values []reflect.Value, r *rand.Rand) {
+		var slot int64
+		switch r.Intn(3) {
+		case 0:
+			slot = r.Int63n(schedule.FirstNormalSlot + 1)
+		case 1:
+			slot = max(0, schedule.FirstNormalSlot+r.Int63n(2*MinimumSlotsPerEpoch)-MinimumSlotsPerEpoch)
+		default:
+			slot = r.Int63n(1 << 40)
+		}
+		values[0] = reflect.ValueOf(slot)
+	}
+}
+
+func TestNewEpochSchedule(t *testing.T) {
+	// as derived by the validator for mainnet's parameters (with warmup, which mainnet itself disabled):
+	assert.Equal(t,
+		EpochSchedule{SlotsPerEpoch: 432_000, FirstNormalEpoch: 14, FirstNormalSlot: 524_256},
+		NewEpochSchedule(432_000, true),
+	)
+	assert.Equal(t, EpochSchedule{SlotsPerEpoch: 432_000}, NewEpochSchedule(432_000, false))
+	assert.Equal(t,
+		EpochSchedule{SlotsPerEpoch: 8192, FirstNormalEpoch: 8, FirstNormalSlot: 8160},
+		NewEpochSchedule(8192, true),
+	)
+	assert.Equal(t, EpochSchedule{SlotsPerEpoch: MinimumSlotsPerEpoch}, NewEpochSchedule(MinimumSlotsPerEpoch, true))
+}
+
+func TestEpochSchedule_EpochBounds(t *testing.T) {
+	schedule := NewEpochSchedule(8192, true)
+	for _, test := range []struct{ epoch, first, last int64 }{
+		{0, 0, 31},
+		{1, 32, 95},
+		{2, 96, 223},
+		{7, 4064, 8159},
+		{8, 8160, 16351},
+		{9, 16352, 24543},
+	} {
+		first, last := schedule.EpochBounds(test.epoch)
+		assert.Equal(t, test.first, first, "first slot of epoch %d", test.epoch)
+		assert.Equal(t, test.last, last, "last slot of epoch %d", test.epoch)
+	}
+}
+
+func TestEpochSchedule_GetEpoch(t *testing.T) {
+	schedule := NewEpochSchedule(8192, true)
+	for _, test := range []struct{ slot, epoch, slotIndex int64 }{
+		{0, 0, 0},
+		{31, 0, 31},
+		{32, 1, 0},
+		{95, 1, 63},
+		{96, 2, 0},
+		{8159, 7, 4095},
+		{8160, 8, 0},
+		{16351, 8, 8191},
+		{16352, 9, 0},
+	} {
+		epoch, slotIndex := schedule.GetEpoch(test.slot)
+		assert.Equal(t, test.epoch, epoch, "epoch of slot %d", test.slot)
+		assert.Equal(t, test.slotIndex, slotIndex, "index of slot %d", test.slot)
+	}
+}
+
+func TestEpochSchedule_properties(t *testing.T) {
+	for _, schedule := range testSchedules {
+		config := &quick.Config{MaxCount: 10_000, Values: slotValues(schedule)}
+
+		// every slot lies within the bounds of its epoch, at its index:
+		withinEpoch := func(slot int64) bool {
+			epoch, slotIndex := schedule.GetEpoch(slot)
+			first, last := schedule.EpochBounds(epoch)
+			return first <= slot && slot <= last && ToAbsoluteSlot(slotIndex, first) == slot &&
+				ToSlotIndex(slot, first) == slotIndex && last-first+1 == schedule.SlotsInEpoch(epoch)
+		}
+		assert.NoError(t, quick.Check(withinEpoch, config), "%+v", schedule)
+
+		// epochs are contiguous, the slot after the last of an epoch being the first of the next:
+		contiguous := func(slot int64) bool {
+			epoch, _ := schedule.GetEpoch(slot)
+			_, last := schedule.EpochBounds(epoch)
+			nextEpoch, nextSlotIndex := schedule.GetEpoch(last + 1)
+			nextFirst, _ := schedule.EpochBounds(epoch + 1)
+			return nextEpoch == epoch+1 && nextSlotIndex == 0 && nextFirst == last+1
+		}
+		assert.NoError(t, quick.Check(contiguous, config), "%+v", schedule)
+
+		// the epoch info the RPC would return for the slot has the same bounds:
+		matchesEpochInfo := func(slot int64) bool {
+			epoch, slotIndex := schedule.GetEpoch(slot)
+			info := &rpc.EpochInfo{
+				AbsoluteSlot: slot, Epoch: epoch, SlotIndex: slotIndex, SlotsInEpoch: schedule.SlotsInEpoch(epoch),
+			}
+			first, last := GetEpochBounds(info)
+			expectedFirst, expectedLast := schedule.EpochBounds(epoch)
+			return first == expectedFirst && last == expectedLast
+		}
+		assert.NoError(t, quick.Check(matchesEpochInfo, config), "%+v", schedule)
+	}
+}
+
+func TestGetEpochBounds(t *testing.T) {
+	epoch := rpc.EpochInfo{AbsoluteSlot: 25, SlotIndex: 5, SlotsInEpoch: 10}
+	first, last := GetEpochBounds(&epoch)
+	assert.Equal(t, int64(20), first)
+	assert.Equal(t, int64(29), last)
+}
+
+func TestNewEpochScheduleFromRpc(t *testing.T) {
+	// the schedule the RPC returns is the one the validator derives:
+	for _, schedule := range []*rpc.EpochSchedule{
+		// e.g., devnet and testnet:
+		{
+			SlotsPerEpoch: 432_000, LeaderScheduleSlotOffset: 432_000, Warmup: true, FirstNormalEpoch: 14,
+			FirstNormalSlot: 524_256,
+		},
+		// e.g., mainnet:
+		{SlotsPerEpoch: 432_000, LeaderScheduleSlotOffset: 432_000},
+	} {
+		assert.Equal(t, NewEpochSchedule(schedule.SlotsPerEpoch, schedule.Warmup), NewEpochScheduleFromRpc(schedule))
+	}
+
+	// warmup epochs are shorter:
+	schedule := NewEpochScheduleFromRpc(
+		&rpc.EpochSchedule{SlotsPerEpoch: 8192, Warmup: true, FirstNormalEpoch: 8, FirstNormalSlot: 8160},
+	)
+	first, last := schedule.EpochBounds(1)
+	assert.Equal(t, int64(32), first)
+	assert.Equal(t, int64(95), last)
+}
+
+func TestIsSlotRangeWithin(t *testing.T) {
+	assert.True(t, IsSlotRangeWithin(20, 29, 20, 29))
+	assert.True(t, IsSlotRangeWithin(22, 25, 20, 29))
+	assert.False(t, IsSlotRangeWithin(19, 25, 20, 29))
+	assert.False(t, IsSlotRangeWithin(22, 30, 20, 29))
+	// nothing new to process, e.g., at the end of the epoch:
+	assert.True(t, IsSlotRangeWithin(30, 29, 20, 29))
+}
+
+func TestSelectFromSchedule(t *testing.T) {
+	selected := SelectFromSchedule(
+		map[string][]int64{
+			"aaa": {0, 3, 6, 9, 12},
+			"bbb": {1, 4, 7, 10, 13},
+			"ccc": {2, 5, 8, 11, 14},
+		},
+		5,
+		10,
+	)
+	assert.Equal(t,
+		map[string][]int64{"aaa": {6, 9}, "bbb": {7, 10}, "ccc": {5, 8}},
+		selected,
+	)
+
+	// the range is inclusive at both ends, and may select nothing:
+	selected = SelectFromSchedule(map[string][]int64{"aaa": {5, 10}}, 5, 10)
+	assert.Equal(t, map[string][]int64{"aaa": {5, 10}}, selected)
+	selected = SelectFromSchedule(map[string][]int64{"aaa": {5, 10}}, 6, 9)
+	assert.Equal(t, map[string][]int64{"aaa": nil}, selected)
+}